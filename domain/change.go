@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// SecretTombstone описывает секрет, удаленный или перемещенный в корзину, в ленте изменений.
+type SecretTombstone struct {
+	// Идентификатор удаленного секрета
+	ID uint64 `json:"id"`
+	// Номер изменения, которым секрет был удален
+	Revision uint64 `json:"revision"`
+	// Время удаления секрета
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SecretChanges описывает изменения секретов пользователя, сделанные после известного клиенту номера изменения.
+// Номер изменения увеличивается при каждом создании, изменении или удалении любого секрета пользователя.
+type SecretChanges struct {
+	// Созданные и измененные секреты
+	Secrets []*Secret `json:"secrets"`
+	// Удаленные секреты
+	Tombstones []*SecretTombstone `json:"tombstones"`
+	// Номер последнего изменения, включенного в ответ
+	Revision uint64 `json:"revision"`
+	// Есть ли изменения после Revision, не поместившиеся в ответ
+	More bool `json:"more"`
+}
+
+// SecretEventType тип события изменения секрета
+type SecretEventType string
+
+const (
+	// SecretCreated секрет создан или восстановлен из корзины
+	SecretCreated SecretEventType = "created"
+	// SecretUpdated секрет изменен
+	SecretUpdated SecretEventType = "updated"
+	// SecretDeleted секрет перемещен в корзину или удален окончательно
+	SecretDeleted SecretEventType = "deleted"
+)
+
+// SecretEvent описывает событие изменения секрета пользователя, рассылаемое подписанным клиентам.
+type SecretEvent struct {
+	// Тип события
+	Type SecretEventType `json:"type"`
+	// Идентификатор пользователя, владельца секрета
+	UserID UserID `json:"user_id"`
+	// Идентификатор измененного секрета
+	SecretID uint64 `json:"secret_id"`
+}
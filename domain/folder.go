@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Folder описывает папку пользователя, в которой хранятся секреты и вложенные папки
+type Folder struct {
+	// Уникальный номер папки
+	ID uint64 `db:"id" json:"id"`
+	// Идентификатор пользователя, владельца папки
+	UserID UserID `db:"user_id" json:"-"`
+	// Идентификатор родительской папки. Нулевой для папок в корне хранилища
+	ParentID uint64 `db:"parent_id" json:"parent_id"`
+	// Название папки, уникальное среди папок с тем же родителем. Хранится на сервере зашифрованным клиентом
+	Name string `db:"name" json:"name"`
+	// Время создания папки
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// Время последнего изменения папки
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
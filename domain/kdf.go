@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// Границы длины соли KDF в байтах
+	KDFMinSaltLength = 16
+	KDFMaxSaltLength = 64
+
+	// Границы параметров scrypt: N - степень двойки, объем памяти 128*N*R байт не превышает KDFMaxMemory
+	ScryptMinN = 1 << 15
+	ScryptMaxN = 1 << 20
+	ScryptMinR = 8
+	ScryptMaxR = 32
+	ScryptMaxP = 4
+
+	// Границы параметров Argon2id (память в КиБ)
+	Argon2MinMemory      = 19 * 1024
+	Argon2MaxMemory      = 1024 * 1024
+	Argon2MinIterations  = 2
+	Argon2MaxIterations  = 10
+	Argon2MaxParallelism = 16
+
+	// KDFMaxMemory максимальный объем памяти в байтах, который может занять формирование ключа
+	KDFMaxMemory = 1 << 30
+)
+
+// ErrInvalidKDFParams определяет ошибку в параметрах KDF хранилища
+var ErrInvalidKDFParams = errors.New("invalid kdf params")
+
+// CheckKDFParams проверяет, что параметры KDF находятся в допустимых границах выбранного алгоритма.
+// Параметры хранятся на сервере и передаются клиенту перед входом, поэтому клиент проверяет их так же,
+// как сервер при регистрации: слишком слабые параметры позволили бы быстро подобрать мастер-пароль
+// по хешу аутентификации, а слишком большие - исчерпать память клиента.
+func CheckKDFParams(params KDFParams) error {
+	if len(params.Salt) < KDFMinSaltLength || len(params.Salt) > KDFMaxSaltLength {
+		return fmt.Errorf("%w: salt must be %d to %d bytes", ErrInvalidKDFParams, KDFMinSaltLength, KDFMaxSaltLength)
+	}
+
+	switch KDFAlgorithm(params.Algorithm) {
+	case KDFScrypt:
+		if params.N < ScryptMinN || params.N > ScryptMaxN || params.N&(params.N-1) != 0 {
+			return fmt.Errorf("%w: scrypt N must be a power of two from %d to %d", ErrInvalidKDFParams, ScryptMinN, ScryptMaxN)
+		}
+		if params.R < ScryptMinR || params.R > ScryptMaxR {
+			return fmt.Errorf("%w: scrypt r must be from %d to %d", ErrInvalidKDFParams, ScryptMinR, ScryptMaxR)
+		}
+		if params.P < 1 || params.P > ScryptMaxP {
+			return fmt.Errorf("%w: scrypt p must be from 1 to %d", ErrInvalidKDFParams, ScryptMaxP)
+		}
+		if 128*uint64(params.N)*uint64(params.R) > KDFMaxMemory {
+			return fmt.Errorf("%w: scrypt needs more than %d bytes of memory", ErrInvalidKDFParams, KDFMaxMemory)
+		}
+	case KDFArgon2id:
+		if params.Memory < Argon2MinMemory || params.Memory > Argon2MaxMemory {
+			return fmt.Errorf("%w: argon2id memory must be from %d to %d KiB", ErrInvalidKDFParams, Argon2MinMemory, Argon2MaxMemory)
+		}
+		if params.Iterations < Argon2MinIterations || params.Iterations > Argon2MaxIterations {
+			return fmt.Errorf("%w: argon2id iterations must be from %d to %d", ErrInvalidKDFParams, Argon2MinIterations, Argon2MaxIterations)
+		}
+		if params.Parallelism < 1 || params.Parallelism > Argon2MaxParallelism {
+			return fmt.Errorf("%w: argon2id parallelism must be from 1 to %d", ErrInvalidKDFParams, Argon2MaxParallelism)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, params.Algorithm)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// SecretSort ключ сортировки списка секретов
+type SecretSort string
+
+const (
+	// SortByUpdatedAt - сортировка по времени последнего обновления
+	SortByUpdatedAt SecretSort = "updated_at"
+	// SortByCreatedAt - сортировка по времени создания
+	SortByCreatedAt SecretSort = "created_at"
+	// SortByTitle - сортировка по заголовку. Недоступна хранилищам с зашифрованными клиентом заголовками:
+	// такие секреты клиент упорядочивает сам
+	SortByTitle SecretSort = "title"
+)
+
+// SecretCursor позиция в отсортированном списке секретов: значение ключа сортировки
+// и идентификатор последнего секрета предыдущей страницы
+type SecretCursor struct {
+	// Ключ сортировки и направление, для которых получена позиция
+	Sort       SecretSort `json:"sort"`
+	Descending bool       `json:"desc,omitempty"`
+	// Значение ключа сортировки, если сортировка выполняется по времени
+	Time time.Time `json:"time,omitempty"`
+	// Значение ключа сортировки, если сортировка выполняется по заголовку
+	Title string `json:"title,omitempty"`
+	// Идентификатор секрета, упорядочивающий секреты с одинаковым значением ключа сортировки
+	ID uint64 `json:"id"`
+}
+
+// SecretQuery описывает параметры получения страницы секретов пользователя.
+// Нижние границы интервалов времени включаются в выборку, верхние - нет; нулевое время не ограничивает выборку.
+type SecretQuery struct {
+	// Типы секретов; пустой список не ограничивает выборку
+	Types []SecretType
+	// Теги, которыми должен быть отмечен каждый секрет выборки; пустой список не ограничивает выборку
+	Tags []string
+	// Токены слепого индекса, которые должны быть у каждого секрета выборки; пустой список не ограничивает выборку
+	SearchTokens [][]byte
+	// Интервал времени создания
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Интервал времени последнего обновления
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	// Ключ и направление сортировки
+	Sort       SecretSort
+	Descending bool
+	// Максимальное количество секретов на странице
+	Limit int
+	// Позиция, после которой начинается страница; nil для первой страницы
+	After *SecretCursor
+	// Секреты возвращаются без данных и ключей данных, только с размером данных
+	MetadataOnly bool
+}
+
+// SecretPage страница списка секретов
+type SecretPage struct {
+	Secrets []*Secret
+	// Позиция следующей страницы; nil, если страница последняя
+	Next *SecretCursor
+}
+
+// CursorAt возвращает позицию секрета secret в списке, отсортированном по параметрам запроса
+func (q *SecretQuery) CursorAt(secret *Secret) *SecretCursor {
+	cursor := &SecretCursor{Sort: q.Sort, Descending: q.Descending, ID: secret.ID}
+
+	switch q.Sort {
+	case SortByUpdatedAt:
+		cursor.Time = secret.UpdatedAt
+	case SortByCreatedAt:
+		cursor.Time = secret.CreatedAt
+	case SortByTitle:
+		cursor.Title = secret.Title
+	}
+
+	return cursor
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// SearchTokenSize размер токена слепого индекса: HMAC-SHA256 слова
+	SearchTokenSize = 32
+	// MaxSearchTokens максимальное количество токенов слепого индекса секрета
+	MaxSearchTokens = 256
+)
+
+// ErrInvalidSearchTokens определяет ошибку в токенах слепого индекса секрета
+var ErrInvalidSearchTokens = errors.New("invalid search tokens")
+
+// CheckSearchTokens проверяет токены слепого индекса: каждый токен должен иметь размер SearchTokenSize,
+// а токенов должно быть не больше MaxSearchTokens. Сервер не знает слов, из которых получены токены,
+// поэтому проверяется только их форма.
+func CheckSearchTokens(tokens [][]byte) error {
+	if len(tokens) > MaxSearchTokens {
+		return fmt.Errorf("%w: %d tokens, at most %d allowed", ErrInvalidSearchTokens, len(tokens), MaxSearchTokens)
+	}
+
+	for _, token := range tokens {
+		if len(token) != SearchTokenSize {
+			return fmt.Errorf("%w: token is %d bytes, want %d", ErrInvalidSearchTokens, len(token), SearchTokenSize)
+		}
+	}
+
+	return nil
+}
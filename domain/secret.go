@@ -6,6 +6,10 @@ import (
 	"time"
 )
 
+// SealedFieldPrefix префикс заголовка и метаданных секрета, зашифрованных клиентом.
+// По нему сервер отличает зашифрованные поля от сохраненных открытым текстом
+const SealedFieldPrefix = "$gk1$"
+
 // Secret описывает структуру для хранения конфиденциальных данных пользователя
 type Secret struct {
 	// Уникальный номер записи с конфиденциальной инфомарцией
@@ -16,12 +20,35 @@ type Secret struct {
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	// Время последнего обновления секрета
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	// Время перемещения секрета в корзину. Нулевое для секретов вне корзины
+	DeletedAt time.Time `db:"deleted_at" json:"deleted_at"`
+	// Время, после которого секрет автоматически удаляется. Нулевое для бессрочных секретов
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+	// Идентификатор папки, в которой находится секрет. Нулевой для секретов в корне хранилища
+	FolderID uint64 `db:"folder_id" json:"folder_id"`
+	// Номер редакции секрета, увеличивается при каждом изменении.
+	// Изменение принимается, только если клиент передал номер текущей редакции
+	Revision uint64 `db:"revision" json:"revision"`
 	// Заголовок секрета
 	Title string `db:"title" json:"title"`
 	// Метаданные, связанные с секретом
 	Metadata string `db:"metadata" json:"metadata"`
+	// Теги секрета в каноническом виде NormalizeTags. Клиент с ключом хранилища шифрует теги вместе с метаданными
+	// и выбирает секреты по токенам тегов, поэтому сервер видит теги только секретов, сохраненных до шифрования полей
+	Tags []string `db:"tags" json:"tags"`
+	// Токены слепого индекса: ключевые HMAC слов секрета, вычисленные клиентом.
+	// Сервер ищет по ним секреты, не получая самих слов
+	SearchTokens [][]byte `db:"search_tokens" json:"-"`
 	// Данные секрета в зашифрованном виде
 	Payload []byte `db:"payload" json:"payload"`
+	// Ключ данных секрета, зашифрованный ключом хранилища.
+	// Пустой для секретов, зашифрованных напрямую ключом из мастер-пароля
+	DataKey []byte `db:"data_key" json:"data_key"`
+	// Ссылка на файл с зашифрованными данными во внешнем хранилище.
+	// Пустая, если данные хранятся в Payload
+	BlobRef string `db:"blob_ref" json:"-"`
+	// Размер зашифрованных данных секрета
+	PayloadSize int64 `db:"-" json:"payload_size"`
 	// Тип секрета
 	SecretType string `db:"secret_type" json:"secret_type"`
 
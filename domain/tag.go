@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTags максимальное количество тегов секрета
+	MaxTags = 20
+	// MaxTagLength максимальная длина тега в символах
+	MaxTagLength = 64
+)
+
+// ErrInvalidTag определяет ошибку в тегах секрета
+var ErrInvalidTag = errors.New("invalid tag")
+
+// NormalizeTags приводит теги к каноническому виду: обрезает пробелы по краям, переводит в нижний регистр,
+// удаляет пустые теги и повторы и сортирует; для пустого списка возвращает nil. Теги с пробелами или запятыми внутри, слишком длинные теги
+// и больше MaxTags тегов отклоняются с ошибкой ErrInvalidTag.
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if len([]rune(tag)) > MaxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, tag, MaxTagLength)
+		}
+		if strings.ContainsFunc(tag, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			return nil, fmt.Errorf("%w: %q contains spaces or commas", ErrInvalidTag, tag)
+		}
+
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("%w: %d tags, at most %d allowed", ErrInvalidTag, len(normalized), MaxTags)
+	}
+
+	sort.Strings(normalized)
+
+	return normalized, nil
+}
+
+// HasTags проверяет, что секрет отмечен всеми тегами tags
+func (s Secret) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(s.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
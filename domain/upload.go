@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// UploadSession описывает сессию загрузки данных файлового секрета.
+// Данные передаются частями и сохраняются в секрет только после получения всех Size байт,
+// поэтому после обрыва соединения загрузку можно продолжить с позиции Received.
+type UploadSession struct {
+	// Идентификатор сессии загрузки
+	ID string `json:"id"`
+	// Идентификатор пользователя, открывшего сессию
+	UserID UserID `json:"user_id"`
+	// Идентификатор файлового секрета, данные которого загружаются
+	SecretID uint64 `json:"secret_id"`
+	// Ключ данных секрета, зашифрованный ключом хранилища
+	DataKey []byte `json:"data_key"`
+	// Общий размер загружаемых зашифрованных данных
+	Size int64 `json:"size"`
+	// Количество уже полученных байт
+	Received int64 `json:"received"`
+	// Время открытия сессии
+	CreatedAt time.Time `json:"created_at"`
+	// Время получения последней части данных
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package domain
+
+// Quota ограничения хранилища одного пользователя. Нулевое значение снимает ограничение
+type Quota struct {
+	// Максимальное количество секретов
+	MaxSecrets int64 `json:"max_secrets"`
+	// Максимальный суммарный размер зашифрованных данных секретов и их редакций
+	MaxBytes int64 `json:"max_bytes"`
+	// Максимальный размер зашифрованных данных одного секрета
+	MaxPayloadSize int64 `json:"max_payload_size"`
+}
+
+// Usage описывает место, занятое секретами пользователя, и ограничения его хранилища.
+// Секреты в корзине учитываются, пока не удалены окончательно; в размер данных входят и предыдущие редакции секретов.
+type Usage struct {
+	// Количество секретов
+	Secrets int64 `json:"secrets"`
+	// Суммарный размер зашифрованных данных секретов и их редакций
+	Bytes int64 `json:"bytes"`
+	// Ограничения хранилища
+	Quota Quota `json:"quota"`
+}
+
+// Fits сообщает, укладывается ли в ограничения сохранение еще secrets секретов с суммарным размером данных bytes,
+// данные наибольшего из которых занимают largest байт
+func (u *Usage) Fits(secrets, bytes, largest int64) bool {
+	q := u.Quota
+
+	return (q.MaxSecrets == 0 || u.Secrets+secrets <= q.MaxSecrets) &&
+		(q.MaxBytes == 0 || u.Bytes+bytes <= q.MaxBytes) &&
+		(q.MaxPayloadSize == 0 || largest <= q.MaxPayloadSize)
+}
@@ -10,10 +10,62 @@ type User struct {
 	ID UserID `json:"id"`
 	// Логин пользователя, используемый для входа в систему
 	Login string `json:"login"`
-	// Пароль пользователя. Это поле не включается в JSON представление
+	// Хеш секрета аутентификации пользователя. Это поле не включается в JSON представление
 	Password string `json:"password"`
+	// Способ формирования секрета аутентификации на клиенте
+	AuthScheme AuthScheme `json:"auth_scheme"`
+	// Параметры формирования ключа шифрования хранилища пользователя
+	KDF KDFParams `json:"kdf"`
+	// Ключ хранилища, зашифрованный ключом, сформированным из мастер-пароля.
+	// Пустой для хранилищ, созданных до перехода на конвертное шифрование
+	VaultKey []byte `json:"vault_key"`
 	// Временная метка создания аккаунта пользователя
 	CreatedAt time.Time `json:"created_at"`
 	// Временная метка последнего обновления данных аккаунта пользователя
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// AuthScheme способ формирования секрета аутентификации, который клиент передает серверу
+type AuthScheme string
+
+const (
+	// AuthSchemePassword - клиент передает мастер-пароль (аккаунты, созданные до разделения ключей)
+	AuthSchemePassword AuthScheme = ""
+	// AuthSchemeDerived - клиент передает хеш аутентификации, выведенный из мастер-пароля независимо
+	// от ключа шифрования, поэтому сервер не получает данных, позволяющих расшифровать хранилище
+	AuthSchemeDerived AuthScheme = "derived"
+)
+
+// KDFAlgorithm алгоритм формирования ключа шифрования из мастер-пароля
+type KDFAlgorithm string
+
+const (
+	// KDFLegacy - ключ сформирован scrypt с пустой солью (аккаунты, созданные до появления параметров KDF)
+	KDFLegacy KDFAlgorithm = ""
+	// KDFScrypt - ключ формируется scrypt с солью пользователя
+	KDFScrypt KDFAlgorithm = "scrypt"
+	// KDFArgon2id - ключ формируется Argon2id с солью пользователя
+	KDFArgon2id KDFAlgorithm = "argon2id"
+)
+
+// KDFParams описывает параметры формирования ключа шифрования хранилища.
+// Параметры генерируются клиентом при регистрации и хранятся на сервере,
+// чтобы их можно было усилить позже, не теряя доступ к уже зашифрованным данным.
+type KDFParams struct {
+	// Algorithm - алгоритм формирования ключа
+	Algorithm string `json:"algorithm"`
+	// Salt - случайная соль пользователя
+	Salt []byte `json:"salt"`
+	// N - параметр стоимости scrypt
+	N uint32 `json:"n,omitempty"`
+	// R - размер блока scrypt
+	R uint32 `json:"r,omitempty"`
+	// P - параметр параллелизма scrypt
+	P uint32 `json:"p,omitempty"`
+	// Memory - объем памяти Argon2id в КиБ
+	Memory uint32 `json:"memory,omitempty"`
+	// Iterations - число проходов Argon2id
+	Iterations uint32 `json:"iterations,omitempty"`
+	// Parallelism - число потоков Argon2id
+	Parallelism uint32 `json:"parallelism,omitempty"`
+}
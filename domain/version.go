@@ -0,0 +1,11 @@
+package domain
+
+// SecretVersion описывает сохраненную редакцию секрета.
+// Редакция сохраняется перед каждым изменением секрета и содержит его данные в зашифрованном виде,
+// поэтому расшифровывается тем же ключом данных и с теми же дополнительными данными шифрования, что и секрет.
+type SecretVersion struct {
+	// Уникальный номер редакции
+	ID uint64 `db:"id" json:"id"`
+	// Секрет в состоянии на момент редакции. UpdatedAt - время, с которого действовала редакция
+	Secret *Secret `json:"secret"`
+}
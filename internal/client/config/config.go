@@ -3,8 +3,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/spf13/viper"
 	"strings"
+	"time"
 )
 
 // Config представляет основную конфигурацию клиентского приложения.
@@ -13,12 +16,22 @@ type Config struct {
 	BuildDate     string // Информация о сборке (дата)
 	BuildVersion  string // Информация о сборке (версия)
 	ServerAddress string // Address определяет адрес сервера.
+
+	KDF           domain.KDFAlgorithm // KDF алгоритм формирования ключа для новых и обновляемых хранилищ.
+	KDFUnlockTime time.Duration       // KDFUnlockTime целевое время разблокировки хранилища при калибровке Argon2id.
+
+	// LegacyAuth разрешает вход в аккаунты, созданные до разделения ключей, с передачей мастер-пароля серверу.
+	// По умолчанию клиент отказывается от такого входа, даже если его запрашивает сервер.
+	LegacyAuth bool
 }
 
 // LoadConfig инициализирует и возвращает новый экземпляр конфигурации.
 // Ошибка возвращается, если обязательные конфигурационные параметры не заданы.
 func LoadConfig() (*Config, error) {
 	viper.SetDefault("address", "127.0.0.1:50051")
+	viper.SetDefault("kdf", string(domain.KDFArgon2id))
+	viper.SetDefault("kdf-unlock-time", 500*time.Millisecond)
+	viper.SetDefault("legacy-auth", false)
 	viper.SetEnvPrefix("GOPHKEEPER")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
@@ -28,7 +41,15 @@ func LoadConfig() (*Config, error) {
 		return nil, errors.New("server address is not set: set GOPHKEEPER_ADDRESS environment variable")
 	}
 
+	kdf := domain.KDFAlgorithm(viper.GetString("kdf"))
+	if kdf != domain.KDFScrypt && kdf != domain.KDFArgon2id {
+		return nil, fmt.Errorf("unsupported kdf %q: set GOPHKEEPER_KDF to %s or %s", kdf, domain.KDFScrypt, domain.KDFArgon2id)
+	}
+
 	return &Config{
 		ServerAddress: address,
+		KDF:           kdf,
+		KDFUnlockTime: viper.GetDuration("kdf-unlock-time"),
+		LegacyAuth:    viper.GetBool("legacy-auth"),
 	}, nil
 }
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -21,6 +23,44 @@ func TestLoadConfig(t *testing.T) {
 			},
 			expectedConfig: &Config{
 				ServerAddress: "127.0.0.1:5000",
+				KDF:           domain.KDFArgon2id,
+				KDFUnlockTime: 500 * time.Millisecond,
+			},
+		},
+		{
+			name: "Scrypt_KDF",
+			setupEnv: func() {
+				os.Setenv("GOPHKEEPER_ADDRESS", "127.0.0.1:5000")
+				os.Setenv("GOPHKEEPER_KDF", "scrypt")
+				os.Setenv("GOPHKEEPER_KDF_UNLOCK_TIME", "1s")
+			},
+			expectedConfig: &Config{
+				ServerAddress: "127.0.0.1:5000",
+				KDF:           domain.KDFScrypt,
+				KDFUnlockTime: time.Second,
+			},
+		},
+		{
+			name: "Unsupported_KDF",
+			setupEnv: func() {
+				os.Setenv("GOPHKEEPER_ADDRESS", "127.0.0.1:5000")
+				os.Setenv("GOPHKEEPER_KDF", "md5")
+			},
+			expectedError: `unsupported kdf "md5": set GOPHKEEPER_KDF to scrypt or argon2id`,
+		},
+		{
+			name: "Legacy_Auth",
+			setupEnv: func() {
+				os.Setenv("GOPHKEEPER_ADDRESS", "127.0.0.1:5000")
+				os.Setenv("GOPHKEEPER_KDF", "argon2id")
+				os.Setenv("GOPHKEEPER_KDF_UNLOCK_TIME", "500ms")
+				os.Setenv("GOPHKEEPER_LEGACY_AUTH", "true")
+			},
+			expectedConfig: &Config{
+				ServerAddress: "127.0.0.1:5000",
+				KDF:           domain.KDFArgon2id,
+				KDFUnlockTime: 500 * time.Millisecond,
+				LegacyAuth:    true,
 			},
 		},
 	}
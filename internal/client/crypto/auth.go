@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+const (
+	// Контексты HKDF, разделяющие ключ аутентификации и ключ шифрования
+	authKeyInfo       = "gophkeeper auth key"
+	encryptionKeyInfo = "gophkeeper encryption key"
+)
+
+// MasterKeys ключи, выведенные из мастер-пароля
+type MasterKeys struct {
+	// AuthHash хеш аутентификации, который передается серверу вместо мастер-пароля
+	AuthHash string
+	// EncryptionKey ключ, которым шифруется ключ хранилища. На сервер не передается
+	EncryptionKey []byte
+}
+
+// DeriveKeys - Генерация хеша аутентификации и ключа шифрования из мастер-пароля.
+// Из мастер-пароля по параметрам KDF формируется мастер-ключ, из которого через HKDF-SHA256
+// выводятся два независимых ключа, поэтому по хешу аутентификации нельзя получить ключ шифрования.
+// Для аккаунтов со способом аутентификации AuthSchemePassword хешем аутентификации служит сам пароль.
+func DeriveKeys(password string, params domain.KDFParams, scheme domain.AuthScheme) (MasterKeys, error) {
+	master, err := DeriveKeyWithParams(password, params)
+	if err != nil {
+		return MasterKeys{}, err
+	}
+
+	if scheme == domain.AuthSchemePassword {
+		return MasterKeys{AuthHash: password, EncryptionKey: master}, nil
+	}
+
+	authKey, err := expandKey(master, authKeyInfo)
+	if err != nil {
+		return MasterKeys{}, err
+	}
+
+	encryptionKey, err := expandKey(master, encryptionKeyInfo)
+	if err != nil {
+		return MasterKeys{}, err
+	}
+
+	return MasterKeys{AuthHash: hex.EncodeToString(authKey), EncryptionKey: encryptionKey}, nil
+}
+
+// expandKey выводит из мастер-ключа ключ длины keyLength для указанного контекста
+func expandKey(master []byte, info string) ([]byte, error) {
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, master, []byte(info)), key); err != nil {
+		return nil, fmt.Errorf("expandKey(): %w", err)
+	}
+	return key, nil
+}
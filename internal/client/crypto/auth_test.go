@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/romanp1989/gophkeeper/domain"
+	"testing"
+)
+
+func TestDeriveKeys(t *testing.T) {
+	params := domain.KDFParams{
+		Algorithm:   string(domain.KDFArgon2id),
+		Salt:        []byte("0123456789abcdef"),
+		Memory:      argon2MinMemory,
+		Iterations:  argon2MinIterations,
+		Parallelism: 1,
+	}
+
+	keys, err := DeriveKeys("password", params, domain.AuthSchemeDerived)
+	if err != nil {
+		t.Fatalf("DeriveKeys() error = %v", err)
+	}
+
+	master, _ := DeriveKeyWithParams("password", params)
+
+	if len(keys.AuthHash) != 2*keyLength {
+		t.Errorf("DeriveKeys() auth hash length = %d, want %d", len(keys.AuthHash), 2*keyLength)
+	}
+	if _, err = hex.DecodeString(keys.AuthHash); err != nil {
+		t.Errorf("DeriveKeys() auth hash is not hex: %v", err)
+	}
+	if len(keys.EncryptionKey) != keyLength {
+		t.Errorf("DeriveKeys() encryption key length = %d, want %d", len(keys.EncryptionKey), keyLength)
+	}
+	if bytes.Equal(keys.EncryptionKey, master) || keys.AuthHash == hex.EncodeToString(master) {
+		t.Errorf("DeriveKeys() returned the master key")
+	}
+	if keys.AuthHash == hex.EncodeToString(keys.EncryptionKey) {
+		t.Errorf("DeriveKeys() auth hash equals encryption key")
+	}
+
+	again, _ := DeriveKeys("password", params, domain.AuthSchemeDerived)
+	if again.AuthHash != keys.AuthHash || !bytes.Equal(again.EncryptionKey, keys.EncryptionKey) {
+		t.Errorf("DeriveKeys() is not deterministic")
+	}
+
+	other, _ := DeriveKeys("other password", params, domain.AuthSchemeDerived)
+	if other.AuthHash == keys.AuthHash {
+		t.Errorf("DeriveKeys() returned the same auth hash for different passwords")
+	}
+}
+
+func TestDeriveKeys_LegacyScheme(t *testing.T) {
+	keys, err := DeriveKeys("password", domain.KDFParams{}, domain.AuthSchemePassword)
+	if err != nil {
+		t.Fatalf("DeriveKeys() error = %v", err)
+	}
+
+	master, _ := DeriveKeyWithParams("password", domain.KDFParams{})
+
+	if keys.AuthHash != "password" {
+		t.Errorf("DeriveKeys() auth hash = %q, want password", keys.AuthHash)
+	}
+	if !bytes.Equal(keys.EncryptionKey, master) {
+		t.Errorf("DeriveKeys() encryption key differs from master key")
+	}
+}
+
+func TestDeriveKeys_EmptyPassword(t *testing.T) {
+	if _, err := DeriveKeys("", domain.KDFParams{}, domain.AuthSchemeDerived); err == nil {
+		t.Errorf("DeriveKeys() expected error for empty password")
+	}
+}
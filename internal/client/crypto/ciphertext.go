@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+const (
+	// ciphertextVersion1 версия бинарного формата зашифрованных данных
+	ciphertextVersion1 byte = 1
+
+	// ciphertextVersion2 версия формата с дополнением открытого текста: в заголовок добавлена схема дополнения
+	ciphertextVersion2 byte = 2
+
+	// algAES256GCM идентификатор алгоритма AES-256-GCM
+	algAES256GCM byte = 1
+
+	// nonceLength стандартный размер nonce для AES-GCM
+	nonceLength = 12
+
+	// headerLength длина заголовка: версия, идентификатор алгоритма и nonce
+	headerLength = 2 + nonceLength
+
+	// paddedHeaderLength длина заголовка версии 2: версия, идентификатор алгоритма, схема дополнения и nonce
+	paddedHeaderLength = 3 + nonceLength
+)
+
+// ErrUnsupportedVersion указывает, что данные зашифрованы в неизвестной версии формата.
+var ErrUnsupportedVersion = errors.New("unsupported ciphertext version")
+
+// ErrUnsupportedAlgorithm указывает, что данные зашифрованы неизвестным алгоритмом.
+var ErrUnsupportedAlgorithm = errors.New("unsupported cipher algorithm")
+
+// Seal - Шифрование данных в бинарный формат: версия, идентификатор алгоритма, nonce и шифротекст.
+// Дополнительные данные aad не шифруются, но аутентифицируются: расшифровать данные можно
+// только с теми же aad, поэтому шифротекст нельзя незаметно перенести в другой контекст.
+func Seal(plaintext, key, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, headerLength, headerLength+len(plaintext)+gcm.Overhead())
+	sealed[0] = ciphertextVersion1
+	sealed[1] = algAES256GCM
+
+	nonce := sealed[2:headerLength]
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(sealed, nonce, plaintext, aad), nil
+}
+
+// SealPadded - Шифрование данных, как Seal, с предварительным дополнением открытого текста до размера корзины,
+// чтобы длина шифротекста не раскрывала точную длину данных. Схема дополнения записывается в заголовок
+// и аутентифицируется вместе с aad.
+func SealPadded(plaintext, key, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	padded := pad(plaintext)
+
+	sealed := make([]byte, paddedHeaderLength, paddedHeaderLength+len(padded)+gcm.Overhead())
+	sealed[0] = ciphertextVersion2
+	sealed[1] = algAES256GCM
+	sealed[2] = paddingBuckets
+
+	nonce := sealed[3:paddedHeaderLength]
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(sealed, nonce, padded, append(sealed[:3:3], aad...)), nil
+}
+
+// Open - Расшифровка данных, зашифрованных Seal или SealPadded; дополнение удаляется по схеме из заголовка.
+// Данные в прежнем текстовом формате Encrypt расшифровываются без проверки aad.
+func Open(sealed, key, aad []byte) ([]byte, error) {
+	if IsLegacy(sealed) {
+		plaintext, err := Decrypt(string(sealed), key)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(plaintext), nil
+	}
+
+	if len(sealed) < headerLength {
+		return nil, ErrCiphertextTooShort
+	}
+
+	if sealed[0] != ciphertextVersion1 && sealed[0] != ciphertextVersion2 {
+		return nil, ErrUnsupportedVersion
+	}
+
+	if sealed[1] != algAES256GCM {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if sealed[0] == ciphertextVersion1 {
+		return gcm.Open(nil, sealed[2:headerLength], sealed[headerLength:], aad)
+	}
+
+	if len(sealed) < paddedHeaderLength {
+		return nil, ErrCiphertextTooShort
+	}
+
+	padding := sealed[2]
+	if err = checkPadding(padding); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[3:paddedHeaderLength], sealed[paddedHeaderLength:], append(sealed[:3:3], aad...))
+	if err != nil || padding == paddingNone {
+		return plaintext, err
+	}
+
+	return unpad(plaintext)
+}
+
+// IsLegacy - Проверка, зашифрованы ли данные в прежнем текстовом формате Encrypt.
+// Бинарный формат начинается с номера версии, который не совпадает ни с одним символом hex-строки.
+func IsLegacy(sealed []byte) bool {
+	if len(sealed) == 0 {
+		return false
+	}
+
+	c := sealed[0]
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f')
+}
+
+// newGCM создает AES-GCM для ключа key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,219 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSeal(t *testing.T) {
+	key, _ := NewKey()
+	otherKey, _ := NewKey()
+	plaintext := []byte("secret data")
+	aad := []byte("secret:1")
+
+	type testCase struct {
+		name    string
+		key     []byte
+		aad     []byte
+		tamper  func(sealed []byte) []byte
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			name: "valid",
+			key:  key,
+			aad:  aad,
+		},
+		{
+			name:    "wrong_key",
+			key:     otherKey,
+			aad:     aad,
+			wantErr: errors.New("cipher: message authentication failed"),
+		},
+		{
+			name:    "wrong_aad",
+			key:     key,
+			aad:     []byte("secret:2"),
+			wantErr: errors.New("cipher: message authentication failed"),
+		},
+		{
+			name: "unsupported_version",
+			key:  key,
+			aad:  aad,
+			tamper: func(sealed []byte) []byte {
+				sealed[0] = 9
+				return sealed
+			},
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name: "unsupported_algorithm",
+			key:  key,
+			aad:  aad,
+			tamper: func(sealed []byte) []byte {
+				sealed[1] = 2
+				return sealed
+			},
+			wantErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name: "too_short",
+			key:  key,
+			aad:  aad,
+			tamper: func(sealed []byte) []byte {
+				return sealed[:headerLength-1]
+			},
+			wantErr: ErrCiphertextTooShort,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sealed, err := Seal(plaintext, key, aad)
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+
+			if len(sealed) != headerLength+len(plaintext)+16 {
+				t.Errorf("Seal() length = %d, want %d", len(sealed), headerLength+len(plaintext)+16)
+			}
+			if IsLegacy(sealed) {
+				t.Errorf("IsLegacy() = true for sealed data")
+			}
+
+			if tc.tamper != nil {
+				sealed = tc.tamper(sealed)
+			}
+
+			opened, err := Open(sealed, tc.key, tc.aad)
+			if tc.wantErr != nil {
+				if err == nil || (!errors.Is(err, tc.wantErr) && err.Error() != tc.wantErr.Error()) {
+					t.Fatalf("Open() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("Open() = %q, want %q", opened, plaintext)
+			}
+		})
+	}
+}
+
+func TestSealPadded(t *testing.T) {
+	key, _ := NewKey()
+	aad := []byte("secret:1")
+
+	type testCase struct {
+		name    string
+		size    int
+		tamper  func(sealed []byte) []byte
+		wantLen int
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			name:    "empty",
+			size:    0,
+			wantLen: paddedHeaderLength + 32 + 16,
+		},
+		{
+			name:    "small",
+			size:    31,
+			wantLen: paddedHeaderLength + 32 + 16,
+		},
+		{
+			name:    "power_of_two",
+			size:    32,
+			wantLen: paddedHeaderLength + 64 + 16,
+		},
+		{
+			name:    "large",
+			size:    StreamSegmentSize + 10,
+			wantLen: paddedHeaderLength + 2*StreamSegmentSize + 16,
+		},
+		{
+			name: "padding_removed",
+			size: 10,
+			tamper: func(sealed []byte) []byte {
+				sealed[2] = paddingNone
+				return sealed
+			},
+			wantLen: paddedHeaderLength + 32 + 16,
+			wantErr: errors.New("cipher: message authentication failed"),
+		},
+		{
+			name: "unsupported_padding",
+			size: 10,
+			tamper: func(sealed []byte) []byte {
+				sealed[2] = 9
+				return sealed
+			},
+			wantLen: paddedHeaderLength + 32 + 16,
+			wantErr: ErrUnsupportedPadding,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plaintext := bytes.Repeat([]byte{'x'}, tc.size)
+
+			sealed, err := SealPadded(plaintext, key, aad)
+			if err != nil {
+				t.Fatalf("SealPadded() error = %v", err)
+			}
+			if len(sealed) != tc.wantLen {
+				t.Errorf("SealPadded() length = %d, want %d", len(sealed), tc.wantLen)
+			}
+			if IsLegacy(sealed) || IsStream(sealed) {
+				t.Errorf("SealPadded() output detected as legacy or stream")
+			}
+
+			if tc.tamper != nil {
+				sealed = tc.tamper(sealed)
+			}
+
+			opened, err := Open(sealed, key, aad)
+			if tc.wantErr != nil {
+				if err == nil || (!errors.Is(err, tc.wantErr) && err.Error() != tc.wantErr.Error()) {
+					t.Fatalf("Open() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if !bytes.Equal(opened, plaintext) {
+				t.Errorf("Open() returned %d bytes, want %d", len(opened), len(plaintext))
+			}
+		})
+	}
+}
+
+func TestOpen_Legacy(t *testing.T) {
+	key, _ := NewKey()
+
+	encrypted, err := Encrypt("legacy data", key)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	if !IsLegacy([]byte(encrypted)) {
+		t.Errorf("IsLegacy() = false for Encrypt output")
+	}
+
+	opened, err := Open([]byte(encrypted), key, []byte("ignored"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if string(opened) != "legacy data" {
+		t.Errorf("Open() = %q, want %q", opened, "legacy data")
+	}
+}
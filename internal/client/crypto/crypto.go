@@ -6,6 +6,11 @@
 // Основные возможности пакета:
 //
 // - DeriveKey: генерация криптографического ключа из пароля и соли.
+// - NewKDFParams: генерация параметров KDF (scrypt или Argon2id) со случайной солью для нового хранилища.
+// - Calibrate: подбор параметров Argon2id под целевое время разблокировки хранилища.
+// - DeriveKeyWithParams: генерация ключа по параметрам KDF, сохраненным на сервере.
+// - NewKey, WrapKey, UnwrapKey: генерация и шифрование ключа хранилища и ключей данных секретов.
+// - WrapVaultKey, UnwrapVaultKey: шифрование ключа хранилища с отметкой о шифровании текстовых полей секретов.
 // - Encrypt: шифрование строки с использованием AES-GCM.
 // - Decrypt: расшифровка строки, зашифрованной с помощью Encrypt.
 // - Обработка ошибок, связанных с недостаточной длиной зашифрованной строки.
@@ -21,6 +26,19 @@ import (
 	"io"
 )
 
+const (
+	// Параметры scrypt по умолчанию для новых хранилищ
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+
+	// keyLength длина ключа AES-256
+	keyLength = 32
+
+	// saltLength длина случайной соли пользователя
+	saltLength = 16
+)
+
 // ErrCiphertextTooShort указывает, что переданная зашифрованная строка
 // (ciphertext) недостаточно длинная для корректной расшифровки.
 // Как правило, требуется минимум 12 байт для nonce в AES-GCM.
@@ -31,7 +49,7 @@ func DeriveKey(password, salt string) ([]byte, error) {
 	if password == "" {
 		return []byte(password), errors.New("empty password")
 	}
-	return scrypt.Key([]byte(password), []byte(salt), 32768, 8, 1, 32)
+	return scrypt.Key([]byte(password), []byte(salt), scryptN, scryptR, scryptP, keyLength)
 }
 
 // Encrypt - Шифрование строки
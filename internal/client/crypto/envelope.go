@@ -0,0 +1,101 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidKeyLength указывает, что расшифрованный ключ имеет длину, отличную от длины ключа AES-256.
+var ErrInvalidKeyLength = errors.New("invalid key length")
+
+// sealedVaultAAD дополнительные данные шифрования ключа хранилища, текстовые поля секретов которого зашифрованы.
+// Отметка проверяется при расшифровке ключа, поэтому сервер не может снять ее, не зная ключа шифрования.
+const sealedVaultAAD = "gophkeeper vault key: sealed fields"
+
+// NewKey - Генерация случайного ключа AES-256 (ключ хранилища или ключ данных секрета)
+func NewKey() ([]byte, error) {
+	key := make([]byte, keyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// WrapKey - Шифрование ключа key ключом kek
+func WrapKey(key, kek []byte) ([]byte, error) {
+	wrapped, err := wrapKey(key, kek, nil)
+	if err != nil {
+		return nil, fmt.Errorf("WrapKey(): %w", err)
+	}
+
+	return wrapped, nil
+}
+
+// UnwrapKey - Расшифровка ключа, зашифрованного WrapKey
+func UnwrapKey(wrapped, kek []byte) ([]byte, error) {
+	key, err := unwrapKey(wrapped, kek, nil)
+	if err != nil {
+		return nil, fmt.Errorf("UnwrapKey(): %w", err)
+	}
+
+	return key, nil
+}
+
+// WrapVaultKey - Шифрование ключа хранилища vaultKey ключом kek. Если sealed равен true, ключ шифруется
+// с отметкой о том, что текстовые поля всех секретов хранилища зашифрованы.
+func WrapVaultKey(vaultKey, kek []byte, sealed bool) ([]byte, error) {
+	var aad []byte
+	if sealed {
+		aad = []byte(sealedVaultAAD)
+	}
+
+	wrapped, err := wrapKey(vaultKey, kek, aad)
+	if err != nil {
+		return nil, fmt.Errorf("WrapVaultKey(): %w", err)
+	}
+
+	return wrapped, nil
+}
+
+// UnwrapVaultKey - Расшифровка ключа хранилища, зашифрованного WrapVaultKey или WrapKey.
+// Возвращает ключ и признак отметки о том, что текстовые поля секретов хранилища зашифрованы.
+// Прежний текстовый формат не аутентифицирует дополнительные данные, поэтому отметки в нем нет.
+func UnwrapVaultKey(wrapped, kek []byte) ([]byte, bool, error) {
+	if !IsLegacy(wrapped) {
+		if key, err := unwrapKey(wrapped, kek, []byte(sealedVaultAAD)); err == nil {
+			return key, true, nil
+		}
+	}
+
+	key, err := unwrapKey(wrapped, kek, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("UnwrapVaultKey(): %w", err)
+	}
+
+	return key, false, nil
+}
+
+// wrapKey шифрует ключ key ключом kek с дополнительными данными aad
+func wrapKey(key, kek, aad []byte) ([]byte, error) {
+	if len(key) != keyLength {
+		return nil, ErrInvalidKeyLength
+	}
+
+	return Seal(key, kek, aad)
+}
+
+// unwrapKey расшифровывает ключ, зашифрованный wrapKey с дополнительными данными aad
+func unwrapKey(wrapped, kek, aad []byte) ([]byte, error) {
+	key, err := Open(wrapped, kek, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != keyLength {
+		return nil, ErrInvalidKeyLength
+	}
+
+	return key, nil
+}
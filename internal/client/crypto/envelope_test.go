@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestNewKey(t *testing.T) {
+	first, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	second, err := NewKey()
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	if len(first) != keyLength {
+		t.Errorf("NewKey() length = %d, want %d", len(first), keyLength)
+	}
+	if bytes.Equal(first, second) {
+		t.Errorf("NewKey() returned the same key twice")
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	kek, _ := NewKey()
+	otherKek, _ := NewKey()
+	key, _ := NewKey()
+
+	type testCase struct {
+		name      string
+		key       []byte
+		unwrapKek []byte
+		wantErr   bool
+	}
+
+	testCases := []testCase{
+		{
+			name:      "valid_key",
+			key:       key,
+			unwrapKek: kek,
+			wantErr:   false,
+		},
+		{
+			name:      "wrong_kek",
+			key:       key,
+			unwrapKek: otherKek,
+			wantErr:   true,
+		},
+		{
+			name:      "short_key",
+			key:       key[:16],
+			unwrapKek: kek,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wrapped, err := WrapKey(tc.key, kek)
+			if err == nil {
+				var unwrapped []byte
+				unwrapped, err = UnwrapKey(wrapped, tc.unwrapKek)
+				if err == nil && !bytes.Equal(unwrapped, tc.key) {
+					t.Errorf("UnwrapKey() = %x, want %x", unwrapped, tc.key)
+				}
+			}
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("WrapKey()/UnwrapKey() error = %v, wantErr = %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnwrapKey_InvalidLength(t *testing.T) {
+	kek, _ := NewKey()
+
+	wrapped, err := Encrypt("short", kek)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	_, err = UnwrapKey([]byte(wrapped), kek)
+	if !errors.Is(err, ErrInvalidKeyLength) {
+		t.Errorf("UnwrapKey() error = %v, want %v", err, ErrInvalidKeyLength)
+	}
+}
+
+func TestUnwrapKey_Legacy(t *testing.T) {
+	kek, _ := NewKey()
+	key, _ := NewKey()
+
+	wrapped, err := Encrypt(string(key), kek)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	unwrapped, err := UnwrapKey([]byte(wrapped), kek)
+	if err != nil {
+		t.Fatalf("UnwrapKey() error = %v", err)
+	}
+	if !bytes.Equal(unwrapped, key) {
+		t.Errorf("UnwrapKey() = %x, want %x", unwrapped, key)
+	}
+}
+
+func TestWrapVaultKey(t *testing.T) {
+	kek, _ := NewKey()
+	otherKek, _ := NewKey()
+	key, _ := NewKey()
+	legacy, _ := Encrypt(string(key), kek)
+	plain, _ := WrapKey(key, kek)
+
+	type testCase struct {
+		name       string
+		wrap       func() ([]byte, error)
+		unwrapKek  []byte
+		wantSealed bool
+		wantErr    bool
+	}
+
+	testCases := []testCase{
+		{
+			name:       "sealed",
+			wrap:       func() ([]byte, error) { return WrapVaultKey(key, kek, true) },
+			unwrapKek:  kek,
+			wantSealed: true,
+		},
+		{
+			name:       "not_sealed",
+			wrap:       func() ([]byte, error) { return WrapVaultKey(key, kek, false) },
+			unwrapKek:  kek,
+			wantSealed: false,
+		},
+		{
+			name:       "wrap_key",
+			wrap:       func() ([]byte, error) { return plain, nil },
+			unwrapKek:  kek,
+			wantSealed: false,
+		},
+		{
+			name:       "legacy",
+			wrap:       func() ([]byte, error) { return []byte(legacy), nil },
+			unwrapKek:  kek,
+			wantSealed: false,
+		},
+		{
+			name:      "wrong_kek",
+			wrap:      func() ([]byte, error) { return WrapVaultKey(key, kek, true) },
+			unwrapKek: otherKek,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wrapped, err := tc.wrap()
+			if err != nil {
+				t.Fatalf("WrapVaultKey() error = %v", err)
+			}
+
+			unwrapped, sealed, err := UnwrapVaultKey(wrapped, tc.unwrapKek)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("UnwrapVaultKey() error = %v, wantErr = %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if !bytes.Equal(unwrapped, key) {
+				t.Errorf("UnwrapVaultKey() = %x, want %x", unwrapped, key)
+			}
+			if sealed != tc.wantSealed {
+				t.Errorf("UnwrapVaultKey() sealed = %v, want %v", sealed, tc.wantSealed)
+			}
+		})
+	}
+}
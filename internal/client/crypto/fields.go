@@ -0,0 +1,60 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	"strings"
+)
+
+// ErrFieldNotSealed указывает, что значение текстового поля не зашифровано SealField.
+var ErrFieldNotSealed = errors.New("field is not sealed")
+
+const (
+	// fieldKeyInfo контекст HKDF ключа шифрования заголовков и метаданных секретов
+	fieldKeyInfo = "gophkeeper field key"
+	// sealedFieldPrefix префикс зашифрованного значения текстового поля
+	sealedFieldPrefix = domain.SealedFieldPrefix
+)
+
+// DeriveFieldKey выводит из ключа хранилища ключ шифрования текстовых полей секретов: заголовка и метаданных.
+func DeriveFieldKey(vaultKey []byte) ([]byte, error) {
+	return expandKey(vaultKey, fieldKeyInfo)
+}
+
+// SealField - Шифрование значения текстового поля в строку: префикс sealedFieldPrefix и данные SealPadded в base64.
+// Пустое значение тоже шифруется, чтобы сервер не отличал заполненные поля от пустых.
+func SealField(value string, key, aad []byte) (string, error) {
+	sealed, err := SealPadded([]byte(value), key, aad)
+	if err != nil {
+		return "", fmt.Errorf("SealField(): %w", err)
+	}
+
+	return sealedFieldPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenField - Расшифровка значения текстового поля, зашифрованного SealField.
+// Значение без префикса sealedFieldPrefix не расшифровывается и приводит к ошибке ErrFieldNotSealed.
+func OpenField(value string, key, aad []byte) (string, error) {
+	if !IsSealedField(value) {
+		return "", fmt.Errorf("OpenField(): %w", ErrFieldNotSealed)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, sealedFieldPrefix))
+	if err != nil {
+		return "", fmt.Errorf("OpenField(): %w", err)
+	}
+
+	plaintext, err := Open(sealed, key, aad)
+	if err != nil {
+		return "", fmt.Errorf("OpenField(): %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// IsSealedField - Проверка, зашифровано ли значение текстового поля SealField
+func IsSealedField(value string) bool {
+	return strings.HasPrefix(value, sealedFieldPrefix)
+}
@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeriveFieldKey(t *testing.T) {
+	vaultKey, _ := NewKey()
+
+	fieldKey, err := DeriveFieldKey(vaultKey)
+	if err != nil {
+		t.Fatalf("DeriveFieldKey() error = %v", err)
+	}
+	searchKey, _ := DeriveSearchKey(vaultKey)
+
+	if len(fieldKey) != keyLength {
+		t.Errorf("DeriveFieldKey() length = %d, want %d", len(fieldKey), keyLength)
+	}
+	if bytes.Equal(fieldKey, vaultKey) || bytes.Equal(fieldKey, searchKey) {
+		t.Errorf("DeriveFieldKey() returned a key used for another purpose")
+	}
+}
+
+func TestSealField(t *testing.T) {
+	key, _ := NewKey()
+	otherKey, _ := NewKey()
+	aad := []byte("title")
+
+	type testCase struct {
+		name    string
+		value   string
+		openKey []byte
+		openAAD []byte
+		wantErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name:    "title",
+			value:   "Online bank",
+			openKey: key,
+			openAAD: aad,
+			wantErr: false,
+		},
+		{
+			name:    "empty",
+			value:   "",
+			openKey: key,
+			openAAD: aad,
+			wantErr: false,
+		},
+		{
+			name:    "wrong_key",
+			value:   "Online bank",
+			openKey: otherKey,
+			openAAD: aad,
+			wantErr: true,
+		},
+		{
+			name:    "wrong_aad",
+			value:   "Online bank",
+			openKey: key,
+			openAAD: []byte("metadata"),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sealed, err := SealField(tc.value, key, aad)
+			if err != nil {
+				t.Fatalf("SealField() error = %v", err)
+			}
+			if !IsSealedField(sealed) {
+				t.Fatalf("SealField() = %q, want sealed value", sealed)
+			}
+			if tc.value != "" && strings.Contains(sealed, tc.value) {
+				t.Errorf("SealField() = %q contains the plaintext", sealed)
+			}
+
+			opened, err := OpenField(sealed, tc.openKey, tc.openAAD)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("OpenField() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && opened != tc.value {
+				t.Errorf("OpenField() = %q, want %q", opened, tc.value)
+			}
+		})
+	}
+}
+
+func TestOpenField_NotSealed(t *testing.T) {
+	key, _ := NewKey()
+
+	if _, err := OpenField("Online bank", key, nil); !errors.Is(err, ErrFieldNotSealed) {
+		t.Errorf("OpenField() error = %v, want %v", err, ErrFieldNotSealed)
+	}
+	if _, err := OpenField("", key, nil); !errors.Is(err, ErrFieldNotSealed) {
+		t.Errorf("OpenField() error = %v, want %v", err, ErrFieldNotSealed)
+	}
+
+	if _, err := OpenField(sealedFieldPrefix+"not base64!", key, nil); err == nil {
+		t.Errorf("OpenField() expected error for malformed value")
+	}
+}
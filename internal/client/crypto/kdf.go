@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// Параметры Argon2id по умолчанию для новых хранилищ (память в КиБ)
+	argon2Memory      = 64 * 1024
+	argon2Iterations  = 3
+	argon2Parallelism = 4
+
+	// Границы, в которых Calibrate подбирает параметры Argon2id, совпадают с допустимыми параметрами хранилища
+	argon2MinMemory     = domain.Argon2MinMemory
+	argon2MaxMemory     = domain.Argon2MaxMemory
+	argon2MinIterations = domain.Argon2MinIterations
+	argon2MaxIterations = domain.Argon2MaxIterations
+)
+
+// DefaultUnlockTime целевое время разблокировки хранилища, используемое при калибровке Argon2id
+const DefaultUnlockTime = 500 * time.Millisecond
+
+// NewKDFParams - Генерация параметров KDF по умолчанию для выбранного алгоритма со случайной солью
+func NewKDFParams(algorithm domain.KDFAlgorithm) (domain.KDFParams, error) {
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return domain.KDFParams{}, err
+	}
+
+	switch algorithm {
+	case domain.KDFScrypt:
+		return domain.KDFParams{
+			Algorithm: string(domain.KDFScrypt),
+			Salt:      salt,
+			N:         scryptN,
+			R:         scryptR,
+			P:         scryptP,
+		}, nil
+	case domain.KDFArgon2id:
+		return domain.KDFParams{
+			Algorithm:   string(domain.KDFArgon2id),
+			Salt:        salt,
+			Memory:      argon2Memory,
+			Iterations:  argon2Iterations,
+			Parallelism: argon2Parallelism,
+		}, nil
+	default:
+		return domain.KDFParams{}, fmt.Errorf("unsupported kdf algorithm %q", algorithm)
+	}
+}
+
+// Calibrate - Подбор параметров Argon2id, при которых формирование ключа на текущей машине
+// занимает примерно target. Сначала при необходимости уменьшается объем памяти,
+// затем подбирается число итераций, а при достижении их предела увеличивается память.
+func Calibrate(target time.Duration) (domain.KDFParams, error) {
+	params, err := NewKDFParams(domain.KDFArgon2id)
+	if err != nil {
+		return domain.KDFParams{}, err
+	}
+
+	params.Iterations = 1
+	elapsed := measureKDF(params)
+
+	for elapsed > target && params.Memory/2 >= argon2MinMemory {
+		params.Memory /= 2
+		elapsed = measureKDF(params)
+	}
+
+	// Время Argon2id растет примерно линейно от числа итераций и объема памяти
+	iterations := uint32(math.Ceil(float64(target) / float64(max(elapsed, time.Nanosecond))))
+	for iterations > argon2MaxIterations && params.Memory*2 <= argon2MaxMemory {
+		params.Memory *= 2
+		iterations = (iterations + 1) / 2
+	}
+
+	params.Iterations = min(max(iterations, argon2MinIterations), argon2MaxIterations)
+
+	return params, nil
+}
+
+// NeedsUpgrade - Проверка, сформирован ли ключ хранилища не тем алгоритмом, который выбран на клиенте
+func NeedsUpgrade(params domain.KDFParams, preferred domain.KDFAlgorithm) bool {
+	return preferred != domain.KDFLegacy && domain.KDFAlgorithm(params.Algorithm) != preferred
+}
+
+// DeriveKeyWithParams - Генерация ключа из мастер-пароля по параметрам KDF хранилища.
+// Хранилища без параметров KDF (созданные до их появления) открываются ключом DeriveKey с пустой солью.
+// Параметры приходят с сервера, поэтому параметры вне границ domain.CheckKDFParams отклоняются.
+func DeriveKeyWithParams(password string, params domain.KDFParams) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("empty password")
+	}
+
+	if domain.KDFAlgorithm(params.Algorithm) == domain.KDFLegacy {
+		return DeriveKey(password, "")
+	}
+
+	if err := domain.CheckKDFParams(params); err != nil {
+		return nil, err
+	}
+
+	return deriveKey(password, params)
+}
+
+// deriveKey формирует ключ по уже проверенным параметрам KDF
+func deriveKey(password string, params domain.KDFParams) ([]byte, error) {
+	switch domain.KDFAlgorithm(params.Algorithm) {
+	case domain.KDFScrypt:
+		return scrypt.Key([]byte(password), params.Salt, int(params.N), int(params.R), int(params.P), keyLength)
+	case domain.KDFArgon2id:
+		return argon2.IDKey([]byte(password), params.Salt, params.Iterations, params.Memory, uint8(params.Parallelism), keyLength), nil
+	default:
+		return nil, fmt.Errorf("unsupported kdf algorithm %q", params.Algorithm)
+	}
+}
+
+// measureKDF замеряет время формирования ключа с указанными параметрами.
+// Калибровка начинает с одной итерации, поэтому параметры не проверяются на допустимые границы.
+func measureKDF(params domain.KDFParams) time.Duration {
+	start := time.Now()
+	_, _ = deriveKey("calibration", params)
+	return time.Since(start)
+}
@@ -0,0 +1,272 @@
+package crypto
+
+import (
+	"bytes"
+	"github.com/romanp1989/gophkeeper/domain"
+	"testing"
+	"time"
+)
+
+func TestNewKDFParams(t *testing.T) {
+	type testCase struct {
+		name      string
+		algorithm domain.KDFAlgorithm
+		wantErr   bool
+	}
+
+	testCases := []testCase{
+		{
+			name:      "scrypt",
+			algorithm: domain.KDFScrypt,
+			wantErr:   false,
+		},
+		{
+			name:      "argon2id",
+			algorithm: domain.KDFArgon2id,
+			wantErr:   false,
+		},
+		{
+			name:      "unknown_algorithm",
+			algorithm: "md5",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, err := NewKDFParams(tc.algorithm)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NewKDFParams() error = %v, wantErr = %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			second, _ := NewKDFParams(tc.algorithm)
+
+			if first.Algorithm != string(tc.algorithm) {
+				t.Errorf("NewKDFParams() algorithm = %s, want %s", first.Algorithm, tc.algorithm)
+			}
+			if len(first.Salt) != saltLength {
+				t.Errorf("NewKDFParams() salt length = %d, want %d", len(first.Salt), saltLength)
+			}
+			if bytes.Equal(first.Salt, second.Salt) {
+				t.Error("NewKDFParams() returned the same salt twice")
+			}
+		})
+	}
+}
+
+func TestDeriveKeyWithParams(t *testing.T) {
+	type testCase struct {
+		name     string
+		password string
+		params   domain.KDFParams
+		wantKey  []byte
+		wantErr  bool
+	}
+
+	legacyKey, err := DeriveKey("password", "")
+	if err != nil {
+		t.Fatalf("unable to prepare legacy key for tests: %v", err)
+	}
+
+	scryptParams := domain.KDFParams{
+		Algorithm: string(domain.KDFScrypt),
+		Salt:      bytes.Repeat([]byte{0x01}, saltLength),
+		N:         domain.ScryptMinN,
+		R:         domain.ScryptMinR,
+		P:         1,
+	}
+
+	argon2Params := domain.KDFParams{
+		Algorithm:   string(domain.KDFArgon2id),
+		Salt:        bytes.Repeat([]byte{0x01}, saltLength),
+		Memory:      domain.Argon2MinMemory,
+		Iterations:  domain.Argon2MinIterations,
+		Parallelism: 1,
+	}
+
+	testCases := []testCase{
+		{
+			name:     "legacy_params",
+			password: "password",
+			params:   domain.KDFParams{},
+			wantKey:  legacyKey,
+			wantErr:  false,
+		},
+		{
+			name:     "scrypt_params",
+			password: "password",
+			params:   scryptParams,
+			wantErr:  false,
+		},
+		{
+			name:     "scrypt_empty_salt",
+			password: "password",
+			params:   domain.KDFParams{Algorithm: string(domain.KDFScrypt), N: domain.ScryptMinN, R: domain.ScryptMinR, P: 1},
+			wantErr:  true,
+		},
+		{
+			name:     "scrypt_weak_n",
+			password: "password",
+			params:   withParams(scryptParams, func(p *domain.KDFParams) { p.N = 2 }),
+			wantErr:  true,
+		},
+		{
+			name:     "scrypt_n_not_power_of_two",
+			password: "password",
+			params:   withParams(scryptParams, func(p *domain.KDFParams) { p.N = domain.ScryptMinN + 1 }),
+			wantErr:  true,
+		},
+		{
+			name:     "scrypt_huge_memory",
+			password: "password",
+			params:   withParams(scryptParams, func(p *domain.KDFParams) { p.N, p.R = domain.ScryptMaxN, domain.ScryptMaxR }),
+			wantErr:  true,
+		},
+		{
+			name:     "argon2id_params",
+			password: "password",
+			params:   argon2Params,
+			wantErr:  false,
+		},
+		{
+			name:     "argon2id_zero_iterations",
+			password: "password",
+			params:   withParams(argon2Params, func(p *domain.KDFParams) { p.Iterations = 0 }),
+			wantErr:  true,
+		},
+		{
+			name:     "argon2id_weak_memory",
+			password: "password",
+			params:   withParams(argon2Params, func(p *domain.KDFParams) { p.Memory = 1 }),
+			wantErr:  true,
+		},
+		{
+			name:     "argon2id_huge_memory",
+			password: "password",
+			params:   withParams(argon2Params, func(p *domain.KDFParams) { p.Memory = 64 * 1024 * 1024 }),
+			wantErr:  true,
+		},
+		{
+			name:     "argon2id_huge_parallelism",
+			password: "password",
+			params:   withParams(argon2Params, func(p *domain.KDFParams) { p.Parallelism = 255 }),
+			wantErr:  true,
+		},
+		{
+			name:     "short_salt",
+			password: "password",
+			params:   withParams(argon2Params, func(p *domain.KDFParams) { p.Salt = []byte("salt") }),
+			wantErr:  true,
+		},
+		{
+			name:     "unknown_algorithm",
+			password: "password",
+			params:   domain.KDFParams{Algorithm: "md5", Salt: []byte("salt")},
+			wantErr:  true,
+		},
+		{
+			name:     "empty_password",
+			password: "",
+			params:   scryptParams,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := DeriveKeyWithParams(tc.password, tc.params)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("DeriveKeyWithParams() error = %v, wantErr = %v", err, tc.wantErr)
+			}
+			if err == nil && len(key) != keyLength {
+				t.Errorf("DeriveKeyWithParams() length = %d, want %d", len(key), keyLength)
+			}
+			if tc.wantKey != nil && !bytes.Equal(key, tc.wantKey) {
+				t.Errorf("DeriveKeyWithParams() key differs from expected")
+			}
+		})
+	}
+
+	otherSalt := scryptParams
+	otherSalt.Salt = bytes.Repeat([]byte{0x02}, saltLength)
+
+	first, _ := DeriveKeyWithParams("password", scryptParams)
+	second, _ := DeriveKeyWithParams("password", otherSalt)
+	if bytes.Equal(first, second) {
+		t.Error("DeriveKeyWithParams() derived the same key for different salts")
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	params, err := Calibrate(time.Millisecond)
+	if err != nil {
+		t.Fatalf("Calibrate() error = %v", err)
+	}
+
+	if params.Algorithm != string(domain.KDFArgon2id) {
+		t.Errorf("Calibrate() algorithm = %s, want %s", params.Algorithm, domain.KDFArgon2id)
+	}
+	if params.Memory < argon2MinMemory || params.Memory > argon2MaxMemory {
+		t.Errorf("Calibrate() memory = %d, want within [%d, %d]", params.Memory, argon2MinMemory, argon2MaxMemory)
+	}
+	if params.Iterations < argon2MinIterations || params.Iterations > argon2MaxIterations {
+		t.Errorf("Calibrate() iterations = %d, want within [%d, %d]", params.Iterations, argon2MinIterations, argon2MaxIterations)
+	}
+
+	if _, err = DeriveKeyWithParams("password", params); err != nil {
+		t.Errorf("DeriveKeyWithParams() with calibrated params error = %v", err)
+	}
+}
+
+func TestNeedsUpgrade(t *testing.T) {
+	type testCase struct {
+		name      string
+		params    domain.KDFParams
+		preferred domain.KDFAlgorithm
+		want      bool
+	}
+
+	testCases := []testCase{
+		{
+			name:      "legacy_to_argon2id",
+			params:    domain.KDFParams{},
+			preferred: domain.KDFArgon2id,
+			want:      true,
+		},
+		{
+			name:      "scrypt_to_argon2id",
+			params:    domain.KDFParams{Algorithm: string(domain.KDFScrypt)},
+			preferred: domain.KDFArgon2id,
+			want:      true,
+		},
+		{
+			name:      "argon2id_up_to_date",
+			params:    domain.KDFParams{Algorithm: string(domain.KDFArgon2id)},
+			preferred: domain.KDFArgon2id,
+			want:      false,
+		},
+		{
+			name:      "no_preference",
+			params:    domain.KDFParams{Algorithm: string(domain.KDFScrypt)},
+			preferred: domain.KDFLegacy,
+			want:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NeedsUpgrade(tc.params, tc.preferred); got != tc.want {
+				t.Errorf("NeedsUpgrade() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// withParams возвращает копию параметров KDF, измененную функцией change
+func withParams(params domain.KDFParams, change func(p *domain.KDFParams)) domain.KDFParams {
+	change(&params)
+	return params
+}
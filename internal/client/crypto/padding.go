@@ -0,0 +1,74 @@
+package crypto
+
+import "errors"
+
+const (
+	// paddingNone открытый текст зашифрован без дополнения
+	paddingNone byte = 0
+
+	// paddingBuckets открытый текст дополнен по схеме ISO/IEC 7816-4 (байт padMarker и нули)
+	// до размера paddedSize
+	paddingBuckets byte = 1
+
+	// padMarker первый байт дополнения
+	padMarker byte = 0x80
+
+	// minPaddedSize минимальный размер дополненного открытого текста
+	minPaddedSize = 32
+)
+
+// ErrUnsupportedPadding указывает, что данные дополнены по неизвестной схеме.
+var ErrUnsupportedPadding = errors.New("unsupported padding scheme")
+
+// ErrInvalidPadding указывает, что дополнение расшифрованных данных повреждено.
+var ErrInvalidPadding = errors.New("invalid padding")
+
+// paddedSize возвращает размер, до которого дополняется открытый текст размера size.
+// Небольшие данные дополняются до степени двойки, большие - до числа, кратного StreamSegmentSize,
+// поэтому по размеру шифротекста можно судить только о порядке размера данных.
+// Дополнение занимает хотя бы один байт, чтобы его можно было однозначно удалить.
+func paddedSize(size int64) int64 {
+	n := size + 1
+	if n > StreamSegmentSize {
+		return (n + StreamSegmentSize - 1) / StreamSegmentSize * StreamSegmentSize
+	}
+
+	padded := int64(minPaddedSize)
+	for padded < n {
+		padded <<= 1
+	}
+
+	return padded
+}
+
+// pad дополняет данные до размера paddedSize
+func pad(data []byte) []byte {
+	padded := make([]byte, paddedSize(int64(len(data))))
+	copy(padded, data)
+	padded[len(data)] = padMarker
+
+	return padded
+}
+
+// unpad удаляет дополнение, добавленное pad
+func unpad(padded []byte) ([]byte, error) {
+	i := len(padded) - 1
+	for i >= 0 && padded[i] == 0 {
+		i--
+	}
+
+	if i < 0 || padded[i] != padMarker {
+		return nil, ErrInvalidPadding
+	}
+
+	return padded[:i], nil
+}
+
+// checkPadding проверяет, что схема дополнения известна
+func checkPadding(padding byte) error {
+	if padding != paddingNone && padding != paddingBuckets {
+		return ErrUnsupportedPadding
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPaddedSize(t *testing.T) {
+	testCases := []struct {
+		size int64
+		want int64
+	}{
+		{size: 0, want: 32},
+		{size: 31, want: 32},
+		{size: 32, want: 64},
+		{size: 1000, want: 1024},
+		{size: StreamSegmentSize - 1, want: StreamSegmentSize},
+		{size: StreamSegmentSize, want: 2 * StreamSegmentSize},
+		{size: 3*StreamSegmentSize + 5, want: 4 * StreamSegmentSize},
+	}
+
+	for _, tc := range testCases {
+		if got := paddedSize(tc.size); got != tc.want {
+			t.Errorf("paddedSize(%d) = %d, want %d", tc.size, got, tc.want)
+		}
+	}
+}
+
+func TestUnpad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		padded  []byte
+		want    []byte
+		wantErr error
+	}{
+		{
+			name:   "round_trip",
+			padded: pad([]byte{1, 0, padMarker, 0}),
+			want:   []byte{1, 0, padMarker, 0},
+		},
+		{
+			name:   "empty",
+			padded: pad(nil),
+			want:   []byte{},
+		},
+		{
+			name:    "zeros_only",
+			padded:  make([]byte, 32),
+			wantErr: ErrInvalidPadding,
+		},
+		{
+			name:    "no_marker",
+			padded:  []byte{1, 2, 3, 0, 0},
+			wantErr: ErrInvalidPadding,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := unpad(tc.padded)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("unpad() error = %v, want %v", err, tc.wantErr)
+			}
+			if err == nil && !bytes.Equal(got, tc.want) {
+				t.Errorf("unpad() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// searchKeyInfo контекст HKDF ключа слепого индекса
+	searchKeyInfo = "gophkeeper search key"
+	// tagKeyInfo контекст HKDF ключа слепого индекса тегов
+	tagKeyInfo = "gophkeeper tag key"
+	// minWordLength минимальная длина индексируемого слова в символах
+	minWordLength = 2
+)
+
+// DeriveSearchKey выводит из ключа хранилища ключ слепого индекса.
+// Ключ хранилища не меняется при смене мастер-пароля, поэтому токены секретов остаются действительными.
+func DeriveSearchKey(vaultKey []byte) ([]byte, error) {
+	return expandKey(vaultKey, searchKeyInfo)
+}
+
+// DeriveTagKey выводит из ключа хранилища ключ слепого индекса тегов.
+// Токены тегов вычисляются отдельным ключом, поэтому тег не совпадает с одноименным словом заголовка или текста
+// и выбор по тегам находит только отмеченные ими секреты.
+func DeriveTagKey(vaultKey []byte) ([]byte, error) {
+	return expandKey(vaultKey, tagKeyInfo)
+}
+
+// BlindIndex возвращает токены слепого индекса слов words: HMAC-SHA256 слова на ключе key.
+// Одинаковые слова дают одинаковые токены, поэтому сервер находит секреты по токенам, не зная самих слов.
+func BlindIndex(key []byte, words []string) [][]byte {
+	tokens := make([][]byte, 0, len(words))
+	for _, word := range words {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(word))
+		tokens = append(tokens, mac.Sum(nil))
+	}
+
+	return tokens
+}
+
+// IndexWords разбивает текст на слова для слепого индекса: последовательности букв и цифр в нижнем регистре
+// длиной не меньше minWordLength символов. Повторы удаляются, порядок первых вхождений сохраняется.
+func IndexWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var words []string
+	for _, field := range fields {
+		if len([]rune(field)) >= minWordLength {
+			words = appendUnique(words, field)
+		}
+	}
+
+	return words
+}
+
+// URLWords возвращает слова для слепого индекса ссылок в тексте: имя узла каждой ссылки, его родительские домены
+// и составляющие их слова. Ссылкой считается слово со схемой, например https://mail.example.com/login,
+// или имя узла с точкой, например example.com.
+func URLWords(text string) []string {
+	var words []string
+
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		host := urlHost(field)
+		if host == "" {
+			continue
+		}
+
+		labels := strings.Split(host, ".")
+		for i := 0; i < len(labels)-1; i++ {
+			words = appendUnique(words, strings.Join(labels[i:], "."))
+		}
+		for _, word := range IndexWords(host) {
+			words = appendUnique(words, word)
+		}
+	}
+
+	return words
+}
+
+// urlHost возвращает имя узла ссылки или пустую строку, если слово не похоже на ссылку
+func urlHost(field string) string {
+	if !strings.Contains(field, "://") {
+		if strings.Contains(field, "@") {
+			return ""
+		}
+		field = "//" + field
+	}
+
+	u, err := url.Parse(field)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.Trim(u.Hostname(), ".")
+	if !strings.Contains(host, ".") || !strings.ContainsFunc(host[strings.LastIndex(host, ".")+1:], unicode.IsLetter) {
+		return ""
+	}
+	for _, r := range host {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' {
+			return ""
+		}
+	}
+
+	return host
+}
+
+// appendUnique добавляет слово, если его еще нет в списке
+func appendUnique(words []string, word string) []string {
+	if slices.Contains(words, word) {
+		return words
+	}
+
+	return append(words, word)
+}
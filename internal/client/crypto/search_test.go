@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDeriveSearchKey(t *testing.T) {
+	vaultKey, _ := NewKey()
+
+	first, err := DeriveSearchKey(vaultKey)
+	if err != nil {
+		t.Fatalf("DeriveSearchKey() error = %v", err)
+	}
+	second, _ := DeriveSearchKey(vaultKey)
+
+	if len(first) != keyLength {
+		t.Errorf("DeriveSearchKey() length = %d, want %d", len(first), keyLength)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("DeriveSearchKey() is not deterministic")
+	}
+	if bytes.Equal(first, vaultKey) {
+		t.Errorf("DeriveSearchKey() returned the vault key")
+	}
+}
+
+func TestDeriveTagKey(t *testing.T) {
+	vaultKey, _ := NewKey()
+
+	tagKey, err := DeriveTagKey(vaultKey)
+	if err != nil {
+		t.Fatalf("DeriveTagKey() error = %v", err)
+	}
+	searchKey, _ := DeriveSearchKey(vaultKey)
+
+	if len(tagKey) != keyLength {
+		t.Errorf("DeriveTagKey() length = %d, want %d", len(tagKey), keyLength)
+	}
+	if bytes.Equal(tagKey, searchKey) {
+		t.Errorf("DeriveTagKey() returned the search key")
+	}
+	if bytes.Equal(BlindIndex(tagKey, []string{"bank"})[0], BlindIndex(searchKey, []string{"bank"})[0]) {
+		t.Errorf("tag token equals the search token of the same word")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	key, _ := NewKey()
+	otherKey, _ := NewKey()
+
+	tokens := BlindIndex(key, []string{"bank", "mail", "bank"})
+	if len(tokens) != 3 || len(tokens[0]) != 32 {
+		t.Fatalf("BlindIndex() = %x, want three 32-byte tokens", tokens)
+	}
+	if !bytes.Equal(tokens[0], tokens[2]) {
+		t.Errorf("BlindIndex() gave different tokens for the same word")
+	}
+	if bytes.Equal(tokens[0], tokens[1]) {
+		t.Errorf("BlindIndex() gave the same token for different words")
+	}
+	if bytes.Equal(tokens[0], BlindIndex(otherKey, []string{"bank"})[0]) {
+		t.Errorf("BlindIndex() gave the same token for different keys")
+	}
+}
+
+func TestIndexWords(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "words",
+			text: "My Bank account, bank PIN",
+			want: []string{"my", "bank", "account", "pin"},
+		},
+		{
+			name: "short_words_skipped",
+			text: "a b cd",
+			want: []string{"cd"},
+		},
+		{
+			name: "unicode",
+			text: "Почта-2024",
+			want: []string{"почта", "2024"},
+		},
+		{
+			name: "empty",
+			text: " ,. ",
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IndexWords(tc.text); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("IndexWords(%q) = %q, want %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestURLWords(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "url_with_scheme",
+			text: "login at https://Mail.Example.com/login?next=1",
+			want: []string{"mail.example.com", "example.com", "mail", "example", "com"},
+		},
+		{
+			name: "bare_host",
+			text: "example.org",
+			want: []string{"example.org", "example", "org"},
+		},
+		{
+			name: "not_urls",
+			text: "user@example.com hello 3.14",
+			want: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := URLWords(tc.text); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("URLWords(%q) = %q, want %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	// streamVersion1 версия формата потокового шифрования
+	streamVersion1 byte = 1
+
+	// streamVersion2 версия формата потока с дополнением открытого текста: в заголовок добавлена схема дополнения
+	streamVersion2 byte = 2
+
+	// algAES256GCMStream идентификатор алгоритма AES-256-GCM с разбиением на сегменты
+	algAES256GCMStream byte = 2
+
+	// noncePrefixLength длина случайного префикса nonce потока.
+	// Оставшиеся байты nonce занимают номер сегмента (4 байта) и признак последнего сегмента (1 байт)
+	noncePrefixLength = nonceLength - 5
+
+	// streamHeaderLengthV1 длина заголовка потока версии 1: версия, идентификатор алгоритма и префикс nonce
+	streamHeaderLengthV1 = 2 + noncePrefixLength
+
+	// streamHeaderLength длина заголовка потока: версия, идентификатор алгоритма, схема дополнения и префикс nonce
+	streamHeaderLength = 3 + noncePrefixLength
+
+	// StreamSegmentSize размер сегмента открытого текста, который шифруется отдельно
+	StreamSegmentSize = 64 * 1024
+)
+
+// ErrStreamTooLong указывает, что поток содержит больше сегментов, чем допускает размер счетчика в nonce.
+var ErrStreamTooLong = errors.New("stream is too long")
+
+// ErrStreamClosed указывает на запись в уже закрытый поток.
+var ErrStreamClosed = errors.New("stream is closed")
+
+// streamCipher формирует nonce сегментов потока.
+// Nonce сегмента состоит из префикса потока, номера сегмента и признака последнего сегмента,
+// поэтому сегменты нельзя переставить, удалить или дописать после последнего незаметно.
+type streamCipher struct {
+	gcm       cipher.AEAD
+	aad       []byte
+	nonce     [nonceLength]byte
+	counter   uint32
+	exhausted bool
+}
+
+// newStreamCipher создает шифр потока с заголовком header.
+// Заголовок аутентифицируется вместе с дополнительными данными aad в каждом сегменте.
+func newStreamCipher(key, header, aad []byte) (*streamCipher, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &streamCipher{gcm: gcm}
+	s.aad = append(append(s.aad, header...), aad...)
+	copy(s.nonce[:], header[len(header)-noncePrefixLength:])
+
+	return s, nil
+}
+
+// next возвращает nonce следующего сегмента
+func (s *streamCipher) next(last bool) ([]byte, error) {
+	if s.exhausted {
+		return nil, ErrStreamTooLong
+	}
+
+	binary.BigEndian.PutUint32(s.nonce[noncePrefixLength:], s.counter)
+	s.nonce[nonceLength-1] = 0
+	if last {
+		s.nonce[nonceLength-1] = 1
+	}
+
+	if s.counter == math.MaxUint32 {
+		s.exhausted = true
+	} else {
+		s.counter++
+	}
+
+	return s.nonce[:], nil
+}
+
+// encryptWriter шифрует записываемые данные сегментами по StreamSegmentSize байт
+type encryptWriter struct {
+	dst     io.Writer
+	stream  *streamCipher
+	buf     []byte
+	written int64
+	closed  bool
+	err     error
+}
+
+// NewEncryptWriter - Создание потока, шифрующего записываемые в него данные ключом key.
+// Данные шифруются сегментами, поэтому объем используемой памяти не зависит от размера данных.
+// Последний сегмент записывается при вызове Close; Close не закрывает dst.
+// Перед шифрованием последнего сегмента данные дополняются до размера корзины, чтобы размер
+// шифротекста не раскрывал точный размер данных.
+func NewEncryptWriter(dst io.Writer, key, aad []byte) (io.WriteCloser, error) {
+	header := make([]byte, streamHeaderLength)
+	header[0] = streamVersion2
+	header[1] = algAES256GCMStream
+	header[2] = paddingBuckets
+	if _, err := io.ReadFull(rand.Reader, header[3:]); err != nil {
+		return nil, err
+	}
+
+	stream, err := newStreamCipher(key, header, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dst.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		dst:    dst,
+		stream: stream,
+		buf:    make([]byte, 0, StreamSegmentSize+stream.gcm.Overhead()),
+	}, nil
+}
+
+// Write накапливает данные и шифрует каждый заполненный сегмент.
+// Заполненный сегмент записывается только после поступления следующих данных,
+// так как последний сегмент шифруется с отдельным признаком.
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrStreamClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var written int
+	for len(p) > 0 {
+		if len(w.buf) == StreamSegmentSize {
+			if w.err = w.flush(false); w.err != nil {
+				return written, w.err
+			}
+		}
+
+		n := copy(w.buf[len(w.buf):StreamSegmentSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+		w.written += int64(n)
+	}
+
+	return written, nil
+}
+
+// Close дополняет данные и шифрует и записывает последний сегмент потока.
+// Дополнение всегда помещается в последний сегмент: размер корзины не превышает StreamSegmentSize
+// или кратен ему.
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if w.err != nil {
+		return w.err
+	}
+
+	if len(w.buf) == StreamSegmentSize {
+		if err := w.flush(false); err != nil {
+			return err
+		}
+	}
+
+	padding := int(paddedSize(w.written) - w.written)
+	w.buf = append(w.buf, padMarker)
+	w.buf = append(w.buf, make([]byte, padding-1)...)
+
+	return w.flush(true)
+}
+
+// flush шифрует накопленный сегмент на месте и записывает его в dst
+func (w *encryptWriter) flush(last bool) error {
+	nonce, err := w.stream.next(last)
+	if err != nil {
+		return err
+	}
+
+	sealed := w.stream.gcm.Seal(w.buf[:0], nonce, w.buf, w.stream.aad)
+	if _, err = w.dst.Write(sealed); err != nil {
+		return err
+	}
+
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// decryptReader расшифровывает поток, сформированный encryptWriter, по одному сегменту
+type decryptReader struct {
+	src     *bufio.Reader
+	stream  *streamCipher
+	padding byte
+	segment []byte
+	plain   []byte
+	done    bool
+	err     error
+}
+
+// NewDecryptReader - Создание потока, расшифровывающего данные, зашифрованные NewEncryptWriter.
+// Каждый сегмент проверяется перед тем, как его данные будут возвращены, поэтому при подмене или
+// обрезке потока Read возвращает ошибку, а не поврежденные данные.
+// Потоки версии 1 расшифровываются без удаления дополнения.
+func NewDecryptReader(src io.Reader, key, aad []byte) (io.Reader, error) {
+	header := make([]byte, streamHeaderLength)
+	if err := readHeader(src, header[:streamHeaderLengthV1]); err != nil {
+		return nil, err
+	}
+
+	padding := paddingNone
+	switch header[0] {
+	case streamVersion1:
+		header = header[:streamHeaderLengthV1]
+	case streamVersion2:
+		if err := readHeader(src, header[streamHeaderLengthV1:]); err != nil {
+			return nil, err
+		}
+		padding = header[2]
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+
+	if header[1] != algAES256GCMStream {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if err := checkPadding(padding); err != nil {
+		return nil, err
+	}
+
+	stream, err := newStreamCipher(key, header, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		src:     bufio.NewReader(src),
+		stream:  stream,
+		padding: padding,
+		segment: make([]byte, StreamSegmentSize+stream.gcm.Overhead()),
+	}, nil
+}
+
+// readHeader читает заголовок потока целиком
+func readHeader(src io.Reader, header []byte) error {
+	if _, err := io.ReadFull(src, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrCiphertextTooShort
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Read возвращает расшифрованные данные текущего сегмента, при необходимости читая следующий.
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+
+		r.err = r.readSegment()
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+
+	return n, nil
+}
+
+// readSegment читает и расшифровывает следующий сегмент.
+// Сегмент считается последним, если за ним нет данных.
+func (r *decryptReader) readSegment() error {
+	var last bool
+
+	n, err := io.ReadFull(r.src, r.segment)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err = r.src.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if n < r.stream.gcm.Overhead() {
+		return ErrCiphertextTooShort
+	}
+
+	nonce, err := r.stream.next(last)
+	if err != nil {
+		return err
+	}
+
+	r.plain, err = r.stream.gcm.Open(r.segment[:0], nonce, r.segment[:n], r.stream.aad)
+	if err != nil {
+		return err
+	}
+
+	if last && r.padding == paddingBuckets {
+		if r.plain, err = unpad(r.plain); err != nil {
+			return err
+		}
+	}
+
+	r.done = last
+	return nil
+}
+
+// IsStream - Проверка, зашифрованы ли данные потоком NewEncryptWriter, по их первым байтам.
+func IsStream(prefix []byte) bool {
+	return len(prefix) >= 2 && (prefix[0] == streamVersion1 || prefix[0] == streamVersion2) &&
+		prefix[1] == algAES256GCMStream
+}
+
+// StreamSize - Размер данных, зашифрованных NewEncryptWriter, для открытого текста размера size с учетом дополнения.
+func StreamSize(size int64) int64 {
+	padded := paddedSize(size)
+	segments := (padded + StreamSegmentSize - 1) / StreamSegmentSize
+	return streamHeaderLength + padded + segments*16
+}
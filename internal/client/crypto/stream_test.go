@@ -0,0 +1,315 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// encryptStream шифрует data потоком, записывая ее частями по chunk байт
+func encryptStream(t *testing.T, data, key, aad []byte, chunk int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key, aad)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter() error = %v", err)
+	}
+
+	for len(data) > 0 {
+		n := min(chunk, len(data))
+		if _, err = w.Write(data[:n]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		data = data[n:]
+	}
+
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// decryptStream расшифровывает поток целиком
+func decryptStream(sealed, key, aad []byte) ([]byte, error) {
+	r, err := NewDecryptReader(iotest.HalfReader(bytes.NewReader(sealed)), key, aad)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream_RoundTrip(t *testing.T) {
+	key, _ := NewKey()
+	aad := []byte("blob:1")
+
+	sizes := []int{0, 1, StreamSegmentSize - 1, StreamSegmentSize, StreamSegmentSize + 1, 3*StreamSegmentSize + 5}
+	for _, size := range sizes {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+
+		for _, chunk := range []int{1000, StreamSegmentSize, 5 * StreamSegmentSize} {
+			sealed := encryptStream(t, data, key, aad, chunk)
+
+			// Данные дополняются до степени двойки или до целого числа сегментов
+			padded := int(paddedSize(int64(size)))
+			segments := (padded + StreamSegmentSize - 1) / StreamSegmentSize
+			wantLen := streamHeaderLength + padded + segments*16
+			if len(sealed) != wantLen {
+				t.Errorf("size %d: sealed length = %d, want %d", size, len(sealed), wantLen)
+			}
+			if StreamSize(int64(size)) != int64(len(sealed)) {
+				t.Errorf("StreamSize(%d) = %d, want %d", size, StreamSize(int64(size)), len(sealed))
+			}
+			if !IsStream(sealed) {
+				t.Errorf("IsStream() = false for stream of size %d", size)
+			}
+
+			opened, err := decryptStream(sealed, key, aad)
+			if err != nil {
+				t.Fatalf("size %d chunk %d: decrypt error = %v", size, chunk, err)
+			}
+			if !bytes.Equal(opened, data) {
+				t.Fatalf("size %d chunk %d: decrypted data differs", size, chunk)
+			}
+		}
+	}
+}
+
+func TestStream_Tampering(t *testing.T) {
+	key, _ := NewKey()
+	otherKey, _ := NewKey()
+	aad := []byte("blob:1")
+	segment := StreamSegmentSize + 16
+
+	data := make([]byte, 3*StreamSegmentSize+100)
+	_, _ = rand.Read(data)
+	sealed := encryptStream(t, data, key, aad, StreamSegmentSize)
+
+	type testCase struct {
+		name    string
+		sealed  func() []byte
+		key     []byte
+		aad     []byte
+		wantErr error
+	}
+
+	testCases := []testCase{
+		{
+			name:   "wrong_key",
+			sealed: func() []byte { return sealed },
+			key:    otherKey,
+			aad:    aad,
+		},
+		{
+			name:   "wrong_aad",
+			sealed: func() []byte { return sealed },
+			key:    key,
+			aad:    []byte("blob:2"),
+		},
+		{
+			name: "truncated_at_segment_boundary",
+			sealed: func() []byte {
+				return sealed[:streamHeaderLength+2*segment]
+			},
+			key: key,
+			aad: aad,
+		},
+		{
+			name: "truncated_inside_segment",
+			sealed: func() []byte {
+				return sealed[:streamHeaderLength+segment+10]
+			},
+			key: key,
+			aad: aad,
+		},
+		{
+			name: "appended_data",
+			sealed: func() []byte {
+				return append(bytes.Clone(sealed), sealed[streamHeaderLength:streamHeaderLength+segment]...)
+			},
+			key: key,
+			aad: aad,
+		},
+		{
+			name: "reordered_segments",
+			sealed: func() []byte {
+				swapped := bytes.Clone(sealed)
+				first := swapped[streamHeaderLength : streamHeaderLength+segment]
+				second := swapped[streamHeaderLength+segment : streamHeaderLength+2*segment]
+				tmp := bytes.Clone(first)
+				copy(first, second)
+				copy(second, tmp)
+				return swapped
+			},
+			key: key,
+			aad: aad,
+		},
+		{
+			name: "modified_nonce_prefix",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				modified[3] ^= 1
+				return modified
+			},
+			key: key,
+			aad: aad,
+		},
+		{
+			name: "padding_removed",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				modified[2] = paddingNone
+				return modified
+			},
+			key: key,
+			aad: aad,
+		},
+		{
+			name: "unsupported_padding",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				modified[2] = 9
+				return modified
+			},
+			key:     key,
+			aad:     aad,
+			wantErr: ErrUnsupportedPadding,
+		},
+		{
+			name: "unsupported_version",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				modified[0] = 9
+				return modified
+			},
+			key:     key,
+			aad:     aad,
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name: "unsupported_algorithm",
+			sealed: func() []byte {
+				modified := bytes.Clone(sealed)
+				modified[1] = algAES256GCM
+				return modified
+			},
+			key:     key,
+			aad:     aad,
+			wantErr: ErrUnsupportedAlgorithm,
+		},
+		{
+			name: "header_only",
+			sealed: func() []byte {
+				return sealed[:streamHeaderLength]
+			},
+			key:     key,
+			aad:     aad,
+			wantErr: ErrCiphertextTooShort,
+		},
+		{
+			name: "truncated_header",
+			sealed: func() []byte {
+				return sealed[:streamHeaderLength-1]
+			},
+			key:     key,
+			aad:     aad,
+			wantErr: ErrCiphertextTooShort,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := decryptStream(tc.sealed(), tc.key, tc.aad)
+			if err == nil {
+				t.Fatalf("decrypt expected error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("decrypt error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestStream_Version1(t *testing.T) {
+	key, _ := NewKey()
+	aad := []byte("blob:1")
+
+	// Поток версии 1 без дополнения из одного последнего сегмента
+	header := make([]byte, streamHeaderLengthV1)
+	header[0] = streamVersion1
+	header[1] = algAES256GCMStream
+	_, _ = rand.Read(header[2:])
+
+	s, err := newStreamCipher(key, header, aad)
+	if err != nil {
+		t.Fatalf("newStreamCipher() error = %v", err)
+	}
+	nonce, _ := s.next(true)
+
+	data := []byte("old blob data")
+	sealed := s.gcm.Seal(bytes.Clone(header), nonce, data, s.aad)
+
+	if !IsStream(sealed) {
+		t.Errorf("IsStream() = false for version 1 stream")
+	}
+
+	opened, err := decryptStream(sealed, key, aad)
+	if err != nil {
+		t.Fatalf("decrypt error = %v", err)
+	}
+	if !bytes.Equal(opened, data) {
+		t.Errorf("decrypt = %q, want %q", opened, data)
+	}
+}
+
+func TestIsStream(t *testing.T) {
+	key, _ := NewKey()
+
+	sealed, _ := Seal([]byte("data"), key, nil)
+	legacy, _ := Encrypt("data", key)
+
+	for name, data := range map[string][]byte{"sealed": sealed, "legacy": []byte(legacy), "empty": nil} {
+		if IsStream(data) {
+			t.Errorf("IsStream(%s) = true, want false", name)
+		}
+	}
+}
+
+func TestStream_WriteAfterClose(t *testing.T) {
+	key, _ := NewKey()
+
+	w, err := NewEncryptWriter(io.Discard, key, nil)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter() error = %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if _, err = w.Write([]byte("data")); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Write() error = %v, want %v", err, ErrStreamClosed)
+	}
+}
+
+func TestStreamCipher_CounterOverflow(t *testing.T) {
+	key, _ := NewKey()
+	header := make([]byte, streamHeaderLength)
+
+	s, err := newStreamCipher(key, header, nil)
+	if err != nil {
+		t.Fatalf("newStreamCipher() error = %v", err)
+	}
+
+	s.counter = ^uint32(0)
+	if _, err = s.next(false); err != nil {
+		t.Fatalf("next() error = %v", err)
+	}
+	if _, err = s.next(true); !errors.Is(err, ErrStreamTooLong) {
+		t.Errorf("next() error = %v, want %v", err, ErrStreamTooLong)
+	}
+}
@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -9,6 +10,7 @@ import (
 	"github.com/romanp1989/gophkeeper/certs"
 	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/romanp1989/gophkeeper/internal/client/config"
+	"github.com/romanp1989/gophkeeper/internal/client/crypto"
 	"github.com/romanp1989/gophkeeper/internal/client/grpc/interceptors"
 	"github.com/romanp1989/gophkeeper/pkg/converter"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
@@ -18,6 +20,7 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
 	"math"
 	"math/rand/v2"
 	"sync"
@@ -26,15 +29,45 @@ import (
 
 type ClientGRPCInterface interface {
 	Login(ctx context.Context, login, password string) (string, error)
-	Register(ctx context.Context, login, password string) (string, error)
+	Register(ctx context.Context, login, password string, kdf domain.KDFParams) (string, error)
+	ChangePassword(ctx context.Context, oldAuthHash, newPassword string, keys crypto.MasterKeys, kdf domain.KDFParams, vaultKey []byte, secrets []*domain.Secret) error
 	LoadSecrets(ctx context.Context) ([]*domain.Secret, error)
 	LoadSecret(ctx context.Context, ID uint64) (*domain.Secret, error)
-	SaveSecret(ctx context.Context, secret *domain.Secret) error
+	ListSecrets(ctx context.Context, query *domain.SecretQuery) (*domain.SecretPage, error)
+	SearchSecrets(ctx context.Context, query *domain.SecretQuery) (*domain.SecretPage, error)
+	SaveSecret(ctx context.Context, secret *domain.Secret) (uint64, error)
 	DeleteSecret(ctx context.Context, id uint64) error
+	BatchSaveSecrets(ctx context.Context, secrets []*domain.Secret, atomic bool) ([]error, error)
+	BatchDeleteSecrets(ctx context.Context, ids []uint64, atomic bool) ([]error, error)
+	ListChanges(ctx context.Context, sinceRevision uint64) (*domain.SecretChanges, error)
+	ListTrash(ctx context.Context) ([]*domain.Secret, error)
+	GetUsage(ctx context.Context) (*domain.Usage, error)
+	ListPlaintextFields(ctx context.Context) ([]*domain.SecretVersion, error)
+	SealPlaintextFields(ctx context.Context, records []*domain.SecretVersion) (uint64, error)
+	WatchSecrets(ctx context.Context) (<-chan domain.SecretEvent, error)
+	RestoreSecret(ctx context.Context, id uint64) error
+	PurgeSecret(ctx context.Context, id uint64) error
+	ListSecretVersions(ctx context.Context, secretID uint64) ([]*domain.SecretVersion, error)
+	RestoreSecretVersion(ctx context.Context, secretID, versionID uint64) (*domain.Secret, error)
+	CreateUploadSession(ctx context.Context, secretID uint64, dataKey []byte, size int64) (*domain.UploadSession, error)
+	GetUploadSession(ctx context.Context, id string) (*domain.UploadSession, error)
+	UploadBlob(ctx context.Context, sessionID string, offset int64, r io.Reader) (int64, bool, error)
+	DownloadBlob(ctx context.Context, secretID uint64) (*BlobReader, error)
+	ListFolders(ctx context.Context) ([]*domain.Folder, error)
+	CreateFolder(ctx context.Context, parentID uint64, name string) (*domain.Folder, error)
+	RenameFolder(ctx context.Context, id uint64, name string) error
+	MoveFolder(ctx context.Context, id, parentID uint64) error
+	DeleteFolder(ctx context.Context, id uint64) error
+	MoveSecrets(ctx context.Context, ids []uint64, folderID uint64) ([]uint64, error)
 	SetToken(token string)
 	GetToken() string
 	SetPassword(password string)
 	GetPassword() string
+	GetKDFParams() domain.KDFParams
+	GetUserID() domain.UserID
+	GetAuthScheme() domain.AuthScheme
+	GetMasterKeys() crypto.MasterKeys
+	GetVaultKey() []byte
 }
 
 type (
@@ -43,15 +76,42 @@ type (
 		config        *config.Config
 		UsersClient   proto.UsersClient
 		SecretsClient proto.SecretsClient
+		FoldersClient proto.FoldersClient
 		accessToken   string
+		userID        domain.UserID
 		password      string
+		kdf           domain.KDFParams
+		scheme        domain.AuthScheme
+		keys          crypto.MasterKeys
+		vaultKey      []byte
 		clientID      uint64
 		previews      sync.Map
 	}
 	// ReloadSecretList метка для обработчика
 	ReloadSecretList struct{}
+	// BlobReader читает данные файлового секрета из потока DownloadBlob
+	BlobReader struct {
+		// DataKey ключ данных секрета, зашифрованный ключом хранилища
+		DataKey []byte
+		// Size размер зашифрованных данных
+		Size   int64
+		stream proto.Secrets_DownloadBlobClient
+		chunk  []byte
+	}
 )
 
+// blobChunkSize размер части данных, передаваемой одним сообщением потока
+const blobChunkSize = 64 * 1024
+
+// ErrConflict данные на сервере были изменены другим запросом после их получения клиентом
+var ErrConflict = errors.New("данные были изменены другим запросом")
+
+// ErrQuotaExceeded изменение превысило бы ограничения хранилища пользователя на сервере
+var ErrQuotaExceeded = errors.New("превышены ограничения хранилища")
+
+// ErrLegacyAuth сервер запросил устаревший способ входа, который не разрешен в настройках клиента
+var ErrLegacyAuth = errors.New("сервер запросил устаревший способ входа; разрешите его переменной GOPHKEEPER_LEGACY_AUTH, если аккаунт создан до разделения ключей")
+
 // NewClientGRPC создаёт новый экземпляр ClientGRPC с предварительной настройкой подключения к серверу.
 func NewClientGRPC(cfg *config.Config) (ClientGRPCInterface, error) {
 	var opts []grpc.DialOption
@@ -67,10 +127,10 @@ func NewClientGRPC(cfg *config.Config) (ClientGRPCInterface, error) {
 			interceptors.Timeout(time.Second*5),
 			interceptors.AddAuth(&newClient.accessToken, uint32(newClient.clientID)),
 		),
-	)
-
-	opts = append(
-		opts,
+		// Для потоков таймаут не задается: время передачи зависит от размера данных
+		grpc.WithChainStreamInterceptor(
+			interceptors.AddStreamAuth(&newClient.accessToken, uint32(newClient.clientID)),
+		),
 	)
 
 	tlsCredential, err := loadTLSConfig("ca-cert.pem", "client-cert.pem", "client-key.pem")
@@ -87,15 +147,34 @@ func NewClientGRPC(cfg *config.Config) (ClientGRPCInterface, error) {
 
 	newClient.UsersClient = proto.NewUsersClient(c)
 	newClient.SecretsClient = proto.NewSecretsClient(c)
+	newClient.FoldersClient = proto.NewFoldersClient(c)
 
 	return &newClient, nil
 }
 
 // Login авторизует пользователя на сервере и получает токен доступа.
+// Параметры KDF и способ аутентификации запрашиваются у сервера до входа: из мастер-пароля по ним
+// формируются хеш аутентификации, который передается серверу, и ключ шифрования, который остается на клиенте.
 func (c *ClientGRPC) Login(ctx context.Context, login string, password string) (string, error) {
+	preLogin, err := c.UsersClient.PreLogin(ctx, &proto.PreLoginRequest{Login: login})
+	if err != nil {
+		return "", parseError(err)
+	}
+
+	scheme := domain.AuthScheme(preLogin.AuthScheme)
+	kdf := converter.ProtoToKDF(preLogin.Kdf)
+	if err = c.checkAuthScheme(scheme, kdf); err != nil {
+		return "", err
+	}
+
+	keys, err := crypto.DeriveKeys(password, kdf, scheme)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive keys: %w", err)
+	}
+
 	req := &proto.LoginRequest{
 		Login:    login,
-		Password: password,
+		Password: keys.AuthHash,
 	}
 
 	response, err := c.UsersClient.Login(ctx, req)
@@ -104,15 +183,57 @@ func (c *ClientGRPC) Login(ctx context.Context, login string, password string) (
 	}
 
 	c.accessToken = response.AccessToken
+	c.userID = domain.UserID(response.UserId)
+	c.password = password
+	c.scheme = scheme
+	c.keys = keys
+	c.kdf = converter.ProtoToKDF(response.Kdf)
+	c.vaultKey = response.VaultKey
 
 	return response.AccessToken, nil
 }
 
-// Register регистрирует нового пользователя и получает токен доступа.
-func (c *ClientGRPC) Register(ctx context.Context, login string, password string) (string, error) {
+// checkAuthScheme проверяет способ аутентификации и параметры KDF, полученные от сервера до входа.
+// Ответу PreLogin нельзя доверять: указав AuthSchemePassword, сервер получил бы мастер-пароль,
+// а указав KDF без соли, упростил бы его подбор по хешу аутентификации. Поэтому прежние способы входа
+// принимаются только при явном разрешении в настройках клиента.
+func (c *ClientGRPC) checkAuthScheme(scheme domain.AuthScheme, kdf domain.KDFParams) error {
+	if scheme != domain.AuthSchemeDerived && scheme != domain.AuthSchemePassword {
+		return fmt.Errorf("неизвестный способ аутентификации %q", scheme)
+	}
+
+	legacy := scheme == domain.AuthSchemePassword || domain.KDFAlgorithm(kdf.Algorithm) == domain.KDFLegacy
+	if legacy && (c.config == nil || !c.config.LegacyAuth) {
+		return ErrLegacyAuth
+	}
+
+	return nil
+}
+
+// Register регистрирует нового пользователя с параметрами KDF и получает токен доступа.
+// На сервер передаются хеш аутентификации и новый ключ хранилища, зашифрованный ключом шифрования.
+func (c *ClientGRPC) Register(ctx context.Context, login string, password string, kdf domain.KDFParams) (string, error) {
+	keys, err := crypto.DeriveKeys(password, kdf, domain.AuthSchemeDerived)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive keys: %w", err)
+	}
+
+	vaultKey, err := crypto.NewKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate vault key: %w", err)
+	}
+
+	// Секреты нового хранилища сохраняются только с зашифрованными полями
+	wrapped, err := crypto.WrapVaultKey(vaultKey, keys.EncryptionKey, true)
+	if err != nil {
+		return "", err
+	}
+
 	req := &proto.RegisterRequest{
 		Login:    login,
-		Password: password,
+		Password: keys.AuthHash,
+		Kdf:      converter.KDFToProto(kdf),
+		VaultKey: wrapped,
 	}
 
 	response, err := c.UsersClient.Register(ctx, req)
@@ -121,30 +242,99 @@ func (c *ClientGRPC) Register(ctx context.Context, login string, password string
 	}
 
 	c.accessToken = response.AccessToken
+	c.userID = domain.UserID(response.UserId)
+	c.password = password
+	c.scheme = domain.AuthSchemeDerived
+	c.keys = keys
+	c.kdf = converter.ProtoToKDF(response.Kdf)
+	c.vaultKey = response.VaultKey
 
 	return response.AccessToken, nil
 }
 
-// LoadSecrets загружает список секретов пользователя.
+// ChangePassword меняет мастер-пароль пользователя. Текущий пароль подтверждается хешем аутентификации oldAuthHash,
+// вместе с хешем аутентификации нового пароля на сервер передаются параметры KDF, ключ хранилища,
+// зашифрованный новым ключом шифрования, и перешифрованные секреты, если они есть.
+// Если смена уже была выполнена предыдущим запросом, сервер возвращает сохраненные параметры и ключ хранилища,
+// и ключи нового пароля формируются заново по сохраненным параметрам.
+func (c *ClientGRPC) ChangePassword(ctx context.Context, oldAuthHash, newPassword string, keys crypto.MasterKeys, kdf domain.KDFParams, vaultKey []byte, secrets []*domain.Secret) error {
+	req := &proto.ChangePasswordRequest{
+		OldPassword: oldAuthHash,
+		NewPassword: keys.AuthHash,
+		Kdf:         converter.KDFToProto(kdf),
+		VaultKey:    vaultKey,
+		Secrets:     reencryptedSecrets(secrets),
+	}
+
+	response, err := c.UsersClient.ChangePassword(ctx, req)
+	if err != nil {
+		return parseError(err)
+	}
+
+	savedKDF := converter.ProtoToKDF(response.Kdf)
+	if !bytes.Equal(response.VaultKey, vaultKey) {
+		keys, err = crypto.DeriveKeys(newPassword, savedKDF, domain.AuthSchemeDerived)
+		if err != nil {
+			return fmt.Errorf("failed to derive keys: %w", err)
+		}
+	}
+
+	c.password = newPassword
+	c.scheme = domain.AuthSchemeDerived
+	c.keys = keys
+	c.kdf = savedKDF
+	c.vaultKey = response.VaultKey
+
+	return nil
+}
+
+// LoadSecrets загружает все секреты пользователя вместе с данными.
+// Секреты загружаются страницами, чтобы ответы сервера не превышали ограничение размера сообщения.
 func (c *ClientGRPC) LoadSecrets(ctx context.Context) ([]*domain.Secret, error) {
-	request := emptypb.Empty{}
+	var secrets []*domain.Secret
+
+	query := &domain.SecretQuery{Sort: domain.SortByCreatedAt}
+	for {
+		page, err := c.ListSecrets(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, page.Secrets...)
+		if page.Next == nil {
+			return secrets, nil
+		}
+		query.After = page.Next
+	}
+}
+
+// ListSecrets загружает страницу секретов пользователя, выбранных и отсортированных по query.
+func (c *ClientGRPC) ListSecrets(ctx context.Context, query *domain.SecretQuery) (*domain.SecretPage, error) {
+	response, err := c.SecretsClient.ListSecrets(ctx, converter.QueryToProto(query))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToPage(response)
+}
 
-	response, err := c.SecretsClient.GetUserSecrets(ctx, &request)
+// SearchSecrets загружает страницу секретов пользователя без данных, у которых есть все токены поиска query.SearchTokens.
+func (c *ClientGRPC) SearchSecrets(ctx context.Context, query *domain.SecretQuery) (*domain.SecretPage, error) {
+	response, err := c.SecretsClient.SearchSecrets(ctx, converter.SearchToProto(query))
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	secrets := converter.ProtoToSecrets(response.Secrets)
-	return secrets, nil
+	return converter.ProtoToPage(response)
 }
 
 // LoadSecret загружает информацию о конкретном секрете.
-func (c *ClientGRPC) LoadSecret(_ context.Context, ID uint64) (*domain.Secret, error) {
+func (c *ClientGRPC) LoadSecret(ctx context.Context, ID uint64) (*domain.Secret, error) {
 	request := &proto.GetUserSecretRequest{
 		Id: ID,
 	}
 
-	response, err := c.SecretsClient.GetUserSecret(context.Background(), request)
+	response, err := c.SecretsClient.GetUserSecret(ctx, request)
 	if err != nil {
 		return nil, parseError(err)
 	}
@@ -154,25 +344,41 @@ func (c *ClientGRPC) LoadSecret(_ context.Context, ID uint64) (*domain.Secret, e
 	return secret, nil
 }
 
-// SaveSecret сохраняет или обновляет секрет пользователя на сервере.
-func (c *ClientGRPC) SaveSecret(ctx context.Context, secret *domain.Secret) error {
+// SaveSecret сохраняет или обновляет секрет пользователя на сервере и возвращает его идентификатор.
+// Ревизия секрета заменяется ревизией, присвоенной сервером. Если секрет был изменен на сервере после
+// получения, возвращается ErrConflict.
+func (c *ClientGRPC) SaveSecret(ctx context.Context, secret *domain.Secret) (uint64, error) {
 	sec := &proto.Secret{
-		Title:      secret.Title,
-		Metadata:   secret.Metadata,
-		SecretType: converter.TypeToProto(secret.SecretType),
-		Payload:    secret.Payload,
-		CreatedAt:  timestamppb.New(secret.CreatedAt),
-		UpdatedAt:  timestamppb.New(secret.UpdatedAt),
+		Title:        secret.Title,
+		Metadata:     secret.Metadata,
+		SecretType:   converter.TypeToProto(secret.SecretType),
+		Payload:      secret.Payload,
+		DataKey:      secret.DataKey,
+		CreatedAt:    timestamppb.New(secret.CreatedAt),
+		UpdatedAt:    timestamppb.New(secret.UpdatedAt),
+		Revision:     secret.Revision,
+		FolderId:     secret.FolderID,
+		Tags:         secret.Tags,
+		SearchTokens: secret.SearchTokens,
 	}
 
 	if secret.ID > 0 {
 		sec.Id = secret.ID
 	}
 
+	if !secret.ExpiresAt.IsZero() {
+		sec.ExpiresAt = timestamppb.New(secret.ExpiresAt)
+	}
+
 	request := &proto.SaveUserSecretRequest{Secret: sec}
-	_, err := c.SecretsClient.SaveUserSecret(ctx, request)
+	response, err := c.SecretsClient.SaveUserSecret(ctx, request)
+	if err != nil {
+		return 0, parseError(err)
+	}
 
-	return parseError(err)
+	secret.Revision = response.Revision
+
+	return response.Id, nil
 }
 
 // DeleteSecret удаляет секрет пользователя.
@@ -183,6 +389,299 @@ func (c *ClientGRPC) DeleteSecret(ctx context.Context, id uint64) error {
 	return parseError(err)
 }
 
+// BatchSaveSecrets сохраняет или обновляет секреты пользователя одной транзакцией на сервере.
+// Возвращает ошибки секретов в порядке их передачи; сохраненным секретам присваиваются идентификатор и редакция.
+// Если atomic равен true, ошибка одного секрета отменяет сохранение всех остальных.
+func (c *ClientGRPC) BatchSaveSecrets(ctx context.Context, secrets []*domain.Secret, atomic bool) ([]error, error) {
+	request := &proto.BatchSaveSecretsRequest{Secrets: converter.SecretsToProto(secrets), Atomic: atomic}
+
+	response, err := c.SecretsClient.BatchSaveSecrets(ctx, request)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	errs, err := batchErrors(response.Results, len(secrets))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range response.Results {
+		if errs[i] == nil {
+			secrets[i].ID, secrets[i].Revision = result.Id, result.Revision
+		}
+	}
+
+	return errs, nil
+}
+
+// BatchDeleteSecrets перемещает секреты пользователя в корзину одной транзакцией на сервере.
+// Возвращает ошибки секретов в порядке их передачи.
+// Если atomic равен true, ошибка одного секрета отменяет удаление всех остальных.
+func (c *ClientGRPC) BatchDeleteSecrets(ctx context.Context, ids []uint64, atomic bool) ([]error, error) {
+	response, err := c.SecretsClient.BatchDeleteSecrets(ctx, &proto.BatchDeleteSecretsRequest{Ids: ids, Atomic: atomic})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return batchErrors(response.Results, len(ids))
+}
+
+// batchErrors конвертирует результаты элементов пакета в ошибки так же, как ошибки одиночных запросов
+func batchErrors(results []*proto.BatchItemResult, n int) ([]error, error) {
+	if len(results) != n {
+		return nil, fmt.Errorf("expected %d batch results, got %d", n, len(results))
+	}
+
+	errs := make([]error, 0, n)
+	for _, result := range results {
+		code := codes.Code(result.Code)
+		if code == codes.OK {
+			errs = append(errs, nil)
+			continue
+		}
+		errs = append(errs, parseError(status.Error(code, result.Error)))
+	}
+
+	return errs, nil
+}
+
+// ListChanges загружает секреты, измененные после изменения с номером sinceRevision, и удаленные с тех пор секреты.
+// Секреты загружаются без данных и ключей данных, с размером данных; сами данные загружаются через LoadSecret.
+// Изменения возвращаются страницей размера по умолчанию: если флаг More установлен, следующая страница
+// запрашивается с номером изменения Revision из ответа.
+func (c *ClientGRPC) ListChanges(ctx context.Context, sinceRevision uint64) (*domain.SecretChanges, error) {
+	request := &proto.ListChangesRequest{SinceRevision: sinceRevision, MetadataOnly: true}
+
+	response, err := c.SecretsClient.ListChanges(ctx, request)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToChanges(response), nil
+}
+
+// WatchSecrets подписывается на события изменения секретов пользователя.
+// Канал событий закрывается, когда поток прерывается или контекст отменяется.
+func (c *ClientGRPC) WatchSecrets(ctx context.Context) (<-chan domain.SecretEvent, error) {
+	stream, err := c.SecretsClient.WatchSecrets(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	events := make(chan domain.SecretEvent)
+	go func() {
+		defer close(events)
+
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			select {
+			case events <- converter.ProtoToEvent(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// ListTrash загружает секреты пользователя, перемещенные в корзину.
+func (c *ClientGRPC) ListTrash(ctx context.Context) ([]*domain.Secret, error) {
+	response, err := c.SecretsClient.ListTrash(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToSecrets(response.Secrets), nil
+}
+
+// GetUsage загружает количество секретов пользователя, суммарный размер их данных и ограничения хранилища.
+func (c *ClientGRPC) GetUsage(ctx context.Context) (*domain.Usage, error) {
+	response, err := c.SecretsClient.GetUsage(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToUsage(response), nil
+}
+
+// ListPlaintextFields загружает редакции секретов пользователя и секреты в корзине, заголовок, метаданные или теги
+// которых сохранены на сервере открытым текстом. Секрет в корзине возвращается как редакция с номером 0.
+func (c *ClientGRPC) ListPlaintextFields(ctx context.Context) ([]*domain.SecretVersion, error) {
+	response, err := c.SecretsClient.ListPlaintextFields(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToVersions(response.Records), nil
+}
+
+// SealPlaintextFields заменяет на сервере открытые поля записей, полученных ListPlaintextFields, зашифрованными.
+// Возвращает количество измененных записей.
+func (c *ClientGRPC) SealPlaintextFields(ctx context.Context, records []*domain.SecretVersion) (uint64, error) {
+	request := &proto.SealPlaintextFieldsRequest{Records: converter.VersionsToProto(records)}
+	response, err := c.SecretsClient.SealPlaintextFields(ctx, request)
+	if err != nil {
+		return 0, parseError(err)
+	}
+
+	return response.Sealed, nil
+}
+
+// RestoreSecret возвращает секрет пользователя из корзины.
+func (c *ClientGRPC) RestoreSecret(ctx context.Context, id uint64) error {
+	_, err := c.SecretsClient.RestoreSecret(ctx, &proto.RestoreSecretRequest{Id: id})
+
+	return parseError(err)
+}
+
+// PurgeSecret окончательно удаляет секрет пользователя из корзины.
+func (c *ClientGRPC) PurgeSecret(ctx context.Context, id uint64) error {
+	_, err := c.SecretsClient.PurgeSecret(ctx, &proto.PurgeSecretRequest{Id: id})
+
+	return parseError(err)
+}
+
+// ListSecretVersions загружает сохраненные редакции секрета, начиная с последней.
+func (c *ClientGRPC) ListSecretVersions(ctx context.Context, secretID uint64) ([]*domain.SecretVersion, error) {
+	request := &proto.ListSecretVersionsRequest{SecretId: secretID}
+	response, err := c.SecretsClient.ListSecretVersions(ctx, request)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToVersions(response.Versions), nil
+}
+
+// RestoreSecretVersion восстанавливает секрет из сохраненной редакции и возвращает восстановленный секрет.
+func (c *ClientGRPC) RestoreSecretVersion(ctx context.Context, secretID, versionID uint64) (*domain.Secret, error) {
+	request := &proto.RestoreSecretVersionRequest{SecretId: secretID, VersionId: versionID}
+	response, err := c.SecretsClient.RestoreSecretVersion(ctx, request)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToSecret(response.Secret), nil
+}
+
+// CreateUploadSession открывает на сервере сессию загрузки size байт зашифрованных данных файлового секрета.
+func (c *ClientGRPC) CreateUploadSession(ctx context.Context, secretID uint64, dataKey []byte, size int64) (*domain.UploadSession, error) {
+	request := &proto.CreateUploadSessionRequest{SecretId: secretID, DataKey: dataKey, Size: uint64(size)}
+
+	response, err := c.SecretsClient.CreateUploadSession(ctx, request)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToUploadSession(response.Session), nil
+}
+
+// GetUploadSession возвращает сессию загрузки с количеством байт, уже полученных сервером.
+func (c *ClientGRPC) GetUploadSession(ctx context.Context, id string) (*domain.UploadSession, error) {
+	response, err := c.SecretsClient.GetUploadSession(ctx, &proto.GetUploadSessionRequest{Id: id})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return converter.ProtoToUploadSession(response.Session), nil
+}
+
+// UploadBlob передает потоком данные r в сессию загрузки, начиная с позиции offset.
+// Возвращает количество байт, полученных сервером, и признак завершения загрузки.
+// При обрыве соединения загрузку можно продолжить с позиции, которую возвращает GetUploadSession.
+func (c *ClientGRPC) UploadBlob(ctx context.Context, sessionID string, offset int64, r io.Reader) (int64, bool, error) {
+	// При ошибке чтения поток отменяется, чтобы сервер не ожидал продолжения данных
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.SecretsClient.UploadBlob(ctx)
+	if err != nil {
+		return 0, false, parseError(err)
+	}
+
+	resume := &proto.UploadResume{SessionId: sessionID, Offset: uint64(offset)}
+	if err = stream.Send(&proto.UploadBlobRequest{Data: &proto.UploadBlobRequest_Resume{Resume: resume}}); err != nil {
+		return 0, false, c.closeUpload(stream, err)
+	}
+
+	for {
+		// Сообщение может удерживаться транспортом после Send, поэтому буфер для каждой части новый
+		chunk := make([]byte, blobChunkSize)
+		n, readErr := io.ReadFull(r, chunk)
+		if n > 0 {
+			if err = stream.Send(&proto.UploadBlobRequest{Data: &proto.UploadBlobRequest_Chunk{Chunk: chunk[:n]}}); err != nil {
+				return 0, false, c.closeUpload(stream, err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return 0, false, readErr
+		}
+	}
+
+	response, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, false, parseError(err)
+	}
+
+	return int64(response.Offset), response.Completed, nil
+}
+
+// closeUpload возвращает ошибку отправки потока.
+// При ошибке Send причина, сообщенная сервером, доступна только через CloseAndRecv.
+func (c *ClientGRPC) closeUpload(stream proto.Secrets_UploadBlobClient, err error) error {
+	if errors.Is(err, io.EOF) {
+		_, err = stream.CloseAndRecv()
+	}
+	return parseError(err)
+}
+
+// DownloadBlob открывает поток зашифрованных данных файлового секрета.
+// Ключ данных и размер доступны сразу, данные читаются из возвращаемого BlobReader по мере получения.
+func (c *ClientGRPC) DownloadBlob(ctx context.Context, secretID uint64) (*BlobReader, error) {
+	stream, err := c.SecretsClient.DownloadBlob(ctx, &proto.DownloadBlobRequest{SecretId: secretID})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	response, err := stream.Recv()
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	header := response.GetHeader()
+	if header == nil {
+		return nil, errors.New("blob header expected")
+	}
+
+	return &BlobReader{DataKey: header.DataKey, Size: int64(header.Size), stream: stream}, nil
+}
+
+// Read возвращает данные очередной части потока
+func (b *BlobReader) Read(p []byte) (int, error) {
+	for len(b.chunk) == 0 {
+		response, err := b.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, parseError(err)
+		}
+		b.chunk = response.GetChunk()
+	}
+
+	n := copy(p, b.chunk)
+	b.chunk = b.chunk[n:]
+
+	return n, nil
+}
+
 // SetToken устанавливает текущий токен доступа клиента.
 func (c *ClientGRPC) SetToken(token string) {
 	c.accessToken = token
@@ -203,6 +702,40 @@ func (c *ClientGRPC) GetPassword() string {
 	return c.password
 }
 
+// GetKDFParams возвращает параметры KDF хранилища, полученные при входе или регистрации.
+func (c *ClientGRPC) GetKDFParams() domain.KDFParams {
+	return c.kdf
+}
+
+// GetUserID возвращает идентификатор пользователя, полученный при входе или регистрации.
+func (c *ClientGRPC) GetUserID() domain.UserID {
+	return c.userID
+}
+
+// GetAuthScheme возвращает способ аутентификации пользователя.
+func (c *ClientGRPC) GetAuthScheme() domain.AuthScheme {
+	return c.scheme
+}
+
+// GetMasterKeys возвращает хеш аутентификации и ключ шифрования, сформированные из мастер-пароля.
+func (c *ClientGRPC) GetMasterKeys() crypto.MasterKeys {
+	return c.keys
+}
+
+// GetVaultKey возвращает ключ хранилища, зашифрованный ключом шифрования.
+func (c *ClientGRPC) GetVaultKey() []byte {
+	return c.vaultKey
+}
+
+// reencryptedSecrets конвертирует перешифрованные секреты в объекты protobuf для смены ключа хранилища.
+func reencryptedSecrets(secrets []*domain.Secret) []*proto.ReencryptedSecret {
+	pbSecrets := make([]*proto.ReencryptedSecret, 0, len(secrets))
+	for _, s := range secrets {
+		pbSecrets = append(pbSecrets, &proto.ReencryptedSecret{Id: s.ID, Payload: s.Payload, DataKey: s.DataKey})
+	}
+	return pbSecrets
+}
+
 // loadTLSConfig загружает TLS конфигурацию для подключения к серверу.
 func loadTLSConfig(caCertFile, clientCertFile, clientKeyFile string) (credentials.TransportCredentials, error) {
 	caPem, err := certs.Cert.ReadFile(caCertFile)
@@ -262,6 +795,10 @@ func parseError(err error) error {
 		return errors.New("недостаточно прав")
 	case codes.DeadlineExceeded:
 		return errors.New("превышено время ожидания")
+	case codes.Aborted:
+		return fmt.Errorf("%w: %s", ErrConflict, st.Message())
+	case codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", ErrQuotaExceeded, st.Message())
 	default:
 		return fmt.Errorf("ошибка gRPC: %s (%d)", st.Message(), st.Code())
 	}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/config"
+	"github.com/romanp1989/gophkeeper/pkg/converter"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"testing"
+)
+
+// fakeUsersClient отвечает на PreLogin заданными параметрами и запоминает запрос Login
+type fakeUsersClient struct {
+	proto.UsersClient
+	preLogin *proto.PreLoginResponse
+	login    *proto.LoginRequest
+}
+
+func (f *fakeUsersClient) PreLogin(context.Context, *proto.PreLoginRequest, ...grpc.CallOption) (*proto.PreLoginResponse, error) {
+	return f.preLogin, nil
+}
+
+func (f *fakeUsersClient) Login(_ context.Context, in *proto.LoginRequest, _ ...grpc.CallOption) (*proto.LoginResponse, error) {
+	f.login = in
+	return &proto.LoginResponse{AccessToken: "token", UserId: 1}, nil
+}
+
+func TestClientGRPC_Login_AuthScheme(t *testing.T) {
+	const password = "master password"
+
+	kdf := converter.KDFToProto(domain.KDFParams{
+		Algorithm:   string(domain.KDFArgon2id),
+		Salt:        []byte("0123456789abcdef"),
+		Memory:      domain.Argon2MinMemory,
+		Iterations:  domain.Argon2MinIterations,
+		Parallelism: 1,
+	})
+
+	tests := []struct {
+		name       string
+		scheme     string
+		kdf        *proto.KDFParams
+		legacyAuth bool
+		wantErr    bool
+		errIs      error
+		wantRaw    bool
+	}{
+		{
+			name:   "Derived_Scheme",
+			scheme: string(domain.AuthSchemeDerived),
+			kdf:    kdf,
+		},
+		{
+			name:    "Password_Scheme_Refused",
+			scheme:  string(domain.AuthSchemePassword),
+			kdf:     kdf,
+			wantErr: true,
+			errIs:   ErrLegacyAuth,
+		},
+		{
+			name:    "Unknown_Password_Scheme_Refused",
+			scheme:  "password",
+			kdf:     kdf,
+			wantErr: true,
+		},
+		{
+			name:    "Legacy_KDF_Refused",
+			scheme:  string(domain.AuthSchemeDerived),
+			kdf:     &proto.KDFParams{},
+			wantErr: true,
+			errIs:   ErrLegacyAuth,
+		},
+		{
+			name:       "Password_Scheme_Allowed",
+			scheme:     string(domain.AuthSchemePassword),
+			kdf:        kdf,
+			legacyAuth: true,
+			wantRaw:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			users := &fakeUsersClient{preLogin: &proto.PreLoginResponse{Kdf: tc.kdf, AuthScheme: tc.scheme}}
+			client := &ClientGRPC{config: &config.Config{LegacyAuth: tc.legacyAuth}, UsersClient: users}
+
+			_, err := client.Login(context.Background(), "user", password)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.errIs != nil {
+					assert.ErrorIs(t, err, tc.errIs)
+				}
+				assert.Nil(t, users.login, "Login must not be called")
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, users.login)
+			assert.Equal(t, tc.wantRaw, users.login.Password == password)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/converter"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ListFolders возвращает все папки пользователя.
+func (c *ClientGRPC) ListFolders(ctx context.Context) ([]*domain.Folder, error) {
+	response, err := c.FoldersClient.ListFolders(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseFolderError(err)
+	}
+
+	return converter.ProtoToFolders(response.Folders), nil
+}
+
+// CreateFolder создает папку в папке parentID; нулевой parentID создает папку в корне хранилища.
+func (c *ClientGRPC) CreateFolder(ctx context.Context, parentID uint64, name string) (*domain.Folder, error) {
+	response, err := c.FoldersClient.CreateFolder(ctx, &proto.CreateFolderRequest{ParentId: parentID, Name: name})
+	if err != nil {
+		return nil, parseFolderError(err)
+	}
+
+	return converter.ProtoToFolder(response.Folder), nil
+}
+
+// RenameFolder изменяет название папки.
+func (c *ClientGRPC) RenameFolder(ctx context.Context, id uint64, name string) error {
+	_, err := c.FoldersClient.RenameFolder(ctx, &proto.RenameFolderRequest{Id: id, Name: name})
+
+	return parseFolderError(err)
+}
+
+// MoveFolder перемещает папку в папку parentID; нулевой parentID перемещает папку в корень хранилища.
+func (c *ClientGRPC) MoveFolder(ctx context.Context, id, parentID uint64) error {
+	_, err := c.FoldersClient.MoveFolder(ctx, &proto.MoveFolderRequest{Id: id, ParentId: parentID})
+
+	return parseFolderError(err)
+}
+
+// DeleteFolder удаляет папку; вложенные папки и секреты переносятся в ее родительскую папку.
+func (c *ClientGRPC) DeleteFolder(ctx context.Context, id uint64) error {
+	_, err := c.FoldersClient.DeleteFolder(ctx, &proto.DeleteFolderRequest{Id: id})
+
+	return parseFolderError(err)
+}
+
+// MoveSecrets перемещает секреты в папку folderID и возвращает идентификаторы перемещенных секретов.
+func (c *ClientGRPC) MoveSecrets(ctx context.Context, ids []uint64, folderID uint64) ([]uint64, error) {
+	response, err := c.FoldersClient.MoveSecrets(ctx, &proto.MoveSecretsRequest{Ids: ids, FolderId: folderID})
+	if err != nil {
+		return nil, parseFolderError(err)
+	}
+
+	return response.MovedIds, nil
+}
+
+// parseFolderError дополняет parseError ошибками, которые возвращают только запросы к папкам
+func parseFolderError(err error) error {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return fmt.Errorf("некорректный запрос: %s", status.Convert(err).Message())
+	default:
+		return parseError(err)
+	}
+}
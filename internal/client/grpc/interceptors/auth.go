@@ -26,3 +26,21 @@ func AddAuth(token *string, clientID uint32) grpc.UnaryClientInterceptor {
 		return invoker(mdCtx, method, req, reply, cc, opts...)
 	}
 }
+
+// AddStreamAuth возвращает StreamClientInterceptor, который добавляет токен доступа и идентификатор клиента
+// в метаданные потоковых вызовов, как AddAuth для обычных вызовов.
+func AddStreamAuth(token *string, clientID uint32) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if len(*token) == 0 {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		md := metadata.New(map[string]string{
+			consts.AccessTokenHeader: *token,
+			consts.ClientIDHeader:    strconv.Itoa(int(clientID)),
+		})
+
+		mdCtx := metadata.NewOutgoingContext(ctx, md)
+		return streamer(mdCtx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"container/list"
+	"github.com/romanp1989/gophkeeper/domain"
+)
+
+// payloadCacheSize суммарный размер данных секретов, которые RemoteStorage хранит расшифрованными в памяти
+const payloadCacheSize = 32 << 20
+
+// cacheEntry расшифрованный секрет в кеше вместе с размером его данных
+type cacheEntry struct {
+	secret *domain.Secret
+	size   int64
+}
+
+// payloadCache хранит расшифрованные секреты, вытесняя давно не использованные,
+// когда суммарный размер их данных превышает capacity. Не безопасен для конкурентного использования.
+type payloadCache struct {
+	capacity int64
+	size     int64
+	order    *list.List
+	entries  map[uint64]*list.Element
+}
+
+// newPayloadCache создает кеш секретов с суммарным размером данных не больше capacity байт
+func newPayloadCache(capacity int64) *payloadCache {
+	return &payloadCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[uint64]*list.Element),
+	}
+}
+
+// get возвращает секрет id, если в кеше сохранена его редакция revision.
+// Секрет другой редакции устарел и удаляется из кеша.
+func (c *payloadCache) get(id, revision uint64) (*domain.Secret, bool) {
+	element, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if entry.secret.Revision != revision {
+		c.remove(id)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.secret, true
+}
+
+// put сохраняет секрет с размером данных size. Секреты больше емкости кеша не сохраняются.
+func (c *payloadCache) put(secret *domain.Secret, size int64) {
+	c.remove(secret.ID)
+	if size > c.capacity {
+		return
+	}
+
+	c.entries[secret.ID] = c.order.PushFront(&cacheEntry{secret: secret, size: size})
+	c.size += size
+
+	for c.size > c.capacity {
+		oldest := c.order.Back()
+		c.remove(oldest.Value.(*cacheEntry).secret.ID)
+	}
+}
+
+// remove удаляет секрет id из кеша
+func (c *payloadCache) remove(id uint64) {
+	element, ok := c.entries[id]
+	if !ok {
+		return
+	}
+
+	c.order.Remove(element)
+	delete(c.entries, id)
+	c.size -= element.Value.(*cacheEntry).size
+}
@@ -2,14 +2,81 @@
 package storage
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/romanp1989/gophkeeper/internal/client/crypto"
 	"github.com/romanp1989/gophkeeper/internal/client/grpc"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
+const (
+	// secretAADPrefix префикс дополнительных данных шифрования секрета
+	secretAADPrefix = "gophkeeper/secret/v1"
+
+	// fieldAADPrefix префикс дополнительных данных шифрования заголовка и метаданных секрета
+	fieldAADPrefix = "gophkeeper/field/v1"
+
+	// Названия шифруемых текстовых полей секрета, входящие в дополнительные данные шифрования
+	titleField    = "title"
+	metadataField = "metadata"
+
+	// folderAADPrefix префикс дополнительных данных шифрования названия папки
+	folderAADPrefix = "gophkeeper/folder/v1"
+
+	// maxFolderNameLength максимальная длина названия папки в символах
+	maxFolderNameLength = 255
+
+	// maxBlobHeaderLength максимальная длина заголовка зашифрованного файла
+	maxBlobHeaderLength = 64 * 1024
+
+	// uploadAttempts количество попыток передачи файла в сессию загрузки
+	uploadAttempts = 5
+
+	// uploadRetryDelay задержка перед повторной попыткой, увеличивается с каждой попыткой
+	uploadRetryDelay = time.Second
+)
+
+// ErrVaultKeyRequired указывает, что операция доступна только хранилищу с ключом хранилища.
+var ErrVaultKeyRequired = errors.New("vault key is required")
+
+// ErrNoSearchWords указывает, что в строке поиска нет слов, которые попадают в индекс.
+var ErrNoSearchWords = errors.New("no words to search for")
+
+// ErrInvalidFolderName указывает на пустое или слишком длинное название папки либо на название, содержащее "/".
+var ErrInvalidFolderName = errors.New("invalid folder name")
+
+// ErrFolderExists указывает, что в родительской папке уже есть папка с таким названием.
+var ErrFolderExists = errors.New("folder already exists")
+
+// Progress получает количество переданных байт и общий размер передачи
+type Progress func(done, total int64)
+
+// sealedMetadata открытый текст зашифрованного поля метаданных: вместе с метаданными в нем шифруются теги секрета,
+// чтобы сервер не хранил их открытыми
+type sealedMetadata struct {
+	Metadata string   `json:"metadata"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// blobHeader заголовок файла, который шифруется в одном потоке с содержимым файла
+type blobHeader struct {
+	FileName string `json:"file_name"`
+}
+
 // Storage описывает интерфейс для базовых операций с хранилищем секретов.
 type Storage interface {
 	Get(ctx context.Context, id uint64) (*domain.Secret, error)
@@ -20,113 +87,1420 @@ type Storage interface {
 	String() string
 }
 
-// RemoteStorage реализует хранилище секретов, используя удаленный сервис через gRPC.
-type RemoteStorage struct {
-	client    grpc.ClientGRPCInterface
-	deriveKey []byte
+// RemoteStorage реализует хранилище секретов, используя удаленный сервис через gRPC.
+//
+// Каждый секрет шифруется собственным случайным ключом данных, который хранится на сервере
+// зашифрованным ключом хранилища. Ключ хранилища, в свою очередь, хранится зашифрованным ключом,
+// сформированным из мастер-пароля. Ключ шифрования на сервер не передается: для аутентификации
+// используется отдельный хеш, выведенный из того же мастер-ключа. Смена пароля или параметров KDF перешифровывает
+// только ключ хранилища, а не содержимое секретов.
+type RemoteStorage struct {
+	client    grpc.ClientGRPCInterface
+	deriveKey []byte
+	// vaultKey ключ хранилища; nil для хранилищ, созданных до перехода на конвертное шифрование
+	vaultKey []byte
+	// fieldsSealed отметка ключа хранилища о том, что текстовые поля всех секретов зашифрованы:
+	// после нее открытое поле, полученное с сервера, считается подмененным
+	fieldsSealed bool
+
+	// mu защищает локальную копию хранилища и кеш
+	mu sync.Mutex
+	// replica секреты без данных по состоянию на изменение с номером revision
+	replica  map[uint64]*domain.Secret
+	revision uint64
+	// cache расшифрованные секреты, которые пользователь открывал
+	cache *payloadCache
+}
+
+// NewRemoteStorage создает новый экземпляр RemoteStorage с ключом шифрования, который клиент сформировал
+// из мастер-пароля при входе или регистрации, и расшифровывает им ключ хранилища.
+func NewRemoteStorage(client grpc.ClientGRPCInterface) (*RemoteStorage, error) {
+	deriveKey := client.GetMasterKeys().EncryptionKey
+	if len(deriveKey) == 0 {
+		return nil, errors.New("NewRemoteStorage(): encryption key is not set")
+	}
+
+	store := &RemoteStorage{
+		client:    client,
+		deriveKey: deriveKey,
+		replica:   make(map[uint64]*domain.Secret),
+		cache:     newPayloadCache(payloadCacheSize),
+	}
+
+	if wrapped := client.GetVaultKey(); len(wrapped) > 0 {
+		var err error
+		store.vaultKey, store.fieldsSealed, err = crypto.UnwrapVaultKey(wrapped, deriveKey)
+		if err != nil {
+			return nil, fmt.Errorf("NewRemoteStorage(): failed to open vault key: %w", err)
+		}
+	}
+
+	return store, nil
+}
+
+// Get возвращает расшифрованный секрет по его идентификатору.
+// Данные секрета загружаются с сервера и расшифровываются только при первом обращении к текущей редакции,
+// после чего секрет хранится в кеше до вытеснения или изменения. Для файлов, зашифрованных потоком,
+// загружается только заголовок с именем файла: содержимое файла получается через DownloadFile.
+func (store *RemoteStorage) Get(ctx context.Context, id uint64) (*domain.Secret, error) {
+	store.mu.Lock()
+	summary, known := store.replica[id]
+	if known {
+		if cached, ok := store.cache.get(id, summary.Revision); ok {
+			store.mu.Unlock()
+			secret := *cached
+			return &secret, nil
+		}
+	}
+	store.mu.Unlock()
+
+	if known && summary.SecretType == string(domain.BlobSecret) {
+		secret, err := store.loadBlobHeader(ctx, summary)
+		if err != nil {
+			return nil, err
+		}
+		if secret != nil {
+			store.remember(secret)
+
+			result := *secret
+			return &result, nil
+		}
+	}
+
+	secret, err := store.client.LoadSecret(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.openFields(secret); err != nil {
+		return nil, err
+	}
+
+	err = store.decryptPayload(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	store.remember(secret)
+
+	result := *secret
+	return &result, nil
+}
+
+// loadBlobHeader загружает и расшифровывает только заголовок файла, зашифрованного потоком, не загружая его содержимое.
+// Для файлов, сохраненных до перехода на потоковое шифрование, возвращает nil: их заголовок не отделен от содержимого.
+func (store *RemoteStorage) loadBlobHeader(ctx context.Context, summary *domain.Secret) (*domain.Secret, error) {
+	if store.vaultKey == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	blob, err := store.client.DownloadBlob(ctx, summary.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	src := bufio.NewReader(blob)
+
+	prefix, err := src.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	if !crypto.IsStream(prefix) {
+		return nil, nil
+	}
+
+	secret := *summary
+	secret.DataKey = blob.DataKey
+
+	if _, err = store.decryptBlob(src, blob.DataKey, &secret); err != nil {
+		return nil, fmt.Errorf("failed to decrypt blob header: %w", err)
+	}
+
+	return &secret, nil
+}
+
+// GetAll возвращает все секреты пользователя из локальной копии хранилища без данных.
+// Перед этим с сервера загружаются только изменения, сделанные после последней синхронизации:
+// измененные секреты заменяют прежние, удаленные секреты убираются из копии и кеша.
+// Изменения запрашиваются страницами без блокировки копии; если за это время копия была синхронизирована с более поздним
+// изменением, полученная страница устарела и не применяется.
+func (store *RemoteStorage) GetAll(ctx context.Context) ([]*domain.Secret, error) {
+	for more := true; more; {
+		store.mu.Lock()
+		since := store.revision
+		store.mu.Unlock()
+
+		changes, err := store.client.ListChanges(ctx, since)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range changes.Secrets {
+			if s.PayloadSize == 0 {
+				continue
+			}
+			if err = store.openFields(s); err != nil {
+				return nil, err
+			}
+		}
+
+		store.mu.Lock()
+		if changes.Revision >= store.revision {
+			store.apply(changes)
+		}
+		store.mu.Unlock()
+
+		more = changes.More
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// Экраны изменяют полученные секреты до сохранения, поэтому возвращаются копии
+	result := make([]*domain.Secret, 0, len(store.replica))
+	for _, s := range store.replica {
+		secret := *s
+		result = append(result, &secret)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UpdatedAt.After(result[j].UpdatedAt)
+	})
+
+	return result, nil
+}
+
+// apply применяет к локальной копии хранилища изменения changes. Секрет, который клиент сохранил позже,
+// чем было получено изменение, не заменяется. Вызывается с заблокированным mu.
+func (store *RemoteStorage) apply(changes *domain.SecretChanges) {
+	for _, t := range changes.Tombstones {
+		delete(store.replica, t.ID)
+		store.cache.remove(t.ID)
+	}
+
+	for _, s := range changes.Secrets {
+		// Запись без данных остается на сервере, если создание секрета было прервано
+		if s.PayloadSize == 0 {
+			delete(store.replica, s.ID)
+			store.cache.remove(s.ID)
+			continue
+		}
+
+		if known, ok := store.replica[s.ID]; ok && known.Revision > s.Revision {
+			continue
+		}
+		store.replica[s.ID] = s
+	}
+	store.revision = changes.Revision
+}
+
+// remember сохраняет расшифрованный секрет в кеш, а его описание без данных в локальную копию хранилища.
+// Зашифрованные данные в кеше не хранятся: при сохранении секрет шифруется заново.
+func (store *RemoteStorage) remember(secret *domain.Secret) {
+	if len(secret.Payload) > 0 {
+		secret.PayloadSize = int64(len(secret.Payload))
+	}
+
+	// Содержимое файла, зашифрованного потоком, в секрете не хранится
+	size := secret.PayloadSize
+	if secret.Blob != nil && secret.Blob.FileBytes == nil {
+		size = int64(len(secret.Blob.FileName))
+	}
+
+	cached := *secret
+	cached.Payload = nil
+
+	summary := cached
+	summary.DataKey = nil
+	summary.Credentials, summary.Text, summary.Blob, summary.Card = nil, nil, nil, nil
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.replica[secret.ID] = &summary
+	store.cache.put(&cached, size)
+}
+
+// forget удаляет секрет из кеша, чтобы при следующем обращении он был загружен с сервера
+func (store *RemoteStorage) forget(id uint64) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.cache.remove(id)
+}
+
+// Create создает новый секрет в хранилище, предварительно зашифровав его.
+// Идентификатор секрета входит в дополнительные данные шифрования, поэтому сначала на сервере создается
+// пустая запись, а затем в нее сохраняются данные и поля, зашифрованные с полученным идентификатором.
+func (store *RemoteStorage) Create(ctx context.Context, secret *domain.Secret) (err error) {
+	if err = store.ensureVaultKey(ctx); err != nil {
+		return err
+	}
+
+	if err = store.index(secret); err != nil {
+		return err
+	}
+
+	secret.ID, err = store.placeholder(ctx, secret)
+	if err != nil {
+		return err
+	}
+
+	err = store.sealWithDataKey(secret, store.vaultKey)
+	if err == nil {
+		_, err = store.save(ctx, secret)
+	}
+
+	if err != nil {
+		// Пустая запись удаляется и после отмены ctx
+		_ = store.client.DeleteSecret(context.WithoutCancel(ctx), secret.ID)
+		secret.ID = 0
+		return err
+	}
+
+	store.remember(secret)
+
+	return nil
+}
+
+// Update обновляет существующий секрет, предварительно зашифровав его.
+func (store *RemoteStorage) Update(ctx context.Context, secret *domain.Secret) (err error) {
+	if err = store.ensureVaultKey(ctx); err != nil {
+		return err
+	}
+
+	if err = store.index(secret); err != nil {
+		return
+	}
+
+	err = store.sealWithDataKey(secret, store.vaultKey)
+	if err != nil {
+		return
+	}
+
+	_, err = store.save(ctx, secret)
+	if err != nil {
+		// После конфликта редакций секрет в кеше мог устареть
+		store.forget(secret.ID)
+		return err
+	}
+
+	store.remember(secret)
+
+	return nil
+}
+
+// Delete перемещает секрет в корзину по его идентификатору.
+func (store *RemoteStorage) Delete(ctx context.Context, id uint64) (err error) {
+	err = store.client.DeleteSecret(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	store.forget(id)
+
+	return nil
+}
+
+// DeleteMany перемещает секреты в корзину одним запросом и возвращает ошибки секретов в порядке идентификаторов.
+// Ошибка одного секрета не отменяет удаление остальных.
+func (store *RemoteStorage) DeleteMany(ctx context.Context, ids []uint64) ([]error, error) {
+	errs, err := store.client.BatchDeleteSecrets(ctx, ids, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		if errs[i] == nil {
+			store.forget(id)
+		}
+	}
+
+	return errs, nil
+}
+
+// Trash загружает секреты из корзины и расшифровывает их.
+func (store *RemoteStorage) Trash(ctx context.Context) ([]*domain.Secret, error) {
+	secrets, err := store.client.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range secrets {
+		if err = store.openFields(s); err != nil {
+			return nil, err
+		}
+
+		// Запись без данных попадает в корзину, если создание секрета было прервано
+		if len(s.Payload) == 0 {
+			continue
+		}
+
+		if err = store.decryptPayload(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// Restore возвращает секрет из корзины.
+func (store *RemoteStorage) Restore(ctx context.Context, id uint64) error {
+	return store.client.RestoreSecret(ctx, id)
+}
+
+// Purge окончательно удаляет секрет из корзины вместе с его историей.
+func (store *RemoteStorage) Purge(ctx context.Context, id uint64) error {
+	return store.client.PurgeSecret(ctx, id)
+}
+
+// FindByTags возвращает идентификаторы секретов, отмеченных всеми тегами tags.
+// Теги хранятся на сервере зашифрованными, поэтому секреты выбираются по токенам слепого индекса тегов,
+// данные секретов при этом не загружаются. Выбор доступен только хранилищу с ключом хранилища.
+func (store *RemoteStorage) FindByTags(ctx context.Context, tags []string) ([]uint64, error) {
+	if store.vaultKey == nil {
+		return nil, fmt.Errorf("FindByTags(): %w", ErrVaultKeyRequired)
+	}
+
+	tags, err := domain.NormalizeTags(tags)
+	if err != nil {
+		return nil, fmt.Errorf("FindByTags(): %w", err)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("FindByTags(): %w", ErrNoSearchWords)
+	}
+
+	key, err := crypto.DeriveTagKey(store.vaultKey)
+	if err != nil {
+		return nil, fmt.Errorf("FindByTags(): %w", err)
+	}
+
+	var ids []uint64
+
+	query := &domain.SecretQuery{SearchTokens: crypto.BlindIndex(key, tags)}
+	for {
+		page, err := store.client.SearchSecrets(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range page.Secrets {
+			ids = append(ids, s.ID)
+		}
+		if page.Next == nil {
+			return ids, nil
+		}
+		query.After = page.Next
+	}
+}
+
+// Search возвращает идентификаторы секретов, в заголовке, тегах или ссылках которых есть все слова строки text.
+// Сервер выбирает секреты по токенам слепого индекса, поэтому не узнает ни слов запроса, ни содержимого секретов.
+// Поиск доступен только хранилищу с ключом хранилища; секреты, сохраненные до появления поиска,
+// находятся после их следующего сохранения.
+func (store *RemoteStorage) Search(ctx context.Context, text string) ([]uint64, error) {
+	if store.vaultKey == nil {
+		return nil, fmt.Errorf("Search(): %w", ErrVaultKeyRequired)
+	}
+
+	words := appendWords(crypto.IndexWords(text), crypto.URLWords(text)...)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("Search(): %w", ErrNoSearchWords)
+	}
+
+	key, err := crypto.DeriveSearchKey(store.vaultKey)
+	if err != nil {
+		return nil, fmt.Errorf("Search(): %w", err)
+	}
+
+	var ids []uint64
+
+	query := &domain.SecretQuery{SearchTokens: crypto.BlindIndex(key, words)}
+	for {
+		page, err := store.client.SearchSecrets(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range page.Secrets {
+			ids = append(ids, s.ID)
+		}
+		if page.Next == nil {
+			return ids, nil
+		}
+		query.After = page.Next
+	}
+}
+
+// index приводит теги секрета к каноническому виду и вычисляет токены слепого индекса секрета: токены тегов
+// и токены слов заголовка, тегов и ссылок в заголовке, метаданных и тексте.
+func (store *RemoteStorage) index(secret *domain.Secret) error {
+	secret.SearchTokens = nil
+	if store.vaultKey == nil {
+		return fmt.Errorf("index(): %w", ErrVaultKeyRequired)
+	}
+
+	// Теги шифруются, поэтому сервер больше не приводит их к каноническому виду
+	tags, err := domain.NormalizeTags(secret.Tags)
+	if err != nil {
+		return fmt.Errorf("index(): %w", err)
+	}
+	secret.Tags = tags
+
+	words := appendWords(crypto.IndexWords(secret.Title), crypto.URLWords(secret.Title)...)
+	for _, tag := range secret.Tags {
+		words = appendWords(words, strings.ToLower(tag))
+		words = appendWords(words, crypto.IndexWords(tag)...)
+	}
+	words = appendWords(words, crypto.URLWords(secret.Metadata)...)
+	if secret.Text != nil {
+		words = appendWords(words, crypto.URLWords(secret.Text.Content)...)
+	}
+
+	key, err := crypto.DeriveSearchKey(store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("index(): %w", err)
+	}
+	tagKey, err := crypto.DeriveTagKey(store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("index(): %w", err)
+	}
+
+	// Токены тегов, слова заголовка и тегов добавлены первыми, поэтому при усечении теряются только ссылки из данных
+	tokens := crypto.BlindIndex(tagKey, secret.Tags)
+	secret.SearchTokens = append(tokens, crypto.BlindIndex(key, words[:min(len(words), domain.MaxSearchTokens-len(tokens))])...)
+
+	return nil
+}
+
+// appendWords добавляет к словам words слова found, которых среди них еще нет
+func appendWords(words []string, found ...string) []string {
+	for _, word := range found {
+		if !slices.Contains(words, word) {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// Folders загружает все папки хранилища, расшифровывает их названия и упорядочивает папки по названию.
+func (store *RemoteStorage) Folders(ctx context.Context) ([]*domain.Folder, error) {
+	folders, err := store.client.ListFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range folders {
+		if err = store.openFolderName(folder); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folders[i].Name < folders[j].Name
+	})
+
+	return folders, nil
+}
+
+// CreateFolder создает папку в папке parentID; нулевой parentID создает папку в корне хранилища.
+// Название шифруется с идентификатором папки, который еще не известен, поэтому папка создается без названия
+// и сразу переименовывается; если переименовать ее не удалось, папка удаляется.
+func (store *RemoteStorage) CreateFolder(ctx context.Context, parentID uint64, name string) (*domain.Folder, error) {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = store.ensureVaultKey(ctx); err != nil {
+		return nil, err
+	}
+
+	folders, err := store.Folders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkFolderName(folders, 0, parentID, name); err != nil {
+		return nil, err
+	}
+
+	folder, err := store.client.CreateFolder(ctx, parentID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.renameFolder(ctx, folder.ID, name); err != nil {
+		_ = store.client.DeleteFolder(context.WithoutCancel(ctx), folder.ID)
+		return nil, err
+	}
+	folder.Name = name
+
+	return folder, nil
+}
+
+// RenameFolder изменяет название папки.
+func (store *RemoteStorage) RenameFolder(ctx context.Context, id uint64, name string) error {
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return err
+	}
+	if err = store.ensureVaultKey(ctx); err != nil {
+		return err
+	}
+
+	folders, err := store.Folders(ctx)
+	if err != nil {
+		return err
+	}
+	if folder := findFolder(folders, id); folder != nil {
+		if err = checkFolderName(folders, id, folder.ParentID, name); err != nil {
+			return err
+		}
+	}
+
+	return store.renameFolder(ctx, id, name)
+}
+
+// MoveFolder перемещает папку в папку parentID; нулевой parentID перемещает папку в корень хранилища.
+func (store *RemoteStorage) MoveFolder(ctx context.Context, id, parentID uint64) error {
+	folders, err := store.Folders(ctx)
+	if err != nil {
+		return err
+	}
+	if folder := findFolder(folders, id); folder != nil {
+		if err = checkFolderName(folders, id, parentID, folder.Name); err != nil {
+			return err
+		}
+	}
+
+	return store.client.MoveFolder(ctx, id, parentID)
+}
+
+// DeleteFolder удаляет папку, перенося ее содержимое в родительскую папку.
+// Новое расположение секретов попадает в локальную копию хранилища при следующей синхронизации.
+func (store *RemoteStorage) DeleteFolder(ctx context.Context, id uint64) error {
+	return store.client.DeleteFolder(ctx, id)
+}
+
+// MoveSecrets перемещает секреты в папку folderID и возвращает количество перемещенных секретов.
+// Перемещение увеличивает номер редакции секретов, поэтому их копии в кеше больше не используются.
+func (store *RemoteStorage) MoveSecrets(ctx context.Context, ids []uint64, folderID uint64) (int, error) {
+	moved, err := store.client.MoveSecrets(ctx, ids, folderID)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(moved), nil
+}
+
+// Versions загружает сохраненные редакции секрета, начиная с последней, и расшифровывает их.
+// Редакции хранятся с ключом данных и идентификатором исходного секрета, поэтому расшифровываются так же, как сам секрет.
+// Содержимое файлов, хранящихся на сервере вне базы данных, в редакциях не передается, такие редакции возвращаются без данных.
+func (store *RemoteStorage) Versions(ctx context.Context, secretID uint64) ([]*domain.SecretVersion, error) {
+	versions, err := store.client.ListSecretVersions(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range versions {
+		if err = store.openFields(v.Secret); err != nil {
+			return nil, fmt.Errorf("failed to decrypt version %d: %w", v.ID, err)
+		}
+
+		if len(v.Secret.Payload) == 0 {
+			continue
+		}
+
+		if err = store.decryptPayload(v.Secret); err != nil {
+			return nil, fmt.Errorf("failed to decrypt version %d: %w", v.ID, err)
+		}
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion восстанавливает секрет из редакции versionID. Текущее состояние секрета
+// при этом сохраняется на сервере как новая редакция. Возвращает расшифрованный восстановленный секрет.
+func (store *RemoteStorage) RestoreVersion(ctx context.Context, secretID, versionID uint64) (*domain.Secret, error) {
+	secret, err := store.client.RestoreSecretVersion(ctx, secretID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.openFields(secret); err != nil {
+		return nil, err
+	}
+
+	if len(secret.Payload) == 0 {
+		return secret, nil
+	}
+
+	if err = store.decryptPayload(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// UploadFile шифрует файл path потоком и передает его на сервер как данные файлового секрета.
+// Файл не загружается в память целиком: он шифруется сегментами во временный файл, который передается
+// в сессию загрузки; после обрыва соединения передача продолжается с позиции, до которой сервер получил данные.
+// Для нового секрета сначала создается пустая запись, которая удаляется, если передача не удалась.
+// Название и метаданные существующего секрета сохраняются до передачи, чтобы конфликт ревизий
+// обнаруживался до загрузки файла.
+func (store *RemoteStorage) UploadFile(ctx context.Context, secret *domain.Secret, path string, progress Progress) (err error) {
+	if err = store.ensureVaultKey(ctx); err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+	defer file.Close()
+
+	header, err := json.Marshal(blobHeader{FileName: filepath.Base(path)})
+	if err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+
+	if err = store.index(secret); err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+
+	created := secret.ID == 0
+	if created {
+		secret.ID, err = store.placeholder(ctx, secret)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			if err != nil {
+				_ = store.client.DeleteSecret(context.WithoutCancel(ctx), secret.ID)
+				secret.ID = 0
+			}
+		}()
+	} else {
+		// Название и метаданные существующего секрета сохраняются без замены загруженных данных
+		metadata := *secret
+		metadata.Payload, metadata.DataKey = nil, nil
+		if _, err = store.save(ctx, &metadata); err != nil {
+			return err
+		}
+		secret.Revision = metadata.Revision
+	}
+
+	dataKey, err := crypto.NewKey()
+	if err != nil {
+		return fmt.Errorf("UploadFile(): failed to generate data key: %w", err)
+	}
+
+	wrapped, err := crypto.WrapKey(dataKey, store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+
+	// Шифротекст сохраняется во временный файл, чтобы после обрыва соединения передать его с той же позиции:
+	// повторное шифрование дало бы другие данные из-за случайного nonce
+	spool, err := os.CreateTemp("", "gophkeeper-upload-*")
+	if err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	if err = encryptBlob(spool, dataKey, store.secretAAD(secret), header, file); err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+
+	total, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("UploadFile(): %w", err)
+	}
+
+	session, err := store.client.CreateUploadSession(ctx, secret.ID, wrapped, total)
+	if err != nil {
+		return err
+	}
+
+	if err = store.uploadSession(ctx, session, spool, progress); err != nil {
+		return err
+	}
+
+	secret.Blob = &domain.Blob{FileName: filepath.Base(path)}
+	// Редакция секрета после загрузки известна только серверу, поэтому он будет загружен заново
+	store.forget(secret.ID)
+
+	return nil
+}
+
+// uploadSession передает данные src в сессию загрузки. После ошибки передачи позиция, до которой сервер
+// получил данные, запрашивается заново, и передача продолжается с нее, пока не будут исчерпаны попытки.
+func (store *RemoteStorage) uploadSession(ctx context.Context, session *domain.UploadSession, src io.ReadSeeker, progress Progress) error {
+	offset := session.Received
+
+	for attempt := 1; ; attempt++ {
+		if _, err := src.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("uploadSession(): %w", err)
+		}
+
+		reader := &progressReader{r: src, done: offset, total: session.Size, progress: progress}
+		received, completed, err := store.client.UploadBlob(ctx, session.ID, offset, reader)
+		if err == nil && completed {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("upload stopped at %d of %d bytes", received, session.Size)
+		}
+
+		if attempt == uploadAttempts || ctx.Err() != nil {
+			return fmt.Errorf("uploadSession(): %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * uploadRetryDelay):
+		}
+
+		current, getErr := store.client.GetUploadSession(ctx, session.ID)
+		if getErr != nil {
+			return fmt.Errorf("uploadSession(): %w (%w)", err, getErr)
+		}
+		offset = current.Received
+	}
+}
+
+// DownloadFile получает с сервера данные файлового секрета, расшифровывает их и сохраняет в файл path.
+// Данные записываются во временный файл рядом с path, который переименовывается только после
+// успешной расшифровки всего потока, поэтому при подмене или обрыве данных файл path не изменяется.
+func (store *RemoteStorage) DownloadFile(ctx context.Context, secret *domain.Secret, path string, progress Progress) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	blob, err := store.client.DownloadBlob(ctx, secret.ID)
+	if err != nil {
+		return err
+	}
+
+	src := bufio.NewReader(&progressReader{r: blob, total: blob.Size, progress: progress})
+
+	prefix, err := src.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("DownloadFile(): %w", err)
+	}
+
+	var content io.Reader
+	if crypto.IsStream(prefix) {
+		content, err = store.decryptBlob(src, blob.DataKey, secret)
+	} else {
+		content, err = store.decryptLegacyBlob(src, blob.DataKey, secret)
+	}
+	if err != nil {
+		return fmt.Errorf("DownloadFile(): %w", err)
+	}
+
+	if err = writeFileAtomic(path, content); err != nil {
+		return fmt.Errorf("DownloadFile(): %w", err)
+	}
+
+	return nil
+}
+
+// decryptBlob расшифровывает поток данных файла и возвращает содержимое файла после заголовка.
+func (store *RemoteStorage) decryptBlob(src io.Reader, wrappedKey []byte, secret *domain.Secret) (io.Reader, error) {
+	if store.vaultKey == nil {
+		return nil, ErrVaultKeyRequired
+	}
+
+	dataKey, err := crypto.UnwrapKey(wrappedKey, store.vaultKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data key: %w", err)
+	}
+
+	plain, err := crypto.NewDecryptReader(src, dataKey, store.secretAAD(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := readBlobHeader(plain)
+	if err != nil {
+		return nil, err
+	}
+	secret.Blob = &domain.Blob{FileName: header.FileName}
+
+	return plain, nil
+}
+
+// decryptLegacyBlob расшифровывает файл, сохраненный до перехода на потоковое шифрование целиком с данными секрета.
+func (store *RemoteStorage) decryptLegacyBlob(src io.Reader, wrappedKey []byte, secret *domain.Secret) (io.Reader, error) {
+	payload, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Payload, secret.DataKey = payload, wrappedKey
+	if err = store.decryptPayload(secret); err != nil {
+		return nil, err
+	}
+	if secret.Blob == nil {
+		return nil, errors.New("empty blob")
+	}
+
+	return bytes.NewReader(secret.Blob.FileBytes), nil
+}
+
+// ensureVaultKey переводит хранилище без ключа хранилища на конвертное шифрование перед сохранением секрета.
+// Обычно перевод выполняется при входе; если он тогда не удался, без ключа хранилища заголовок и метаданные секрета
+// пришлось бы сохранить открытыми, поэтому перевод повторяется. Хранилище, созданное до появления параметров KDF,
+// переводится на параметры Argon2id по умолчанию.
+func (store *RemoteStorage) ensureVaultKey(ctx context.Context) error {
+	if store.vaultKey != nil {
+		return nil
+	}
+
+	params := store.client.GetKDFParams()
+	if params.Algorithm == string(domain.KDFLegacy) {
+		var err error
+		if params, err = crypto.NewKDFParams(domain.KDFArgon2id); err != nil {
+			return fmt.Errorf("%w: %w", ErrVaultKeyRequired, err)
+		}
+	}
+
+	if err := store.UpgradeKDF(ctx, params); err != nil {
+		return fmt.Errorf("%w: the vault could not be upgraded to encrypt secret titles, log in again to retry: %w",
+			ErrVaultKeyRequired, err)
+	}
+
+	return nil
+}
+
+// HasVaultKey сообщает, переведено ли хранилище на конвертное шифрование.
+func (store *RemoteStorage) HasVaultKey() bool {
+	return store.vaultKey != nil
 }
 
-// NewRemoteStorage создает новый экземпляр RemoteStorage с предварительно вычисленным ключом шифрования.
-func NewRemoteStorage(client grpc.ClientGRPCInterface) (*RemoteStorage, error) {
-	deriveKey, err := crypto.DeriveKey(client.GetPassword(), "")
+// SealFields шифрует заголовки, метаданные и теги секретов, сохраненных открытым текстом до перехода на шифрование полей,
+// и возвращает количество зашифрованных секретов. Секреты сохраняются без данных, поэтому содержимое не перешифровывается;
+// токены поиска при этом вычисляются заново. Секреты, измененные другим клиентом во время перехода, остаются открытыми
+// до следующего вызова. Редакции секретов и секреты в корзине сохранить нельзя, поэтому после того, как зашифрованы
+// все секреты, их открытые поля, включая сохраненные в редакциях при самом переходе, шифруются и передаются серверу на замену.
+// Затем шифруются названия папок.
+// Когда зашифровано все, ключ хранилища перешифровывается с отметкой об этом, и хранилище с отметкой больше не переводится.
+func (store *RemoteStorage) SealFields(ctx context.Context) (int, error) {
+	if store.vaultKey == nil || store.fieldsSealed {
+		return 0, nil
+	}
+
+	var secrets []*domain.Secret
+	for since, more := uint64(0), true; more; {
+		changes, err := store.client.ListChanges(ctx, since)
+		if err != nil {
+			return 0, fmt.Errorf("SealFields(): %w", err)
+		}
+		secrets = append(secrets, changes.Secrets...)
+		since, more = changes.Revision, changes.More
+	}
+
+	var (
+		sealed int
+		errs   []error
+		err    error
+	)
+	for _, s := range secrets {
+		if s.PayloadSize == 0 || (crypto.IsSealedField(s.Title) && crypto.IsSealedField(s.Metadata) && len(s.Tags) == 0) {
+			continue
+		}
+
+		if err = store.sealSecretFields(ctx, s); err != nil {
+			errs = append(errs, fmt.Errorf("secret %d: %w", s.ID, err))
+			continue
+		}
+		sealed++
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return sealed, fmt.Errorf("SealFields(): %w", err)
+	}
+
+	if err = store.sealStoredFields(ctx); err != nil {
+		return sealed, fmt.Errorf("SealFields(): %w", err)
+	}
+
+	if err = store.sealFolderNames(ctx); err != nil {
+		return sealed, fmt.Errorf("SealFields(): %w", err)
+	}
+
+	// Ключ хранилища перешифровывается с отметкой, после которой открытые поля больше не принимаются
+	err = store.changePassword(ctx, store.client.GetMasterKeys().AuthHash, store.client.GetPassword(), store.client.GetKDFParams(), true)
 	if err != nil {
-		return nil, err
+		return sealed, fmt.Errorf("SealFields(): failed to mark vault fields as sealed: %w", err)
 	}
-	return &RemoteStorage{
-		client:    client,
-		deriveKey: deriveKey,
-	}, nil
+
+	return sealed, nil
 }
 
-// Get извлекает секрет по его идентификатору, расшифровывает его и возвращает.
-func (store *RemoteStorage) Get(_ context.Context, id uint64) (*domain.Secret, error) {
-	secret, err := store.client.LoadSecret(context.Background(), id)
+// sealFolderNames шифрует названия папок, сохраненные открытым текстом до перехода на шифрование названий
+func (store *RemoteStorage) sealFolderNames(ctx context.Context) error {
+	folders, err := store.client.ListFolders(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = store.decryptPayload(secret)
-	if err != nil {
-		return nil, err
+	for _, folder := range folders {
+		if folder.Name == "" || crypto.IsSealedField(folder.Name) {
+			continue
+		}
+
+		if err = store.renameFolder(ctx, folder.ID, folder.Name); err != nil {
+			return fmt.Errorf("folder %d: %w", folder.ID, err)
+		}
 	}
 
-	return secret, nil
+	return nil
 }
 
-// GetAll извлекает все секреты пользователя, расшифровывает их и возвращает.
-func (store *RemoteStorage) GetAll(_ context.Context) ([]*domain.Secret, error) {
-	secrets, err := store.client.LoadSecrets(context.Background())
-	if err != nil {
-		return nil, err
+// sealStoredFields шифрует открытые поля редакций секретов и секретов в корзине и заменяет их на сервере пакетами.
+// Сервер изменяет только записи, поля которых все еще открыты, поэтому каждый следующий пакет содержит новые записи.
+func (store *RemoteStorage) sealStoredFields(ctx context.Context) error {
+	for {
+		records, err := store.client.ListPlaintextFields(ctx)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		for _, record := range records {
+			if err = store.openFields(record.Secret); err != nil {
+				return fmt.Errorf("record %d of secret %d: %w", record.ID, record.Secret.ID, err)
+			}
+			if err = store.sealFields(record.Secret); err != nil {
+				return fmt.Errorf("record %d of secret %d: %w", record.ID, record.Secret.ID, err)
+			}
+		}
+
+		sealed, err := store.client.SealPlaintextFields(ctx, records)
+		if err != nil {
+			return err
+		}
+		if sealed == 0 {
+			return fmt.Errorf("server did not replace plaintext fields of %d records", len(records))
+		}
+	}
+}
+
+// sealSecretFields сохраняет секрет summary без данных с зашифрованными заголовком, метаданными и тегами
+func (store *RemoteStorage) sealSecretFields(ctx context.Context, summary *domain.Secret) error {
+	secret := *summary
+	if err := store.openFields(&secret); err != nil {
+		return err
 	}
 
-	for _, s := range secrets {
-		err = store.decryptPayload(s)
+	// Ссылки из текста попадают в индекс поиска, поэтому текстовые секреты расшифровываются целиком
+	if secret.SecretType == string(domain.TextSecret) {
+		full, err := store.Get(ctx, secret.ID)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		secret.Text = full.Text
 	}
 
-	return secrets, nil
+	if err := store.index(&secret); err != nil {
+		return err
+	}
+
+	secret.Payload, secret.DataKey = nil, nil
+	if _, err := store.save(ctx, &secret); err != nil {
+		return err
+	}
+
+	store.forget(secret.ID)
+
+	return nil
 }
 
-// Create создает новый секрет в хранилище, предварительно зашифровав его.
-func (store *RemoteStorage) Create(_ context.Context, secret *domain.Secret) (err error) {
-	err = store.encryptPayload(secret)
+// UpgradeKDF переводит хранилище на новые параметры KDF и на формирование хеша аутентификации из мастер-ключа.
+// Хеш аутентификации зависит от параметров KDF, поэтому перевод выполняется сменой пароля на тот же самый.
+func (store *RemoteStorage) UpgradeKDF(ctx context.Context, params domain.KDFParams) error {
+	err := store.changePassword(ctx, store.client.GetMasterKeys().AuthHash, store.client.GetPassword(), params, store.fieldsSealed)
 	if err != nil {
-		return
+		return fmt.Errorf("UpgradeKDF(): %w", err)
 	}
 
-	err = store.client.SaveSecret(context.Background(), secret)
-	return err
+	return nil
 }
 
-// Update обновляет существующий секрет, предварительно зашифровав его.
-func (store *RemoteStorage) Update(_ context.Context, secret *domain.Secret) (err error) {
-	err = store.encryptPayload(secret)
+// ChangePassword меняет мастер-пароль: ключ хранилища шифруется ключом, сформированным из нового пароля
+// по новым параметрам KDF, и сохраняется на сервере вместе с новым хешем аутентификации одной транзакцией.
+// Все вычисления выполняются до запроса к серверу, поэтому при сбое клиента до его завершения
+// продолжает действовать старый пароль, а повтор смены после сбоя завершает ее.
+func (store *RemoteStorage) ChangePassword(ctx context.Context, oldPassword, newPassword string, params domain.KDFParams) error {
+	oldKeys, err := crypto.DeriveKeys(oldPassword, store.client.GetKDFParams(), store.client.GetAuthScheme())
 	if err != nil {
-		return
+		return fmt.Errorf("ChangePassword(): failed to derive keys: %w", err)
 	}
 
-	err = store.client.SaveSecret(context.Background(), secret)
-	return err
+	if err = store.changePassword(ctx, oldKeys.AuthHash, newPassword, params, store.fieldsSealed); err != nil {
+		return fmt.Errorf("ChangePassword(): %w", err)
+	}
+
+	return nil
 }
 
-// Delete удаляет секрет по его идентификатору.
-func (store *RemoteStorage) Delete(_ context.Context, id uint64) (err error) {
-	err = store.client.DeleteSecret(context.Background(), id)
-	return err
+// changePassword формирует ключи нового пароля, перешифровывает ими ключ хранилища и сохраняет его на сервере.
+// Если sealed равен true, ключ хранилища шифруется с отметкой о том, что поля всех секретов зашифрованы.
+func (store *RemoteStorage) changePassword(ctx context.Context, oldAuthHash, newPassword string, params domain.KDFParams, sealed bool) error {
+	newKeys, err := crypto.DeriveKeys(newPassword, params, domain.AuthSchemeDerived)
+	if err != nil {
+		return fmt.Errorf("failed to derive keys: %w", err)
+	}
+
+	vaultKey, wrapped, secrets, err := store.rewrapVault(ctx, newKeys.EncryptionKey, sealed)
+	if err != nil {
+		return err
+	}
+
+	if err = store.client.ChangePassword(ctx, oldAuthHash, newPassword, newKeys, params, wrapped, secrets); err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	newKey := newKeys.EncryptionKey
+
+	// Смена была выполнена предыдущим запросом: сервер вернул сохраненный тогда ключ хранилища
+	if !bytes.Equal(store.client.GetVaultKey(), wrapped) {
+		reopened, err := NewRemoteStorage(store.client)
+		if err != nil {
+			return err
+		}
+		newKey, vaultKey, sealed = reopened.deriveKey, reopened.vaultKey, reopened.fieldsSealed
+	}
+
+	store.deriveKey = newKey
+	store.vaultKey = vaultKey
+	store.fieldsSealed = sealed
+
+	// Секреты хранилищ без ключа хранилища перешифрованы, поэтому кеш их прежних данных сбрасывается
+	store.mu.Lock()
+	store.cache = newPayloadCache(payloadCacheSize)
+	store.mu.Unlock()
+
+	return nil
+}
+
+// rewrapVault шифрует ключ хранилища ключом newKey, с отметкой о шифровании полей секретов, если sealed равен true.
+// Хранилище без ключа хранилища при этом переводится на конвертное шифрование: все секреты, включая секреты в корзине,
+// расшифровываются текущим ключом и шифруются новыми ключами данных, чтобы сохранить их одной транзакцией.
+func (store *RemoteStorage) rewrapVault(ctx context.Context, newKey []byte, sealed bool) (vaultKey, wrapped []byte, secrets []*domain.Secret, err error) {
+	vaultKey = store.vaultKey
+
+	if vaultKey == nil {
+		vaultKey, err = crypto.NewKey()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to generate vault key: %w", err)
+		}
+
+		secrets, err = store.client.LoadSecrets(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load secrets: %w", err)
+		}
+
+		// Секреты в корзине тоже перешифровываются, чтобы их можно было восстановить после смены пароля
+		trash, err := store.client.ListTrash(ctx)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load trash: %w", err)
+		}
+		secrets = append(secrets, trash...)
+
+		for _, secret := range secrets {
+			// Записи без данных передаются как есть, чтобы сервер убедился, что перешифрованы все секреты
+			if len(secret.Payload) == 0 {
+				secret.DataKey = nil
+				continue
+			}
+
+			if err = store.decryptPayload(secret); err != nil {
+				return nil, nil, nil, err
+			}
+			if err = store.sealWithDataKey(secret, vaultKey); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	wrapped, err = crypto.WrapVaultKey(vaultKey, newKey, sealed)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return vaultKey, wrapped, secrets, nil
 }
 
 func (store *RemoteStorage) String() string {
 	return "remote storage"
 }
 
-// encryptPayload шифрует данные секрета перед сохранением.
-func (store *RemoteStorage) encryptPayload(secret *domain.Secret) (err error) {
+// sealWithDataKey шифрует данные секрета новым случайным ключом данных,
+// а сам ключ данных - ключом хранилища vaultKey.
+func (store *RemoteStorage) sealWithDataKey(secret *domain.Secret, vaultKey []byte) error {
+	dataKey, err := crypto.NewKey()
+	if err != nil {
+		return fmt.Errorf("sealWithDataKey(): failed to generate data key: %w", err)
+	}
+
+	if err = encryptPayload(secret, dataKey, store.secretAAD(secret)); err != nil {
+		return err
+	}
+
+	secret.DataKey, err = crypto.WrapKey(dataKey, vaultKey)
+	if err != nil {
+		return fmt.Errorf("sealWithDataKey(): %w", err)
+	}
+
+	return nil
+}
+
+// placeholder создает на сервере пустую запись нового секрета secret, записывает в secret номер ее редакции
+// и возвращает ее идентификатор. Поля секрета шифруются с его идентификатором, который еще не известен,
+// поэтому запись сохраняется без заголовка, метаданных, тегов и токенов поиска.
+func (store *RemoteStorage) placeholder(ctx context.Context, secret *domain.Secret) (uint64, error) {
+	record := &domain.Secret{SecretType: secret.SecretType, FolderID: secret.FolderID, ExpiresAt: secret.ExpiresAt}
+
+	id, err := store.client.SaveSecret(ctx, record)
+	secret.Revision = record.Revision
+
+	return id, err
+}
+
+// save сохраняет секрет на сервере и записывает в secret номер новой редакции.
+// Заголовок, метаданные и теги передаются зашифрованными, а в самом secret остаются открытыми.
+func (store *RemoteStorage) save(ctx context.Context, secret *domain.Secret) (uint64, error) {
+	sealed := *secret
+	if err := store.sealFields(&sealed); err != nil {
+		return 0, err
+	}
+
+	id, err := store.client.SaveSecret(ctx, &sealed)
+	secret.Revision = sealed.Revision
+
+	return id, err
+}
+
+// sealFields шифрует заголовок и метаданные секрета вместе с тегами ключом, выведенным из ключа хранилища,
+// поэтому сервер видит только их шифротекст и размер. Хранилище без ключа хранилища не может сохранить секрет,
+// не раскрыв его полей, поэтому сохранение завершается ошибкой ErrVaultKeyRequired.
+func (store *RemoteStorage) sealFields(secret *domain.Secret) (err error) {
+	if store.vaultKey == nil {
+		return fmt.Errorf("sealFields(): %w", ErrVaultKeyRequired)
+	}
+
+	key, err := crypto.DeriveFieldKey(store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("sealFields(): %w", err)
+	}
+
+	if secret.Title, err = crypto.SealField(secret.Title, key, store.fieldAAD(secret, titleField)); err != nil {
+		return fmt.Errorf("sealFields(): %w", err)
+	}
+
+	metadata, err := json.Marshal(sealedMetadata{Metadata: secret.Metadata, Tags: secret.Tags})
+	if err != nil {
+		return fmt.Errorf("sealFields(): %w", err)
+	}
+	if secret.Metadata, err = crypto.SealField(string(metadata), key, store.fieldAAD(secret, metadataField)); err != nil {
+		return fmt.Errorf("sealFields(): %w", err)
+	}
+	secret.Tags = nil
+
+	return nil
+}
+
+// openFields расшифровывает заголовок, метаданные и теги секрета, полученного с сервера.
+// Пока ключ хранилища не отмечен как ключ хранилища с зашифрованными полями, поля, сохраненные до перехода на их
+// шифрование, остаются как есть. После отметки открытое поле означает, что его подменил сервер, и расшифровка
+// завершается ошибкой crypto.ErrFieldNotSealed. Пустыми могут быть только одновременно заголовок и метаданные
+// записи, которую клиент создает перед сохранением нового секрета.
+func (store *RemoteStorage) openFields(secret *domain.Secret) (err error) {
+	if !crypto.IsSealedField(secret.Title) && !crypto.IsSealedField(secret.Metadata) {
+		if !store.fieldsSealed || (secret.Title == "" && secret.Metadata == "" && len(secret.Tags) == 0) {
+			return nil
+		}
+	}
+	if store.vaultKey == nil {
+		return fmt.Errorf("openFields(): %w", ErrVaultKeyRequired)
+	}
+
+	key, err := crypto.DeriveFieldKey(store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("openFields(): %w", err)
+	}
+
+	if secret.Title, err = store.openField(secret.Title, key, store.fieldAAD(secret, titleField)); err != nil {
+		return fmt.Errorf("openFields(): failed to decrypt title of secret %d: %w", secret.ID, err)
+	}
+	if !store.fieldsSealed && !crypto.IsSealedField(secret.Metadata) {
+		return nil
+	}
+
+	plaintext, err := store.openField(secret.Metadata, key, store.fieldAAD(secret, metadataField))
+	if err != nil {
+		return fmt.Errorf("openFields(): failed to decrypt metadata of secret %d: %w", secret.ID, err)
+	}
+
+	var metadata sealedMetadata
+	if err = json.Unmarshal([]byte(plaintext), &metadata); err != nil {
+		return fmt.Errorf("openFields(): failed to decode metadata of secret %d: %w", secret.ID, err)
+	}
+	secret.Metadata, secret.Tags = metadata.Metadata, metadata.Tags
+
+	return nil
+}
+
+// openField расшифровывает значение текстового поля. Открытое значение возвращается как есть,
+// только пока поля хранилища не отмечены как зашифрованные.
+func (store *RemoteStorage) openField(value string, key, aad []byte) (string, error) {
+	if !store.fieldsSealed && !crypto.IsSealedField(value) {
+		return value, nil
+	}
+
+	return crypto.OpenField(value, key, aad)
+}
+
+// renameFolder шифрует название папки id и сохраняет его на сервере
+func (store *RemoteStorage) renameFolder(ctx context.Context, id uint64, name string) error {
+	if store.vaultKey == nil {
+		return fmt.Errorf("renameFolder(): %w", ErrVaultKeyRequired)
+	}
+
+	key, err := crypto.DeriveFieldKey(store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("renameFolder(): %w", err)
+	}
+
+	sealed, err := crypto.SealField(name, key, store.folderAAD(id))
+	if err != nil {
+		return fmt.Errorf("renameFolder(): %w", err)
+	}
+
+	return store.client.RenameFolder(ctx, id, sealed)
+}
+
+// openFolderName расшифровывает название папки, полученной с сервера. Папка без названия, созданная прерванным
+// CreateFolder, остается без названия. Открытое название, сохраненное до перехода на шифрование названий,
+// допускается, только пока ключ хранилища не отмечен как ключ хранилища с зашифрованными полями.
+func (store *RemoteStorage) openFolderName(folder *domain.Folder) error {
+	if folder.Name == "" || (!store.fieldsSealed && !crypto.IsSealedField(folder.Name)) {
+		return nil
+	}
+	if store.vaultKey == nil {
+		return fmt.Errorf("openFolderName(): %w", ErrVaultKeyRequired)
+	}
+
+	key, err := crypto.DeriveFieldKey(store.vaultKey)
+	if err != nil {
+		return fmt.Errorf("openFolderName(): %w", err)
+	}
+
+	if folder.Name, err = crypto.OpenField(folder.Name, key, store.folderAAD(folder.ID)); err != nil {
+		return fmt.Errorf("openFolderName(): failed to decrypt name of folder %d: %w", folder.ID, err)
+	}
+
+	return nil
+}
+
+// folderAAD формирует дополнительные данные шифрования названия папки: идентификатор папки и владельца.
+// Поэтому сервер не может незаметно подменить название одной папки названием другой.
+func (store *RemoteStorage) folderAAD(id uint64) []byte {
+	aad := make([]byte, 0, len(folderAADPrefix)+16)
+	aad = append(aad, folderAADPrefix...)
+	aad = binary.BigEndian.AppendUint64(aad, id)
+	aad = binary.BigEndian.AppendUint64(aad, uint64(store.client.GetUserID()))
+	return aad
+}
+
+// fieldAAD формирует дополнительные данные шифрования текстового поля: идентификатор секрета, владельца и название поля.
+// Поэтому сервер не может незаметно подменить заголовок одного секрета заголовком другого.
+func (store *RemoteStorage) fieldAAD(secret *domain.Secret, field string) []byte {
+	aad := make([]byte, 0, len(fieldAADPrefix)+16+len(field))
+	aad = append(aad, fieldAADPrefix...)
+	aad = binary.BigEndian.AppendUint64(aad, secret.ID)
+	aad = binary.BigEndian.AppendUint64(aad, uint64(store.client.GetUserID()))
+	aad = append(aad, field...)
+	return aad
+}
+
+// secretAAD формирует дополнительные данные шифрования секрета: идентификатор, владельца и тип.
+// Они проверяются при расшифровке, поэтому сервер не может незаметно подменить данные одного секрета
+// данными другого или изменить тип секрета.
+func (store *RemoteStorage) secretAAD(secret *domain.Secret) []byte {
+	aad := make([]byte, 0, len(secretAADPrefix)+16+len(secret.SecretType))
+	aad = append(aad, secretAADPrefix...)
+	aad = binary.BigEndian.AppendUint64(aad, secret.ID)
+	aad = binary.BigEndian.AppendUint64(aad, uint64(store.client.GetUserID()))
+	aad = append(aad, secret.SecretType...)
+	return aad
+}
+
+// encryptPayload шифрует данные секрета ключом key перед сохранением.
+func encryptPayload(secret *domain.Secret, key, aad []byte) (err error) {
 	data, err := marshalSecret(secret)
 	if err != nil {
 		return fmt.Errorf("encryptPayload(): error serializing data: %w", err)
 	}
 
-	encryptedData, err := crypto.Encrypt(string(data), store.deriveKey)
+	secret.Payload, err = crypto.SealPadded(data, key, aad)
 	if err != nil {
 		return fmt.Errorf("encryptPayload(): error encrypting Data: %w", err)
 	}
 
-	secret.Payload = []byte(encryptedData)
 	return nil
 }
 
 // decryptPayload расшифровывает данные секрета после извлечения.
+// Секреты без ключа данных зашифрованы напрямую ключом шифрования.
+// Данные в прежнем текстовом формате расшифровываются без проверки дополнительных данных.
 func (store *RemoteStorage) decryptPayload(secret *domain.Secret) (err error) {
-	decryptedData, err := crypto.Decrypt(string(secret.Payload), store.deriveKey)
+	key := store.deriveKey
+	if len(secret.DataKey) > 0 {
+		if store.vaultKey == nil {
+			return errors.New("decryptPayload: vault key is not set")
+		}
+
+		key, err = crypto.UnwrapKey(secret.DataKey, store.vaultKey)
+		if err != nil {
+			return fmt.Errorf("decryptPayload: failed to open data key: %w", err)
+		}
+	}
+
+	// Содержимое файла, зашифрованного потоком, получается через DownloadFile; здесь читается только его заголовок
+	if secret.SecretType == string(domain.BlobSecret) && crypto.IsStream(secret.Payload) {
+		plain, err := crypto.NewDecryptReader(bytes.NewReader(secret.Payload), key, store.secretAAD(secret))
+		if err != nil {
+			return fmt.Errorf("decryptPayload: failed to decrypt data: %w", err)
+		}
+
+		header, err := readBlobHeader(plain)
+		if err != nil {
+			return fmt.Errorf("decryptPayload: failed to decrypt data: %w", err)
+		}
+
+		secret.Blob = &domain.Blob{FileName: header.FileName}
+		return nil
+	}
+
+	decryptedData, err := crypto.Open(secret.Payload, key, store.secretAAD(secret))
 	if err != nil {
 		return fmt.Errorf("decryptPayload: failed to decrypt data: %w", err)
-
 	}
 
-	err = unmarshalSecret(secret, []byte(decryptedData))
+	err = unmarshalSecret(secret, decryptedData)
 	if err != nil {
 		return fmt.Errorf("decryptPayload: failed to unmarshal data: %w", err)
 	}
@@ -172,3 +1546,122 @@ func unmarshalSecret(secret *domain.Secret, data []byte) error {
 
 	return err
 }
+
+// encryptBlob шифрует заголовок файла и содержимое file потоком в dst.
+// Заголовок записывается с префиксом длины, чтобы отделить его от содержимого при расшифровке.
+func encryptBlob(dst io.Writer, key, aad, header []byte, file io.Reader) error {
+	w, err := crypto.NewEncryptWriter(dst, key, aad)
+	if err != nil {
+		return err
+	}
+
+	if err = binary.Write(w, binary.BigEndian, uint32(len(header))); err != nil {
+		return err
+	}
+	if _, err = w.Write(header); err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, file); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// readBlobHeader читает заголовок файла из расшифрованного потока.
+func readBlobHeader(r io.Reader) (*blobHeader, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, fmt.Errorf("failed to read blob header: %w", err)
+	}
+	if length > maxBlobHeaderLength {
+		return nil, errors.New("blob header is too long")
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("failed to read blob header: %w", err)
+	}
+
+	var header blobHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to read blob header: %w", err)
+	}
+
+	return &header, nil
+}
+
+// writeFileAtomic записывает данные r во временный файл рядом с path и переименовывает его в path.
+func writeFileAtomic(path string, r io.Reader) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// progressReader сообщает о количестве прочитанных байт
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress Progress
+}
+
+// Read читает данные и вызывает progress с общим количеством прочитанных байт
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if n > 0 && p.progress != nil {
+		p.progress(p.done, p.total)
+	}
+	return n, err
+}
+
+// normalizeFolderName удаляет пробелы по краям названия папки и проверяет его. Сервер получает название
+// зашифрованным и проверить его не может.
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxFolderNameLength || strings.Contains(name, "/") {
+		return "", ErrInvalidFolderName
+	}
+
+	return name, nil
+}
+
+// checkFolderName проверяет, что среди папок folders в папке parentID нет другой папки, кроме id, с названием name.
+// Сервер получает названия зашифрованными, поэтому их уникальность проверяется клиентом.
+func checkFolderName(folders []*domain.Folder, id, parentID uint64, name string) error {
+	for _, folder := range folders {
+		if folder.ID != id && folder.ParentID == parentID && folder.Name == name {
+			return ErrFolderExists
+		}
+	}
+
+	return nil
+}
+
+// findFolder возвращает папку id из списка folders; nil, если ее нет
+func findFolder(folders []*domain.Folder, id uint64) *domain.Folder {
+	for _, folder := range folders {
+		if folder.ID == id {
+			return folder
+		}
+	}
+
+	return nil
+}
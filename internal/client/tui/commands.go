@@ -34,3 +34,39 @@ func SetBodyPane(screen Screen, opts ...NavigateOption) tea.Cmd {
 	opts = append(opts, WithPosition(BodyPane))
 	return NavigateTo(screen, opts...)
 }
+
+// ProgressMsg представляет сообщение о ходе фоновой операции.
+// Text отображается как информационное сообщение, Next ожидает следующее сообщение операции.
+type ProgressMsg struct {
+	Text string
+	Next tea.Cmd
+}
+
+// Transfer создает команду, которая выполняет передачу run в фоне и сообщает о ее ходе в процентах.
+// После завершения передачи выполняется команда, которую возвращает onDone.
+func Transfer(title string, run func(progress func(done, total int64)) error, onDone func(err error) tea.Cmd) tea.Cmd {
+	updates := make(chan ProgressMsg)
+
+	var wait tea.Cmd
+	wait = func() tea.Msg {
+		msg := <-updates
+		if msg.Next == nil {
+			msg.Next = wait
+		}
+		return msg
+	}
+
+	go func() {
+		percent := int64(-1)
+		err := run(func(done, total int64) {
+			if total <= 0 || done*100/total == percent {
+				return
+			}
+			percent = done * 100 / total
+			updates <- ProgressMsg{Text: fmt.Sprintf("%s: %d%%", title, percent)}
+		})
+		updates <- ProgressMsg{Next: onDone(err)}
+	}()
+
+	return wait
+}
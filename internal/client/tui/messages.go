@@ -16,6 +16,7 @@ type NavigationMsg struct {
 	Callback     NavigationCallback
 	Client       grpc.ClientGRPCInterface
 	DisableFocus bool
+	Folder       *uint64
 	Page         Page
 	Position     Position
 	Screen       Screen
@@ -49,6 +50,13 @@ func WithClient(c grpc.ClientGRPCInterface) NavigateOption {
 	}
 }
 
+// WithFolder определяет опцию навигации для установки папки хранилища; 0 соответствует корню хранилища.
+func WithFolder(id uint64) NavigateOption {
+	return func(msg *NavigationMsg) {
+		msg.Folder = &id
+	}
+}
+
 // WithPosition определяет опцию навигации для установки позиции элемента.
 func WithPosition(position Position) NavigateOption {
 	return func(msg *NavigationMsg) {
@@ -38,6 +38,18 @@ const (
 
 	// BlobEditScreen Экран редактирования файлов
 	BlobEditScreen
+
+	// ChangePasswordScreen Экран смены мастер-пароля
+	ChangePasswordScreen
+
+	// SecretHistoryScreen Экран истории секрета
+	SecretHistoryScreen
+
+	// TrashScreen Экран корзины
+	TrashScreen
+
+	// FolderScreen Экран папок хранилища
+	FolderScreen
 )
 
 const (
@@ -21,6 +21,9 @@ type PromptMsg struct {
 	// Клавиша для отмены ввода
 	Cancel key.Binding
 
+	// Варианты действий, выбираемые отдельными клавишами
+	Choices []PromptChoice
+
 	// Начальное значение поля
 	InitialValue string
 
@@ -39,6 +42,7 @@ type Prompt struct {
 	action    PromptAction
 	anyCancel bool
 	cancel    key.Binding
+	choices   []PromptChoice
 	model     textinput.Model
 	trigger   key.Binding
 }
@@ -46,6 +50,15 @@ type Prompt struct {
 // PromptAction определяет тип функции, вызываемой после ввода пользователя.
 type PromptAction func(text string) tea.Cmd
 
+// PromptChoice вариант действия в диалоговом окне, выбираемый нажатием клавиши.
+type PromptChoice struct {
+	// Клавиша выбора варианта
+	Key key.Binding
+
+	// Действие, выполняемое при выборе варианта
+	Action func() tea.Cmd
+}
+
 // StringPrompt создает команду для отображения диалога с однострочным текстовым полем.
 func StringPrompt(prompt string, action PromptAction) tea.Cmd {
 	return CmdHandler(PromptMsg{
@@ -78,6 +91,16 @@ func YesNoPrompt(prompt string, action tea.Cmd) tea.Cmd {
 	})
 }
 
+// ChoicePrompt создает команду для отображения диалога с выбором одного из нескольких действий.
+// Нажатие любой другой клавиши отменяет выбор.
+func ChoicePrompt(prompt string, choices ...PromptChoice) tea.Cmd {
+	return CmdHandler(PromptMsg{
+		Prompt:    fmt.Sprintf("%s: ", prompt),
+		Choices:   choices,
+		AnyCancel: true,
+	})
+}
+
 // NewPrompt создает новое диалоговое окно с пользовательским вводом.
 func NewPrompt(msg PromptMsg) (*Prompt, tea.Cmd) {
 	model := textinput.New()
@@ -92,6 +115,7 @@ func NewPrompt(msg PromptMsg) (*Prompt, tea.Cmd) {
 		action:    msg.Action,
 		trigger:   msg.Key,
 		cancel:    msg.Cancel,
+		choices:   msg.Choices,
 		anyCancel: msg.AnyCancel,
 	}
 	return &prompt, blink
@@ -99,6 +123,12 @@ func NewPrompt(msg PromptMsg) (*Prompt, tea.Cmd) {
 
 // HandleKey обрабатывает ввод с клавиатуры в диалоговом окне.
 func (p *Prompt) HandleKey(msg tea.KeyMsg) (closePrompt bool, cmd tea.Cmd) {
+	for _, choice := range p.choices {
+		if key.Matches(msg, choice.Key) {
+			return true, choice.Action()
+		}
+	}
+
 	switch {
 	case key.Matches(msg, p.trigger):
 		cmd = p.action(p.model.Value())
@@ -133,8 +163,12 @@ func (p *Prompt) View(width int) string {
 
 // HelpBindings возвращает привязки клавиш для действий в диалоговом окне.
 func (p *Prompt) HelpBindings() []key.Binding {
-	bindings := []key.Binding{
-		p.trigger,
+	var bindings []key.Binding
+	for _, choice := range p.choices {
+		bindings = append(bindings, choice.Key)
+	}
+	if len(p.trigger.Keys()) > 0 {
+		bindings = append(bindings, p.trigger)
 	}
 	if p.anyCancel {
 		bindings = append(bindings, key.NewBinding(key.WithHelp("n", "cancel")))
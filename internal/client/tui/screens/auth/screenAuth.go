@@ -4,14 +4,18 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/crypto"
 	"github.com/romanp1989/gophkeeper/internal/client/grpc"
 	"github.com/romanp1989/gophkeeper/internal/client/storage"
 	"github.com/romanp1989/gophkeeper/internal/client/tui"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/components"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/styles"
+	"time"
 )
 
 const (
@@ -31,6 +35,19 @@ const (
 type AuthenticateScreen struct {
 	client     grpc.ClientGRPCInterface
 	inputGroup components.InputGroup
+	kdf        domain.KDFAlgorithm
+	unlockTime time.Duration
+}
+
+// AuthenticateScreenMaker структура для создания экрана AuthenticateScreen с настройками KDF клиента.
+type AuthenticateScreenMaker struct {
+	KDF        domain.KDFAlgorithm
+	UnlockTime time.Duration
+}
+
+// Make создаёт новый экран AuthenticateScreen на основе переданного клиента и настроек KDF.
+func (m AuthenticateScreenMaker) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
+	return NewLoginScreen(msg.Client, m.KDF, m.UnlockTime), nil
 }
 
 type inputOpts struct {
@@ -42,13 +59,16 @@ type inputOpts struct {
 
 // Make создаёт новый экран AuthenticateScreen на основе переданного клиента.
 func (s *AuthenticateScreen) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
-	return NewLoginScreen(msg.Client), nil
+	return NewLoginScreen(msg.Client, s.kdf, s.unlockTime), nil
 }
 
 // NewLoginScreen инициализирует и возвращает новый экран входа/регистрации.
-func NewLoginScreen(client grpc.ClientGRPCInterface) *AuthenticateScreen {
+// kdf и unlockTime определяют параметры формирования ключа для новых хранилищ.
+func NewLoginScreen(client grpc.ClientGRPCInterface, kdf domain.KDFAlgorithm, unlockTime time.Duration) *AuthenticateScreen {
 	m := AuthenticateScreen{
-		client: client,
+		client:     client,
+		kdf:        kdf,
+		unlockTime: unlockTime,
 	}
 
 	inputs := make([]textinput.Model, 2)
@@ -111,28 +131,79 @@ func (s *AuthenticateScreen) Submit(mode Mode) tea.Cmd {
 	case modeLogin:
 		token, err = s.client.Login(context.Background(), login, password)
 	case modeRegister:
-		token, err = s.client.Register(context.Background(), login, password)
+		kdf, kdfErr := newKDFParams(s.kdf, s.unlockTime)
+		if kdfErr != nil {
+			return tui.ReportError(kdfErr)
+		}
+		token, err = s.client.Register(context.Background(), login, password, kdf)
 	}
 
 	if err != nil {
 		commands = append(commands, tui.ReportError(err))
 	} else {
 		s.client.SetToken(token)
-		s.client.SetPassword(password)
 
-		var store storage.Storage
+		var store *storage.RemoteStorage
 		store, err = storage.NewRemoteStorage(s.client)
 		if err != nil {
 			commands = append(commands, tui.ReportError(err))
 		} else {
-			commands = append(commands, tui.ReportInfo("success!"))
+			commands = append(commands, s.upgradeKDF(store))
 			commands = append(commands, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(store)))
+			commands = append(commands, tui.WatchSecrets(s.client))
 		}
 	}
 
 	return tea.Batch(commands...)
 }
 
+// newKDFParams формирует параметры KDF для нового ключа хранилища согласно настройкам клиента.
+func newKDFParams(kdf domain.KDFAlgorithm, unlockTime time.Duration) (domain.KDFParams, error) {
+	if kdf == domain.KDFArgon2id {
+		return crypto.Calibrate(unlockTime)
+	}
+
+	return crypto.NewKDFParams(kdf)
+}
+
+// upgradeKDF переводит хранилище, созданное с другим алгоритмом KDF, на выбранный в настройках клиента,
+// хранилище без ключа хранилища - на конвертное шифрование, а аккаунт, передающий серверу мастер-пароль, -
+// на хеш аутентификации. Затем шифруются заголовки и метаданные секретов, сохраненные открытым текстом.
+// При ошибке хранилище остается на прежних параметрах и продолжает открываться.
+func (s *AuthenticateScreen) upgradeKDF(store *storage.RemoteStorage) tea.Cmd {
+	needsUpgrade := crypto.NeedsUpgrade(s.client.GetKDFParams(), s.kdf)
+	upgraded := needsUpgrade || !store.HasVaultKey() || s.client.GetAuthScheme() != domain.AuthSchemeDerived
+
+	if upgraded {
+		var err error
+		params := s.client.GetKDFParams()
+		if needsUpgrade || params.Algorithm == string(domain.KDFLegacy) {
+			params, err = newKDFParams(s.kdf, s.unlockTime)
+		}
+		if err == nil {
+			err = store.UpgradeKDF(context.Background(), params)
+		}
+
+		if err != nil {
+			return tui.ReportError(fmt.Errorf("logged in, but failed to upgrade vault: %w", err))
+		}
+	}
+
+	sealed, err := store.SealFields(context.Background())
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("logged in, but failed to encrypt secret titles: %w", err))
+	}
+
+	switch {
+	case upgraded:
+		return tui.ReportInfo("success! vault upgraded")
+	case sealed > 0:
+		return tui.ReportInfo("success! encrypted titles of %d secrets", sealed)
+	default:
+		return tui.ReportInfo("success!")
+	}
+}
+
 // View отображает текущее состояние экрана в виде строки.
 func (s *AuthenticateScreen) View() string {
 	return screens.RenderContent("Fill in credentials:", s.inputGroup.View())
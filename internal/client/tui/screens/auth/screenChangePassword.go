@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/storage"
+	"github.com/romanp1989/gophkeeper/internal/client/tui"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/components"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/screens"
+	"time"
+)
+
+const (
+	posOldPassword = iota
+	posNewPassword
+	posConfirmPassword
+)
+
+// passwordChanger описывает хранилище, поддерживающее смену мастер-пароля.
+type passwordChanger interface {
+	ChangePassword(ctx context.Context, oldPassword, newPassword string, params domain.KDFParams) error
+}
+
+// ChangePasswordScreen структура для экрана смены мастер-пароля.
+type ChangePasswordScreen struct {
+	inputGroup components.InputGroup
+	storage    storage.Storage
+	changer    passwordChanger
+	kdf        domain.KDFAlgorithm
+	unlockTime time.Duration
+}
+
+// ChangePasswordScreenMaker структура для создания экрана ChangePasswordScreen с настройками KDF клиента.
+type ChangePasswordScreenMaker struct {
+	KDF        domain.KDFAlgorithm
+	UnlockTime time.Duration
+}
+
+// Make создаёт новый экран ChangePasswordScreen для переданного хранилища.
+func (m ChangePasswordScreenMaker) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
+	changer, ok := msg.Storage.(passwordChanger)
+	if !ok {
+		return nil, errors.New("storage does not support password change")
+	}
+
+	return NewChangePasswordScreen(msg.Storage, changer, m.KDF, m.UnlockTime), nil
+}
+
+// NewChangePasswordScreen инициализирует и возвращает новый экран смены мастер-пароля.
+func NewChangePasswordScreen(store storage.Storage, changer passwordChanger, kdf domain.KDFAlgorithm, unlockTime time.Duration) *ChangePasswordScreen {
+	m := ChangePasswordScreen{
+		storage:    store,
+		changer:    changer,
+		kdf:        kdf,
+		unlockTime: unlockTime,
+	}
+
+	inputs := make([]textinput.Model, 3)
+	inputs[posOldPassword] = newInput(inputOpts{placeholder: "Current password", charLimit: 64, secret: true})
+	inputs[posNewPassword] = newInput(inputOpts{placeholder: "New password", charLimit: 64, secret: true})
+	inputs[posConfirmPassword] = newInput(inputOpts{placeholder: "Repeat new password", charLimit: 64, secret: true})
+
+	var buttons []components.Button
+	buttons = append(buttons, components.Button{Title: "[ Change ]", Cmd: func() tea.Cmd {
+		if err := m.Submit(); err != nil {
+			return tui.ReportError(err)
+		}
+		return tea.Batch(
+			tui.ReportInfo("master password changed"),
+			tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage)),
+		)
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+	}})
+
+	m.inputGroup = components.NewInputGroup(inputs, buttons)
+
+	return &m
+}
+
+// Init инициализирует компоненты экрана.
+func (s *ChangePasswordScreen) Init() tea.Cmd {
+	return s.inputGroup.Init()
+}
+
+// Update обрабатывает пользовательский ввод и обновляет состояние экрана.
+func (s *ChangePasswordScreen) Update(msg tea.Msg) tea.Cmd {
+	ig, cmd := s.inputGroup.Update(msg)
+	s.inputGroup = ig.(components.InputGroup)
+
+	return cmd
+}
+
+// Submit проверяет введенные пароли и меняет мастер-пароль хранилища.
+func (s *ChangePasswordScreen) Submit() error {
+	oldPassword := s.inputGroup.Inputs[posOldPassword].Value()
+	newPassword := s.inputGroup.Inputs[posNewPassword].Value()
+
+	if len(oldPassword) == 0 {
+		return errors.New("please enter current password")
+	}
+	if len(newPassword) == 0 {
+		return errors.New("please enter new password")
+	}
+	if newPassword != s.inputGroup.Inputs[posConfirmPassword].Value() {
+		return errors.New("new passwords do not match")
+	}
+
+	params, err := newKDFParams(s.kdf, s.unlockTime)
+	if err != nil {
+		return err
+	}
+
+	return s.changer.ChangePassword(context.Background(), oldPassword, newPassword, params)
+}
+
+// View отображает текущее состояние экрана в виде строки.
+func (s *ChangePasswordScreen) View() string {
+	return screens.RenderContent("Change master password:", s.inputGroup.View())
+}
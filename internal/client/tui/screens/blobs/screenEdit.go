@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/grpc"
 	"github.com/romanp1989/gophkeeper/internal/client/storage"
 	"github.com/romanp1989/gophkeeper/internal/client/tui"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/components"
@@ -20,8 +21,15 @@ import (
 const (
 	blobTitle = iota
 	blobMetadata
+	blobTTL
+	blobTags
 )
 
+// fileUploader описывает хранилище, которое передает файлы на сервер потоком.
+type fileUploader interface {
+	UploadFile(ctx context.Context, secret *domain.Secret, path string, progress storage.Progress) error
+}
+
 // BlobEditScreen представляет экран для редактирования и загрузки данных файлов (blob).
 type BlobEditScreen struct {
 	inputGroup components.InputGroup
@@ -47,9 +55,11 @@ func NewBlobEditScreen(secret *domain.Secret, store storage.Storage) *BlobEditSc
 		storage: store,
 	}
 
-	inputs := make([]textinput.Model, 2)
+	inputs := make([]textinput.Model, 4)
 	inputs[blobTitle] = newInput(inputOpts{placeholder: "Title", charLimit: 64})
 	inputs[blobMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
+	inputs[blobTTL] = screens.NewTTLInput(secret)
+	inputs[blobTags] = screens.NewTagsInput(secret)
 
 	var buttons []components.Button
 	buttons = append(buttons, components.Button{Title: "[ Pick file ]", Cmd: func() tea.Cmd {
@@ -65,12 +75,7 @@ func NewBlobEditScreen(secret *domain.Secret, store storage.Storage) *BlobEditSc
 				return tui.ReportError(fmt.Errorf("error opening file"))
 			}
 
-			err := m.Submit(str)
-			if err != nil {
-				return tui.ReportError(fmt.Errorf("error uploading file: %w", err))
-			}
-
-			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+			return m.Submit(str)
 		}
 
 		return tui.SetBodyPane(tui.FilePickScreen, tui.WithStorage(m.storage), tui.WithCallback(f), tui.WithSecret(secret))
@@ -128,35 +133,86 @@ func (s *BlobEditScreen) validateInputs() error {
 		return errors.New("please enter metadata")
 	}
 
+	if _, err := tui.ParseTTL(s.inputGroup.Inputs[blobTTL].Value()); err != nil {
+		return err
+	}
+
+	if _, err := tui.ParseTags(s.inputGroup.Inputs[blobTags].Value()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // Submit отправляет данные на сервер после проверки валидности.
-func (s *BlobEditScreen) Submit(path string) error {
-	var (
-		err error
-	)
-
-	err = s.validateInputs()
+// Если хранилище поддерживает потоковую передачу, файл передается в фоне с отображением хода загрузки.
+func (s *BlobEditScreen) Submit(path string) tea.Cmd {
+	err := s.validateInputs()
 	if err != nil {
-		return err
+		return tui.ReportError(err)
+	}
+
+	s.secret.Title = s.inputGroup.Inputs[blobTitle].Value()
+	s.secret.Metadata = s.inputGroup.Inputs[blobMetadata].Value()
+	if err = screens.ApplyTTL(s.secret, s.inputGroup.Inputs[blobTTL].Value()); err != nil {
+		return tui.ReportError(err)
+	}
+	if err = screens.ApplyTags(s.secret, s.inputGroup.Inputs[blobTags].Value()); err != nil {
+		return tui.ReportError(err)
+	}
+	s.secret.UpdatedAt = time.Now()
+	if s.secret.ID == 0 {
+		s.secret.CreatedAt = time.Now()
 	}
 
+	return s.upload(path)
+}
+
+// upload сохраняет секрет вместе с файлом path. Если секрет был изменен другим клиентом,
+// предлагается перечитать его, перезаписать или сохранить копию с повторной передачей файла.
+func (s *BlobEditScreen) upload(path string) tea.Cmd {
+	retry := func(secret *domain.Secret) tea.Cmd {
+		s.secret = secret
+		return s.upload(path)
+	}
+
+	uploader, ok := s.storage.(fileUploader)
+	if !ok {
+		if err := s.submitFile(path); err != nil {
+			return screens.HandleSaveError(fmt.Errorf("error uploading file: %w", err), s.storage, s.secret, tui.BlobEditScreen, retry)
+		}
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+	}
+
+	secret := s.secret
+	upload := func(progress func(done, total int64)) error {
+		return uploader.UploadFile(context.Background(), secret, path, progress)
+	}
+
+	return tea.Batch(
+		tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage)),
+		tui.Transfer("uploading file", upload, func(err error) tea.Cmd {
+			if err != nil {
+				return screens.HandleSaveError(fmt.Errorf("error uploading file: %w", err), s.storage, secret, tui.BlobEditScreen, retry)
+			}
+			return tea.Batch(tui.CmdHandler(grpc.ReloadSecretList{}), tui.ReportInfo("file uploaded successfully"))
+		}),
+	)
+}
+
+// submitFile сохраняет файл вместе с данными секрета в хранилищах без потоковой передачи.
+func (s *BlobEditScreen) submitFile(path string) error {
 	bts, err := readFileToBytes(path)
 	if err != nil {
 		return err
 	}
 
-	s.secret.Title = s.inputGroup.Inputs[blobTitle].Value()
-	s.secret.Metadata = s.inputGroup.Inputs[blobMetadata].Value()
 	s.secret.Blob = &domain.Blob{
 		FileName:  path,
 		FileBytes: bts,
 	}
-	s.secret.UpdatedAt = time.Now()
 
 	if s.secret.ID == 0 {
-		s.secret.CreatedAt = time.Now()
 		err = s.storage.Create(context.Background(), s.secret)
 	} else {
 		err = s.storage.Update(context.Background(), s.secret)
@@ -25,6 +25,8 @@ const (
 	cardExpYear
 	cardExpMonth
 	cardCVV
+	cardTTL
+	cardTags
 )
 
 // CardEditScreen представляет экран для редактирования и создания информации о картах.
@@ -52,22 +54,24 @@ func NewCardEditScreen(secret *domain.Secret, store storage.Storage) *CardEditSc
 		storage: store,
 	}
 
-	inputs := make([]textinput.Model, 6)
+	inputs := make([]textinput.Model, 8)
 	inputs[cardTitle] = newInput(inputOpts{placeholder: "Title", charLimit: 64})
 	inputs[cardMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
 	inputs[cardNumber] = newInput(inputOpts{placeholder: "Card number", charLimit: 64})
 	inputs[cardExpYear] = newInput(inputOpts{placeholder: "Exp Year", charLimit: 2})
 	inputs[cardExpMonth] = newInput(inputOpts{placeholder: "Exp Month", charLimit: 2})
 	inputs[cardCVV] = newInput(inputOpts{placeholder: "CVV", charLimit: 6})
+	inputs[cardTTL] = screens.NewTTLInput(secret)
+	inputs[cardTags] = screens.NewTagsInput(secret)
 
 	var buttons []components.Button
 	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
-		err := m.Submit()
-		if err != nil {
-			return tui.ReportError(err)
-		} else {
-			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+		if err := m.Submit(); err != nil {
+			return screens.HandleSaveError(err, m.storage, m.secret, tui.CardEditScreen, func(secret *domain.Secret) tea.Cmd {
+				return screens.Save(m.storage, secret, tui.CardEditScreen)
+			})
 		}
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
 	}})
 
 	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
@@ -149,6 +153,14 @@ func (s *CardEditScreen) Submit() error {
 		return errFieldEmpty("CVV")
 	}
 
+	if err = screens.ApplyTTL(s.secret, s.inputGroup.Inputs[cardTTL].Value()); err != nil {
+		return err
+	}
+
+	if err = screens.ApplyTags(s.secret, s.inputGroup.Inputs[cardTags].Value()); err != nil {
+		return err
+	}
+
 	s.secret.Title = title
 	s.secret.Metadata = metadata
 	card := &domain.Card{Number: cardNumberValue}
@@ -20,6 +20,8 @@ const (
 	credMetadata
 	credLogin
 	credPassword
+	credTTL
+	credTags
 )
 
 // CredentialEditScreen структура для экрана редактирования учетных данных.
@@ -47,16 +49,20 @@ func NewCredentialEditScreen(secret *domain.Secret, store storage.Storage) *Cred
 		storage: store,
 	}
 
-	inputs := make([]textinput.Model, 4)
+	inputs := make([]textinput.Model, 6)
 	inputs[credTitle] = newInput(inputOpts{placeholder: "Title", charLimit: 64})
 	inputs[credMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
 	inputs[credLogin] = newInput(inputOpts{placeholder: "Login", charLimit: 64})
 	inputs[credPassword] = newInput(inputOpts{placeholder: "Password", charLimit: 64})
+	inputs[credTTL] = screens.NewTTLInput(secret)
+	inputs[credTags] = screens.NewTagsInput(secret)
 
 	var buttons []components.Button
 	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
 		if err := m.Submit(); err != nil {
-			return tui.ReportError(err)
+			return screens.HandleSaveError(err, m.storage, m.secret, tui.CredentialEditScreen, func(secret *domain.Secret) tea.Cmd {
+				return screens.Save(m.storage, secret, tui.CredentialEditScreen)
+			})
 		}
 		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
 	}})
@@ -108,6 +114,14 @@ func (s *CredentialEditScreen) Submit() error {
 		return err
 	}
 
+	if err = screens.ApplyTTL(s.secret, s.inputGroup.Inputs[credTTL].Value()); err != nil {
+		return err
+	}
+
+	if err = screens.ApplyTags(s.secret, s.inputGroup.Inputs[credTags].Value()); err != nil {
+		return err
+	}
+
 	title := s.inputGroup.Inputs[credTitle].Value()
 	metadata := s.inputGroup.Inputs[credMetadata].Value()
 	login := s.inputGroup.Inputs[credLogin].Value()
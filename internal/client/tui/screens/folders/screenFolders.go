@@ -0,0 +1,389 @@
+// Package folders предоставляет экран дерева папок хранилища.
+package folders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/storage"
+	"github.com/romanp1989/gophkeeper/internal/client/tui"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/styles"
+	"strconv"
+	"strings"
+)
+
+const (
+	tableBorderSize = 4
+)
+
+// folderStore описывает хранилище с папками.
+type folderStore interface {
+	Folders(ctx context.Context) ([]*domain.Folder, error)
+	CreateFolder(ctx context.Context, parentID uint64, name string) (*domain.Folder, error)
+	RenameFolder(ctx context.Context, id uint64, name string) error
+	MoveFolder(ctx context.Context, id, parentID uint64) error
+	DeleteFolder(ctx context.Context, id uint64) error
+}
+
+// createFolderMsg сообщение о вводе названия новой папки
+type createFolderMsg struct {
+	parentID uint64
+	name     string
+}
+
+// renameFolderMsg сообщение о вводе нового названия папки
+type renameFolderMsg struct {
+	id   uint64
+	name string
+}
+
+// deleteFolderMsg сообщение о подтверждении удаления папки
+type deleteFolderMsg struct {
+	id uint64
+}
+
+// folderNode папка в дереве папок; папка nil соответствует корню хранилища
+type folderNode struct {
+	folder *domain.Folder
+	depth  int
+}
+
+// FolderScreenMaker структура для создания экрана папок.
+type FolderScreenMaker struct{}
+
+// Make создаёт экран папок для хранилища из сообщения навигации.
+// Если в сообщении передан callback, экран используется для выбора папки: выбранный идентификатор передается в callback.
+func (m FolderScreenMaker) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
+	folders, ok := msg.Storage.(folderStore)
+	if !ok {
+		return nil, errors.New("storage does not support folders")
+	}
+
+	var current uint64
+	if msg.Folder != nil {
+		current = *msg.Folder
+	}
+
+	return NewFolderScreen(msg.Storage, folders, current, msg.Callback), nil
+}
+
+// FolderScreen экран с деревом папок хранилища.
+type FolderScreen struct {
+	storage storage.Storage
+	folders folderStore
+	// pick получает выбранную папку; nil, если экран открыт для управления папками
+	pick tui.NavigationCallback
+	// moving папка, для которой выбирается новая родительская папка
+	moving *domain.Folder
+	nodes  []folderNode
+	table  table.Model
+	err    error
+}
+
+// NewFolderScreen создает экран папок и выделяет в дереве папку current.
+func NewFolderScreen(store storage.Storage, folders folderStore, current uint64, pick tui.NavigationCallback) *FolderScreen {
+	scr := &FolderScreen{
+		storage: store,
+		folders: folders,
+		pick:    pick,
+		table:   prepareTable(),
+	}
+
+	scr.updateRows()
+	scr.selectFolder(current)
+
+	return scr
+}
+
+// Init инициализирует экран.
+func (s *FolderScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update обрабатывает сообщения и нажатия клавиш.
+func (s *FolderScreen) Update(msg tea.Msg) tea.Cmd {
+	var (
+		cmd      tea.Cmd
+		commands []tea.Cmd
+	)
+
+	switch msg := msg.(type) {
+	case createFolderMsg:
+		commands = append(commands, s.handleCreate(msg))
+	case renameFolderMsg:
+		commands = append(commands, s.handleRename(msg))
+	case deleteFolderMsg:
+		commands = append(commands, s.handleDelete(msg.id))
+	case tea.WindowSizeMsg:
+		s.table.SetHeight(max(msg.Height-tableBorderSize, 3))
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			commands = append(commands, s.handleEnter())
+		case "n":
+			parentID := s.selectedID()
+			commands = append(commands, tui.StringPrompt("New folder name", func(name string) tea.Cmd {
+				return tui.CmdHandler(createFolderMsg{parentID: parentID, name: name})
+			}))
+		case "r":
+			if folder := s.selectedFolder(); folder != nil {
+				id := folder.ID
+				commands = append(commands, tui.StringPrompt(fmt.Sprintf("New name for %q", folder.Name), func(name string) tea.Cmd {
+					return tui.CmdHandler(renameFolderMsg{id: id, name: name})
+				}))
+			}
+		case "m":
+			if folder := s.selectedFolder(); folder != nil && s.pick == nil {
+				s.moving = folder
+				commands = append(commands, tui.ReportInfo("choose a new parent folder for %q and press enter", folder.Name))
+			}
+		case "d":
+			if folder := s.selectedFolder(); folder != nil && s.pick == nil {
+				id := folder.ID
+				commands = append(commands, tui.YesNoPrompt(
+					fmt.Sprintf("Delete folder %q? Its contents move to the parent folder", folder.Name),
+					func() tea.Msg { return deleteFolderMsg{id: id} },
+				))
+			}
+		case "esc":
+			s.moving = nil
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+	commands = append(commands, cmd)
+
+	return tea.Batch(commands...)
+}
+
+// View отображает дерево папок.
+func (s *FolderScreen) View() string {
+	var b strings.Builder
+
+	switch {
+	case s.pick != nil:
+		b.WriteString(fmt.Sprintf("Choose destination folder in %s\n", styles.Highlighted.Render(s.storage.String())))
+		b.WriteString("Use ↑↓ to navigate, choose[enter], new folder[n], rename[r], back[b]\n")
+	case s.moving != nil:
+		b.WriteString(fmt.Sprintf("Moving folder %s\n", styles.Highlighted.Render(s.moving.Name)))
+		b.WriteString("Use ↑↓ to navigate, move here[enter], cancel[esc]\n")
+	default:
+		b.WriteString(fmt.Sprintf("Folders of %s\n", styles.Highlighted.Render(s.storage.String())))
+		b.WriteString("Use ↑↓ to navigate, open[enter], new folder[n], rename[r], move[m], delete[d], back[b]\n")
+	}
+
+	if s.err != nil {
+		b.WriteString(fmt.Sprintf("failed to load folders: %s\n", s.err))
+	} else {
+		b.WriteString(styles.TableStyle.Render(s.table.View()))
+	}
+
+	return styles.StorageScreenStyle.Render(b.String())
+}
+
+// HelpBindings возвращает набор горячих клавиш для экрана.
+func (s *FolderScreen) HelpBindings() []key.Binding {
+	if s.pick != nil {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "choose folder")),
+			key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new folder")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename folder")),
+			key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to storage")),
+		}
+	}
+
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open folder")),
+		key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new folder")),
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rename folder")),
+		key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "move folder")),
+		key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete folder")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to storage")),
+	}
+}
+
+// handleEnter выбирает папку для callback, завершает перемещение папки или открывает папку в хранилище
+func (s *FolderScreen) handleEnter() tea.Cmd {
+	id := s.selectedID()
+
+	switch {
+	case s.pick != nil:
+		return s.pick(id)
+	case s.moving != nil:
+		moving := s.moving
+		s.moving = nil
+
+		if err := s.folders.MoveFolder(context.Background(), moving.ID, id); err != nil {
+			return tui.ReportError(fmt.Errorf("failed to move folder: %w", err))
+		}
+
+		s.updateRows()
+		s.selectFolder(moving.ID)
+
+		return tui.ReportInfo("folder %q moved", moving.Name)
+	default:
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage), tui.WithFolder(id))
+	}
+}
+
+func (s *FolderScreen) handleCreate(msg createFolderMsg) tea.Cmd {
+	folder, err := s.folders.CreateFolder(context.Background(), msg.parentID, msg.name)
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to create folder: %w", err))
+	}
+
+	s.updateRows()
+	s.selectFolder(folder.ID)
+
+	return tui.ReportInfo("folder %q created", folder.Name)
+}
+
+func (s *FolderScreen) handleRename(msg renameFolderMsg) tea.Cmd {
+	if err := s.folders.RenameFolder(context.Background(), msg.id, msg.name); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to rename folder: %w", err))
+	}
+
+	s.updateRows()
+	s.selectFolder(msg.id)
+
+	return tui.ReportInfo("folder renamed")
+}
+
+func (s *FolderScreen) handleDelete(id uint64) tea.Cmd {
+	if err := s.folders.DeleteFolder(context.Background(), id); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to delete folder: %w", err))
+	}
+
+	s.updateRows()
+
+	return tui.ReportInfo("folder deleted")
+}
+
+// updateRows загружает папки и количество секретов в них и строит дерево папок
+func (s *FolderScreen) updateRows() {
+	var folders []*domain.Folder
+	folders, s.err = s.folders.Folders(context.Background())
+	if s.err != nil {
+		return
+	}
+
+	s.nodes = buildTree(folders)
+
+	// Секреты из неизвестных папок показываются в корне хранилища
+	counts := make(map[uint64]int)
+	secrets, _ := s.storage.GetAll(context.Background())
+	for _, sec := range secrets {
+		counts[sec.FolderID]++
+	}
+
+	rows := make([]table.Row, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		name, id := "/", uint64(0)
+		if node.folder != nil {
+			name, id = strings.Repeat("  ", node.depth-1)+"▸ "+node.folder.Name, node.folder.ID
+		}
+		rows = append(rows, table.Row{name, strconv.Itoa(counts[id])})
+	}
+
+	known := make(map[uint64]struct{}, len(folders))
+	for _, f := range folders {
+		known[f.ID] = struct{}{}
+	}
+	for id, n := range counts {
+		if _, ok := known[id]; !ok && id != 0 {
+			counts[0] += n
+		}
+	}
+	if len(rows) > 0 {
+		rows[0][1] = strconv.Itoa(counts[0])
+	}
+
+	s.table.SetRows(rows)
+}
+
+// selectFolder перемещает курсор на папку id
+func (s *FolderScreen) selectFolder(id uint64) {
+	for i, node := range s.nodes {
+		if node.folder != nil && node.folder.ID == id {
+			s.table.SetCursor(i)
+			return
+		}
+	}
+}
+
+// selectedFolder возвращает выбранную папку; nil для корня хранилища
+func (s *FolderScreen) selectedFolder() *domain.Folder {
+	cursor := s.table.Cursor()
+	if cursor < 0 || cursor >= len(s.nodes) {
+		return nil
+	}
+
+	return s.nodes[cursor].folder
+}
+
+// selectedID возвращает идентификатор выбранной папки; 0 для корня хранилища
+func (s *FolderScreen) selectedID() uint64 {
+	if folder := s.selectedFolder(); folder != nil {
+		return folder.ID
+	}
+
+	return 0
+}
+
+// buildTree упорядочивает папки в дерево, начинающееся с корня хранилища.
+// Вложенные папки следуют за родительской в порядке, в котором получены; папки с неизвестным родителем
+// показываются в корне.
+func buildTree(folders []*domain.Folder) []folderNode {
+	known := make(map[uint64]struct{}, len(folders))
+	for _, f := range folders {
+		known[f.ID] = struct{}{}
+	}
+
+	children := make(map[uint64][]*domain.Folder)
+	for _, f := range folders {
+		parent := f.ParentID
+		if _, ok := known[parent]; !ok {
+			parent = 0
+		}
+		children[parent] = append(children[parent], f)
+	}
+
+	nodes := []folderNode{{}}
+
+	var walk func(parent uint64, depth int)
+	walk = func(parent uint64, depth int) {
+		for _, f := range children[parent] {
+			nodes = append(nodes, folderNode{folder: f, depth: depth})
+			walk(f.ID, depth+1)
+		}
+	}
+	walk(0, 1)
+
+	return nodes
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "Folder", Width: 40},
+		{Title: "Secrets", Width: 8},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = styles.TableHeaderStyle
+	st.Selected = styles.TableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
@@ -0,0 +1,222 @@
+// Package history предоставляет экран просмотра и восстановления сохраненных редакций секрета.
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/storage"
+	"github.com/romanp1989/gophkeeper/internal/client/tui"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/styles"
+	"strconv"
+	"strings"
+)
+
+const (
+	tableBorderSize = 4
+	compareWidth    = 40
+)
+
+// versionStore описывает хранилище, которое хранит редакции секретов и умеет восстанавливать их.
+type versionStore interface {
+	Versions(ctx context.Context, secretID uint64) ([]*domain.SecretVersion, error)
+	RestoreVersion(ctx context.Context, secretID, versionID uint64) (*domain.Secret, error)
+}
+
+// HistoryScreenMaker структура для создания экрана истории секрета.
+type HistoryScreenMaker struct{}
+
+// Make создаёт экран истории для секрета из сообщения навигации.
+func (m HistoryScreenMaker) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
+	versions, ok := msg.Storage.(versionStore)
+	if !ok {
+		return nil, errors.New("storage does not support secret history")
+	}
+
+	if msg.Secret == nil {
+		return nil, errors.New("secret is not set")
+	}
+
+	return NewHistoryScreen(msg.Storage, versions, msg.Secret), nil
+}
+
+// HistoryScreen экран со списком редакций секрета и сравнением выбранной редакции с текущим состоянием.
+type HistoryScreen struct {
+	storage  storage.Storage
+	store    versionStore
+	secret   *domain.Secret
+	versions []*domain.SecretVersion
+	table    table.Model
+	err      error
+}
+
+// NewHistoryScreen создает экран истории секрета и загружает его редакции.
+func NewHistoryScreen(store storage.Storage, versions versionStore, secret *domain.Secret) *HistoryScreen {
+	scr := &HistoryScreen{
+		storage: store,
+		store:   versions,
+		secret:  secret,
+		table:   prepareTable(),
+	}
+
+	scr.updateRows()
+
+	return scr
+}
+
+// Init инициализирует экран.
+func (s *HistoryScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update обрабатывает сообщения и нажатия клавиш.
+func (s *HistoryScreen) Update(msg tea.Msg) tea.Cmd {
+	var (
+		cmd      tea.Cmd
+		commands []tea.Cmd
+	)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.table.SetHeight(max(msg.Height-tableBorderSize-2, 3))
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r":
+			commands = append(commands, s.handleRestore())
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+	commands = append(commands, cmd)
+
+	return tea.Batch(commands...)
+}
+
+// View отображает список редакций и сравнение выбранной редакции с текущим секретом.
+func (s *HistoryScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("History of %s\n", styles.Highlighted.Render(s.secret.Title)))
+	b.WriteString("Use ↑↓ to navigate, restore[r], back[b]\n")
+
+	if s.err != nil {
+		b.WriteString(fmt.Sprintf("failed to load history: %s\n", s.err))
+		return styles.StorageScreenStyle.Render(b.String())
+	}
+
+	if len(s.versions) == 0 {
+		b.WriteString("No saved versions\n")
+		return styles.StorageScreenStyle.Render(b.String())
+	}
+
+	var selected string
+	if version := s.selectedVersion(); version != nil {
+		selected = describe(version.Secret)
+	}
+
+	compare := lipgloss.JoinHorizontal(lipgloss.Top,
+		styles.Border.Width(compareWidth).Render("Current\n\n"+describe(s.secret)),
+		styles.Border.Width(compareWidth).Render("Selected version\n\n"+selected),
+	)
+
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, styles.TableStyle.Render(s.table.View()), compare))
+
+	return styles.StorageScreenStyle.Render(b.String())
+}
+
+// HelpBindings возвращает набор горячих клавиш для экрана.
+func (s *HistoryScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restore version")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to storage")),
+	}
+}
+
+func (s *HistoryScreen) updateRows() {
+	s.versions, s.err = s.store.Versions(context.Background(), s.secret.ID)
+
+	rows := make([]table.Row, 0, len(s.versions))
+	for _, v := range s.versions {
+		rows = append(rows, table.Row{
+			strconv.FormatUint(v.ID, 10),
+			v.Secret.Title,
+			v.Secret.UpdatedAt.Format("02 Jan 06 15:04"),
+		})
+	}
+
+	s.table.SetRows(rows)
+}
+
+func (s *HistoryScreen) handleRestore() tea.Cmd {
+	version := s.selectedVersion()
+	if version == nil {
+		return nil
+	}
+
+	if _, err := s.store.RestoreVersion(context.Background(), s.secret.ID, version.ID); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to restore version: %w", err))
+	}
+
+	return tea.Batch(
+		tui.ReportInfo("version %d restored", version.ID),
+		tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage)),
+	)
+}
+
+func (s *HistoryScreen) selectedVersion() *domain.SecretVersion {
+	cursor := s.table.Cursor()
+	if cursor < 0 || cursor >= len(s.versions) {
+		return nil
+	}
+
+	return s.versions[cursor]
+}
+
+// describe формирует описание секрета для сравнения редакций
+func describe(secret *domain.Secret) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("Title: %s\n", secret.Title))
+	if secret.Metadata != "" {
+		b.WriteString(fmt.Sprintf("Metadata: %s\n", secret.Metadata))
+	}
+
+	switch {
+	case secret.Blob != nil:
+		b.WriteString(fmt.Sprintf("File: %s\n", secret.Blob.FileName))
+	case secret.SecretType == string(domain.BlobSecret):
+		b.WriteString("File content is not available\n")
+	case secret.Credentials != nil || secret.Card != nil || secret.Text != nil:
+		b.WriteString(secret.ToClipboard())
+	}
+
+	return b.String()
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "Version", Width: 8},
+		{Title: "Title", Width: 20},
+		{Title: "Saved", Width: 16},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = styles.TableHeaderStyle
+	st.Selected = styles.TableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
@@ -46,6 +46,7 @@ func (s *RemoteOpenScreen) Init() tea.Cmd {
 			commands = append(commands, tui.ReportError(err))
 		} else {
 			commands = append(commands, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(store)))
+			commands = append(commands, tui.WatchSecrets(s.client))
 		}
 	} else {
 		commands = append(commands, tui.SetBodyPane(tui.LoginScreen, tui.WithClient(s.client)))
@@ -2,10 +2,23 @@
 package screens
 
 import (
+	"context"
+	"errors"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/grpc"
+	"github.com/romanp1989/gophkeeper/internal/client/storage"
+	"github.com/romanp1989/gophkeeper/internal/client/tui"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/styles"
 	"strings"
+	"time"
 )
 
+// SaveFunc повторно сохраняет секрет после разрешения конфликта ревизий.
+type SaveFunc func(secret *domain.Secret) tea.Cmd
+
 // RenderContent форматирует заголовок и содержимое экрана с применением стилей.
 func RenderContent(header, content string) string {
 	var b strings.Builder
@@ -16,3 +29,117 @@ func RenderContent(header, content string) string {
 
 	return styles.ContentPaddedStyle.Render(b.String())
 }
+
+// NewTTLInput создает поле ввода срока действия секрета, заполненное оставшимся сроком существующего секрета
+func NewTTLInput(secret *domain.Secret) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = 16
+	t.Placeholder = "TTL, e.g. 12h or 7d (empty - never expires)"
+
+	if !secret.ExpiresAt.IsZero() {
+		t.SetValue(tui.FormatTTL(time.Until(secret.ExpiresAt)))
+	}
+
+	return t
+}
+
+// ApplyTTL устанавливает время истечения секрета по сроку действия, введенному в поле NewTTLInput.
+// Пустой срок снимает ограничение.
+func ApplyTTL(secret *domain.Secret, value string) error {
+	ttl, err := tui.ParseTTL(value)
+	if err != nil {
+		return err
+	}
+
+	secret.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		secret.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	return nil
+}
+
+// NewTagsInput создает поле ввода тегов секрета через запятую, заполненное тегами существующего секрета
+func NewTagsInput(secret *domain.Secret) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = 256
+	t.Placeholder = "Tags, comma separated, e.g. work, prod"
+	t.SetValue(tui.FormatTags(secret.Tags))
+
+	return t
+}
+
+// ApplyTags устанавливает теги секрета, введенные в поле NewTagsInput
+func ApplyTags(secret *domain.Secret, value string) error {
+	tags, err := tui.ParseTags(value)
+	if err != nil {
+		return err
+	}
+
+	secret.Tags = tags
+
+	return nil
+}
+
+// Save создает новый секрет или обновляет существующий и возвращается к списку секретов.
+// Ошибки сохранения обрабатываются HandleSaveError.
+func Save(store storage.Storage, secret *domain.Secret, editScreen tui.Screen) tea.Cmd {
+	var err error
+	if secret.ID == 0 {
+		err = store.Create(context.Background(), secret)
+	} else {
+		err = store.Update(context.Background(), secret)
+	}
+
+	if err != nil {
+		return HandleSaveError(err, store, secret, editScreen, func(secret *domain.Secret) tea.Cmd {
+			return Save(store, secret, editScreen)
+		})
+	}
+
+	return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(store))
+}
+
+// HandleSaveError обрабатывает ошибку сохранения секрета. Если секрет был изменен другим клиентом,
+// пользователю предлагается перечитать секрет, перезаписать его своей редакцией или сохранить редакцию копией.
+// Выбранная редакция сохраняется функцией save.
+func HandleSaveError(err error, store storage.Storage, secret *domain.Secret, editScreen tui.Screen, save SaveFunc) tea.Cmd {
+	if !errors.Is(err, grpc.ErrConflict) {
+		return tui.ReportError(err)
+	}
+
+	reload := func() tea.Cmd {
+		fresh, err := store.Get(context.Background(), secret.ID)
+		if err != nil {
+			return tui.ReportError(err)
+		}
+
+		return tui.SetBodyPane(editScreen, tui.WithStorage(store), tui.WithSecret(fresh))
+	}
+
+	overwrite := func() tea.Cmd {
+		current, err := store.Get(context.Background(), secret.ID)
+		if err != nil {
+			return tui.ReportError(err)
+		}
+
+		secret.Revision = current.Revision
+
+		return save(secret)
+	}
+
+	saveCopy := func() tea.Cmd {
+		duplicate := *secret
+		duplicate.ID, duplicate.Revision = 0, 0
+		duplicate.Title += " (copy)"
+		duplicate.CreatedAt = time.Now()
+
+		return save(&duplicate)
+	}
+
+	return tui.ChoicePrompt("secret was changed on another device",
+		tui.PromptChoice{Key: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reload")), Action: reload},
+		tui.PromptChoice{Key: key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "overwrite")), Action: overwrite},
+		tui.PromptChoice{Key: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "save as copy")), Action: saveCopy},
+	)
+}
@@ -26,17 +26,24 @@ type SecretTypeScreen struct {
 	choice  string
 	list    list.Model
 	storage storage.Storage
+	// folder папка, в которой создается секрет
+	folder uint64
 	tea.Model
 }
 
 // Make создает экземпляр SecretTypeScreen на основе переданного сообщения.
 func (s *SecretTypeScreen) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
-	return NewSecretTypeScreen(msg.Storage), nil
+	var folder uint64
+	if msg.Folder != nil {
+		folder = *msg.Folder
+	}
+
+	return NewSecretTypeScreen(msg.Storage, folder), nil
 }
 
-// NewSecretTypeScreen создает и инициализирует новый экран выбора типа секрета.
-func NewSecretTypeScreen(store storage.Storage) *SecretTypeScreen {
-	m := &SecretTypeScreen{storage: store}
+// NewSecretTypeScreen создает и инициализирует новый экран выбора типа секрета, создающего секреты в папке folder.
+func NewSecretTypeScreen(store storage.Storage, folder uint64) *SecretTypeScreen {
+	m := &SecretTypeScreen{storage: store, folder: folder}
 	m.prepareSecretListModel()
 
 	return m
@@ -106,6 +113,7 @@ func (s *SecretTypeScreen) Update(msg tea.Msg) tea.Cmd {
 
 			case selectCredential:
 				sec := domain.NewSecret(domain.CredSecret)
+				sec.FolderID = s.folder
 
 				cmd = tui.SetBodyPane(
 					tui.CredentialEditScreen,
@@ -114,6 +122,7 @@ func (s *SecretTypeScreen) Update(msg tea.Msg) tea.Cmd {
 				)
 			case selectText:
 				sec := domain.NewSecret(domain.TextSecret)
+				sec.FolderID = s.folder
 
 				cmd = tui.SetBodyPane(
 					tui.TextEditScreen,
@@ -122,6 +131,7 @@ func (s *SecretTypeScreen) Update(msg tea.Msg) tea.Cmd {
 				)
 			case selectCard:
 				sec := domain.NewSecret(domain.CardSecret)
+				sec.FolderID = s.folder
 
 				cmd = tui.SetBodyPane(
 					tui.CardEditScreen,
@@ -130,6 +140,7 @@ func (s *SecretTypeScreen) Update(msg tea.Msg) tea.Cmd {
 				)
 			case selectBlob:
 				sec := domain.NewSecret(domain.BlobSecret)
+				sec.FolderID = s.folder
 
 				cmd = tui.SetBodyPane(
 					tui.BlobEditScreen,
@@ -3,6 +3,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
@@ -17,35 +18,126 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	tableBorderSize = 4
 )
 
+// batchDeleter описывает хранилище, которое удаляет несколько секретов одним запросом.
+type batchDeleter interface {
+	DeleteMany(ctx context.Context, ids []uint64) ([]error, error)
+}
+
+// fileDownloader описывает хранилище, которое получает файлы с сервера потоком.
+type fileDownloader interface {
+	DownloadFile(ctx context.Context, secret *domain.Secret, path string, progress storage.Progress) error
+}
+
+// folderBrowser описывает хранилище, которое раскладывает секреты по папкам.
+type folderBrowser interface {
+	Folders(ctx context.Context) ([]*domain.Folder, error)
+	MoveSecrets(ctx context.Context, ids []uint64, folderID uint64) (int, error)
+}
+
+// tagFinder описывает хранилище, которое выбирает секреты по тегам на сервере.
+type tagFinder interface {
+	FindByTags(ctx context.Context, tags []string) ([]uint64, error)
+}
+
+// secretSearcher описывает хранилище, которое ищет секреты по словам на сервере.
+type secretSearcher interface {
+	Search(ctx context.Context, text string) ([]uint64, error)
+}
+
+// tagFilterMsg сообщение о вводе тегов для фильтрации секретов
+type tagFilterMsg struct {
+	value string
+}
+
+// searchMsg сообщение о вводе строки поиска секретов
+type searchMsg struct {
+	value string
+}
+
 type savePathMsg = struct {
 	path   string
 	secret *domain.Secret
 }
 
+// BrowseStorageScreenMaker создает экраны просмотра хранилища и запоминает открытую папку, фильтр по тегам и поиск,
+// чтобы возврат на экран хранилища из других экранов не сбрасывал их.
+type BrowseStorageScreenMaker struct {
+	storage storage.Storage
+	folder  uint64
+	// screen последний созданный экран, из которого переносятся фильтр по тегам и поиск
+	screen *BrowseStorageScreen
+}
+
+// Make создает экран для просмотра хранилища в папке из сообщения навигации или в последней открытой папке.
+func (m *BrowseStorageScreenMaker) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
+	if m.storage != msg.Storage {
+		m.storage = msg.Storage
+		m.folder = 0
+		m.screen = nil
+	}
+
+	if msg.Folder != nil {
+		m.folder = *msg.Folder
+	}
+
+	var (
+		tags   []string
+		search string
+	)
+	if m.screen != nil {
+		tags, search = m.screen.tags, m.screen.search
+	}
+
+	scr := NewStorageBrowseScreenScreen(msg.Storage, m.folder, tags, search)
+	m.folder = scr.folder
+	m.screen = scr
+
+	return scr, nil
+}
+
 // BrowseStorageScreen предоставляет модель экрана для просмотра хранилища секретов.
 type BrowseStorageScreen struct {
 	storage storage.Storage
 	table   table.Model
+	// marked секреты, отмеченные для удаления
+	marked map[uint64]struct{}
+	// folder открытая папка; 0 соответствует корню хранилища
+	folder uint64
+	// folders папки хранилища; nil, если хранилище не поддерживает папки
+	folders map[uint64]*domain.Folder
+	// tags теги, которыми должны быть отмечены показанные секреты; пустой список не ограничивает выборку
+	tags []string
+	// tagged секреты, выбранные сервером по тегам tags; nil, если секреты выбираются по тегам локальной копии
+	tagged map[uint64]struct{}
+	// search строка поиска секретов; пустая строка не ограничивает выборку
+	search string
+	// found секреты, найденные сервером по строке search; nil, если секреты ищутся по заголовкам локальной копии
+	found map[uint64]struct{}
 }
 
-// Make создает экран для просмотра хранилища.
-func (s *BrowseStorageScreen) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
-	return NewStorageBrowseScreenScreen(msg.Storage), nil
-}
-
-// NewStorageBrowseScreenScreen создает новый экран для просмотра хранилища.
-func NewStorageBrowseScreenScreen(storage storage.Storage) *BrowseStorageScreen {
+// NewStorageBrowseScreenScreen создает новый экран для просмотра папки folder хранилища.
+// Если заданы теги tags или строка поиска search, показываются секреты всего хранилища,
+// отмеченные всеми этими тегами и содержащие все слова строки поиска.
+func NewStorageBrowseScreenScreen(storage storage.Storage, folder uint64, tags []string, search string) *BrowseStorageScreen {
 	scr := &BrowseStorageScreen{
 		storage: storage,
 		table:   prepareTable(),
+		marked:  make(map[uint64]struct{}),
+		folder:  folder,
+		tags:    tags,
+		search:  search,
 	}
 
+	scr.loadFolders()
+	_ = scr.loadTagged()
+	_ = scr.loadFound()
 	scr.updateRows()
 
 	return scr
@@ -66,31 +158,55 @@ func (s *BrowseStorageScreen) Update(msg tea.Msg) tea.Cmd {
 
 	switch msg := msg.(type) {
 	case grpc.ReloadSecretList:
+		s.loadFolders()
+		_ = s.loadTagged()
+		_ = s.loadFound()
 		s.updateRows()
+	case tagFilterMsg:
+		commands = append(commands, s.handleTagFilter(msg.value))
+	case searchMsg:
+		commands = append(commands, s.handleSearch(msg.value))
 	case savePathMsg:
-		err := os.WriteFile(msg.path, msg.secret.Blob.FileBytes, 0644)
-		if err != nil {
-			commands = append(commands, tui.ReportError(err))
-		} else {
-			commands = append(commands, infoCmd("file saved successfully"))
-		}
+		commands = append(commands, s.saveFile(msg.path, msg.secret))
 	case tea.WindowSizeMsg:
 		s.table.SetWidth(min(msg.Width, s.colsWidth()))
 		s.table.SetHeight(msg.Height - tableBorderSize)
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "a":
-			cmd = tui.SetBodyPane(tui.SecretTypeScreen, tui.WithStorage(s.storage))
+			cmd = tui.SetBodyPane(tui.SecretTypeScreen, tui.WithStorage(s.storage), tui.WithFolder(s.folder))
 			commands = append(commands, cmd)
 		case "e", "enter":
 			commands = append(commands, s.handleEdit())
 		case "c":
 			commands = append(commands, s.handleCopy())
+		case " ":
+			s.toggleMark()
 		case "d":
 			commands = append(commands, s.handleDelete())
 
 			s.updateRows()
 			commands = append(commands, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage)))
+		case "h":
+			commands = append(commands, s.handleHistory())
+		case "f":
+			if s.folders != nil {
+				commands = append(commands, tui.SetBodyPane(tui.FolderScreen, tui.WithStorage(s.storage), tui.WithFolder(s.folder)))
+			}
+		case "m":
+			commands = append(commands, s.handleMove())
+		case "g":
+			commands = append(commands, tui.StringPrompt("Filter by tags, comma separated (empty - show all)", func(value string) tea.Cmd {
+				return tui.CmdHandler(tagFilterMsg{value: value})
+			}))
+		case "/":
+			commands = append(commands, tui.StringPrompt("Search titles, tags and links (empty - show all)", func(value string) tea.Cmd {
+				return tui.CmdHandler(searchMsg{value: value})
+			}))
+		case "t":
+			commands = append(commands, tui.SetBodyPane(tui.TrashScreen, tui.WithStorage(s.storage)))
+		case "p":
+			commands = append(commands, tui.SetBodyPane(tui.ChangePasswordScreen, tui.WithStorage(s.storage)))
 		}
 	}
 
@@ -105,8 +221,25 @@ func (s *BrowseStorageScreen) Update(msg tea.Msg) tea.Cmd {
 func (s *BrowseStorageScreen) View() string {
 	var b strings.Builder
 
-	b.WriteString(fmt.Sprintf("Operating storage %s\n", styles.Highlighted.Render(s.storage.String())))
-	b.WriteString("Use ↑↓ to navigate, add[a], edit[e], delete[d], copy[c]\n")
+	switch {
+	case len(s.tags) > 0 && s.search != "":
+		b.WriteString(fmt.Sprintf("Operating storage %s, tags %s, search %s\n", styles.Highlighted.Render(s.storage.String()),
+			styles.Highlighted.Render(tui.FormatTags(s.tags)), styles.Highlighted.Render(s.search)))
+	case len(s.tags) > 0:
+		b.WriteString(fmt.Sprintf("Operating storage %s, tags %s\n", styles.Highlighted.Render(s.storage.String()), styles.Highlighted.Render(tui.FormatTags(s.tags))))
+	case s.search != "":
+		b.WriteString(fmt.Sprintf("Operating storage %s, search %s\n", styles.Highlighted.Render(s.storage.String()), styles.Highlighted.Render(s.search)))
+	case s.folders != nil:
+		b.WriteString(fmt.Sprintf("Operating storage %s, folder %s\n", styles.Highlighted.Render(s.storage.String()), styles.Highlighted.Render(s.folderPath())))
+	default:
+		b.WriteString(fmt.Sprintf("Operating storage %s\n", styles.Highlighted.Render(s.storage.String())))
+	}
+
+	if s.folders == nil {
+		b.WriteString("Use ↑↓ to navigate, add[a], edit[e], mark[space], delete[d], copy[c], history[h], tags[g], search[/], trash[t], change password[p]\n")
+	} else {
+		b.WriteString("Use ↑↓ to navigate, add[a], edit[e], mark[space], delete[d], copy[c], history[h], tags[g], search[/], folders[f], move[m], trash[t], change password[p]\n")
+	}
 	b.WriteString(styles.TableStyle.Render(s.table.View()))
 
 	return styles.StorageScreenStyle.Render(b.String())
@@ -117,8 +250,16 @@ func (s *BrowseStorageScreen) HelpBindings() []key.Binding {
 	return []key.Binding{
 		key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add secret")),
 		key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit secret")),
-		key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete secret")),
+		key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark secret")),
+		key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "move secret or marked secrets to trash")),
 		key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy/save secret")),
+		key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "secret history")),
+		key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "filter by tags")),
+		key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search secrets")),
+		key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "browse folders")),
+		key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "move secret or marked secrets to folder")),
+		key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "open trash")),
+		key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "change master password")),
 	}
 }
 
@@ -127,20 +268,244 @@ func (s *BrowseStorageScreen) updateRows() {
 
 	sortSecrets(secrets)
 
+	// Отметки удаленных секретов сбрасываются
+	marked := make(map[uint64]struct{}, len(s.marked))
+
 	var rows []table.Row
 	for _, sec := range secrets {
+		if len(s.tags) > 0 || s.search != "" {
+			if !s.hasTags(sec) || !s.matches(sec) {
+				continue
+			}
+		} else if s.folders != nil && s.folderOf(sec) != s.folder {
+			continue
+		}
+
+		mark := ""
+		if _, ok := s.marked[sec.ID]; ok {
+			marked[sec.ID] = struct{}{}
+			mark = "*"
+		}
+
 		rows = append(rows, table.Row{
+			mark,
 			strconv.Itoa(int(sec.ID)),
 			sec.Title,
 			sec.SecretType,
+			tui.FormatTags(sec.Tags),
+			tui.FormatSize(sec.PayloadSize),
 			sec.CreatedAt.Format("02 Jan 06 15:04"),
 			sec.UpdatedAt.Format("02 Jan 06 15:04"),
+			formatExpiry(sec.ExpiresAt),
 		})
 	}
 
+	s.marked = marked
 	s.table.SetRows(rows)
 }
 
+// loadFolders загружает папки хранилища. Если открытая папка удалена, открывается корень хранилища.
+// Если хранилище не поддерживает папки или их не удалось загрузить, секреты показываются без разбиения по папкам.
+func (s *BrowseStorageScreen) loadFolders() {
+	s.folders = nil
+
+	browser, ok := s.storage.(folderBrowser)
+	if !ok {
+		return
+	}
+
+	folders, err := browser.Folders(context.Background())
+	if err != nil {
+		return
+	}
+
+	s.folders = make(map[uint64]*domain.Folder, len(folders))
+	for _, f := range folders {
+		s.folders[f.ID] = f
+	}
+
+	if _, ok = s.folders[s.folder]; !ok {
+		s.folder = 0
+	}
+}
+
+// handleTagFilter устанавливает фильтр по тегам, введенным через запятую; пустой ввод снимает фильтр
+func (s *BrowseStorageScreen) handleTagFilter(value string) tea.Cmd {
+	tags, err := tui.ParseTags(value)
+	if err != nil {
+		return errCmd("failed to filter secrets", err)
+	}
+
+	s.tags = tags
+	err = s.loadTagged()
+	s.updateRows()
+
+	if err != nil {
+		return errCmd("failed to find secrets by tags", err)
+	}
+
+	return nil
+}
+
+// loadTagged выбирает на сервере секреты, отмеченные тегами фильтра.
+// Если хранилище не поддерживает выбор по тегам или запрос не удался, секреты выбираются по тегам локальной копии.
+func (s *BrowseStorageScreen) loadTagged() error {
+	s.tagged = nil
+
+	finder, ok := s.storage.(tagFinder)
+	if !ok || len(s.tags) == 0 {
+		return nil
+	}
+
+	ids, err := finder.FindByTags(context.Background(), s.tags)
+	if err != nil {
+		// Хранилище без ключа хранилища не индексирует теги, поэтому секреты выбираются по локальной копии
+		if errors.Is(err, storage.ErrVaultKeyRequired) {
+			return nil
+		}
+		return err
+	}
+
+	s.tagged = make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		s.tagged[id] = struct{}{}
+	}
+
+	return nil
+}
+
+// hasTags проверяет, что секрет попадает в фильтр по тегам
+func (s *BrowseStorageScreen) hasTags(secret *domain.Secret) bool {
+	if s.tagged == nil {
+		return secret.HasTags(s.tags)
+	}
+
+	_, ok := s.tagged[secret.ID]
+	return ok
+}
+
+// handleSearch устанавливает строку поиска секретов; пустой ввод снимает поиск
+func (s *BrowseStorageScreen) handleSearch(value string) tea.Cmd {
+	s.search = strings.TrimSpace(value)
+	err := s.loadFound()
+	s.updateRows()
+
+	if err != nil {
+		return errCmd("failed to search secrets", err)
+	}
+
+	return nil
+}
+
+// loadFound ищет на сервере секреты по строке поиска.
+// Если хранилище не поддерживает поиск или запрос не удался, секреты ищутся по заголовкам локальной копии.
+func (s *BrowseStorageScreen) loadFound() error {
+	s.found = nil
+
+	searcher, ok := s.storage.(secretSearcher)
+	if !ok || s.search == "" {
+		return nil
+	}
+
+	ids, err := searcher.Search(context.Background(), s.search)
+	if err != nil {
+		// Хранилище без ключа хранилища не индексирует секреты, поэтому поиск выполняется по локальной копии
+		if errors.Is(err, storage.ErrVaultKeyRequired) {
+			return nil
+		}
+		return err
+	}
+
+	s.found = make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		s.found[id] = struct{}{}
+	}
+
+	return nil
+}
+
+// matches проверяет, что секрет попадает в результаты поиска. Без результатов сервера секрет подходит,
+// если его заголовок содержит все слова строки поиска.
+func (s *BrowseStorageScreen) matches(secret *domain.Secret) bool {
+	if s.search == "" {
+		return true
+	}
+
+	if s.found == nil {
+		title := strings.ToLower(secret.Title)
+		for _, word := range strings.Fields(strings.ToLower(s.search)) {
+			if !strings.Contains(title, word) {
+				return false
+			}
+		}
+		return true
+	}
+
+	_, ok := s.found[secret.ID]
+	return ok
+}
+
+// folderOf возвращает папку секрета; секреты из неизвестных папок показываются в корне хранилища
+func (s *BrowseStorageScreen) folderOf(secret *domain.Secret) uint64 {
+	if _, ok := s.folders[secret.FolderID]; !ok {
+		return 0
+	}
+
+	return secret.FolderID
+}
+
+// folderPath возвращает путь открытой папки от корня хранилища
+func (s *BrowseStorageScreen) folderPath() string {
+	var names []string
+	seen := make(map[uint64]struct{})
+
+	for id := s.folder; id != 0; {
+		f, ok := s.folders[id]
+		if !ok {
+			break
+		}
+		if _, ok = seen[id]; ok {
+			break
+		}
+		seen[id] = struct{}{}
+
+		names = append([]string{f.Name}, names...)
+		id = f.ParentID
+	}
+
+	return "/" + strings.Join(names, "/")
+}
+
+// formatExpiry возвращает оставшийся срок действия секрета для колонки таблицы
+func formatExpiry(expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return ""
+	}
+
+	remaining := time.Until(expiresAt)
+	if remaining <= 0 {
+		return "expired"
+	}
+
+	return tui.FormatTTL(remaining)
+}
+
+// toggleMark отмечает выбранный секрет для удаления или снимает с него отметку
+func (s *BrowseStorageScreen) toggleMark() {
+	id, err := s.selectedID()
+	if err != nil {
+		return
+	}
+
+	if _, ok := s.marked[id]; ok {
+		delete(s.marked, id)
+	} else {
+		s.marked[id] = struct{}{}
+	}
+
+	s.updateRows()
+}
+
 func (s *BrowseStorageScreen) handleEdit() tea.Cmd {
 	secret, err := s.getSelectedSecret()
 	if err != nil {
@@ -155,6 +520,15 @@ func (s *BrowseStorageScreen) handleEdit() tea.Cmd {
 	return tui.SetBodyPane(screen, tui.WithSecret(secret), tui.WithStorage(s.storage))
 }
 
+func (s *BrowseStorageScreen) handleHistory() tea.Cmd {
+	secret, err := s.getSelectedSecret()
+	if err != nil {
+		return errCmd("failed to load secret", err)
+	}
+
+	return tui.SetBodyPane(tui.SecretHistoryScreen, tui.WithSecret(secret), tui.WithStorage(s.storage))
+}
+
 func (s *BrowseStorageScreen) handleCopy() tea.Cmd {
 	secret, err := s.getSelectedSecret()
 	if err != nil {
@@ -172,18 +546,113 @@ func (s *BrowseStorageScreen) handleCopy() tea.Cmd {
 	return infoCmd("secret copied successfully")
 }
 
+// saveFile сохраняет файловый секрет по пути path.
+// Если хранилище поддерживает потоковую передачу, файл загружается в фоне с отображением хода загрузки.
+func (s *BrowseStorageScreen) saveFile(path string, secret *domain.Secret) tea.Cmd {
+	downloader, ok := s.storage.(fileDownloader)
+	if !ok {
+		if err := os.WriteFile(path, secret.Blob.FileBytes, 0644); err != nil {
+			return tui.ReportError(err)
+		}
+		return infoCmd("file saved successfully")
+	}
+
+	download := func(progress func(done, total int64)) error {
+		return downloader.DownloadFile(context.Background(), secret, path, progress)
+	}
+
+	return tui.Transfer("downloading file", download, func(err error) tea.Cmd {
+		if err != nil {
+			return errCmd("failed to save file", err)
+		}
+		return infoCmd("file saved successfully")
+	})
+}
+
 func (s *BrowseStorageScreen) handleDelete() tea.Cmd {
-	secret, err := s.getSelectedSecret()
+	if deleter, ok := s.storage.(batchDeleter); ok && len(s.marked) > 0 {
+		return s.deleteMarked(deleter)
+	}
+
+	// Для удаления данные секрета не нужны, поэтому он не загружается
+	id, err := s.selectedID()
 	if err != nil {
 		return errCmd("failed to load secret", err)
 	}
 
-	err = s.storage.Delete(context.Background(), secret.ID)
+	err = s.storage.Delete(context.Background(), id)
 	if err != nil {
 		return errCmd("failed to delete secret", err)
 	}
 
-	return infoCmd("secret deleted")
+	return infoCmd("secret moved to trash")
+}
+
+// deleteMarked перемещает отмеченные секреты в корзину одним запросом.
+// Секреты, которые не удалось удалить, остаются отмеченными.
+func (s *BrowseStorageScreen) deleteMarked(deleter batchDeleter) tea.Cmd {
+	ids := make([]uint64, 0, len(s.marked))
+	for id := range s.marked {
+		ids = append(ids, id)
+	}
+
+	errs, err := deleter.DeleteMany(context.Background(), ids)
+	if err != nil {
+		return errCmd("failed to delete secrets", err)
+	}
+
+	var failed error
+	for i, id := range ids {
+		if errs[i] != nil {
+			failed = errs[i]
+			continue
+		}
+		delete(s.marked, id)
+	}
+
+	if failed != nil {
+		return errCmd(fmt.Sprintf("failed to delete %d of %d secrets", len(s.marked), len(ids)), failed)
+	}
+
+	return infoCmd(fmt.Sprintf("%d secrets moved to trash", len(ids)))
+}
+
+// handleMove открывает выбор папки для отмеченных секретов или выбранного секрета и перемещает их в выбранную папку
+func (s *BrowseStorageScreen) handleMove() tea.Cmd {
+	browser, ok := s.storage.(folderBrowser)
+	if !ok || s.folders == nil {
+		return nil
+	}
+
+	ids := make([]uint64, 0, len(s.marked))
+	for id := range s.marked {
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		id, err := s.selectedID()
+		if err != nil {
+			return errCmd("failed to load secret", err)
+		}
+		ids = append(ids, id)
+	}
+
+	store := s.storage
+	move := func(args ...any) tea.Cmd {
+		folder := args[0].(uint64)
+
+		moved, err := browser.MoveSecrets(context.Background(), ids, folder)
+		if err != nil {
+			return errCmd("failed to move secrets", err)
+		}
+
+		return tea.Batch(
+			infoCmd(fmt.Sprintf("%d secrets moved", moved)),
+			tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(store), tui.WithFolder(folder)),
+		)
+	}
+
+	return tui.SetBodyPane(tui.FolderScreen, tui.WithStorage(s.storage), tui.WithFolder(s.folder), tui.WithCallback(move))
 }
 
 func errCmd(msg string, err error) tea.Cmd {
@@ -197,7 +666,7 @@ func infoCmd(msg string) tea.Cmd {
 func (s *BrowseStorageScreen) getSelectedSecret() (secret *domain.Secret, err error) {
 	row := s.table.SelectedRow()
 
-	secret, err = s.loadSecret(row[0])
+	secret, err = s.loadSecret(row[1])
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +674,16 @@ func (s *BrowseStorageScreen) getSelectedSecret() (secret *domain.Secret, err er
 	return secret, err
 }
 
+// selectedID возвращает идентификатор выбранного секрета
+func (s *BrowseStorageScreen) selectedID() (uint64, error) {
+	row := s.table.SelectedRow()
+	if row == nil {
+		return 0, errors.New("no secret selected")
+	}
+
+	return strconv.ParseUint(row[1], 10, 64)
+}
+
 func (s *BrowseStorageScreen) loadSecret(rawID string) (*domain.Secret, error) {
 	var err error
 
@@ -254,11 +733,15 @@ func sortSecrets(secrets []*domain.Secret) {
 
 func prepareTable() table.Model {
 	columns := []table.Column{
+		{Title: "", Width: 1},
 		{Title: "id", Width: 5},
 		{Title: "Title", Width: 20},
 		{Title: "Secret Type", Width: 20},
+		{Title: "Tags", Width: 20},
+		{Title: "Size", Width: 10},
 		{Title: "Created", Width: 20},
 		{Title: "Updated", Width: 20},
+		{Title: "Expires", Width: 10},
 	}
 
 	t := table.New(
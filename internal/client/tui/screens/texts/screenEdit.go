@@ -19,6 +19,8 @@ const (
 	textTitle = iota
 	textMetadata
 	textContent
+	textTTL
+	textTags
 )
 
 // TextEditScreen структура для экрана редактирования текстовых секретов
@@ -46,19 +48,21 @@ func NewTextEditScreen(secret *domain.Secret, store storage.Storage) *TextEditSc
 		storage: store,
 	}
 
-	inputs := make([]textinput.Model, 3)
+	inputs := make([]textinput.Model, 5)
 	inputs[textTitle] = newInput(inputOpts{placeholder: "Title", charLimit: 64})
 	inputs[textMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
 	inputs[textContent] = newInput(inputOpts{placeholder: "Content", charLimit: 164})
+	inputs[textTTL] = screens.NewTTLInput(secret)
+	inputs[textTags] = screens.NewTagsInput(secret)
 
 	var buttons []components.Button
 	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
-		err := m.Submit()
-		if err != nil {
-			return tui.ReportError(err)
-		} else {
-			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+		if err := m.Submit(); err != nil {
+			return screens.HandleSaveError(err, m.storage, m.secret, tui.TextEditScreen, func(secret *domain.Secret) tea.Cmd {
+				return screens.Save(m.storage, secret, tui.TextEditScreen)
+			})
 		}
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
 	}})
 
 	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
@@ -119,6 +123,14 @@ func (s *TextEditScreen) Submit() error {
 		return errors.New("please enter content")
 	}
 
+	if err = screens.ApplyTTL(s.secret, s.inputGroup.Inputs[textTTL].Value()); err != nil {
+		return err
+	}
+
+	if err = screens.ApplyTags(s.secret, s.inputGroup.Inputs[textTags].Value()); err != nil {
+		return err
+	}
+
 	s.secret.Title = title
 	s.secret.Metadata = metadata
 	s.secret.Text = &domain.Text{Content: content}
@@ -0,0 +1,208 @@
+// Package trash предоставляет экран корзины с удаленными секретами.
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/storage"
+	"github.com/romanp1989/gophkeeper/internal/client/tui"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/styles"
+	"strconv"
+	"strings"
+)
+
+const (
+	tableBorderSize = 4
+)
+
+// trashStore описывает хранилище с корзиной удаленных секретов.
+type trashStore interface {
+	Trash(ctx context.Context) ([]*domain.Secret, error)
+	Restore(ctx context.Context, id uint64) error
+	Purge(ctx context.Context, id uint64) error
+}
+
+// purgeMsg сообщение о подтверждении окончательного удаления секрета
+type purgeMsg struct {
+	id uint64
+}
+
+// TrashScreenMaker структура для создания экрана корзины.
+type TrashScreenMaker struct{}
+
+// Make создаёт экран корзины для хранилища из сообщения навигации.
+func (m TrashScreenMaker) Make(msg tui.NavigationMsg, _, _ int) (tui.TeaLike, error) {
+	trash, ok := msg.Storage.(trashStore)
+	if !ok {
+		return nil, errors.New("storage does not support trash")
+	}
+
+	return NewTrashScreen(msg.Storage, trash), nil
+}
+
+// TrashScreen экран со списком секретов в корзине.
+type TrashScreen struct {
+	storage storage.Storage
+	trash   trashStore
+	secrets []*domain.Secret
+	table   table.Model
+	err     error
+}
+
+// NewTrashScreen создает экран корзины и загружает удаленные секреты.
+func NewTrashScreen(store storage.Storage, trash trashStore) *TrashScreen {
+	scr := &TrashScreen{
+		storage: store,
+		trash:   trash,
+		table:   prepareTable(),
+	}
+
+	scr.updateRows()
+
+	return scr
+}
+
+// Init инициализирует экран.
+func (s *TrashScreen) Init() tea.Cmd {
+	return nil
+}
+
+// Update обрабатывает сообщения и нажатия клавиш.
+func (s *TrashScreen) Update(msg tea.Msg) tea.Cmd {
+	var (
+		cmd      tea.Cmd
+		commands []tea.Cmd
+	)
+
+	switch msg := msg.(type) {
+	case purgeMsg:
+		commands = append(commands, s.handlePurge(msg.id))
+	case tea.WindowSizeMsg:
+		s.table.SetHeight(max(msg.Height-tableBorderSize, 3))
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r":
+			commands = append(commands, s.handleRestore())
+		case "x":
+			if secret := s.selectedSecret(); secret != nil {
+				id := secret.ID
+				commands = append(commands, tui.YesNoPrompt(
+					fmt.Sprintf("Delete %q permanently?", secret.Title),
+					func() tea.Msg { return purgeMsg{id: id} },
+				))
+			}
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+	commands = append(commands, cmd)
+
+	return tea.Batch(commands...)
+}
+
+// View отображает список секретов в корзине.
+func (s *TrashScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("Trash of %s\n", styles.Highlighted.Render(s.storage.String())))
+	b.WriteString("Use ↑↓ to navigate, restore[r], delete permanently[x], back[b]\n")
+
+	switch {
+	case s.err != nil:
+		b.WriteString(fmt.Sprintf("failed to load trash: %s\n", s.err))
+	case len(s.secrets) == 0:
+		b.WriteString("Trash is empty\n")
+	default:
+		b.WriteString(styles.TableStyle.Render(s.table.View()))
+	}
+
+	return styles.StorageScreenStyle.Render(b.String())
+}
+
+// HelpBindings возвращает набор горячих клавиш для экрана.
+func (s *TrashScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restore secret")),
+		key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "delete permanently")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to storage")),
+	}
+}
+
+func (s *TrashScreen) updateRows() {
+	s.secrets, s.err = s.trash.Trash(context.Background())
+
+	rows := make([]table.Row, 0, len(s.secrets))
+	for _, sec := range s.secrets {
+		rows = append(rows, table.Row{
+			strconv.FormatUint(sec.ID, 10),
+			sec.Title,
+			sec.SecretType,
+			sec.DeletedAt.Format("02 Jan 06 15:04"),
+		})
+	}
+
+	s.table.SetRows(rows)
+}
+
+func (s *TrashScreen) handleRestore() tea.Cmd {
+	secret := s.selectedSecret()
+	if secret == nil {
+		return nil
+	}
+
+	if err := s.trash.Restore(context.Background(), secret.ID); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to restore secret: %w", err))
+	}
+
+	s.updateRows()
+
+	return tui.ReportInfo("secret %q restored", secret.Title)
+}
+
+func (s *TrashScreen) handlePurge(id uint64) tea.Cmd {
+	if err := s.trash.Purge(context.Background(), id); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to delete secret: %w", err))
+	}
+
+	s.updateRows()
+
+	return tui.ReportInfo("secret deleted permanently")
+}
+
+func (s *TrashScreen) selectedSecret() *domain.Secret {
+	cursor := s.table.Cursor()
+	if cursor < 0 || cursor >= len(s.secrets) {
+		return nil
+	}
+
+	return s.secrets[cursor]
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "id", Width: 5},
+		{Title: "Title", Width: 20},
+		{Title: "Secret Type", Width: 20},
+		{Title: "Deleted", Width: 20},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = styles.TableHeaderStyle
+	st.Selected = styles.TableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
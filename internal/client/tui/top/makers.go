@@ -2,28 +2,36 @@
 package top
 
 import (
+	"github.com/romanp1989/gophkeeper/internal/client/config"
 	"github.com/romanp1989/gophkeeper/internal/client/grpc"
 	"github.com/romanp1989/gophkeeper/internal/client/tui"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/auth"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/blobs"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/cards"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/credentials"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/folders"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/history"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/remotes"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/secrets"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/storage"
 	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/texts"
+	"github.com/romanp1989/gophkeeper/internal/client/tui/screens/trash"
 )
 
-func prepareMakers(client grpc.ClientGRPCInterface) map[tui.Screen]tui.ScreenMaker {
+func prepareMakers(client grpc.ClientGRPCInterface, cfg *config.Config) map[tui.Screen]tui.ScreenMaker {
 	return map[tui.Screen]tui.ScreenMaker{
 		tui.BlobEditScreen:       &blobs.BlobEditScreen{},
 		tui.CardEditScreen:       &cards.CardEditScreen{},
+		tui.ChangePasswordScreen: &auth.ChangePasswordScreenMaker{KDF: cfg.KDF, UnlockTime: cfg.KDFUnlockTime},
 		tui.CredentialEditScreen: &credentials.CredentialEditScreen{},
 		tui.FilePickScreen:       &blobs.FilePickScreen{},
-		tui.LoginScreen:          &auth.AuthenticateScreen{},
+		tui.FolderScreen:         &folders.FolderScreenMaker{},
+		tui.LoginScreen:          &auth.AuthenticateScreenMaker{KDF: cfg.KDF, UnlockTime: cfg.KDFUnlockTime},
 		tui.RemoteOpenScreen:     &remotes.RemoteOpenScreenMaker{Client: client},
+		tui.SecretHistoryScreen:  &history.HistoryScreenMaker{},
 		tui.SecretTypeScreen:     &secrets.SecretTypeScreen{},
-		tui.StorageBrowseScreen:  &storage.BrowseStorageScreen{},
+		tui.StorageBrowseScreen:  &storage.BrowseStorageScreenMaker{},
 		tui.TextEditScreen:       &texts.TextEditScreen{},
+		tui.TrashScreen:          &trash.TrashScreenMaker{},
 	}
 }
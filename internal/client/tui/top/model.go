@@ -2,11 +2,13 @@
 package top
 
 import (
+	"context"
 	"fmt"
 	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/romanp1989/gophkeeper/internal/client/config"
 	"github.com/romanp1989/gophkeeper/internal/client/grpc"
 	"github.com/romanp1989/gophkeeper/internal/client/tui"
@@ -39,8 +41,12 @@ const (
 var (
 	helpStyle    = styles.Padded.Background(styles.Grey).Foreground(styles.White)
 	versionStyle = styles.Padded.Background(styles.DarkGrey).Foreground(styles.White)
+	usageStyle   = styles.Padded.Background(styles.Grey).Foreground(styles.White)
 )
 
+// usageMsg сообщение с занятым местом в хранилище пользователя на сервере
+type usageMsg *domain.Usage
+
 // Model структура, представляющая модель данных для главного интерфейса пользователя.
 type Model struct {
 	*tui.PaneManager
@@ -54,12 +60,13 @@ type Model struct {
 	mode                      mode
 	prompt                    *tui.Prompt
 	showHelp                  bool
+	usageWidget               string
 	width                     int
 }
 
 // NewModel создает и инициализирует новую модель интерфейса пользователя.
 func NewModel(config *config.Config, client grpc.ClientGRPCInterface) (*Model, error) {
-	makers := prepareMakers(client)
+	makers := prepareMakers(client, config)
 
 	m := Model{
 		config:        config,
@@ -133,6 +140,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tui.InfoMsg:
 		m.info = string(msg)
 
+	case usageMsg:
+		m.usageWidget = usageStyle.Render(formatUsage(msg))
+
+	case tui.NavigationMsg:
+		commands = append(commands, m.PaneManager.Update(msg))
+		if msg.Screen == tui.StorageBrowseScreen {
+			commands = append(commands, m.loadUsage())
+		}
+
+	case grpc.ReloadSecretList:
+		commands = append(commands, m.PaneManager.Update(msg), m.loadUsage())
+
+	case tui.ProgressMsg:
+		if msg.Text != "" {
+			m.info = msg.Text
+		}
+		return m, msg.Next
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -195,7 +220,7 @@ func (m *Model) View() string {
 			Width(m.availableFooterMsgWidth()).
 			Render(m.info)
 	}
-	footer += m.versionWidget
+	footer += m.usageWidget + m.versionWidget
 
 	components = append(components, styles.Regular.
 		Inline(true).
@@ -207,7 +232,38 @@ func (m *Model) View() string {
 }
 
 func (m *Model) availableFooterMsgWidth() int {
-	return max(0, m.width-lipgloss.Width(m.helpWidget)-lipgloss.Width(m.versionWidget))
+	return max(0, m.width-lipgloss.Width(m.helpWidget)-lipgloss.Width(m.usageWidget)-lipgloss.Width(m.versionWidget))
+}
+
+// loadUsage создает команду, которая загружает занятое место в хранилище пользователя.
+// До входа в систему и при ошибке загрузки строка состояния не меняется.
+func (m *Model) loadUsage() tea.Cmd {
+	if m.client.GetToken() == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		usage, err := m.client.GetUsage(context.Background())
+		if err != nil {
+			return nil
+		}
+		return usageMsg(usage)
+	}
+}
+
+// formatUsage форматирует занятое место в хранилище вместе с ограничениями, если они заданы
+func formatUsage(usage *domain.Usage) string {
+	secrets := fmt.Sprintf("%d", usage.Secrets)
+	if usage.Quota.MaxSecrets > 0 {
+		secrets += fmt.Sprintf("/%d", usage.Quota.MaxSecrets)
+	}
+
+	bytes := tui.FormatSize(usage.Bytes)
+	if usage.Quota.MaxBytes > 0 {
+		bytes += "/" + tui.FormatSize(usage.Quota.MaxBytes)
+	}
+
+	return fmt.Sprintf("%s secrets, %s", secrets, bytes)
 }
 
 func (m *Model) viewHeight() int {
@@ -1,7 +1,21 @@
 // Package tui предоставляет утилиты и компоненты для создания текстового пользовательского интерфейса в приложении.
 package tui
 
-import "github.com/charmbracelet/bubbletea"
+import (
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// day длительность суток в сроке действия секрета
+const day = 24 * time.Hour
+
+// ErrInvalidTTL указывает, что срок действия секрета введен в неверном формате
+var ErrInvalidTTL = errors.New("invalid TTL: use a positive duration such as 30m, 12h or 7d")
 
 // CmdHandler создаёт команду для Bubble Tea, которая возвращает предоставленное сообщение.
 func CmdHandler(msg tea.Msg) tea.Cmd {
@@ -9,3 +23,82 @@ func CmdHandler(msg tea.Msg) tea.Cmd {
 		return msg
 	}
 }
+
+// FormatSize форматирует размер данных в двоичных единицах
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseTTL разбирает срок действия секрета: длительность в формате time.ParseDuration, перед которой
+// может быть указано количество суток, например 45m, 12h, 7d или 1d12h. Для пустой строки возвращает 0.
+func ParseTTL(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	var ttl time.Duration
+	if days, rest, ok := strings.Cut(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, ErrInvalidTTL
+		}
+		ttl, value = time.Duration(n)*day, rest
+	}
+
+	if value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, ErrInvalidTTL
+		}
+		ttl += d
+	}
+
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	return ttl, nil
+}
+
+// FormatTTL форматирует оставшийся срок действия секрета с точностью до минуты в формате, который понимает ParseTTL
+func FormatTTL(ttl time.Duration) string {
+	ttl = ttl.Round(time.Minute)
+	ttl = max(ttl, time.Minute)
+
+	days, hours, minutes := ttl/day, ttl%day/time.Hour, ttl%time.Hour/time.Minute
+
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// ParseTags разбирает теги, введенные через запятую, и приводит их к каноническому виду domain.NormalizeTags
+func ParseTags(value string) ([]string, error) {
+	return domain.NormalizeTags(strings.Split(value, ","))
+}
+
+// FormatTags форматирует теги в строку, которую понимает ParseTags
+func FormatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
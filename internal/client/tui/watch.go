@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"context"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/client/grpc"
+	"sync"
+	"time"
+)
+
+// watchRetryDelay задержка перед повторной подпиской на события после обрыва потока
+const watchRetryDelay = 5 * time.Second
+
+// watching клиенты, для которых уже запущено ожидание событий
+var watching sync.Map
+
+// WatchSecrets создает команду, которая ожидает события изменения секретов пользователя на сервере
+// и при каждом событии отправляет экранам ReloadSecretList. После обрыва потока подписка возобновляется,
+// а список секретов перезагружается, чтобы учесть изменения, сделанные за время обрыва.
+// Для одного клиента события ожидаются только одной командой.
+func WatchSecrets(client grpc.ClientGRPCInterface) tea.Cmd {
+	if _, loaded := watching.LoadOrStore(client, struct{}{}); loaded {
+		return nil
+	}
+
+	var (
+		events <-chan domain.SecretEvent
+		wait   tea.Cmd
+	)
+
+	reload := func() tea.Msg {
+		return ProgressMsg{Next: tea.Batch(CmdHandler(grpc.ReloadSecretList{}), wait)}
+	}
+
+	wait = func() tea.Msg {
+		reconnected := false
+		for {
+			if events == nil {
+				stream, err := client.WatchSecrets(context.Background())
+				if err != nil {
+					time.Sleep(watchRetryDelay)
+					continue
+				}
+				events = stream
+			}
+
+			if reconnected {
+				return reload()
+			}
+
+			if _, ok := <-events; !ok {
+				events = nil
+				reconnected = true
+				time.Sleep(watchRetryDelay)
+				continue
+			}
+
+			return reload()
+		}
+	}
+
+	return wait
+}
@@ -0,0 +1,127 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ReferenceRepository interface {
+	BlobRefs(ctx context.Context) (map[string][]uint64, error)
+}
+
+// MissingBlob ссылка секрета на отсутствующий файл
+type MissingBlob struct {
+	SecretID uint64
+	Ref      string
+}
+
+// Report результат проверки согласованности хранилища
+type Report struct {
+	// Orphaned файлы, на которые не ссылается ни один секрет
+	Orphaned []BlobInfo
+	// Missing ссылки секретов на файлы, которых нет в хранилище
+	Missing []MissingBlob
+}
+
+// Checker сверяет файлы хранилища со ссылками на них в базе данных.
+type Checker struct {
+	store      *FileStore
+	repository ReferenceRepository
+	config     *Config
+	logger     *zap.Logger
+}
+
+func NewChecker(store *FileStore, repository ReferenceRepository, config *Config, logger *zap.Logger) *Checker {
+	return &Checker{store: store, repository: repository, config: config, logger: logger}
+}
+
+// Check находит файлы без ссылок и ссылки на отсутствующие файлы.
+// Файл записывается до сохранения ссылки на него, поэтому файлы моложе OrphanGrace не считаются потерянными.
+// Файлы перечисляются до чтения ссылок, а отсутствие файла перепроверяется, поэтому файл,
+// записанный во время проверки, не попадает в отчет как отсутствующий.
+func (c *Checker) Check(ctx context.Context, now time.Time) (*Report, error) {
+	blobs, err := c.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := c.repository.BlobRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	listed := make(map[string]struct{}, len(blobs))
+
+	for _, blob := range blobs {
+		listed[blob.Ref] = struct{}{}
+
+		if _, ok := refs[blob.Ref]; !ok && blob.ModTime.Before(now.Add(-c.config.OrphanGrace)) {
+			report.Orphaned = append(report.Orphaned, blob)
+		}
+	}
+
+	for ref, secretIDs := range refs {
+		if _, ok := listed[ref]; ok {
+			continue
+		}
+
+		exists, err := c.store.Exists(ctx, ref)
+		if err != nil && !errors.Is(err, ErrInvalidRef) {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		for _, secretID := range secretIDs {
+			report.Missing = append(report.Missing, MissingBlob{SecretID: secretID, Ref: ref})
+		}
+	}
+
+	return report, nil
+}
+
+// Run проверяет хранилище с интервалом CheckInterval до отмены контекста и сообщает о найденных расхождениях.
+// Если включено RemoveOrphans, файлы без ссылок удаляются.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkOnce(ctx)
+		}
+	}
+}
+
+// checkOnce выполняет одну проверку и сообщает о ее результатах
+func (c *Checker) checkOnce(ctx context.Context) {
+	report, err := c.Check(ctx, time.Now())
+	if err != nil {
+		c.logger.Error("failed to check blob store", zap.Error(err))
+		return
+	}
+
+	for _, missing := range report.Missing {
+		c.logger.Error("blob file is missing", zap.Uint64("secret_id", missing.SecretID), zap.String("ref", missing.Ref))
+	}
+
+	for _, orphan := range report.Orphaned {
+		if !c.config.RemoveOrphans {
+			c.logger.Warn("orphaned blob file", zap.String("ref", orphan.Ref), zap.Int64("size", orphan.Size))
+			continue
+		}
+
+		if err = c.store.Delete(ctx, orphan.Ref); err != nil {
+			c.logger.Error("failed to remove orphaned blob file", zap.String("ref", orphan.Ref), zap.Error(err))
+			continue
+		}
+		c.logger.Info("orphaned blob file removed", zap.String("ref", orphan.Ref), zap.Int64("size", orphan.Size))
+	}
+}
@@ -0,0 +1,98 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/romanp1989/gophkeeper/tests/mocks"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	missingRef := strings.Repeat("a", refLength)
+
+	tests := []struct {
+		name          string
+		age           time.Duration
+		referenced    bool
+		expectOrphans int
+		expectMissing int
+	}{
+		{name: "Referenced", age: 2 * time.Hour, referenced: true, expectOrphans: 0, expectMissing: 1},
+		{name: "Orphaned", age: 2 * time.Hour, referenced: false, expectOrphans: 1, expectMissing: 1},
+		{name: "Orphaned_WithinGrace", age: time.Minute, referenced: false, expectOrphans: 0, expectMissing: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store, err := NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create blob store: %v", err)
+			}
+
+			ref, _, err := store.Put(ctx, bytes.NewReader([]byte("blob")))
+			if err != nil {
+				t.Fatalf("Failed to put blob: %v", err)
+			}
+			modTime := time.Now().Add(-tc.age)
+			_ = os.Chtimes(store.path(ref), modTime, modTime)
+
+			refs := map[string][]uint64{missingRef: {2}}
+			if tc.referenced {
+				refs[ref] = []uint64{1}
+			}
+
+			mockRepo := mocks.NewMockIReferenceRepository(ctrl)
+			mockRepo.EXPECT().BlobRefs(gomock.Any()).Return(refs, nil)
+
+			checker := NewChecker(store, mockRepo, &Config{CheckInterval: time.Hour, OrphanGrace: time.Hour}, zap.NewNop())
+
+			report, err := checker.Check(ctx, time.Now())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(report.Orphaned) != tc.expectOrphans {
+				t.Errorf("Expected %d orphaned blobs, got %+v", tc.expectOrphans, report.Orphaned)
+			}
+			if len(report.Missing) != tc.expectMissing || report.Missing[0] != (MissingBlob{SecretID: 2, Ref: missingRef}) {
+				t.Errorf("Expected %d missing blobs, got %+v", tc.expectMissing, report.Missing)
+			}
+		})
+	}
+}
+
+func TestChecker_RemoveOrphans(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create blob store: %v", err)
+	}
+
+	ref, _, err := store.Put(ctx, bytes.NewReader([]byte("blob")))
+	if err != nil {
+		t.Fatalf("Failed to put blob: %v", err)
+	}
+	modTime := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(store.path(ref), modTime, modTime)
+
+	mockRepo := mocks.NewMockIReferenceRepository(ctrl)
+	mockRepo.EXPECT().BlobRefs(gomock.Any()).Return(map[string][]uint64{}, nil)
+
+	checker := NewChecker(store, mockRepo, &Config{CheckInterval: time.Hour, OrphanGrace: time.Minute, RemoveOrphans: true}, zap.NewNop())
+	checker.checkOnce(ctx)
+
+	if exists, _ := store.Exists(ctx, ref); exists {
+		t.Errorf("Expected orphaned blob to be removed")
+	}
+}
@@ -0,0 +1,10 @@
+package blobstore
+
+import "time"
+
+type Config struct {
+	Dir           string        // Dir каталог хранилища файлов; пустой, если данные секретов хранятся только в PostgreSQL
+	CheckInterval time.Duration // CheckInterval интервал проверки согласованности файлов и ссылок на них
+	OrphanGrace   time.Duration // OrphanGrace возраст файла без ссылок, после которого он считается потерянным
+	RemoveOrphans bool          // RemoveOrphans удалять ли найденные файлы без ссылок
+}
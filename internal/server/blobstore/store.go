@@ -0,0 +1,231 @@
+// Package blobstore хранит данные файловых секретов в файлах вне PostgreSQL.
+// Файлы адресуются по содержимому: имя файла - SHA-256 его данных в hex.
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// refLength длина ссылки на файл: SHA-256 в hex
+	refLength = 2 * sha256.Size
+
+	// tmpDir каталог для записываемых файлов. Находится в той же файловой системе, что и хранилище,
+	// поэтому готовый файл перемещается на место атомарным переименованием
+	tmpDir = "tmp"
+)
+
+// ErrInvalidRef указывает на ссылку, которая не является SHA-256 в hex.
+var ErrInvalidRef = errors.New("invalid blob reference")
+
+// BlobInfo описывает файл хранилища
+type BlobInfo struct {
+	Ref     string
+	Size    int64
+	ModTime time.Time
+}
+
+// FileStore хранилище файлов в локальной файловой системе.
+// Файлы раскладываются по двум уровням каталогов по первым байтам ссылки, чтобы в одном каталоге
+// не накапливалось слишком много файлов: ab/cd/abcd...
+type FileStore struct {
+	root string
+}
+
+// NewFileStore создает хранилище в каталоге root
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Join(root, tmpDir), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create blob store directory: %w", err)
+	}
+
+	return &FileStore{root: root}, nil
+}
+
+// Put сохраняет данные r в файл и возвращает ссылку на него и размер данных.
+// Данные пишутся во временный файл, который переименовывается после записи на диск,
+// поэтому файл с именем ссылки всегда содержит данные целиком.
+func (s *FileStore) Put(ctx context.Context, r io.Reader) (ref string, size int64, err error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, tmpDir), "blob-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	size, err = io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return "", 0, err
+	}
+	if err = ctx.Err(); err != nil {
+		return "", 0, err
+	}
+	if err = tmp.Sync(); err != nil {
+		return "", 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	ref = hex.EncodeToString(hash.Sum(nil))
+	path := s.path(ref)
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", 0, err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+
+	return ref, size, syncDir(filepath.Dir(path))
+}
+
+// Open открывает файл по ссылке и возвращает его размер
+func (s *FileStore) Open(_ context.Context, ref string) (io.ReadCloser, int64, error) {
+	if !validRef(ref) {
+		return nil, 0, ErrInvalidRef
+	}
+
+	file, err := os.Open(s.path(ref))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, 0, storageErrors.ErrNotFound
+		}
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+// Size возвращает размер файла по ссылке без его открытия
+func (s *FileStore) Size(_ context.Context, ref string) (int64, error) {
+	if !validRef(ref) {
+		return 0, ErrInvalidRef
+	}
+
+	info, err := os.Stat(s.path(ref))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, storageErrors.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// Exists сообщает, есть ли в хранилище файл по ссылке
+func (s *FileStore) Exists(_ context.Context, ref string) (bool, error) {
+	if !validRef(ref) {
+		return false, ErrInvalidRef
+	}
+
+	_, err := os.Stat(s.path(ref))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Delete удаляет файл по ссылке. Удаление отсутствующего файла не является ошибкой
+func (s *FileStore) Delete(_ context.Context, ref string) error {
+	if !validRef(ref) {
+		return ErrInvalidRef
+	}
+
+	err := os.Remove(s.path(ref))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// List возвращает все файлы хранилища. Незавершенные временные файлы не включаются
+func (s *FileStore) List(ctx context.Context) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path == filepath.Join(s.root, tmpDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ref := d.Name()
+		if !validRef(ref) || path != s.path(ref) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		blobs = append(blobs, BlobInfo{Ref: ref, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+
+	return blobs, err
+}
+
+// path возвращает путь к файлу по ссылке
+func (s *FileStore) path(ref string) string {
+	return filepath.Join(s.root, ref[0:2], ref[2:4], ref)
+}
+
+// validRef проверяет, что ссылка является SHA-256 в hex. Ссылки из базы данных проверяются перед
+// построением пути, чтобы они не могли указать на файл вне хранилища
+func validRef(ref string) bool {
+	if len(ref) != refLength {
+		return false
+	}
+
+	for _, c := range ref {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// syncDir сбрасывает на диск запись каталога, чтобы переименование файла сохранилось при сбое питания
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
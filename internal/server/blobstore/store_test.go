@@ -0,0 +1,172 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	data := []byte("encrypted blob data")
+	sum := sha256.Sum256(data)
+	ref := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, store *FileStore, root string)
+	}{
+		{
+			name: "Put_Open_Success",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				gotRef, size, err := store.Put(ctx, bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if gotRef != ref || size != int64(len(data)) {
+					t.Errorf("Expected ref %s of size %d, got %s of size %d", ref, len(data), gotRef, size)
+				}
+				if _, err = os.Stat(filepath.Join(root, ref[0:2], ref[2:4], ref)); err != nil {
+					t.Errorf("Expected blob in sharded directory, got %v", err)
+				}
+
+				blob, size, err := store.Open(ctx, ref)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				defer blob.Close()
+
+				got, _ := io.ReadAll(blob)
+				if !bytes.Equal(got, data) || size != int64(len(data)) {
+					t.Errorf("Expected %q, got %q", data, got)
+				}
+			},
+		},
+		{
+			name: "Put_SameContent",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				first, _, _ := store.Put(ctx, bytes.NewReader(data))
+				second, _, err := store.Put(ctx, bytes.NewReader(data))
+				if err != nil || first != second {
+					t.Errorf("Expected the same ref for the same content, got %s and %s, %v", first, second, err)
+				}
+
+				blobs, _ := store.List(ctx)
+				if len(blobs) != 1 {
+					t.Errorf("Expected 1 blob, got %d", len(blobs))
+				}
+			},
+		},
+		{
+			name: "Put_Fail_ReadError",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				_, _, err := store.Put(ctx, iotest.ErrReader(errors.New("read error")))
+				if err == nil {
+					t.Fatalf("Expected error, got nil")
+				}
+
+				entries, _ := os.ReadDir(filepath.Join(root, tmpDir))
+				if len(entries) != 0 {
+					t.Errorf("Expected temporary file to be removed, got %d files", len(entries))
+				}
+				blobs, _ := store.List(ctx)
+				if len(blobs) != 0 {
+					t.Errorf("Expected no blobs, got %d", len(blobs))
+				}
+			},
+		},
+		{
+			name: "Open_Fail_NotFound",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				_, _, err := store.Open(ctx, ref)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+		{
+			name: "Open_Fail_InvalidRef",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				_, _, err := store.Open(ctx, "../../etc/passwd")
+				if !errors.Is(err, ErrInvalidRef) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidRef, err)
+				}
+			},
+		},
+		{
+			name: "Size",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				if _, err := store.Size(ctx, ref); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+
+				_, _, _ = store.Put(ctx, bytes.NewReader(data))
+
+				size, err := store.Size(ctx, ref)
+				if err != nil || size != int64(len(data)) {
+					t.Errorf("Expected size %d, got %d, %v", len(data), size, err)
+				}
+			},
+		},
+		{
+			name: "Exists_Delete",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(data)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				if exists, err := store.Exists(ctx, ref); !exists || err != nil {
+					t.Errorf("Expected blob to exist, got %v, %v", exists, err)
+				}
+
+				if err := store.Delete(ctx, ref); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if exists, err := store.Exists(ctx, ref); exists || err != nil {
+					t.Errorf("Expected blob to be deleted, got %v, %v", exists, err)
+				}
+				if err := store.Delete(ctx, ref); err != nil {
+					t.Errorf("Expected deleting missing blob to succeed, got %v", err)
+				}
+			},
+		},
+		{
+			name: "List_SkipsForeignFiles",
+			testFunc: func(t *testing.T, store *FileStore, root string) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(data)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				_ = os.WriteFile(filepath.Join(root, tmpDir, "blob-123"), []byte("partial"), 0600)
+				_ = os.WriteFile(filepath.Join(root, "README"), []byte("foreign"), 0600)
+
+				blobs, err := store.List(ctx)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(blobs) != 1 || blobs[0].Ref != ref || blobs[0].Size != int64(len(data)) {
+					t.Errorf("Unexpected blobs: %+v", blobs)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+
+			store, err := NewFileStore(root)
+			if err != nil {
+				t.Fatalf("Failed to create blob store: %v", err)
+			}
+
+			tc.testFunc(t, store, root)
+		})
+	}
+}
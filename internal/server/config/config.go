@@ -6,19 +6,32 @@ import (
 	"errors"
 	"fmt"
 	"github.com/romanp1989/gophkeeper/certs"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/blobstore"
 	"github.com/romanp1989/gophkeeper/internal/server/db"
+	"github.com/romanp1989/gophkeeper/internal/server/events"
+	"github.com/romanp1989/gophkeeper/internal/server/secret"
 	"github.com/romanp1989/gophkeeper/internal/server/token"
+	"github.com/romanp1989/gophkeeper/internal/server/upload"
+	"github.com/romanp1989/gophkeeper/internal/server/user"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc/credentials"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
 // Config представляет основную конфигурацию клиентского приложения.
 type Config struct {
-	Address string        // Address определяет адрес сервера.
-	Db      *db.Config    // Db конфиг подключения к PostgreSQL.
-	Token   *token.Config // Token конфиг JWT токена для авторизации
+	Address string            // Address определяет адрес сервера.
+	Db      *db.Config        // Db конфиг подключения к PostgreSQL.
+	Token   *token.Config     // Token конфиг JWT токена для авторизации
+	User    *user.Config      // User конфиг аутентификации пользователей
+	Upload  *upload.Config    // Upload конфиг сессий загрузки файлов
+	Blob    *blobstore.Config // Blob конфиг хранилища файлов вне PostgreSQL
+	Secret  *secret.Config    // Secret конфиг хранения секретов и их истории
+	Events  *events.Config    // Events конфиг рассылки событий изменения секретов
 }
 
 // NewConfig инициализирует и возвращает новый экземпляр конфигурации.
@@ -55,10 +68,102 @@ func NewConfig() (*Config, error) {
 		Expire: 24 * time.Hour,
 	}
 
+	fakeSaltKey := viper.GetString("fake-salt-key")
+	if fakeSaltKey == "" {
+		return nil, errors.New("key for PreLogin params of unknown logins is not set: set GOPHKEEPER_FAKE_SALT_KEY environment variable")
+	}
+	if fakeSaltKey == secretKey {
+		return nil, errors.New("GOPHKEEPER_FAKE_SALT_KEY must differ from GOPHKEEPER_SECRET_KEY")
+	}
+
+	userConfig := &user.Config{
+		FakeSaltKey: []byte(fakeSaltKey),
+	}
+
+	viper.SetDefault("upload-dir", filepath.Join(os.TempDir(), "gophkeeper-uploads"))
+	viper.SetDefault("upload-session-ttl", 24*time.Hour)
+	viper.SetDefault("upload-cleanup-interval", 10*time.Minute)
+
+	uploadConfig := &upload.Config{
+		Dir:             viper.GetString("upload-dir"),
+		SessionTTL:      viper.GetDuration("upload-session-ttl"),
+		CleanupInterval: viper.GetDuration("upload-cleanup-interval"),
+	}
+	if uploadConfig.Dir == "" {
+		return nil, errors.New("upload directory is not set: check GOPHKEEPER_UPLOAD_DIR environment variable")
+	}
+	if uploadConfig.SessionTTL <= 0 || uploadConfig.CleanupInterval <= 0 {
+		return nil, errors.New("upload session TTL and cleanup interval must be positive: check GOPHKEEPER_UPLOAD_SESSION_TTL and GOPHKEEPER_UPLOAD_CLEANUP_INTERVAL environment variables")
+	}
+
+	viper.SetDefault("blob-threshold", 1<<20)
+	viper.SetDefault("blob-check-interval", 24*time.Hour)
+	viper.SetDefault("blob-orphan-grace", time.Hour)
+
+	blobConfig := &blobstore.Config{
+		Dir:           viper.GetString("blob-dir"),
+		CheckInterval: viper.GetDuration("blob-check-interval"),
+		OrphanGrace:   viper.GetDuration("blob-orphan-grace"),
+		RemoveOrphans: viper.GetBool("blob-remove-orphans"),
+	}
+	if blobConfig.Dir != "" && (blobConfig.CheckInterval <= 0 || blobConfig.OrphanGrace < 0) {
+		return nil, errors.New("blob store check interval and orphan grace period are invalid: check GOPHKEEPER_BLOB_CHECK_INTERVAL and GOPHKEEPER_BLOB_ORPHAN_GRACE environment variables")
+	}
+
+	viper.SetDefault("secret-versions", 10)
+	viper.SetDefault("trash-retention-days", 30)
+	viper.SetDefault("trash-purge-interval", time.Hour)
+	viper.SetDefault("expire-interval", time.Minute)
+
+	secretConfig := &secret.Config{
+		BlobThreshold:  viper.GetInt64("blob-threshold"),
+		Versions:       viper.GetInt("secret-versions"),
+		TrashRetention: time.Duration(viper.GetInt("trash-retention-days")) * 24 * time.Hour,
+		PurgeInterval:  viper.GetDuration("trash-purge-interval"),
+		ExpireInterval: viper.GetDuration("expire-interval"),
+		PurgeExpired:   viper.GetBool("expire-purge"),
+	}
+	if secretConfig.BlobThreshold < 0 || secretConfig.Versions < 0 {
+		return nil, errors.New("blob threshold and secret versions count must not be negative: check GOPHKEEPER_BLOB_THRESHOLD and GOPHKEEPER_SECRET_VERSIONS environment variables")
+	}
+	if secretConfig.TrashRetention < 0 || secretConfig.PurgeInterval <= 0 {
+		return nil, errors.New("trash retention must not be negative and purge interval must be positive: check GOPHKEEPER_TRASH_RETENTION_DAYS and GOPHKEEPER_TRASH_PURGE_INTERVAL environment variables")
+	}
+
+	if secretConfig.ExpireInterval <= 0 {
+		return nil, errors.New("expired secrets check interval must be positive: check GOPHKEEPER_EXPIRE_INTERVAL environment variable")
+	}
+
+	secretConfig.Quota = domain.Quota{
+		MaxSecrets:     viper.GetInt64("quota-secrets"),
+		MaxBytes:       viper.GetInt64("quota-bytes"),
+		MaxPayloadSize: viper.GetInt64("quota-payload-size"),
+	}
+	if secretConfig.Quota.MaxSecrets < 0 || secretConfig.Quota.MaxBytes < 0 || secretConfig.Quota.MaxPayloadSize < 0 {
+		return nil, errors.New("storage quotas must not be negative: check GOPHKEEPER_QUOTA_SECRETS, GOPHKEEPER_QUOTA_BYTES and GOPHKEEPER_QUOTA_PAYLOAD_SIZE environment variables")
+	}
+
+	viper.SetDefault("events-buffer", 64)
+	viper.SetDefault("events-reconnect-delay", time.Minute)
+
+	eventsConfig := &events.Config{
+		Postgres:       viper.GetBool("events-postgres"),
+		BufferSize:     viper.GetInt("events-buffer"),
+		ReconnectDelay: viper.GetDuration("events-reconnect-delay"),
+	}
+	if eventsConfig.BufferSize <= 0 || eventsConfig.ReconnectDelay <= 0 {
+		return nil, errors.New("events buffer size and reconnect delay must be positive: check GOPHKEEPER_EVENTS_BUFFER and GOPHKEEPER_EVENTS_RECONNECT_DELAY environment variables")
+	}
+
 	return &Config{
 		Address: address,
 		Db:      dbConfig,
 		Token:   tokenConfig,
+		User:    userConfig,
+		Upload:  uploadConfig,
+		Blob:    blobConfig,
+		Secret:  secretConfig,
+		Events:  eventsConfig,
 	}, nil
 }
 
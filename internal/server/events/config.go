@@ -0,0 +1,9 @@
+package events
+
+import "time"
+
+type Config struct {
+	Postgres       bool          // Postgres рассылать события между экземплярами сервера через LISTEN/NOTIFY PostgreSQL
+	BufferSize     int           // BufferSize количество событий, ожидающих отправки подписчику; при переполнении подписка закрывается
+	ReconnectDelay time.Duration // ReconnectDelay максимальная задержка переподключения к PostgreSQL для получения событий
+}
@@ -0,0 +1,96 @@
+// Package events рассылает события изменения секретов клиентам, подписанным на изменения своих секретов.
+package events
+
+import (
+	"context"
+	"github.com/romanp1989/gophkeeper/domain"
+	"sync"
+)
+
+// Hub рассылает события изменения секретов подписчикам в пределах одного экземпляра сервера.
+// События отправляются без ожидания: подписка, не успевающая получать события, закрывается,
+// и клиент после переподключения получает пропущенные изменения через ленту изменений.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[domain.UserID]map[chan domain.SecretEvent]struct{}
+	bufferSize  int
+}
+
+// NewHub создает рассыльщик событий с буфером config.BufferSize событий на подписку.
+func NewHub(config *Config) *Hub {
+	return &Hub{
+		subscribers: make(map[domain.UserID]map[chan domain.SecretEvent]struct{}),
+		bufferSize:  max(config.BufferSize, 1),
+	}
+}
+
+// Subscribe подписывает на события секретов пользователя userID.
+// Канал закрывается после вызова возвращенной функции отмены или при переполнении буфера подписки.
+func (h *Hub) Subscribe(userID domain.UserID) (<-chan domain.SecretEvent, func()) {
+	ch := make(chan domain.SecretEvent, h.bufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan domain.SecretEvent]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.remove(userID, ch)
+		})
+	}
+}
+
+// Publish рассылает событие подписчикам владельца секрета.
+func (h *Hub) Publish(_ context.Context, e domain.SecretEvent) {
+	h.Deliver(e)
+}
+
+// Deliver отправляет событие подписчикам этого экземпляра сервера.
+func (h *Hub) Deliver(e domain.SecretEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[e.UserID] {
+		select {
+		case ch <- e:
+		default:
+			h.remove(e.UserID, ch)
+		}
+	}
+}
+
+// Reset закрывает все подписки.
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for userID, subscribers := range h.subscribers {
+		for ch := range subscribers {
+			h.remove(userID, ch)
+		}
+	}
+}
+
+// remove закрывает подписку. Вызывается при захваченном mu
+func (h *Hub) remove(userID domain.UserID, ch chan domain.SecretEvent) {
+	subscribers, ok := h.subscribers[userID]
+	if !ok {
+		return
+	}
+	if _, ok = subscribers[ch]; !ok {
+		return
+	}
+
+	delete(subscribers, ch)
+	close(ch)
+
+	if len(subscribers) == 0 {
+		delete(h.subscribers, userID)
+	}
+}
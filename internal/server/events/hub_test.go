@@ -0,0 +1,114 @@
+package events
+
+import (
+	"context"
+	"github.com/romanp1989/gophkeeper/domain"
+	"testing"
+)
+
+func TestHub(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, hub *Hub)
+	}{
+		{
+			name: "Publish_DeliversToOwner",
+			testFunc: func(t *testing.T, hub *Hub) {
+				owner, cancelOwner := hub.Subscribe(1)
+				defer cancelOwner()
+				other, cancelOther := hub.Subscribe(2)
+				defer cancelOther()
+
+				event := domain.SecretEvent{Type: domain.SecretUpdated, UserID: 1, SecretID: 10}
+				hub.Publish(ctx, event)
+
+				if got := <-owner; got != event {
+					t.Errorf("Expected event %+v, got %+v", event, got)
+				}
+				select {
+				case got := <-other:
+					t.Errorf("Expected no event for another user, got %+v", got)
+				default:
+				}
+			},
+		},
+		{
+			name: "Publish_DeliversToEverySubscription",
+			testFunc: func(t *testing.T, hub *Hub) {
+				first, cancelFirst := hub.Subscribe(1)
+				defer cancelFirst()
+				second, cancelSecond := hub.Subscribe(1)
+				defer cancelSecond()
+
+				hub.Publish(ctx, domain.SecretEvent{Type: domain.SecretCreated, UserID: 1, SecretID: 10})
+
+				if got := <-first; got.SecretID != 10 {
+					t.Errorf("Unexpected event in first subscription: %+v", got)
+				}
+				if got := <-second; got.SecretID != 10 {
+					t.Errorf("Unexpected event in second subscription: %+v", got)
+				}
+			},
+		},
+		{
+			name: "Cancel_ClosesSubscription",
+			testFunc: func(t *testing.T, hub *Hub) {
+				events, cancel := hub.Subscribe(1)
+				cancel()
+				cancel()
+
+				if _, ok := <-events; ok {
+					t.Errorf("Expected closed subscription")
+				}
+
+				hub.Publish(ctx, domain.SecretEvent{Type: domain.SecretDeleted, UserID: 1, SecretID: 10})
+				if len(hub.subscribers) != 0 {
+					t.Errorf("Expected no subscribers, got %d", len(hub.subscribers))
+				}
+			},
+		},
+		{
+			name: "Publish_ClosesOverflowedSubscription",
+			testFunc: func(t *testing.T, hub *Hub) {
+				events, cancel := hub.Subscribe(1)
+				defer cancel()
+
+				hub.Publish(ctx, domain.SecretEvent{Type: domain.SecretUpdated, UserID: 1, SecretID: 10})
+				hub.Publish(ctx, domain.SecretEvent{Type: domain.SecretUpdated, UserID: 1, SecretID: 11})
+
+				if got := <-events; got.SecretID != 10 {
+					t.Errorf("Expected buffered event, got %+v", got)
+				}
+				if _, ok := <-events; ok {
+					t.Errorf("Expected overflowed subscription to be closed")
+				}
+			},
+		},
+		{
+			name: "Reset_ClosesAllSubscriptions",
+			testFunc: func(t *testing.T, hub *Hub) {
+				first, cancelFirst := hub.Subscribe(1)
+				defer cancelFirst()
+				second, cancelSecond := hub.Subscribe(2)
+				defer cancelSecond()
+
+				hub.Reset()
+
+				if _, ok := <-first; ok {
+					t.Errorf("Expected first subscription to be closed")
+				}
+				if _, ok := <-second; ok {
+					t.Errorf("Expected second subscription to be closed")
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.testFunc(t, NewHub(&Config{BufferSize: 1}))
+		})
+	}
+}
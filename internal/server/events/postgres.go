@@ -0,0 +1,81 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/lib/pq"
+	"github.com/romanp1989/gophkeeper/domain"
+	"go.uber.org/zap"
+	"time"
+)
+
+// channel канал PostgreSQL, через который экземпляры сервера обмениваются событиями
+const channel = "secret_events"
+
+// PostgresHub рассылает события между экземплярами сервера через LISTEN/NOTIFY PostgreSQL.
+// Событие публикуется уведомлением, и каждый экземпляр, включая отправивший его, доставляет событие своим подписчикам.
+type PostgresHub struct {
+	*Hub
+	db     *sql.DB
+	dsn    string
+	config *Config
+	logger *zap.Logger
+}
+
+// NewPostgresHub создает рассыльщик событий, публикующий события в PostgreSQL по подключению db.
+// Уведомления получаются отдельным подключением к dsn после запуска Run.
+func NewPostgresHub(db *sql.DB, dsn string, config *Config, logger *zap.Logger) *PostgresHub {
+	return &PostgresHub{Hub: NewHub(config), db: db, dsn: dsn, config: config, logger: logger}
+}
+
+// Publish публикует событие для всех экземпляров сервера.
+// Если уведомление отправить не удалось, событие доставляется только подписчикам этого экземпляра.
+func (h *PostgresHub) Publish(ctx context.Context, e domain.SecretEvent) {
+	payload, err := json.Marshal(e)
+	if err == nil {
+		_, err = h.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, string(payload))
+	}
+
+	if err != nil {
+		h.logger.Error("failed to publish secret event", zap.Uint64("secret_id", e.SecretID), zap.Error(err))
+		h.Deliver(e)
+	}
+}
+
+// Run получает уведомления о событиях и доставляет их подписчикам до отмены контекста.
+func (h *PostgresHub) Run(ctx context.Context) {
+	listener := pq.NewListener(h.dsn, time.Second, h.config.ReconnectDelay, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			h.logger.Warn("secret events listener connection failed", zap.Error(err))
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		h.logger.Error("failed to listen for secret events", zap.Error(err))
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			// После переподключения приходит nil: уведомления за время обрыва потеряны,
+			// поэтому подписки закрываются, и клиенты загружают пропущенные изменения при переподключении
+			if n == nil {
+				h.Reset()
+				continue
+			}
+
+			var e domain.SecretEvent
+			if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+				h.logger.Warn("malformed secret event", zap.String("payload", n.Extra), zap.Error(err))
+				continue
+			}
+
+			h.Deliver(e)
+		}
+	}
+}
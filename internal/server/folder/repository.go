@@ -0,0 +1,284 @@
+package folder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// folderColumns список колонок, читаемых из таблицы folders
+const folderColumns = "id, user_id, parent_id, name, created_at, updated_at"
+
+// ErrFolderCycle указывает, что папку пытаются переместить в нее саму или во вложенную в нее папку.
+var ErrFolderCycle = errors.New("folder cannot be moved into itself")
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewFolderRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// List получение всех папок пользователя в порядке создания. Названия папок зашифрованы клиентом,
+// поэтому упорядочить папки по названию может только он.
+func (r *Repository) List(ctx context.Context, userID domain.UserID) ([]*domain.Folder, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+folderColumns+" FROM folders WHERE user_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := make([]*domain.Folder, 0)
+	for rows.Next() {
+		folder, err := scanFolder(rows)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// Create сохранение новой папки. Возвращает ErrNotFound, если родительская папка не найдена среди папок пользователя.
+func (r *Repository) Create(ctx context.Context, folder *domain.Folder) (*domain.Folder, error) {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO folders (user_id, parent_id, name, created_at, updated_at)
+			SELECT $1, $2, $3, $4, $4
+			WHERE $2::bigint IS NULL OR EXISTS (SELECT 1 FROM folders WHERE id = $2 AND user_id = $1)
+			RETURNING id`,
+		folder.UserID, nullID(folder.ParentID), folder.Name, folder.CreatedAt,
+	).Scan(&folder.ID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return folder, nil
+}
+
+// Rename изменение названия папки пользователя
+func (r *Repository) Rename(ctx context.Context, id uint64, userID domain.UserID, name string, updatedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE folders SET name = $1, updated_at = $2 WHERE id = $3 AND user_id = $4",
+		name, updatedAt, id, userID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return checkAffected(result)
+}
+
+// Move перемещение папки пользователя в папку parentID; нулевой parentID перемещает папку в корень хранилища.
+// Папки пользователя блокируются до конца транзакции, чтобы одновременные перемещения не образовали цикл.
+func (r *Repository) Move(ctx context.Context, id uint64, userID domain.UserID, parentID uint64, updatedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.ExecContext(ctx, "SELECT id FROM folders WHERE user_id = $1 FOR UPDATE", userID); err != nil {
+		return err
+	}
+
+	if parentID > 0 {
+		var cycle, exists bool
+		err = tx.QueryRowContext(ctx,
+			`WITH RECURSIVE subtree AS (
+				SELECT id FROM folders WHERE id = $1 AND user_id = $2
+				UNION ALL
+				SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $3), EXISTS (SELECT 1 FROM folders WHERE id = $3 AND user_id = $2)`,
+			id, userID, parentID,
+		).Scan(&cycle, &exists)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return storageErrors.ErrNotFound
+		}
+		if cycle {
+			return ErrFolderCycle
+		}
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE folders SET parent_id = $1, updated_at = $2 WHERE id = $3 AND user_id = $4",
+		nullID(parentID), updatedAt, id, userID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	if err = checkAffected(result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete удаление папки пользователя. Вложенные папки и секреты, в том числе находящиеся в корзине,
+// переносятся в родительскую папку удаляемой папки. Возвращает идентификаторы перенесенных секретов.
+func (r *Repository) Delete(ctx context.Context, id uint64, userID domain.UserID, updatedAt time.Time) ([]uint64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var parentID sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT parent_id FROM folders WHERE id = $1 AND user_id = $2 FOR UPDATE", id, userID).Scan(&parentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE folders SET parent_id = $1, updated_at = $2 WHERE parent_id = $3", parentID, updatedAt, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	moved, err := updateFolder(ctx, tx, "folder_id = $2", parentID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM folders WHERE id = $1", id); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// MoveSecrets перемещение секретов пользователя вне корзины в папку folderID; нулевой folderID перемещает секреты
+// в корень хранилища. Возвращает идентификаторы перемещенных секретов: не найденные секреты пропускаются.
+func (r *Repository) MoveSecrets(ctx context.Context, ids []uint64, userID domain.UserID, folderID uint64) ([]uint64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Папка блокируется, чтобы она не была удалена до перемещения в нее секретов
+	if folderID > 0 {
+		var locked uint64
+		err = tx.QueryRowContext(ctx, "SELECT id FROM folders WHERE id = $1 AND user_id = $2 FOR SHARE", folderID, userID).Scan(&locked)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, storageErrors.ErrNotFound
+			}
+			return nil, err
+		}
+	}
+
+	args := []any{nullID(folderID), userID}
+	placeholders := make([]string, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+
+	moved, err := updateFolder(ctx, tx, "user_id = $2 AND deleted_at IS NULL AND id IN ("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// updateFolder переносит секреты, выбранные условием where, в папку из первого аргумента запроса
+// и возвращает их идентификаторы. Номер редакции секретов увеличивается, чтобы клиенты получили изменение.
+func updateFolder(ctx context.Context, tx *sql.Tx, where string, args ...any) ([]uint64, error) {
+	rows, err := tx.QueryContext(ctx,
+		"UPDATE secrets SET folder_id = $1, revision = revision + 1 WHERE "+where+" RETURNING id",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]uint64, 0)
+	for rows.Next() {
+		var id uint64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// scanFolder читает папку из строки результата запроса
+func scanFolder(row interface{ Scan(dest ...any) error }) (*domain.Folder, error) {
+	var (
+		folder   domain.Folder
+		parentID sql.NullInt64
+	)
+
+	err := row.Scan(&folder.ID, &folder.UserID, &parentID, &folder.Name, &folder.CreatedAt, &folder.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	folder.ParentID = uint64(parentID.Int64)
+
+	return &folder, nil
+}
+
+// nullID возвращает значение ссылки на папку, равное NULL для корня хранилища
+func nullID(id uint64) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id > 0}
+}
+
+// mapError заменяет ошибки PostgreSQL ошибками репозитория
+func mapError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storageErrors.ErrNotFound
+	}
+
+	return err
+}
+
+// checkAffected возвращает ErrNotFound, если запрос не изменил ни одной строки
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storageErrors.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestFolderRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+		expectErr bool
+	}{
+		{
+			name: "List_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "parent_id", "name", "created_at", "updated_at"}).
+					AddRow(1, 1, nil, "Bank", now, now).
+					AddRow(2, 1, 1, "Cards", now, now)
+				mock.ExpectQuery(`SELECT id, user_id, parent_id, name, created_at, updated_at FROM folders WHERE user_id = \$1 ORDER BY id`).
+					WithArgs(1).
+					WillReturnRows(rows)
+
+				folders, err := repo.List(ctx, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(folders) != 2 || folders[0].ParentID != 0 || folders[1].ParentID != 1 {
+					t.Errorf("Unexpected folders: %+v, %+v", folders[0], folders[1])
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Create_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO folders \(user_id, parent_id, name, created_at, updated_at\)\s+SELECT \$1, \$2, \$3, \$4, \$4\s+WHERE \$2::bigint IS NULL OR EXISTS \(SELECT 1 FROM folders WHERE id = \$2 AND user_id = \$1\)\s+RETURNING id`).
+					WithArgs(1, 3, "Cards", now).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+				folder, err := repo.Create(ctx, &domain.Folder{UserID: 1, ParentID: 3, Name: "Cards", CreatedAt: now})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if folder.ID != 4 {
+					t.Errorf("Expected ID 4, got %v", folder.ID)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Create_Fail_ParentNotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`INSERT INTO folders`).
+					WithArgs(1, 3, "Cards", now).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+				_, err := repo.Create(ctx, &domain.Folder{UserID: 1, ParentID: 3, Name: "Cards", CreatedAt: now})
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Rename_Fail_NotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE folders SET name = \$1, updated_at = \$2 WHERE id = \$3 AND user_id = \$4`).
+					WithArgs("Work", now, 5, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := repo.Rename(ctx, 5, 1, "Work", now)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Move_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT id FROM folders WHERE user_id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectQuery(`WITH RECURSIVE subtree AS`).
+					WithArgs(2, 1, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"cycle", "exists"}).AddRow(false, true))
+				mock.ExpectExec(`UPDATE folders SET parent_id = \$1, updated_at = \$2 WHERE id = \$3 AND user_id = \$4`).
+					WithArgs(3, now, 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				if err := repo.Move(ctx, 2, 1, 3, now); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Move_Fail_Cycle",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT id FROM folders WHERE user_id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectQuery(`WITH RECURSIVE subtree AS`).
+					WithArgs(1, 1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"cycle", "exists"}).AddRow(true, true))
+				mock.ExpectRollback()
+
+				err := repo.Move(ctx, 1, 1, 2, now)
+				if !errors.Is(err, ErrFolderCycle) {
+					t.Errorf("Expected error 'ErrFolderCycle', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Move_ToRoot",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT id FROM folders WHERE user_id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`UPDATE folders SET parent_id = \$1, updated_at = \$2 WHERE id = \$3 AND user_id = \$4`).
+					WithArgs(nil, now, 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				if err := repo.Move(ctx, 2, 1, 0, now); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Delete_MovesContentToParent",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT parent_id FROM folders WHERE id = \$1 AND user_id = \$2 FOR UPDATE`).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(1))
+				mock.ExpectExec(`UPDATE folders SET parent_id = \$1, updated_at = \$2 WHERE parent_id = \$3`).
+					WithArgs(1, now, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE secrets SET folder_id = \$1, revision = revision \+ 1 WHERE folder_id = \$2 RETURNING id`).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+				mock.ExpectExec(`DELETE FROM folders WHERE id = \$1`).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				moved, err := repo.Delete(ctx, 2, 1, now)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(moved) != 2 || moved[0] != 7 || moved[1] != 8 {
+					t.Errorf("Expected moved secrets [7 8], got %v", moved)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Delete_Fail_NotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT parent_id FROM folders WHERE id = \$1 AND user_id = \$2 FOR UPDATE`).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}))
+				mock.ExpectRollback()
+
+				_, err := repo.Delete(ctx, 2, 1, now)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "MoveSecrets_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM folders WHERE id = \$1 AND user_id = \$2 FOR SHARE`).
+					WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectQuery(`UPDATE secrets SET folder_id = \$1, revision = revision \+ 1 WHERE user_id = \$2 AND deleted_at IS NULL AND id IN \(\$3, \$4\) RETURNING id`).
+					WithArgs(3, 1, 10, 11).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectCommit()
+
+				moved, err := repo.MoveSecrets(ctx, []uint64{10, 11}, 1, 3)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(moved) != 1 || moved[0] != 10 {
+					t.Errorf("Expected moved secrets [10], got %v", moved)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "MoveSecrets_Fail_FolderNotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id FROM folders WHERE id = \$1 AND user_id = \$2 FOR SHARE`).
+					WithArgs(3, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+
+				_, err := repo.MoveSecrets(ctx, []uint64{10}, 1, 3)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewFolderRepository(db)
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
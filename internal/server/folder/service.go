@@ -0,0 +1,113 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"github.com/romanp1989/gophkeeper/domain"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxNameLength максимальная длина названия папки в символах
+	maxNameLength = 255
+	// maxSealedNameLength максимальная длина названия папки, зашифрованного клиентом, в байтах
+	maxSealedNameLength = 4096
+)
+
+// ErrInvalidName определяет ошибку, возникающую при пустом или слишком длинном названии папки,
+// а также при открытом названии, содержащем разделитель пути "/".
+var ErrInvalidName = errors.New("invalid folder name")
+
+type FolderRepository interface {
+	List(ctx context.Context, userID domain.UserID) ([]*domain.Folder, error)
+	Create(ctx context.Context, folder *domain.Folder) (*domain.Folder, error)
+	Rename(ctx context.Context, id uint64, userID domain.UserID, name string, updatedAt time.Time) error
+	Move(ctx context.Context, id uint64, userID domain.UserID, parentID uint64, updatedAt time.Time) error
+	Delete(ctx context.Context, id uint64, userID domain.UserID, updatedAt time.Time) ([]uint64, error)
+	MoveSecrets(ctx context.Context, ids []uint64, userID domain.UserID, folderID uint64) ([]uint64, error)
+}
+
+type Service struct {
+	repository FolderRepository
+}
+
+func NewFolderService(repository FolderRepository) *Service {
+	return &Service{repository: repository}
+}
+
+// List получение всех папок пользователя
+func (s *Service) List(ctx context.Context, userID domain.UserID) ([]*domain.Folder, error) {
+	return s.repository.List(ctx, userID)
+}
+
+// Create создание папки пользователя в папке folder.ParentID. Клиент шифрует название папки с ее идентификатором,
+// который еще не известен, поэтому папка может быть создана без названия и названа затем переименованием.
+func (s *Service) Create(ctx context.Context, folder *domain.Folder) (*domain.Folder, error) {
+	if folder.Name != "" {
+		name, err := normalizeName(folder.Name)
+		if err != nil {
+			return nil, err
+		}
+		folder.Name = name
+	}
+
+	folder.CreatedAt = time.Now()
+	folder.UpdatedAt = folder.CreatedAt
+
+	return s.repository.Create(ctx, folder)
+}
+
+// Rename изменение названия папки пользователя
+func (s *Service) Rename(ctx context.Context, id uint64, userID domain.UserID, name string) error {
+	name, err := normalizeName(name)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Rename(ctx, id, userID, name, time.Now())
+}
+
+// Move перемещение папки пользователя в папку parentID; нулевой parentID перемещает папку в корень хранилища
+func (s *Service) Move(ctx context.Context, id uint64, userID domain.UserID, parentID uint64) error {
+	if id == parentID {
+		return ErrFolderCycle
+	}
+
+	return s.repository.Move(ctx, id, userID, parentID, time.Now())
+}
+
+// Delete удаление папки пользователя с переносом ее содержимого в родительскую папку.
+// Возвращает идентификаторы перенесенных секретов.
+func (s *Service) Delete(ctx context.Context, id uint64, userID domain.UserID) ([]uint64, error) {
+	return s.repository.Delete(ctx, id, userID, time.Now())
+}
+
+// MoveSecrets перемещение секретов пользователя в папку folderID.
+// Возвращает идентификаторы перемещенных секретов.
+func (s *Service) MoveSecrets(ctx context.Context, ids []uint64, userID domain.UserID, folderID uint64) ([]uint64, error) {
+	if len(ids) == 0 {
+		return []uint64{}, nil
+	}
+
+	return s.repository.MoveSecrets(ctx, ids, userID, folderID)
+}
+
+// normalizeName удаляет пробелы по краям названия папки и проверяет его. Название, зашифрованное клиентом,
+// проверено клиентом до шифрования, поэтому у него проверяется только длина.
+func normalizeName(name string) (string, error) {
+	if strings.HasPrefix(name, domain.SealedFieldPrefix) {
+		if len(name) > maxSealedNameLength {
+			return "", ErrInvalidName
+		}
+		return name, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength || strings.Contains(name, "/") {
+		return "", ErrInvalidName
+	}
+
+	return name, nil
+}
@@ -0,0 +1,169 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/tests/mocks"
+	"strings"
+	"testing"
+)
+
+func TestFolderService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIFolderRepository(ctrl)
+	service := NewFolderService(mockRepo)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "Create_Success_TrimsName",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, folder *domain.Folder) (*domain.Folder, error) {
+					folder.ID = 4
+					return folder, nil
+				})
+
+				folder, err := service.Create(ctx, &domain.Folder{UserID: 1, ParentID: 3, Name: "  Cards "})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if folder.ID != 4 || folder.Name != "Cards" || folder.CreatedAt.IsZero() {
+					t.Errorf("Unexpected folder: %+v", folder)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Create_Fail_InvalidName",
+			testFunc: func(t *testing.T) {
+				for _, name := range []string{" ", "a/b", strings.Repeat("x", maxNameLength+1)} {
+					_, err := service.Create(ctx, &domain.Folder{UserID: 1, Name: name})
+					if !errors.Is(err, ErrInvalidName) {
+						t.Errorf("Expected error 'ErrInvalidName' for %q, got %v", name, err)
+					}
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Create_Success_WithoutName",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, folder *domain.Folder) (*domain.Folder, error) {
+					folder.ID = 5
+					return folder, nil
+				})
+
+				folder, err := service.Create(ctx, &domain.Folder{UserID: 1})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if folder.ID != 5 || folder.Name != "" {
+					t.Errorf("Unexpected folder: %+v", folder)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Rename_Success_Sealed",
+			testFunc: func(t *testing.T) {
+				// Зашифрованное название сохраняется как есть: оно может содержать "/" и быть длиннее открытого
+				name := domain.SealedFieldPrefix + strings.Repeat("a/", maxNameLength)
+				mockRepo.EXPECT().Rename(ctx, uint64(2), domain.UserID(1), name, gomock.Any()).Return(nil)
+
+				if err := service.Rename(ctx, 2, 1, name); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Rename_Fail_SealedTooLong",
+			testFunc: func(t *testing.T) {
+				name := domain.SealedFieldPrefix + strings.Repeat("a", maxSealedNameLength)
+				if err := service.Rename(ctx, 2, 1, name); !errors.Is(err, ErrInvalidName) {
+					t.Errorf("Expected error 'ErrInvalidName', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Rename_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Rename(ctx, uint64(2), domain.UserID(1), "Work", gomock.Any()).Return(nil)
+
+				if err := service.Rename(ctx, 2, 1, "Work "); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Move_Fail_IntoItself",
+			testFunc: func(t *testing.T) {
+				err := service.Move(ctx, 2, 1, 2)
+				if !errors.Is(err, ErrFolderCycle) {
+					t.Errorf("Expected error 'ErrFolderCycle', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Move_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Move(ctx, uint64(2), domain.UserID(1), uint64(0), gomock.Any()).Return(nil)
+
+				if err := service.Move(ctx, 2, 1, 0); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Delete_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Delete(ctx, uint64(2), domain.UserID(1), gomock.Any()).Return([]uint64{7}, nil)
+
+				moved, err := service.Delete(ctx, 2, 1)
+				if err != nil || len(moved) != 1 {
+					t.Errorf("Expected one moved secret, got %v, %v", moved, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "MoveSecrets_Empty",
+			testFunc: func(t *testing.T) {
+				moved, err := service.MoveSecrets(ctx, nil, 1, 3)
+				if err != nil || len(moved) != 0 {
+					t.Errorf("Expected nothing moved, got %v, %v", moved, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "MoveSecrets_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().MoveSecrets(ctx, []uint64{10, 11}, domain.UserID(1), uint64(3)).Return([]uint64{10, 11}, nil)
+
+				moved, err := service.MoveSecrets(ctx, []uint64{10, 11}, 1, 3)
+				if err != nil || len(moved) != 2 {
+					t.Errorf("Expected two moved secrets, got %v, %v", moved, err)
+				}
+			},
+			expectErr: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
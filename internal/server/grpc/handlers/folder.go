@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/folder"
+	"github.com/romanp1989/gophkeeper/pkg/converter"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type FolderService interface {
+	List(ctx context.Context, userID domain.UserID) ([]*domain.Folder, error)
+	Create(ctx context.Context, folder *domain.Folder) (*domain.Folder, error)
+	Rename(ctx context.Context, id uint64, userID domain.UserID, name string) error
+	Move(ctx context.Context, id uint64, userID domain.UserID, parentID uint64) error
+	Delete(ctx context.Context, id uint64, userID domain.UserID) ([]uint64, error)
+	MoveSecrets(ctx context.Context, ids []uint64, userID domain.UserID, folderID uint64) ([]uint64, error)
+}
+
+type FolderHandler struct {
+	proto.UnimplementedFoldersServer
+	folderService FolderService
+	events        SecretEvents
+	logger        *zap.Logger
+}
+
+// NewFolderHandler создает обработчик папок. Перемещение секретов между папками рассылается
+// подписанным клиентам через events как изменение секретов.
+func NewFolderHandler(folderService FolderService, events SecretEvents, logger *zap.Logger) *FolderHandler {
+	return &FolderHandler{
+		folderService: folderService,
+		events:        events,
+		logger:        logger,
+	}
+}
+
+// ListFolders возвращает все папки пользователя
+func (h *FolderHandler) ListFolders(ctx context.Context, _ *emptypb.Empty) (*proto.ListFoldersResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	folders, err := h.folderService.List(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.ListFoldersResponse{Folders: converter.FoldersToProto(folders)}, nil
+}
+
+// CreateFolder создает папку пользователя. Нулевой parent_id создает папку в корне хранилища.
+func (h *FolderHandler) CreateFolder(ctx context.Context, in *proto.CreateFolderRequest) (*proto.CreateFolderResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	created, err := h.folderService.Create(ctx, &domain.Folder{UserID: userID, ParentID: in.ParentId, Name: in.Name})
+	if err != nil {
+		return nil, folderError(err)
+	}
+
+	return &proto.CreateFolderResponse{Folder: converter.FolderToProto(created)}, nil
+}
+
+// RenameFolder изменяет название папки пользователя
+func (h *FolderHandler) RenameFolder(ctx context.Context, in *proto.RenameFolderRequest) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = h.folderService.Rename(ctx, in.Id, userID, in.Name); err != nil {
+		return nil, folderError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// MoveFolder перемещает папку пользователя в другую папку. Нулевой parent_id перемещает папку в корень хранилища.
+func (h *FolderHandler) MoveFolder(ctx context.Context, in *proto.MoveFolderRequest) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = h.folderService.Move(ctx, in.Id, userID, in.ParentId); err != nil {
+		return nil, folderError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteFolder удаляет папку пользователя; ее содержимое переносится в родительскую папку
+func (h *FolderHandler) DeleteFolder(ctx context.Context, in *proto.DeleteFolderRequest) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	moved, err := h.folderService.Delete(ctx, in.Id, userID)
+	if err != nil {
+		return nil, folderError(err)
+	}
+
+	h.publishMoved(ctx, userID, moved)
+
+	return &emptypb.Empty{}, nil
+}
+
+// MoveSecrets перемещает секреты пользователя в папку и возвращает идентификаторы перемещенных секретов.
+// Нулевой folder_id перемещает секреты в корень хранилища.
+func (h *FolderHandler) MoveSecrets(ctx context.Context, in *proto.MoveSecretsRequest) (*proto.MoveSecretsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	moved, err := h.folderService.MoveSecrets(ctx, in.Ids, userID, in.FolderId)
+	if err != nil {
+		return nil, folderError(err)
+	}
+
+	h.publishMoved(ctx, userID, moved)
+
+	return &proto.MoveSecretsResponse{MovedIds: moved}, nil
+}
+
+// publishMoved рассылает события изменения перемещенных секретов
+func (h *FolderHandler) publishMoved(ctx context.Context, userID domain.UserID, ids []uint64) {
+	for _, id := range ids {
+		h.events.Publish(context.WithoutCancel(ctx), domain.SecretEvent{Type: domain.SecretUpdated, UserID: userID, SecretID: id})
+	}
+}
+
+// folderError преобразует ошибку сервиса папок в ошибку GRPC
+func folderError(err error) error {
+	switch {
+	case errors.Is(err, storageErrors.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, folder.ErrInvalidName), errors.Is(err, folder.ErrFolderCycle):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
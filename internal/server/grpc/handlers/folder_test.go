@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/events"
+	"github.com/romanp1989/gophkeeper/internal/server/folder"
+	"github.com/romanp1989/gophkeeper/pkg/consts"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"github.com/romanp1989/gophkeeper/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestFolderHandler_CreateFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockIFolderService(ctrl)
+	handler := NewFolderHandler(mockService, events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		input     *proto.CreateFolderRequest
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().Create(gomock.Any(), &domain.Folder{UserID: 123, ParentID: 1, Name: "Cards"}).
+					Return(&domain.Folder{ID: 2, UserID: 123, ParentID: 1, Name: "Cards"}, nil).Times(1)
+			},
+			ctx:   userCtx,
+			input: &proto.CreateFolderRequest{ParentId: 1, Name: "Cards"},
+		},
+		{
+			name: "Error_InvalidName",
+			setupMock: func() {
+				mockService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, folder.ErrInvalidName).Times(1)
+			},
+			ctx:       userCtx,
+			input:     &proto.CreateFolderRequest{Name: "a/b"},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid folder name",
+		},
+		{
+			name: "Error_ParentNotFound",
+			setupMock: func() {
+				mockService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			input:     &proto.CreateFolderRequest{ParentId: 9, Name: "Cards"},
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			input:     &proto.CreateFolderRequest{Name: "Cards"},
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.CreateFolder(tc.ctx, tc.input)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(2), resp.GetFolder().GetId())
+				assert.Equal(t, uint64(1), resp.GetFolder().GetParentId())
+			}
+		})
+	}
+}
+
+func TestFolderHandler_MoveSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockIFolderService(ctrl)
+	hub := events.NewHub(&events.Config{BufferSize: 4})
+	handler := NewFolderHandler(mockService, hub, zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	t.Run("Success", func(t *testing.T) {
+		received, unsubscribe := hub.Subscribe(123)
+		defer unsubscribe()
+
+		mockService.EXPECT().MoveSecrets(gomock.Any(), []uint64{1, 2}, domain.UserID(123), uint64(5)).
+			Return([]uint64{1, 2}, nil).Times(1)
+
+		resp, err := handler.MoveSecrets(userCtx, &proto.MoveSecretsRequest{Ids: []uint64{1, 2}, FolderId: 5})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2}, resp.GetMovedIds())
+
+		for _, id := range []uint64{1, 2} {
+			event := <-received
+			assert.Equal(t, domain.SecretUpdated, event.Type)
+			assert.Equal(t, id, event.SecretID)
+		}
+	})
+
+	t.Run("Error_FolderNotFound", func(t *testing.T) {
+		mockService.EXPECT().MoveSecrets(gomock.Any(), []uint64{1}, domain.UserID(123), uint64(9)).
+			Return(nil, storageErrors.ErrNotFound).Times(1)
+
+		_, err := handler.MoveSecrets(userCtx, &proto.MoveSecretsRequest{Ids: []uint64{1}, FolderId: 9})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = not found")
+	})
+}
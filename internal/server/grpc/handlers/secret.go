@@ -4,34 +4,75 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/secret"
+	"github.com/romanp1989/gophkeeper/internal/server/upload"
 	"github.com/romanp1989/gophkeeper/pkg/consts"
 	"github.com/romanp1989/gophkeeper/pkg/converter"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
 )
 
 type SecretService interface {
 	Get(ctx context.Context, secretID uint64, userID domain.UserID) (*domain.Secret, error)
 	GetUserSecrets(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error)
+	List(ctx context.Context, userID domain.UserID, query *domain.SecretQuery) (*domain.SecretPage, error)
+	Search(ctx context.Context, userID domain.UserID, query *domain.SecretQuery) (*domain.SecretPage, error)
 	Add(ctx context.Context, secret *domain.Secret) (*domain.Secret, error)
 	Update(ctx context.Context, secret *domain.Secret) (*domain.Secret, error)
 	Delete(ctx context.Context, secretID uint64, userID domain.UserID) error
+	BatchSave(ctx context.Context, secrets []*domain.Secret, atomic bool) ([]error, error)
+	BatchDelete(ctx context.Context, ids []uint64, userID domain.UserID, atomic bool) ([]error, error)
+	ListTrash(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error)
+	Restore(ctx context.Context, secretID uint64, userID domain.UserID) error
+	Purge(ctx context.Context, secretID uint64, userID domain.UserID) error
+	SaveBlob(ctx context.Context, secret *domain.Secret, payload io.Reader) (int64, error)
+	OpenBlob(ctx context.Context, secretID uint64, userID domain.UserID) (*domain.Secret, io.ReadCloser, error)
+	ListChanges(ctx context.Context, userID domain.UserID, since uint64, metadataOnly bool, limit int) (*domain.SecretChanges, error)
+	ListVersions(ctx context.Context, secretID uint64, userID domain.UserID) ([]*domain.SecretVersion, error)
+	RestoreVersion(ctx context.Context, secretID, versionID uint64, userID domain.UserID) (*domain.Secret, error)
+	GetUsage(ctx context.Context, userID domain.UserID) (*domain.Usage, error)
+	ListPlaintextFields(ctx context.Context, userID domain.UserID) ([]*domain.SecretVersion, error)
+	SealPlaintextFields(ctx context.Context, userID domain.UserID, records []*domain.SecretVersion) (int64, error)
 }
 
+type UploadService interface {
+	Create(ctx context.Context, session *domain.UploadSession) (*domain.UploadSession, error)
+	Get(ctx context.Context, id string, userID domain.UserID) (*domain.UploadSession, error)
+	Append(ctx context.Context, id string, userID domain.UserID, offset int64, chunk []byte) (int64, error)
+	Complete(ctx context.Context, id string, userID domain.UserID) (int64, error)
+}
+
+// SecretEvents рассылает события изменения секретов клиентам, подписанным на изменения.
+type SecretEvents interface {
+	Publish(ctx context.Context, event domain.SecretEvent)
+	Subscribe(userID domain.UserID) (<-chan domain.SecretEvent, func())
+}
+
+// blobChunkSize размер части данных файлового секрета, передаваемой одним сообщением потока
+const blobChunkSize = 64 * 1024
+
+// errBlobSizeMismatch указывает, что объем полученных данных не совпадает с заявленным в заголовке
+var errBlobSizeMismatch = errors.New("blob size mismatch")
+
 type SecretHandler struct {
 	proto.UnimplementedSecretsServer
 	secretService SecretService
+	uploadService UploadService
+	events        SecretEvents
 	logger        *zap.Logger
 }
 
-func NewSecretHandler(secretService SecretService, logger *zap.Logger) *SecretHandler {
+func NewSecretHandler(secretService SecretService, uploadService UploadService, events SecretEvents, logger *zap.Logger) *SecretHandler {
 	return &SecretHandler{
 		secretService: secretService,
+		uploadService: uploadService,
+		events:        events,
 		logger:        logger,
 	}
 }
@@ -70,7 +111,59 @@ func (s *SecretHandler) GetUserSecretList(ctx context.Context, _ *emptypb.Empty)
 	return &proto.GetUserSecretsResponse{Secrets: converter.SecretsToProto(secrets)}, nil
 }
 
-func (s *SecretHandler) SaveUserSecret(ctx context.Context, in *proto.SaveUserSecretRequest) (*emptypb.Empty, error) {
+// ListSecrets возвращает страницу секретов пользователя с учетом фильтров и сортировки запроса.
+// Следующая страница запрашивается с позицией next_cursor из ответа; пустая позиция означает последнюю страницу.
+func (s *SecretHandler) ListSecrets(ctx context.Context, in *proto.ListSecretsRequest) (*proto.ListSecretsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	query, err := converter.ProtoToQuery(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := s.secretService.List(ctx, userID, query)
+	if err != nil {
+		if errors.Is(err, secret.ErrInvalidQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return converter.PageToProto(page), nil
+}
+
+// SearchSecrets возвращает страницу секретов пользователя без данных, у которых есть все токены слепого индекса запроса.
+// Токены вычисляются клиентом из слов заголовков, тегов и ссылок, поэтому сервер не узнает ни слов запроса, ни содержимого секретов.
+func (s *SecretHandler) SearchSecrets(ctx context.Context, in *proto.SearchSecretsRequest) (*proto.ListSecretsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	query, err := converter.ProtoToSearch(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := s.secretService.Search(ctx, userID, query)
+	if err != nil {
+		if errors.Is(err, secret.ErrInvalidQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return converter.PageToProto(page), nil
+}
+
+// SaveUserSecret создает или обновляет секрет пользователя и возвращает его идентификатор и номер редакции.
+// Идентификатор нужен клиенту, чтобы связать с ним шифротекст секрета.
+// Если секрет был изменен после получения клиентом, возвращается codes.Aborted,
+// если сохранение превысит ограничения хранилища пользователя - codes.ResourceExhausted.
+func (s *SecretHandler) SaveUserSecret(ctx context.Context, in *proto.SaveUserSecretRequest) (*proto.SaveUserSecretResponse, error) {
 	userID, err := extractUserID(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -79,17 +172,31 @@ func (s *SecretHandler) SaveUserSecret(ctx context.Context, in *proto.SaveUserSe
 	secretEntity := converter.ProtoToSecret(in.Secret)
 	secretEntity.UserID = userID
 
+	eventType := domain.SecretCreated
 	if secretEntity.ID > 0 {
-		_, err = s.secretService.Update(ctx, secretEntity)
+		eventType = domain.SecretUpdated
+		secretEntity, err = s.secretService.Update(ctx, secretEntity)
 	} else {
-		_, err = s.secretService.Add(ctx, secretEntity)
+		secretEntity, err = s.secretService.Add(ctx, secretEntity)
 	}
 
 	if err != nil {
+		switch {
+		case errors.Is(err, secret.ErrRevisionConflict):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case errors.Is(err, storageErrors.ErrNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, storageErrors.ErrQuotaExceeded):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		case errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrInvalidSearchTokens):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &empty.Empty{}, nil
+	s.publish(ctx, eventType, userID, secretEntity.ID)
+
+	return &proto.SaveUserSecretResponse{Id: secretEntity.ID, Revision: secretEntity.Revision}, nil
 }
 
 func (s *SecretHandler) DeleteUserSecret(ctx context.Context, in *proto.DeleteUserSecretRequest) (*emptypb.Empty, error) {
@@ -101,15 +208,547 @@ func (s *SecretHandler) DeleteUserSecret(ctx context.Context, in *proto.DeleteUs
 
 	err = s.secretService.Delete(ctx, in.Id, userID)
 	if err != nil {
-		if errors.Is(err, fmt.Errorf("secret not found id %d", in.Id)) {
+		if errors.Is(err, storageErrors.ErrNotFound) || errors.Is(err, fmt.Errorf("secret not found id %d", in.Id)) {
 			return nil, status.Error(codes.NotFound, err.Error())
 		}
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	s.publish(ctx, domain.SecretDeleted, userID, in.Id)
+
 	return &emptypb.Empty{}, nil
 }
 
+// BatchSaveSecrets создает и обновляет секреты пользователя одной транзакцией.
+// Результаты возвращаются в порядке секретов запроса; код результата соответствует коду ошибки SaveUserSecret.
+// С флагом atomic ошибка одного секрета отменяет сохранение всех остальных.
+func (s *SecretHandler) BatchSaveSecrets(ctx context.Context, in *proto.BatchSaveSecretsRequest) (*proto.BatchSaveSecretsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secrets := make([]*domain.Secret, 0, len(in.Secrets))
+	created := make([]bool, 0, len(in.Secrets))
+	for _, pbSecret := range in.Secrets {
+		secretEntity := converter.ProtoToSecret(pbSecret)
+		secretEntity.UserID = userID
+
+		secrets = append(secrets, secretEntity)
+		created = append(created, secretEntity.ID == 0)
+	}
+
+	errs, err := s.secretService.BatchSave(ctx, secrets, in.Atomic)
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	results := make([]*proto.BatchItemResult, 0, len(secrets))
+	for i, secretEntity := range secrets {
+		result := batchItemResult(errs[i])
+		if errs[i] == nil {
+			result.Id, result.Revision = secretEntity.ID, secretEntity.Revision
+
+			eventType := domain.SecretUpdated
+			if created[i] {
+				eventType = domain.SecretCreated
+			}
+			s.publish(ctx, eventType, userID, secretEntity.ID)
+		}
+
+		results = append(results, result)
+	}
+
+	return &proto.BatchSaveSecretsResponse{Results: results}, nil
+}
+
+// BatchDeleteSecrets перемещает секреты пользователя в корзину одной транзакцией.
+// Результаты возвращаются в порядке идентификаторов запроса.
+// С флагом atomic ошибка одного секрета отменяет удаление всех остальных.
+func (s *SecretHandler) BatchDeleteSecrets(ctx context.Context, in *proto.BatchDeleteSecretsRequest) (*proto.BatchDeleteSecretsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	errs, err := s.secretService.BatchDelete(ctx, in.Ids, userID, in.Atomic)
+	if err != nil {
+		return nil, batchError(err)
+	}
+
+	results := make([]*proto.BatchItemResult, 0, len(in.Ids))
+	for i, id := range in.Ids {
+		result := batchItemResult(errs[i])
+		result.Id = id
+		if errs[i] == nil {
+			s.publish(ctx, domain.SecretDeleted, userID, id)
+		}
+
+		results = append(results, result)
+	}
+
+	return &proto.BatchDeleteSecretsResponse{Results: results}, nil
+}
+
+// batchError возвращает ошибку gRPC для пакета, который не удалось выполнить целиком
+func batchError(err error) error {
+	switch {
+	case errors.Is(err, secret.ErrBatchTooLarge), errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrInvalidSearchTokens):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, storageErrors.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// batchItemResult возвращает результат элемента пакета с кодом, соответствующим его ошибке
+func batchItemResult(err error) *proto.BatchItemResult {
+	if err == nil {
+		return &proto.BatchItemResult{Code: uint32(codes.OK)}
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, secret.ErrRevisionConflict), errors.Is(err, secret.ErrBatchAborted):
+		code = codes.Aborted
+	case errors.Is(err, storageErrors.ErrNotFound):
+		code = codes.NotFound
+	}
+
+	return &proto.BatchItemResult{Code: uint32(code), Error: err.Error()}
+}
+
+// GetUsage возвращает количество секретов пользователя, суммарный размер их данных и ограничения его хранилища.
+func (s *SecretHandler) GetUsage(ctx context.Context, _ *emptypb.Empty) (*proto.GetUsageResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	usage, err := s.secretService.GetUsage(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return converter.UsageToProto(usage), nil
+}
+
+// ListPlaintextFields возвращает редакции секретов пользователя и секреты в корзине, заголовок, метаданные или теги
+// которых сохранены открытым текстом. Секрет в корзине возвращается как редакция с номером 0.
+func (s *SecretHandler) ListPlaintextFields(ctx context.Context, _ *emptypb.Empty) (*proto.ListPlaintextFieldsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	records, err := s.secretService.ListPlaintextFields(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.ListPlaintextFieldsResponse{Records: converter.VersionsToProto(records)}, nil
+}
+
+// SealPlaintextFields заменяет открытые заголовки и метаданные редакций секретов пользователя и секретов в корзине,
+// которые клиент не может сохранить сам, значениями, зашифрованными клиентом.
+func (s *SecretHandler) SealPlaintextFields(ctx context.Context, in *proto.SealPlaintextFieldsRequest) (*proto.SealPlaintextFieldsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	for _, record := range in.Records {
+		if record.GetSecret() == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "record %d has no fields", record.GetId())
+		}
+	}
+
+	sealed, err := s.secretService.SealPlaintextFields(ctx, userID, converter.ProtoToVersions(in.Records))
+	if err != nil {
+		if errors.Is(err, secret.ErrFieldNotSealed) || errors.Is(err, secret.ErrBatchTooLarge) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.SealPlaintextFieldsResponse{Sealed: uint64(sealed)}, nil
+}
+
+// ListTrash возвращает секреты пользователя из корзины в зашифрованном виде.
+func (s *SecretHandler) ListTrash(ctx context.Context, _ *emptypb.Empty) (*proto.ListTrashResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secrets, err := s.secretService.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.ListTrashResponse{Secrets: converter.SecretsToProto(secrets)}, nil
+}
+
+// ListChanges возвращает секреты пользователя, измененные после изменения с номером since_revision,
+// и удаленные с тех пор секреты, чтобы клиент мог обновить свою копию хранилища без полной загрузки.
+// С флагом metadata_only секреты передаются без данных: клиент загружает их через GetUserSecret, когда они нужны.
+// Изменения передаются страницами по page_size: пока в ответе has_more, клиент запрашивает следующую страницу
+// с номером изменения из ответа.
+func (s *SecretHandler) ListChanges(ctx context.Context, in *proto.ListChangesRequest) (*proto.ListChangesResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	changes, err := s.secretService.ListChanges(ctx, userID, in.SinceRevision, in.MetadataOnly, int(in.PageSize))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return converter.ChangesToProto(changes), nil
+}
+
+// RestoreSecret возвращает секрет из корзины.
+func (s *SecretHandler) RestoreSecret(ctx context.Context, in *proto.RestoreSecretRequest) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = s.secretService.Restore(ctx, in.Id, userID); err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.publish(ctx, domain.SecretCreated, userID, in.Id)
+
+	return &emptypb.Empty{}, nil
+}
+
+// PurgeSecret окончательно удаляет секрет из корзины вместе с его историей.
+func (s *SecretHandler) PurgeSecret(ctx context.Context, in *proto.PurgeSecretRequest) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = s.secretService.Purge(ctx, in.Id, userID); err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.publish(ctx, domain.SecretDeleted, userID, in.Id)
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchSecrets передает потоком события изменения секретов пользователя до отключения клиента.
+// Поток закрывается с codes.Unavailable, если клиент не успевает получать события или сервер потерял часть событий;
+// после переподключения клиент загружает пропущенные изменения через ListChanges.
+func (s *SecretHandler) WatchSecrets(_ *emptypb.Empty, stream proto.Secrets_WatchSecretsServer) error {
+	ctx := stream.Context()
+
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	events, cancel := s.events.Subscribe(userID)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "secret events subscription closed")
+			}
+			if err = stream.Send(converter.EventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// publish рассылает событие изменения секрета подписанным клиентам пользователя
+func (s *SecretHandler) publish(ctx context.Context, eventType domain.SecretEventType, userID domain.UserID, secretID uint64) {
+	// Событие рассылается и после отмены запроса клиентом: изменение к этому моменту уже сохранено
+	s.events.Publish(context.WithoutCancel(ctx), domain.SecretEvent{Type: eventType, UserID: userID, SecretID: secretID})
+}
+
+// ListSecretVersions возвращает сохраненные редакции секрета в зашифрованном виде, начиная с последней.
+func (s *SecretHandler) ListSecretVersions(ctx context.Context, in *proto.ListSecretVersionsRequest) (*proto.ListSecretVersionsResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	versions, err := s.secretService.ListVersions(ctx, in.SecretId, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.ListSecretVersionsResponse{Versions: converter.VersionsToProto(versions)}, nil
+}
+
+// RestoreSecretVersion восстанавливает секрет из сохраненной редакции и возвращает восстановленный секрет.
+func (s *SecretHandler) RestoreSecretVersion(ctx context.Context, in *proto.RestoreSecretVersionRequest) (*proto.RestoreSecretVersionResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secret, err := s.secretService.RestoreVersion(ctx, in.SecretId, in.VersionId, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.publish(ctx, domain.SecretUpdated, userID, secret.ID)
+
+	return &proto.RestoreSecretVersionResponse{Secret: converter.SecretToProto(secret)}, nil
+}
+
+// CreateUploadSession открывает сессию загрузки данных файлового секрета.
+// Данные сессии передаются через UploadBlob и могут быть переданы за несколько вызовов.
+func (s *SecretHandler) CreateUploadSession(ctx context.Context, in *proto.CreateUploadSessionRequest) (*proto.CreateUploadSessionResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	session, err := s.uploadService.Create(ctx, &domain.UploadSession{
+		UserID:   userID,
+		SecretID: in.SecretId,
+		DataKey:  in.DataKey,
+		Size:     int64(in.Size),
+	})
+	if err != nil {
+		return nil, uploadError(err)
+	}
+
+	return &proto.CreateUploadSessionResponse{Session: converter.UploadSessionToProto(session)}, nil
+}
+
+// GetUploadSession возвращает сессию загрузки с количеством уже полученных байт,
+// с которого клиент продолжает загрузку после обрыва соединения.
+func (s *SecretHandler) GetUploadSession(ctx context.Context, in *proto.GetUploadSessionRequest) (*proto.GetUploadSessionResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	session, err := s.uploadService.Get(ctx, in.Id, userID)
+	if err != nil {
+		return nil, uploadError(err)
+	}
+
+	return &proto.GetUploadSessionResponse{Session: converter.UploadSessionToProto(session)}, nil
+}
+
+// UploadBlob принимает потоком зашифрованные данные файлового секрета.
+// Первое сообщение потока содержит заголовок с идентификатором секрета и ключом данных, остальные - части данных.
+// Если первое сообщение указывает сессию загрузки и позицию, данные дописываются в сессию с этой позиции.
+func (s *SecretHandler) UploadBlob(stream proto.Secrets_UploadBlobServer) error {
+	ctx := stream.Context()
+
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	req, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "blob header expected")
+	}
+
+	if resume := req.GetResume(); resume != nil {
+		return s.resumeUpload(stream, userID, resume)
+	}
+
+	header := req.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "blob header expected")
+	}
+
+	secret := &domain.Secret{
+		ID:         header.SecretId,
+		UserID:     userID,
+		SecretType: string(domain.BlobSecret),
+		DataKey:    header.DataKey,
+	}
+
+	size, err := s.secretService.SaveBlob(ctx, secret, &uploadReader{stream: stream, size: header.Size})
+	if err != nil {
+		switch {
+		case errors.Is(err, errBlobSizeMismatch):
+			return status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, storageErrors.ErrNotFound):
+			return status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, storageErrors.ErrQuotaExceeded):
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	s.publish(ctx, domain.SecretUpdated, userID, secret.ID)
+
+	return stream.SendAndClose(&proto.UploadBlobResponse{Size: uint64(size)})
+}
+
+// resumeUpload дописывает части данных потока в сессию загрузки, начиная с позиции resume.Offset.
+// Каждая часть сохраняется сразу, поэтому при обрыве потока полученные данные не теряются.
+// Когда получены все данные сессии, они сохраняются в секрет, и сессия закрывается.
+func (s *SecretHandler) resumeUpload(stream proto.Secrets_UploadBlobServer, userID domain.UserID, resume *proto.UploadResume) error {
+	ctx := stream.Context()
+
+	session, err := s.uploadService.Get(ctx, resume.SessionId, userID)
+	if err != nil {
+		return uploadError(err)
+	}
+
+	offset := int64(resume.Offset)
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		chunk := req.GetChunk()
+		if chunk == nil {
+			return status.Error(codes.InvalidArgument, "blob chunk expected")
+		}
+
+		offset, err = s.uploadService.Append(ctx, session.ID, userID, offset, chunk)
+		if err != nil {
+			return uploadError(err)
+		}
+	}
+
+	if offset < session.Size {
+		return stream.SendAndClose(&proto.UploadBlobResponse{Offset: uint64(offset)})
+	}
+
+	size, err := s.uploadService.Complete(ctx, session.ID, userID)
+	if err != nil {
+		return uploadError(err)
+	}
+
+	s.publish(ctx, domain.SecretUpdated, userID, session.SecretID)
+
+	return stream.SendAndClose(&proto.UploadBlobResponse{Size: uint64(size), Offset: uint64(size), Completed: true})
+}
+
+// uploadError конвертирует ошибку сессии загрузки в статус gRPC
+func uploadError(err error) error {
+	switch {
+	case errors.Is(err, storageErrors.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, upload.ErrInvalidSession):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, storageErrors.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, upload.ErrOffsetMismatch), errors.Is(err, upload.ErrIncomplete), errors.Is(err, secret.ErrBlobStoreDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// DownloadBlob передает потоком зашифрованные данные файлового секрета.
+// Первым сообщением передается заголовок с ключом данных и размером, затем данные частями по blobChunkSize байт.
+func (s *SecretHandler) DownloadBlob(in *proto.DownloadBlobRequest, stream proto.Secrets_DownloadBlobServer) error {
+	ctx := stream.Context()
+
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	secret, payload, err := s.secretService.OpenBlob(ctx, in.SecretId, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return status.Error(codes.NotFound, err.Error())
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer payload.Close()
+
+	err = stream.Send(&proto.DownloadBlobResponse{Data: &proto.DownloadBlobResponse_Header{Header: &proto.BlobHeader{
+		SecretId: secret.ID,
+		DataKey:  secret.DataKey,
+		Size:     uint64(secret.PayloadSize),
+	}}})
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Сообщение может удерживаться транспортом после Send, поэтому буфер для каждой части новый
+		chunk := make([]byte, blobChunkSize)
+		n, err := io.ReadFull(payload, chunk)
+		if n > 0 {
+			if sendErr := stream.Send(&proto.DownloadBlobResponse{Data: &proto.DownloadBlobResponse_Chunk{Chunk: chunk[:n]}}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+}
+
+// uploadReader читает части данных из потока UploadBlob.
+// При завершении потока проверяет, что получено ровно столько байт, сколько заявлено в заголовке.
+type uploadReader struct {
+	stream proto.Secrets_UploadBlobServer
+	size   uint64
+	read   uint64
+	chunk  []byte
+}
+
+// Read возвращает данные очередной части потока
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		req, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			if r.read != r.size {
+				return 0, fmt.Errorf("%w: received %d bytes, expected %d", errBlobSizeMismatch, r.read, r.size)
+			}
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		r.chunk = req.GetChunk()
+		r.read += uint64(len(r.chunk))
+		if r.read > r.size {
+			return 0, fmt.Errorf("%w: received more than %d bytes", errBlobSizeMismatch, r.size)
+		}
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
+
 // extractUserID получает userID из контекста
 func extractUserID(ctx context.Context) (domain.UserID, error) {
 	uid := ctx.Value(consts.UserIDKeyCtx)
@@ -1,19 +1,29 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/events"
+	"github.com/romanp1989/gophkeeper/internal/server/secret"
+	"github.com/romanp1989/gophkeeper/internal/server/upload"
 	"github.com/romanp1989/gophkeeper/pkg/consts"
+	"github.com/romanp1989/gophkeeper/pkg/converter"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
 	"github.com/romanp1989/gophkeeper/tests/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
 	"testing"
+	"time"
 )
 
 func TestSecretHandler_GetUserSecret(t *testing.T) {
@@ -22,7 +32,7 @@ func TestSecretHandler_GetUserSecret(t *testing.T) {
 
 	mockService := mocks.NewMockISecretService(ctrl)
 	logger := zap.NewNop()
-	handler := NewSecretHandler(mockService, logger)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), logger)
 
 	tests := []struct {
 		name      string
@@ -85,7 +95,7 @@ func TestSecretHandler_GetUserSecretList(t *testing.T) {
 
 	mockService := mocks.NewMockISecretService(ctrl)
 	logger := zap.NewNop()
-	handler := NewSecretHandler(mockService, logger)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), logger)
 
 	tests := []struct {
 		name      string
@@ -147,13 +157,14 @@ func TestSecretHandler_SaveUserSecret(t *testing.T) {
 
 	mockService := mocks.NewMockISecretService(ctrl)
 	logger := zap.NewNop()
-	handler := NewSecretHandler(mockService, logger)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), logger)
 
 	tests := []struct {
 		name      string
 		setupMock func()
 		ctx       context.Context
 		input     *proto.SaveUserSecretRequest
+		wantID    uint64
 		expectErr string
 	}{
 		{
@@ -162,7 +173,7 @@ func TestSecretHandler_SaveUserSecret(t *testing.T) {
 				mockService.EXPECT().Add(gomock.Any(), gomock.Any()).Return(&domain.Secret{ID: 1}, nil).Times(1)
 			},
 			ctx: metadata.NewIncomingContext(
-				context.WithValue(context.Background(), consts.UserIDKeyCtx, uint64(123)),
+				context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
 				metadata.New(map[string]string{consts.ClientIDHeader: "456"}),
 			),
 			input: &proto.SaveUserSecretRequest{
@@ -172,6 +183,7 @@ func TestSecretHandler_SaveUserSecret(t *testing.T) {
 					SecretType: proto.SecretType_SECRET_TYPE_TEXT,
 				},
 			},
+			wantID:    1,
 			expectErr: "",
 		},
 		{
@@ -189,7 +201,7 @@ func TestSecretHandler_SaveUserSecret(t *testing.T) {
 				mockService.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, errors.New("create error")).Times(1)
 			},
 			ctx: metadata.NewIncomingContext(
-				context.WithValue(context.Background(), consts.UserIDKeyCtx, uint64(123)),
+				context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
 				metadata.New(map[string]string{consts.ClientIDHeader: "456"}),
 			),
 			input: &proto.SaveUserSecretRequest{
@@ -197,17 +209,60 @@ func TestSecretHandler_SaveUserSecret(t *testing.T) {
 			},
 			expectErr: "rpc error: code = Internal desc = create error",
 		},
+		{
+			name: "Error_RevisionConflict",
+			setupMock: func() {
+				mockService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, secret.ErrRevisionConflict).Times(1)
+			},
+			ctx: metadata.NewIncomingContext(
+				context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
+				metadata.New(nil),
+			),
+			input: &proto.SaveUserSecretRequest{
+				Secret: &proto.Secret{Id: 1, Revision: 1},
+			},
+			expectErr: "rpc error: code = Aborted desc = secret was changed concurrently",
+		},
+		{
+			name: "Error_QuotaExceeded",
+			setupMock: func() {
+				mockService.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, storageErrors.ErrQuotaExceeded).Times(1)
+			},
+			ctx: metadata.NewIncomingContext(
+				context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
+				metadata.New(nil),
+			),
+			input: &proto.SaveUserSecretRequest{
+				Secret: &proto.Secret{},
+			},
+			expectErr: "rpc error: code = ResourceExhausted desc = storage quota exceeded",
+		},
+		{
+			name: "Error_InvalidTag",
+			setupMock: func() {
+				mockService.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, domain.ErrInvalidTag).Times(1)
+			},
+			ctx: metadata.NewIncomingContext(
+				context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
+				metadata.New(nil),
+			),
+			input: &proto.SaveUserSecretRequest{
+				Secret: &proto.Secret{Tags: []string{"two words"}},
+			},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid tag",
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMock()
 
-			_, err := handler.SaveUserSecret(tc.ctx, tc.input)
+			resp, err := handler.SaveUserSecret(tc.ctx, tc.input)
 			if tc.expectErr != "" {
 				assert.EqualError(t, err, tc.expectErr)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tc.wantID, resp.Id)
 			}
 		})
 	}
@@ -219,7 +274,7 @@ func TestSecretHandler_DeleteUserSecret(t *testing.T) {
 
 	mockService := mocks.NewMockISecretService(ctrl)
 	logger := zap.NewNop()
-	handler := NewSecretHandler(mockService, logger)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), logger)
 
 	tests := []struct {
 		name      string
@@ -286,3 +341,1187 @@ func TestSecretHandler_DeleteUserSecret(t *testing.T) {
 		})
 	}
 }
+
+// fakeUploadStream поток UploadBlob, возвращающий заранее заданные сообщения
+type fakeUploadStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	requests []*proto.UploadBlobRequest
+	response *proto.UploadBlobResponse
+}
+
+func (s *fakeUploadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeUploadStream) Recv() (*proto.UploadBlobRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *fakeUploadStream) SendAndClose(resp *proto.UploadBlobResponse) error {
+	s.response = resp
+	return nil
+}
+
+// fakeDownloadStream поток DownloadBlob, сохраняющий отправленные сообщения
+type fakeDownloadStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*proto.DownloadBlobResponse
+}
+
+func (s *fakeDownloadStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeDownloadStream) Send(resp *proto.DownloadBlobResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+// fakeWatchStream поток WatchSecrets, сохраняющий отправленные события и отменяющий контекст после первого из них
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel context.CancelFunc
+	events []*proto.SecretEvent
+}
+
+func (s *fakeWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeWatchStream) Send(event *proto.SecretEvent) error {
+	s.events = append(s.events, event)
+	s.cancel()
+	return nil
+}
+
+func uploadHeader(size uint64) *proto.UploadBlobRequest {
+	return &proto.UploadBlobRequest{Data: &proto.UploadBlobRequest_Header{Header: &proto.BlobHeader{SecretId: 1, DataKey: []byte("key"), Size: size}}}
+}
+
+func uploadChunk(chunk string) *proto.UploadBlobRequest {
+	return &proto.UploadBlobRequest{Data: &proto.UploadBlobRequest_Chunk{Chunk: []byte(chunk)}}
+}
+
+func TestSecretHandler_UploadBlob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	// saveBlob читает поток так же, как сервис, и возвращает ошибку чтения
+	saveBlob := func(_ context.Context, secret *domain.Secret, payload io.Reader) (int64, error) {
+		data, err := io.ReadAll(payload)
+		return int64(len(data)), err
+	}
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		requests  []*proto.UploadBlobRequest
+		wantSize  uint64
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().SaveBlob(gomock.Any(), &domain.Secret{
+					ID:         1,
+					UserID:     123,
+					SecretType: string(domain.BlobSecret),
+					DataKey:    []byte("key"),
+				}, gomock.Any()).DoAndReturn(saveBlob).Times(1)
+			},
+			ctx:      userCtx,
+			requests: []*proto.UploadBlobRequest{uploadHeader(10), uploadChunk("hello"), uploadChunk("world")},
+			wantSize: 10,
+		},
+		{
+			name:      "Error_MissingHeader",
+			setupMock: func() {},
+			ctx:       userCtx,
+			requests:  []*proto.UploadBlobRequest{uploadChunk("hello")},
+			expectErr: "rpc error: code = InvalidArgument desc = blob header expected",
+		},
+		{
+			name:      "Error_EmptyStream",
+			setupMock: func() {},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = InvalidArgument desc = blob header expected",
+		},
+		{
+			name: "Error_Truncated",
+			setupMock: func() {
+				mockService.EXPECT().SaveBlob(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(saveBlob).Times(1)
+			},
+			ctx:       userCtx,
+			requests:  []*proto.UploadBlobRequest{uploadHeader(10), uploadChunk("hello")},
+			expectErr: "rpc error: code = InvalidArgument desc = blob size mismatch: received 5 bytes, expected 10",
+		},
+		{
+			name: "Error_TooLong",
+			setupMock: func() {
+				mockService.EXPECT().SaveBlob(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(saveBlob).Times(1)
+			},
+			ctx:       userCtx,
+			requests:  []*proto.UploadBlobRequest{uploadHeader(4), uploadChunk("hello")},
+			expectErr: "rpc error: code = InvalidArgument desc = blob size mismatch: received more than 4 bytes",
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockService.EXPECT().SaveBlob(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			requests:  []*proto.UploadBlobRequest{uploadHeader(0)},
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			requests:  []*proto.UploadBlobRequest{uploadHeader(0)},
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			stream := &fakeUploadStream{ctx: tc.ctx, requests: tc.requests}
+			err := handler.UploadBlob(stream)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantSize, stream.response.GetSize())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_DownloadBlob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	payload := bytes.Repeat([]byte("x"), 2*blobChunkSize+1)
+
+	tests := []struct {
+		name       string
+		setupMock  func()
+		ctx        context.Context
+		wantChunks int
+		expectErr  string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().OpenBlob(gomock.Any(), uint64(1), domain.UserID(123)).
+					Return(&domain.Secret{ID: 1, DataKey: []byte("key"), PayloadSize: int64(len(payload))}, io.NopCloser(bytes.NewReader(payload)), nil).Times(1)
+			},
+			ctx:        userCtx,
+			wantChunks: 3,
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockService.EXPECT().OpenBlob(gomock.Any(), uint64(1), domain.UserID(123)).Return(nil, nil, storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			stream := &fakeDownloadStream{ctx: tc.ctx}
+			err := handler.DownloadBlob(&proto.DownloadBlobRequest{SecretId: 1}, stream)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			if assert.Len(t, stream.responses, tc.wantChunks+1) {
+				header := stream.responses[0].GetHeader()
+				assert.Equal(t, []byte("key"), header.GetDataKey())
+				assert.Equal(t, uint64(len(payload)), header.GetSize())
+
+				var received []byte
+				for _, resp := range stream.responses[1:] {
+					received = append(received, resp.GetChunk()...)
+				}
+				assert.Equal(t, payload, received)
+			}
+		})
+	}
+}
+
+func TestSecretHandler_CreateUploadSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpload := mocks.NewMockIUploadService(ctrl)
+	handler := NewSecretHandler(mocks.NewMockISecretService(ctrl), mockUpload, events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockUpload.EXPECT().Create(gomock.Any(), &domain.UploadSession{UserID: 123, SecretID: 1, DataKey: []byte("key"), Size: 10}).
+					Return(&domain.UploadSession{ID: "session", SecretID: 1, Size: 10}, nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockUpload.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name: "Error_InvalidSession",
+			setupMock: func() {
+				mockUpload.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, upload.ErrInvalidSession).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = InvalidArgument desc = invalid upload session",
+		},
+		{
+			name: "Error_BlobStoreDisabled",
+			setupMock: func() {
+				mockUpload.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, secret.ErrBlobStoreDisabled).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = FailedPrecondition desc = blob store is not configured",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.CreateUploadSession(tc.ctx, &proto.CreateUploadSessionRequest{SecretId: 1, DataKey: []byte("key"), Size: 10})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "session", resp.Session.GetId())
+				assert.Equal(t, uint64(10), resp.Session.GetSize())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_GetUploadSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpload := mocks.NewMockIUploadService(ctrl)
+	handler := NewSecretHandler(mocks.NewMockISecretService(ctrl), mockUpload, events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name       string
+		setupMock  func()
+		wantOffset uint64
+		expectErr  string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).
+					Return(&domain.UploadSession{ID: "session", Size: 10, Received: 4}, nil).Times(1)
+			},
+			wantOffset: 4,
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).Return(nil, storageErrors.ErrNotFound).Times(1)
+			},
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.GetUploadSession(userCtx, &proto.GetUploadSessionRequest{Id: "session"})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantOffset, resp.Session.GetOffset())
+			}
+		})
+	}
+}
+
+func uploadResume(offset uint64) *proto.UploadBlobRequest {
+	return &proto.UploadBlobRequest{Data: &proto.UploadBlobRequest_Resume{Resume: &proto.UploadResume{SessionId: "session", Offset: offset}}}
+}
+
+func TestSecretHandler_UploadBlob_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUpload := mocks.NewMockIUploadService(ctrl)
+	handler := NewSecretHandler(mocks.NewMockISecretService(ctrl), mockUpload, events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+	session := &domain.UploadSession{ID: "session", Size: 10, Received: 5}
+
+	tests := []struct {
+		name         string
+		setupMock    func()
+		requests     []*proto.UploadBlobRequest
+		wantResponse *proto.UploadBlobResponse
+		expectErr    string
+	}{
+		{
+			name: "Success_Completed",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).Return(session, nil).Times(1)
+				mockUpload.EXPECT().Append(gomock.Any(), "session", domain.UserID(123), int64(5), []byte("world")).Return(int64(10), nil).Times(1)
+				mockUpload.EXPECT().Complete(gomock.Any(), "session", domain.UserID(123)).Return(int64(10), nil).Times(1)
+			},
+			requests:     []*proto.UploadBlobRequest{uploadResume(5), uploadChunk("world")},
+			wantResponse: &proto.UploadBlobResponse{Size: 10, Offset: 10, Completed: true},
+		},
+		{
+			name: "Success_Partial",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).Return(session, nil).Times(1)
+				mockUpload.EXPECT().Append(gomock.Any(), "session", domain.UserID(123), int64(5), []byte("wo")).Return(int64(7), nil).Times(1)
+			},
+			requests:     []*proto.UploadBlobRequest{uploadResume(5), uploadChunk("wo")},
+			wantResponse: &proto.UploadBlobResponse{Offset: 7},
+		},
+		{
+			name: "Error_OffsetMismatch",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).Return(session, nil).Times(1)
+				mockUpload.EXPECT().Append(gomock.Any(), "session", domain.UserID(123), int64(3), []byte("world")).Return(int64(0), upload.ErrOffsetMismatch).Times(1)
+			},
+			requests:  []*proto.UploadBlobRequest{uploadResume(3), uploadChunk("world")},
+			expectErr: "rpc error: code = FailedPrecondition desc = upload offset mismatch",
+		},
+		{
+			name: "Error_SessionNotFound",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).Return(nil, storageErrors.ErrNotFound).Times(1)
+			},
+			requests:  []*proto.UploadBlobRequest{uploadResume(5)},
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name: "Error_UnexpectedHeader",
+			setupMock: func() {
+				mockUpload.EXPECT().Get(gomock.Any(), "session", domain.UserID(123)).Return(session, nil).Times(1)
+			},
+			requests:  []*proto.UploadBlobRequest{uploadResume(5), uploadHeader(10)},
+			expectErr: "rpc error: code = InvalidArgument desc = blob chunk expected",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			stream := &fakeUploadStream{ctx: userCtx, requests: tc.requests}
+			err := handler.UploadBlob(stream)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantResponse.GetSize(), stream.response.GetSize())
+				assert.Equal(t, tc.wantResponse.GetOffset(), stream.response.GetOffset())
+				assert.Equal(t, tc.wantResponse.GetCompleted(), stream.response.GetCompleted())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_ListSecretVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().ListVersions(gomock.Any(), uint64(1), domain.UserID(123)).
+					Return([]*domain.SecretVersion{{ID: 2, Secret: &domain.Secret{ID: 1, Title: "Old", SecretType: string(domain.TextSecret)}}}, nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_Internal",
+			setupMock: func() {
+				mockService.EXPECT().ListVersions(gomock.Any(), uint64(1), domain.UserID(123)).Return(nil, errors.New("database error")).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockService.EXPECT().ListVersions(gomock.Any(), uint64(1), domain.UserID(123)).Return(nil, storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = NotFound desc = " + storageErrors.ErrNotFound.Error(),
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.ListSecretVersions(tc.ctx, &proto.ListSecretVersionsRequest{SecretId: 1})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.Versions, 1)
+				assert.Equal(t, uint64(2), resp.Versions[0].GetId())
+				assert.Equal(t, "Old", resp.Versions[0].GetSecret().GetTitle())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_RestoreSecretVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().RestoreVersion(gomock.Any(), uint64(1), uint64(2), domain.UserID(123)).
+					Return(&domain.Secret{ID: 1, Title: "Old", SecretType: string(domain.TextSecret)}, nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockService.EXPECT().RestoreVersion(gomock.Any(), uint64(1), uint64(2), domain.UserID(123)).Return(nil, storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.RestoreSecretVersion(tc.ctx, &proto.RestoreSecretVersionRequest{SecretId: 1, VersionId: 2})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Old", resp.Secret.GetTitle())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_ListTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().ListTrash(gomock.Any(), domain.UserID(123)).
+					Return([]*domain.Secret{{ID: 1, Title: "Deleted", SecretType: string(domain.TextSecret), DeletedAt: deletedAt}}, nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().ListTrash(gomock.Any(), domain.UserID(123)).Return(nil, errors.New("database error")).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.ListTrash(tc.ctx, &emptypb.Empty{})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.Secrets, 1)
+				assert.Equal(t, deletedAt, resp.Secrets[0].GetDeletedAt().AsTime())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_GetUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().GetUsage(gomock.Any(), domain.UserID(123)).
+					Return(&domain.Usage{Secrets: 2, Bytes: 1024, Quota: domain.Quota{MaxSecrets: 10}}, nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().GetUsage(gomock.Any(), domain.UserID(123)).Return(nil, errors.New("database error")).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.GetUsage(tc.ctx, &emptypb.Empty{})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(2), resp.GetSecrets())
+				assert.Equal(t, uint64(1024), resp.GetBytes())
+				assert.Equal(t, uint64(10), resp.GetQuota().GetMaxSecrets())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_ListPlaintextFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				records := []*domain.SecretVersion{
+					{ID: 0, Secret: &domain.Secret{ID: 1, Title: "trashed", Tags: []string{"work"}}},
+					{ID: 7, Secret: &domain.Secret{ID: 2, Title: "versioned"}},
+				}
+				mockService.EXPECT().ListPlaintextFields(gomock.Any(), domain.UserID(123)).Return(records, nil).Times(1)
+			},
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().ListPlaintextFields(gomock.Any(), domain.UserID(123)).Return(nil, errors.New("database error")).Times(1)
+			},
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.ListPlaintextFields(userCtx, &emptypb.Empty{})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.GetRecords(), 2)
+				assert.Equal(t, []string{"work"}, resp.GetRecords()[0].GetSecret().GetTags())
+				assert.Equal(t, uint64(7), resp.GetRecords()[1].GetId())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_SealPlaintextFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+	sealed := &proto.SecretVersion{Id: 7, Secret: &proto.Secret{Id: 2, Title: domain.SealedFieldPrefix + "dGl0bGU", Metadata: domain.SealedFieldPrefix + "bWV0YQ"}}
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		request   *proto.SealPlaintextFieldsRequest
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().SealPlaintextFields(gomock.Any(), domain.UserID(123), gomock.Len(1)).Return(int64(1), nil).Times(1)
+			},
+			request: &proto.SealPlaintextFieldsRequest{Records: []*proto.SecretVersion{sealed}},
+		},
+		{
+			name:      "Error_NoFields",
+			setupMock: func() {},
+			request:   &proto.SealPlaintextFieldsRequest{Records: []*proto.SecretVersion{{Id: 7}}},
+			expectErr: "rpc error: code = InvalidArgument desc = record 7 has no fields",
+		},
+		{
+			name: "Error_NotSealed",
+			setupMock: func() {
+				mockService.EXPECT().SealPlaintextFields(gomock.Any(), domain.UserID(123), gomock.Len(1)).
+					Return(int64(0), fmt.Errorf("%w: record 7 of secret 2", secret.ErrFieldNotSealed)).Times(1)
+			},
+			request:   &proto.SealPlaintextFieldsRequest{Records: []*proto.SecretVersion{sealed}},
+			expectErr: "rpc error: code = InvalidArgument desc = field is not sealed: record 7 of secret 2",
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().SealPlaintextFields(gomock.Any(), domain.UserID(123), gomock.Len(1)).
+					Return(int64(0), errors.New("database error")).Times(1)
+			},
+			request:   &proto.SealPlaintextFieldsRequest{Records: []*proto.SecretVersion{sealed}},
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.SealPlaintextFields(userCtx, tc.request)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(1), resp.GetSealed())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_ListChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().ListChanges(gomock.Any(), domain.UserID(123), uint64(5), true, 50).
+					Return(&domain.SecretChanges{
+						Secrets:    []*domain.Secret{{ID: 1, Title: "Changed", SecretType: string(domain.TextSecret), Revision: 3}},
+						Tombstones: []*domain.SecretTombstone{{ID: 2, Revision: 7, DeletedAt: deletedAt}},
+						Revision:   7,
+						More:       true,
+					}, nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().ListChanges(gomock.Any(), domain.UserID(123), uint64(5), true, 50).Return(nil, errors.New("database error")).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.ListChanges(tc.ctx, &proto.ListChangesRequest{SinceRevision: 5, MetadataOnly: true, PageSize: 50})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(7), resp.Revision)
+				assert.True(t, resp.HasMore)
+				assert.Len(t, resp.Secrets, 1)
+				assert.Len(t, resp.Tombstones, 1)
+				assert.Equal(t, uint64(2), resp.Tombstones[0].Id)
+				assert.Equal(t, deletedAt, resp.Tombstones[0].GetDeletedAt().AsTime())
+			}
+		})
+	}
+}
+
+func TestSecretHandler_RestoreSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().Restore(gomock.Any(), uint64(1), domain.UserID(123)).Return(nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockService.EXPECT().Restore(gomock.Any(), uint64(1), domain.UserID(123)).Return(storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			_, err := handler.RestoreSecret(tc.ctx, &proto.RestoreSecretRequest{Id: 1})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretHandler_PurgeSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().Purge(gomock.Any(), uint64(1), domain.UserID(123)).Return(nil).Times(1)
+			},
+			ctx: userCtx,
+		},
+		{
+			name: "Error_NotFound",
+			setupMock: func() {
+				mockService.EXPECT().Purge(gomock.Any(), uint64(1), domain.UserID(123)).Return(storageErrors.ErrNotFound).Times(1)
+			},
+			ctx:       userCtx,
+			expectErr: "rpc error: code = NotFound desc = not found",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			_, err := handler.PurgeSecret(tc.ctx, &proto.PurgeSecretRequest{Id: 1})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretHandler_WatchSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	hub := events.NewHub(&events.Config{BufferSize: 1})
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), hub, zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		publish    func()
+		wantEvents int
+		expectErr  string
+	}{
+		{
+			name: "Success_SaveUserSecret",
+			ctx:  userCtx,
+			publish: func() {
+				mockService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&domain.Secret{ID: 1, Revision: 2}, nil).Times(1)
+				_, err := handler.SaveUserSecret(userCtx, &proto.SaveUserSecretRequest{Secret: &proto.Secret{Id: 1, Revision: 1}})
+				assert.NoError(t, err)
+			},
+			wantEvents: 1,
+		},
+		{
+			name: "Error_SubscriptionClosed",
+			ctx:  userCtx,
+			publish: func() {
+				hub.Reset()
+			},
+			expectErr: "rpc error: code = Unavailable desc = secret events subscription closed",
+		},
+		{
+			name:      "Error_MissingUserID",
+			ctx:       context.Background(),
+			publish:   func() {},
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(tc.ctx)
+			defer cancel()
+			stream := &fakeWatchStream{ctx: ctx, cancel: cancel}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- handler.WatchSecrets(&emptypb.Empty{}, stream)
+			}()
+
+			// Событие публикуется после подписки обработчика
+			time.Sleep(10 * time.Millisecond)
+			tc.publish()
+
+			err := <-done
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, stream.events, tc.wantEvents)
+			assert.Equal(t, proto.SecretEventType_SECRET_EVENT_TYPE_UPDATED, stream.events[0].Type)
+			assert.Equal(t, uint64(1), stream.events[0].SecretId)
+		})
+	}
+}
+
+func TestSecretHandler_ListSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+	next := &domain.SecretCursor{Sort: domain.SortByCreatedAt, ID: 1}
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		request   *proto.ListSecretsRequest
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().List(gomock.Any(), domain.UserID(123), &domain.SecretQuery{
+					Types:        []domain.SecretType{domain.CardSecret},
+					Sort:         domain.SortByCreatedAt,
+					Limit:        1,
+					MetadataOnly: true,
+				}).Return(&domain.SecretPage{Secrets: []*domain.Secret{{ID: 1, PayloadSize: 10}}, Next: next}, nil).Times(1)
+			},
+			request: &proto.ListSecretsRequest{
+				PageSize:     1,
+				Types:        []proto.SecretType{proto.SecretType_SECRET_TYPE_CARD},
+				Sort:         proto.SecretSortKey_SECRET_SORT_KEY_CREATED_AT,
+				MetadataOnly: true,
+			},
+		},
+		{
+			name:      "Error_InvalidCursor",
+			setupMock: func() {},
+			request:   &proto.ListSecretsRequest{Cursor: "not a cursor"},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid cursor: illegal base64 data at input byte 3",
+		},
+		{
+			name: "Error_InvalidQuery",
+			setupMock: func() {
+				mockService.EXPECT().List(gomock.Any(), domain.UserID(123), gomock.Any()).Return(nil, secret.ErrInvalidQuery).Times(1)
+			},
+			request:   &proto.ListSecretsRequest{},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid secret query",
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().List(gomock.Any(), domain.UserID(123), gomock.Any()).Return(nil, errors.New("database error")).Times(1)
+			},
+			request:   &proto.ListSecretsRequest{},
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.ListSecrets(userCtx, tc.request)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.Secrets, 1)
+				assert.Equal(t, int64(10), resp.Secrets[0].PayloadSize)
+				assert.Equal(t, converter.EncodeCursor(next), resp.NextCursor)
+			}
+		})
+	}
+}
+
+func TestSecretHandler_SearchSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), events.NewHub(&events.Config{BufferSize: 1}), zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+	tokens := [][]byte{make([]byte, domain.SearchTokenSize)}
+	next := &domain.SecretCursor{Sort: domain.SortByUpdatedAt, Descending: true, ID: 1}
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		request   *proto.SearchSecretsRequest
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().Search(gomock.Any(), domain.UserID(123), &domain.SecretQuery{SearchTokens: tokens, Limit: 1}).
+					Return(&domain.SecretPage{Secrets: []*domain.Secret{{ID: 1}}, Next: next}, nil).Times(1)
+			},
+			request: &proto.SearchSecretsRequest{Tokens: tokens, PageSize: 1},
+		},
+		{
+			name:      "Error_InvalidCursor",
+			setupMock: func() {},
+			request:   &proto.SearchSecretsRequest{Tokens: tokens, Cursor: "not a cursor"},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid cursor: illegal base64 data at input byte 3",
+		},
+		{
+			name: "Error_InvalidQuery",
+			setupMock: func() {
+				mockService.EXPECT().Search(gomock.Any(), domain.UserID(123), gomock.Any()).Return(nil, secret.ErrInvalidQuery).Times(1)
+			},
+			request:   &proto.SearchSecretsRequest{},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid secret query",
+		},
+		{
+			name: "Error_Service",
+			setupMock: func() {
+				mockService.EXPECT().Search(gomock.Any(), domain.UserID(123), gomock.Any()).Return(nil, errors.New("database error")).Times(1)
+			},
+			request:   &proto.SearchSecretsRequest{Tokens: tokens},
+			expectErr: "rpc error: code = Internal desc = database error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.SearchSecrets(userCtx, tc.request)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.Secrets, 1)
+				assert.Equal(t, converter.EncodeCursor(next), resp.NextCursor)
+			}
+		})
+	}
+}
+
+func TestSecretHandler_Batch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockISecretService(ctrl)
+	hub := events.NewHub(&events.Config{BufferSize: 4})
+	handler := NewSecretHandler(mockService, mocks.NewMockIUploadService(ctrl), hub, zap.NewNop())
+	userCtx := context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123))
+
+	t.Run("BatchSave_Success", func(t *testing.T) {
+		subscription, cancel := hub.Subscribe(123)
+		defer cancel()
+
+		mockService.EXPECT().BatchSave(gomock.Any(), gomock.Any(), true).
+			DoAndReturn(func(_ context.Context, secrets []*domain.Secret, _ bool) ([]error, error) {
+				assert.Equal(t, domain.UserID(123), secrets[0].UserID)
+				secrets[0].ID, secrets[0].Revision = 10, 1
+				return []error{nil, secret.ErrBatchAborted}, nil
+			}).Times(1)
+
+		resp, err := handler.BatchSaveSecrets(userCtx, &proto.BatchSaveSecretsRequest{
+			Secrets: []*proto.Secret{{Title: "New"}, {Id: 2, Title: "Other"}},
+			Atomic:  true,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Results, 2)
+		assert.Equal(t, uint64(10), resp.Results[0].Id)
+		assert.Equal(t, uint32(codes.OK), resp.Results[0].Code)
+		assert.Equal(t, uint32(codes.Aborted), resp.Results[1].Code)
+		assert.Equal(t, domain.SecretEvent{Type: domain.SecretCreated, UserID: 123, SecretID: 10}, <-subscription)
+	})
+
+	t.Run("BatchSave_Fail_TooLarge", func(t *testing.T) {
+		mockService.EXPECT().BatchSave(gomock.Any(), gomock.Any(), false).Return(nil, secret.ErrBatchTooLarge).Times(1)
+
+		_, err := handler.BatchSaveSecrets(userCtx, &proto.BatchSaveSecretsRequest{})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = batch is too large")
+	})
+
+	t.Run("BatchDelete_Success", func(t *testing.T) {
+		mockService.EXPECT().BatchDelete(gomock.Any(), []uint64{1, 2}, domain.UserID(123), false).
+			Return([]error{nil, storageErrors.ErrNotFound}, nil).Times(1)
+
+		resp, err := handler.BatchDeleteSecrets(userCtx, &proto.BatchDeleteSecretsRequest{Ids: []uint64{1, 2}})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Results, 2)
+		assert.Equal(t, uint64(2), resp.Results[1].Id)
+		assert.Equal(t, uint32(codes.NotFound), resp.Results[1].Code)
+	})
+
+	t.Run("BatchDelete_Fail_Service", func(t *testing.T) {
+		mockService.EXPECT().BatchDelete(gomock.Any(), gomock.Any(), domain.UserID(123), true).Return(nil, errors.New("database error")).Times(1)
+
+		_, err := handler.BatchDeleteSecrets(userCtx, &proto.BatchDeleteSecretsRequest{Ids: []uint64{1}, Atomic: true})
+		assert.EqualError(t, err, "rpc error: code = Internal desc = database error")
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/romanp1989/gophkeeper/internal/server/token"
+	"github.com/romanp1989/gophkeeper/internal/server/user"
+	"github.com/romanp1989/gophkeeper/pkg/converter"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -13,29 +16,48 @@ import (
 )
 
 type UserService interface {
-	RegisterUser(ctx context.Context, login string, password string) (*domain.User, error)
+	PreLogin(ctx context.Context, login string) (domain.KDFParams, domain.AuthScheme, error)
+	RegisterUser(ctx context.Context, login string, password string, kdf domain.KDFParams, vaultKey []byte) (*domain.User, error)
 	LoginUser(ctx context.Context, login string, password string) (*domain.User, error)
+	ChangePassword(ctx context.Context, userID domain.UserID, oldPassword, newPassword string, kdf domain.KDFParams, vaultKey []byte, secrets []*domain.Secret) (*domain.User, error)
 }
 
 type UserHandler struct {
 	proto.UnimplementedUsersServer
 	userService  UserService
-	tokenService token.Service
+	tokenService *token.Service
 	logger       *zap.Logger
 }
 
-func NewUserHandler(userService UserService, logger *zap.Logger) *UserHandler {
+func NewUserHandler(userService UserService, tokenService *token.Service, logger *zap.Logger) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		logger:      logger,
+		userService:  userService,
+		tokenService: tokenService,
+		logger:       logger,
 	}
 }
 
+// PreLogin возвращает параметры KDF и способ аутентификации, по которым клиент формирует хеш аутентификации
+func (h *UserHandler) PreLogin(ctx context.Context, req *proto.PreLoginRequest) (*proto.PreLoginResponse, error) {
+	kdf, scheme, err := h.userService.PreLogin(ctx, req.Login)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.PreLoginResponse{
+		Kdf:        converter.KDFToProto(kdf),
+		AuthScheme: string(scheme),
+	}, nil
+}
+
 func (h *UserHandler) Register(ctx context.Context, req *proto.RegisterRequest) (*proto.RegisterResponse, error) {
 	var tokenAuth string
 
-	userEntity, err := h.userService.RegisterUser(ctx, req.Login, req.Password)
+	userEntity, err := h.userService.RegisterUser(ctx, req.Login, req.Password, converter.ProtoToKDF(req.Kdf), req.VaultKey)
 	if err != nil {
+		if errors.Is(err, user.ErrInvalidPassword) || errors.Is(err, user.ErrInvalidKDF) || errors.Is(err, user.ErrInvalidVaultKey) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		if errors.Is(err, fmt.Errorf("user already exists %s", req.Login)) {
 			return nil, status.Error(codes.AlreadyExists, fmt.Sprintf("user already exists %s", req.Login))
 		}
@@ -46,7 +68,12 @@ func (h *UserHandler) Register(ctx context.Context, req *proto.RegisterRequest)
 	if err != nil {
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed auth: %s", err.Error()))
 	}
-	return &proto.RegisterResponse{AccessToken: tokenAuth}, nil
+	return &proto.RegisterResponse{
+		AccessToken: tokenAuth,
+		Kdf:         converter.KDFToProto(userEntity.KDF),
+		VaultKey:    userEntity.VaultKey,
+		UserId:      uint64(userEntity.ID),
+	}, nil
 }
 
 func (h *UserHandler) Login(ctx context.Context, req *proto.LoginRequest) (*proto.LoginResponse, error) {
@@ -59,5 +86,51 @@ func (h *UserHandler) Login(ctx context.Context, req *proto.LoginRequest) (*prot
 	if err != nil {
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed auth: %s", err.Error()))
 	}
-	return &proto.LoginResponse{AccessToken: tokenAuth}, nil
+	return &proto.LoginResponse{
+		AccessToken: tokenAuth,
+		Kdf:         converter.KDFToProto(userEntity.KDF),
+		VaultKey:    userEntity.VaultKey,
+		UserId:      uint64(userEntity.ID),
+	}, nil
+}
+
+func (h *UserHandler) ChangePassword(ctx context.Context, req *proto.ChangePasswordRequest) (*proto.ChangePasswordResponse, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secrets := reencryptedSecrets(userID, req.Secrets)
+
+	userEntity, err := h.userService.ChangePassword(ctx, userID, req.OldPassword, req.NewPassword,
+		converter.ProtoToKDF(req.Kdf), req.VaultKey, secrets)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrBadCredentials):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, user.ErrInvalidPassword), errors.Is(err, user.ErrInvalidKDF), errors.Is(err, user.ErrInvalidVaultKey):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, user.ErrIncompleteReencryption):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, user.ErrConcurrentUpdate):
+			return nil, status.Error(codes.Aborted, err.Error())
+		case errors.Is(err, storageErrors.ErrNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.ChangePasswordResponse{
+		Kdf:      converter.KDFToProto(userEntity.KDF),
+		VaultKey: userEntity.VaultKey,
+	}, nil
+}
+
+// reencryptedSecrets конвертирует перешифрованные клиентом секреты в объекты модели данных
+func reencryptedSecrets(userID domain.UserID, pbSecrets []*proto.ReencryptedSecret) []*domain.Secret {
+	secrets := make([]*domain.Secret, 0, len(pbSecrets))
+	for _, s := range pbSecrets {
+		secrets = append(secrets, &domain.Secret{ID: s.Id, UserID: userID, Payload: s.Payload, DataKey: s.DataKey})
+	}
+	return secrets
 }
@@ -3,22 +3,84 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/token"
+	"github.com/romanp1989/gophkeeper/internal/server/user"
+	"github.com/romanp1989/gophkeeper/pkg/consts"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
 	"github.com/romanp1989/gophkeeper/tests/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"testing"
+	"time"
 )
 
+func newTokenService() *token.Service {
+	return token.NewJwtService(&token.Config{Secret: "secret", Name: "Authorization", Expire: time.Hour})
+}
+
+func TestUserHandler_PreLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockIUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockService, newTokenService(), logger)
+
+	kdf := domain.KDFParams{Algorithm: string(domain.KDFArgon2id), Salt: []byte("0123456789abcdef"), Memory: 65536, Iterations: 3, Parallelism: 4}
+
+	tests := []struct {
+		name       string
+		setupMock  func()
+		input      *proto.PreLoginRequest
+		wantScheme string
+		expectErr  string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().PreLogin(gomock.Any(), "valid_user").Return(kdf, domain.AuthSchemeDerived, nil).Times(1)
+			},
+			input:      &proto.PreLoginRequest{Login: "valid_user"},
+			wantScheme: string(domain.AuthSchemeDerived),
+			expectErr:  "",
+		},
+		{
+			name: "Internal_Error",
+			setupMock: func() {
+				mockService.EXPECT().PreLogin(gomock.Any(), "valid_user").Return(domain.KDFParams{}, domain.AuthSchemePassword, errors.New("internal error")).Times(1)
+			},
+			input:     &proto.PreLoginRequest{Login: "valid_user"},
+			expectErr: "rpc error: code = Internal desc = internal error",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			resp, err := handler.PreLogin(context.Background(), tc.input)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantScheme, resp.AuthScheme)
+			assert.Equal(t, kdf.Salt, resp.Kdf.Salt)
+		})
+	}
+}
+
 func TestUserHandler_Register(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockService := mocks.NewMockIUserService(ctrl)
 	logger := zap.NewNop()
-	handler := NewUserHandler(mockService, logger)
+	handler := NewUserHandler(mockService, newTokenService(), logger)
 
 	tests := []struct {
 		name      string
@@ -29,7 +91,7 @@ func TestUserHandler_Register(t *testing.T) {
 		{
 			name: "Success",
 			setupMock: func() {
-				mockService.EXPECT().RegisterUser(gomock.Any(), "new_user", "password123").Return(&domain.User{ID: 1}, nil).Times(1)
+				mockService.EXPECT().RegisterUser(gomock.Any(), "new_user", "password123", gomock.Any(), gomock.Any()).Return(&domain.User{ID: 1}, nil).Times(1)
 			},
 			input:     &proto.RegisterRequest{Login: "new_user", Password: "password123"},
 			expectErr: "",
@@ -37,7 +99,7 @@ func TestUserHandler_Register(t *testing.T) {
 		{
 			name: "User_Already_Exists",
 			setupMock: func() {
-				mockService.EXPECT().RegisterUser(gomock.Any(), "existing_user", "password123").Return(nil, errors.New("user already exists (existing_user)")).Times(1)
+				mockService.EXPECT().RegisterUser(gomock.Any(), "existing_user", "password123", gomock.Any(), gomock.Any()).Return(nil, errors.New("user already exists (existing_user)")).Times(1)
 			},
 			input:     &proto.RegisterRequest{Login: "existing_user", Password: "password123"},
 			expectErr: "rpc error: code = Internal desc = user already exists (existing_user)",
@@ -45,11 +107,27 @@ func TestUserHandler_Register(t *testing.T) {
 		{
 			name: "Internal_Error",
 			setupMock: func() {
-				mockService.EXPECT().RegisterUser(gomock.Any(), "new_user", "password123").Return(nil, errors.New("internal error")).Times(1)
+				mockService.EXPECT().RegisterUser(gomock.Any(), "new_user", "password123", gomock.Any(), gomock.Any()).Return(nil, errors.New("internal error")).Times(1)
 			},
 			input:     &proto.RegisterRequest{Login: "new_user", Password: "password123"},
 			expectErr: "rpc error: code = Internal desc = internal error",
 		},
+		{
+			name: "Invalid_KDF",
+			setupMock: func() {
+				mockService.EXPECT().RegisterUser(gomock.Any(), "new_user", "password123", gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: algorithm is not set", user.ErrInvalidKDF)).Times(1)
+			},
+			input:     &proto.RegisterRequest{Login: "new_user", Password: "password123"},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid kdf params: algorithm is not set",
+		},
+		{
+			name: "Invalid_Auth_Hash",
+			setupMock: func() {
+				mockService.EXPECT().RegisterUser(gomock.Any(), "new_user", "password123", gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: auth hash must be 64 hex characters", user.ErrInvalidPassword)).Times(1)
+			},
+			input:     &proto.RegisterRequest{Login: "new_user", Password: "password123"},
+			expectErr: "rpc error: code = InvalidArgument desc = invalid password: auth hash must be 64 hex characters",
+		},
 	}
 
 	for _, tc := range tests {
@@ -75,7 +153,7 @@ func TestUserHandler_Login(t *testing.T) {
 
 	mockService := mocks.NewMockIUserService(ctrl)
 	logger := zap.NewNop()
-	handler := NewUserHandler(mockService, logger)
+	handler := NewUserHandler(mockService, newTokenService(), logger)
 
 	tests := []struct {
 		name      string
@@ -125,3 +203,76 @@ func TestUserHandler_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockIUserService(ctrl)
+	logger := zap.NewNop()
+	handler := NewUserHandler(mockService, newTokenService(), logger)
+
+	tests := []struct {
+		name      string
+		setupMock func()
+		ctx       context.Context
+		input     *proto.ChangePasswordRequest
+		expectErr string
+	}{
+		{
+			name: "Success",
+			setupMock: func() {
+				mockService.EXPECT().ChangePassword(gomock.Any(), domain.UserID(123), "old", "new", gomock.Any(), []byte("wrapped-vault-key"), gomock.Len(0)).
+					Return(&domain.User{ID: 123, VaultKey: []byte("wrapped-vault-key")}, nil).Times(1)
+			},
+			ctx: context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
+			input: &proto.ChangePasswordRequest{
+				OldPassword: "old",
+				NewPassword: "new",
+				Kdf:         &proto.KDFParams{Algorithm: "argon2id", Salt: []byte("0123456789abcdef")},
+				VaultKey:    []byte("wrapped-vault-key"),
+			},
+			expectErr: "",
+		},
+		{
+			name: "Wrong_Password",
+			setupMock: func() {
+				mockService.EXPECT().ChangePassword(gomock.Any(), domain.UserID(123), "wrong", "new", gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, user.ErrBadCredentials).Times(1)
+			},
+			ctx:       context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
+			input:     &proto.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new"},
+			expectErr: "rpc error: code = Unauthenticated desc = bad token credentials",
+		},
+		{
+			name: "Concurrent_Update",
+			setupMock: func() {
+				mockService.EXPECT().ChangePassword(gomock.Any(), domain.UserID(123), "old", "new", gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, user.ErrConcurrentUpdate).Times(1)
+			},
+			ctx:       context.WithValue(context.Background(), consts.UserIDKeyCtx, domain.UserID(123)),
+			input:     &proto.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			expectErr: "rpc error: code = Aborted desc = vault was changed concurrently",
+		},
+		{
+			name:      "Error_MissingUserID",
+			setupMock: func() {},
+			ctx:       context.Background(),
+			input:     &proto.ChangePasswordRequest{},
+			expectErr: "rpc error: code = Internal desc = failed to extract user id from context",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMock()
+
+			_, err := handler.ChangePassword(tc.ctx, tc.input)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -38,3 +38,27 @@ func Authentication(tokenService *token.Service) grpc.UnaryServerInterceptor {
 		return handler(ctx, req)
 	}
 }
+
+// authServerStream подменяет контекст потока контекстом с идентификатором пользователя
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока с идентификатором пользователя
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamAuthentication создает и возвращает interceptor для потоковых вызовов gRPC.
+// Как и Authentication, добавляет в контекст потока идентификатор пользователя из JWT токена.
+func StreamAuthentication(tokenService *token.Service) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authContext(tokenService, ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -3,11 +3,15 @@ package grpc
 import (
 	"context"
 	"database/sql"
+	"github.com/romanp1989/gophkeeper/internal/server/blobstore"
 	serverConfig "github.com/romanp1989/gophkeeper/internal/server/config"
+	"github.com/romanp1989/gophkeeper/internal/server/events"
+	"github.com/romanp1989/gophkeeper/internal/server/folder"
 	"github.com/romanp1989/gophkeeper/internal/server/grpc/handlers"
 	"github.com/romanp1989/gophkeeper/internal/server/grpc/interceptors"
 	"github.com/romanp1989/gophkeeper/internal/server/secret"
 	"github.com/romanp1989/gophkeeper/internal/server/token"
+	"github.com/romanp1989/gophkeeper/internal/server/upload"
 	"github.com/romanp1989/gophkeeper/internal/server/user"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
 	"go.uber.org/zap"
@@ -22,23 +26,82 @@ import (
 type Server struct {
 	config     *serverConfig.Config
 	grpcServer *grpc.Server
+	janitor    *upload.Janitor
+	checker    *blobstore.Checker
+	purger     *secret.Purger
+	reaper     *secret.Reaper
+	listener   *events.PostgresHub
 	logger     *zap.Logger
 }
 
 func NewServer(config *serverConfig.Config, db *sql.DB, logger *zap.Logger) *Server {
-	grpcServer := grpcServerSetup(config, db, logger)
-	return &Server{
+	store := blobStoreSetup(config, logger)
+	spool := uploadSpoolSetup(config, logger)
+
+	// События рассылаются между экземплярами сервера через PostgreSQL, только если это включено в конфигурации
+	var (
+		secretEvents handlers.SecretEvents = events.NewHub(config.Events)
+		listener     *events.PostgresHub
+	)
+	if config.Events.Postgres {
+		listener = events.NewPostgresHub(db, config.Db.Dsn, config.Events, logger)
+		secretEvents = listener
+	}
+
+	grpcServer := grpcServerSetup(config, db, store, spool, secretEvents, logger)
+
+	var secretBlobs secret.BlobStore
+	if store != nil {
+		secretBlobs = store
+	}
+	secretRepository := secret.NewSecretRepository(db, secretBlobs, config.Secret)
+
+	server := &Server{
 		config:     config,
 		grpcServer: grpcServer,
+		janitor:    upload.NewJanitor(upload.NewUploadRepository(db, nil, spool, config.Secret), config.Upload, logger),
+		purger:     secret.NewPurger(secretRepository, config.Secret, logger),
+		reaper:     secret.NewReaper(secretRepository, secretEvents, config.Secret, logger),
+		listener:   listener,
 		logger:     logger,
 	}
+	if store != nil {
+		server.checker = blobstore.NewChecker(store, secretRepository, config.Blob, logger)
+	}
+
+	return server
+}
+
+// blobStoreSetup Создание хранилища файлов. Возвращает nil, если хранилище не настроено
+func blobStoreSetup(cfg *serverConfig.Config, logger *zap.Logger) *blobstore.FileStore {
+	if cfg.Blob == nil || cfg.Blob.Dir == "" {
+		return nil
+	}
+
+	store, err := blobstore.NewFileStore(cfg.Blob.Dir)
+	if err != nil {
+		logger.Fatal("Failed to create blob store", zap.Error(err))
+	}
+
+	return store
+}
+
+// uploadSpoolSetup Создание каталога данных незавершенных загрузок
+func uploadSpoolSetup(cfg *serverConfig.Config, logger *zap.Logger) *upload.FileSpool {
+	spool, err := upload.NewFileSpool(cfg.Upload.Dir)
+	if err != nil {
+		logger.Fatal("Failed to create upload directory", zap.Error(err))
+	}
+
+	return spool
 }
 
 // grpcServerSetup Конфигурирование GRPC сервера
-func grpcServerSetup(cfg *serverConfig.Config, db *sql.DB, logger *zap.Logger) *grpc.Server {
+func grpcServerSetup(cfg *serverConfig.Config, db *sql.DB, store *blobstore.FileStore, spool *upload.FileSpool, secretEvents handlers.SecretEvents, logger *zap.Logger) *grpc.Server {
 	tokenService := token.NewJwtService(cfg.Token)
 	opts := []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(interceptors.Authentication(tokenService)),
+		grpc.ChainStreamInterceptor(interceptors.StreamAuthentication(tokenService)),
 	}
 
 	tlsCredentials, err := cfg.LoadTLSConfig("ca-cert.pem", "server-cert.pem", "server-key.pem")
@@ -50,11 +113,22 @@ func grpcServerSetup(cfg *serverConfig.Config, db *sql.DB, logger *zap.Logger) *
 
 	server := grpc.NewServer(opts...)
 
+	var (
+		secretBlobs secret.BlobStore
+		uploadBlobs upload.BlobStore
+	)
+	if store != nil {
+		secretBlobs, uploadBlobs = store, store
+	}
+
 	userRepository := user.NewUserRepository(db)
-	secretRepository := secret.NewSecretRepository(db)
+	secretRepository := secret.NewSecretRepository(db, secretBlobs, cfg.Secret)
+
+	proto.RegisterUsersServer(server, handlers.NewUserHandler(user.NewUserService(userRepository, cfg.User.FakeSaltKey), tokenService, logger))
+	uploadService := upload.NewUploadService(upload.NewUploadRepository(db, uploadBlobs, spool, cfg.Secret), secretRepository, cfg.Secret.Quota)
 
-	proto.RegisterUsersServer(server, handlers.NewUserHandler(user.NewUserService(userRepository), logger))
-	proto.RegisterSecretsServer(server, handlers.NewSecretHandler(secret.NewSecretService(secretRepository), logger))
+	proto.RegisterSecretsServer(server, handlers.NewSecretHandler(secret.NewSecretService(secretRepository, cfg.Secret.Quota), uploadService, secretEvents, logger))
+	proto.RegisterFoldersServer(server, handlers.NewFolderHandler(folder.NewFolderService(folder.NewFolderRepository(db)), secretEvents, logger))
 
 	return server
 }
@@ -73,6 +147,18 @@ func (s *Server) Start() error {
 		}
 	}()
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go s.janitor.Run(jobsCtx)
+	go s.purger.Run(jobsCtx)
+	go s.reaper.Run(jobsCtx)
+	if s.checker != nil {
+		go s.checker.Run(jobsCtx)
+	}
+	if s.listener != nil {
+		go s.listener.Run(jobsCtx)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 
@@ -5,7 +5,11 @@ import (
 	"github.com/golang/mock/gomock"
 	serverConfig "github.com/romanp1989/gophkeeper/internal/server/config"
 	"github.com/romanp1989/gophkeeper/internal/server/db"
+	"github.com/romanp1989/gophkeeper/internal/server/events"
+	"github.com/romanp1989/gophkeeper/internal/server/secret"
 	"github.com/romanp1989/gophkeeper/internal/server/token"
+	"github.com/romanp1989/gophkeeper/internal/server/upload"
+	"github.com/romanp1989/gophkeeper/internal/server/user"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"testing"
@@ -27,6 +31,14 @@ func TestNewServer(t *testing.T) {
 			Name:   "Authorization",
 			Expire: time.Hour * 1,
 		},
+		Upload: &upload.Config{
+			Dir:             t.TempDir(),
+			SessionTTL:      time.Hour,
+			CleanupInterval: time.Minute,
+		},
+		Secret: &secret.Config{Versions: 10, TrashRetention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+		Events: &events.Config{BufferSize: 64, ReconnectDelay: time.Minute},
+		User:   &user.Config{FakeSaltKey: []byte("fake-salt-key")},
 	}
 	dbMock := &sql.DB{}
 
@@ -56,12 +68,25 @@ func Test_grpcServerSetup(t *testing.T) {
 			Name:   "Authorization",
 			Expire: time.Hour * 1,
 		},
+		Upload: &upload.Config{
+			Dir:             t.TempDir(),
+			SessionTTL:      time.Hour,
+			CleanupInterval: time.Minute,
+		},
+		Secret: &secret.Config{Versions: 10, TrashRetention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+		Events: &events.Config{BufferSize: 64, ReconnectDelay: time.Minute},
+		User:   &user.Config{FakeSaltKey: []byte("fake-salt-key")},
 	}
 	dbMock := &sql.DB{}
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	server := grpcServerSetup(cfg, dbMock, logger)
+	spool, err := upload.NewFileSpool(cfg.Upload.Dir)
+	if err != nil {
+		t.Fatalf("Failed to create upload directory: %v", err)
+	}
+
+	server := grpcServerSetup(cfg, dbMock, nil, spool, events.NewHub(cfg.Events), logger)
 
 	assert.NotNil(t, server)
 }
@@ -0,0 +1,2 @@
+alter table "users"
+    drop column if exists kdf;
\ No newline at end of file
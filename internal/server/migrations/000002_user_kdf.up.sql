@@ -0,0 +1,2 @@
+alter table "users"
+    add column if not exists kdf jsonb;
\ No newline at end of file
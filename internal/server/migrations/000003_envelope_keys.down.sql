@@ -0,0 +1,2 @@
+alter table "secrets" drop column if exists data_key;
+alter table "users" drop column if exists vault_key;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table "users" add column if not exists vault_key bytea;
+alter table "secrets" add column if not exists data_key bytea;
\ No newline at end of file
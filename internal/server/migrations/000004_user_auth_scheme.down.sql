@@ -0,0 +1 @@
+alter table "users" drop column if exists auth_scheme;
\ No newline at end of file
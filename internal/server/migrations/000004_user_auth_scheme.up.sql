@@ -0,0 +1 @@
+alter table "users" add column if not exists auth_scheme varchar(20) not null default '';
\ No newline at end of file
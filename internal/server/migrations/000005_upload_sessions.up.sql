@@ -0,0 +1,22 @@
+create table if not exists "upload_sessions"
+(
+    id varchar(32) primary key,
+    user_id bigint not null,
+    secret_id bigint not null references secrets (id) on delete cascade,
+    data_key bytea,
+    size bigint not null,
+    received bigint not null default 0,
+    created_at timestamp with time zone not null,
+    updated_at timestamp with time zone not null
+);
+
+create index if not exists upload_sessions_updated_at_idx
+    on "upload_sessions" (updated_at);
+
+create table if not exists "upload_chunks"
+(
+    session_id varchar(32) not null references upload_sessions (id) on delete cascade,
+    chunk_offset bigint not null,
+    data bytea not null,
+    primary key (session_id, chunk_offset)
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+drop index if exists secrets_blob_ref_idx;
+alter table "secrets" drop column if exists blob_ref;
\ No newline at end of file
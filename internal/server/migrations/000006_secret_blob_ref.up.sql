@@ -0,0 +1,2 @@
+alter table "secrets" add column if not exists blob_ref varchar(64);
+create index if not exists secrets_blob_ref_idx on "secrets" (blob_ref) where blob_ref is not null;
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists "secret_versions";
\ No newline at end of file
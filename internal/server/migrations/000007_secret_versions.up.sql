@@ -0,0 +1,19 @@
+create table if not exists "secret_versions"
+(
+    id bigserial primary key,
+    secret_id bigint not null references secrets (id) on delete cascade,
+    user_id bigint not null,
+    title varchar(255) not null,
+    metadata jsonb,
+    secret_type secret_type not null,
+    payload bytea not null,
+    data_key bytea,
+    blob_ref varchar(64),
+    created_at timestamp with time zone
+);
+
+create index if not exists secret_versions_secret_id_idx
+    on "secret_versions" (secret_id, id);
+
+create index if not exists secret_versions_blob_ref_idx
+    on "secret_versions" (blob_ref) where blob_ref is not null;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table "secrets" add column if not exists deleted_at timestamp with time zone;
+create index if not exists secrets_deleted_at_idx on "secrets" (deleted_at) where deleted_at is not null;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table "secrets" drop column if exists revision;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table "secrets" add column if not exists revision bigint not null default 1;
\ No newline at end of file
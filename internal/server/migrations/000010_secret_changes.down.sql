@@ -0,0 +1,8 @@
+drop trigger if exists secrets_tombstone_trg on "secrets";
+drop trigger if exists secrets_change_seq_trg on "secrets";
+drop function if exists secret_tombstone();
+drop function if exists secret_change_seq();
+drop table if exists "secret_tombstones";
+drop index if exists secrets_change_seq_idx;
+alter table "secrets" drop column if exists change_seq;
+alter table "users" drop column if exists change_seq;
\ No newline at end of file
@@ -0,0 +1,45 @@
+alter table "users" add column if not exists change_seq bigint not null default 0;
+alter table "secrets" add column if not exists change_seq bigint not null default 0;
+update "users" set change_seq = 1 where id in (select user_id from "secrets");
+update "secrets" set change_seq = 1;
+create index if not exists secrets_change_seq_idx on "secrets" (user_id, change_seq);
+
+create table if not exists "secret_tombstones"
+(
+    secret_id bigint primary key,
+    user_id bigint not null,
+    change_seq bigint not null,
+    deleted_at timestamp with time zone not null
+);
+
+create index if not exists secret_tombstones_change_seq_idx
+    on "secret_tombstones" (user_id, change_seq);
+
+create or replace function secret_change_seq() returns trigger as
+$$
+begin
+    update "users" set change_seq = change_seq + 1 where id = new.user_id returning change_seq into new.change_seq;
+    return new;
+end;
+$$ language plpgsql;
+
+create or replace function secret_tombstone() returns trigger as
+$$
+declare
+    seq bigint;
+begin
+    update "users" set change_seq = change_seq + 1 where id = old.user_id returning change_seq into seq;
+    insert into "secret_tombstones" (secret_id, user_id, change_seq, deleted_at)
+        values (old.id, old.user_id, seq, coalesce(old.deleted_at, now()))
+        on conflict (secret_id) do update set change_seq = excluded.change_seq, deleted_at = excluded.deleted_at;
+    return old;
+end;
+$$ language plpgsql;
+
+create trigger secrets_change_seq_trg
+    before insert or update on "secrets"
+    for each row execute function secret_change_seq();
+
+create trigger secrets_tombstone_trg
+    after delete on "secrets"
+    for each row execute function secret_tombstone();
\ No newline at end of file
@@ -0,0 +1,5 @@
+drop index if exists secrets_list_title_idx;
+drop index if exists secrets_list_created_idx;
+drop index if exists secrets_list_updated_idx;
+alter table "secrets" alter column updated_at drop not null, alter column updated_at drop default;
+alter table "secrets" alter column created_at drop not null, alter column created_at drop default;
\ No newline at end of file
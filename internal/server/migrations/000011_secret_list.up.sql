@@ -0,0 +1,7 @@
+update "secrets" set created_at = coalesce(created_at, updated_at, now()), updated_at = coalesce(updated_at, created_at, now())
+    where created_at is null or updated_at is null;
+alter table "secrets" alter column created_at set default now(), alter column created_at set not null;
+alter table "secrets" alter column updated_at set default now(), alter column updated_at set not null;
+create index if not exists secrets_list_updated_idx on "secrets" (user_id, updated_at, id) where deleted_at is null;
+create index if not exists secrets_list_created_idx on "secrets" (user_id, created_at, id) where deleted_at is null;
+create index if not exists secrets_list_title_idx on "secrets" (user_id, title, id) where deleted_at is null;
\ No newline at end of file
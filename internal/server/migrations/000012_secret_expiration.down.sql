@@ -0,0 +1,2 @@
+drop index if exists secrets_expires_at_idx;
+alter table "secrets" drop column if exists expires_at;
\ No newline at end of file
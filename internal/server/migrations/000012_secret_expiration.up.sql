@@ -0,0 +1,2 @@
+alter table "secrets" add column if not exists expires_at timestamp with time zone;
+create index if not exists secrets_expires_at_idx on "secrets" (expires_at) where expires_at is not null and deleted_at is null;
\ No newline at end of file
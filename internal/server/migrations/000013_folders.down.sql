@@ -0,0 +1,3 @@
+drop index if exists secrets_folder_id_idx;
+alter table "secrets" drop column if exists folder_id;
+drop table if exists "folders";
\ No newline at end of file
@@ -0,0 +1,15 @@
+create table if not exists "folders"
+(
+    id bigserial primary key,
+    user_id bigint not null,
+    parent_id bigint references folders (id) on delete cascade,
+    name varchar(255) not null,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create unique index if not exists folders_name_udx
+    on "folders" (user_id, coalesce(parent_id, 0), name);
+
+alter table "secrets" add column if not exists folder_id bigint references folders (id) on delete set null;
+create index if not exists secrets_folder_id_idx on "secrets" (folder_id) where folder_id is not null;
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table "secret_versions" drop column if exists tags;
+
+drop index if exists secrets_tags_idx;
+alter table "secrets" drop column if exists tags;
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table "secrets" add column if not exists tags text[] not null default '{}';
+create index if not exists secrets_tags_idx on "secrets" using gin (tags);
+
+alter table "secret_versions" add column if not exists tags text[] not null default '{}';
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table "secret_versions" drop column if exists search_tokens;
+
+drop index if exists secrets_search_tokens_idx;
+alter table "secrets" drop column if exists search_tokens;
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table "secrets" add column if not exists search_tokens bytea[] not null default '{}';
+create index if not exists secrets_search_tokens_idx on "secrets" using gin (search_tokens);
+
+alter table "secret_versions" add column if not exists search_tokens bytea[] not null default '{}';
\ No newline at end of file
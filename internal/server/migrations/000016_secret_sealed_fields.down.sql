@@ -0,0 +1,2 @@
+alter table "secret_versions" alter column title type varchar(255) using left(title, 255), alter column metadata type jsonb using to_jsonb(metadata);
+alter table "secrets" alter column title type varchar(255) using left(title, 255), alter column metadata type jsonb using to_jsonb(metadata);
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table "secrets" alter column title type text, alter column metadata type text using metadata #>> '{}';
+alter table "secret_versions" alter column title type text, alter column metadata type text using metadata #>> '{}';
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table "secret_versions" drop column if exists blob_size;
+alter table "secrets" drop column if exists blob_size;
\ No newline at end of file
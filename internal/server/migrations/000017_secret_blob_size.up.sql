@@ -0,0 +1,2 @@
+alter table "secrets" add column if not exists blob_size bigint;
+alter table "secret_versions" add column if not exists blob_size bigint;
\ No newline at end of file
@@ -0,0 +1,7 @@
+create table if not exists "upload_chunks"
+(
+    session_id varchar(32) not null references upload_sessions (id) on delete cascade,
+    chunk_offset bigint not null,
+    data bytea not null,
+    primary key (session_id, chunk_offset)
+);
\ No newline at end of file
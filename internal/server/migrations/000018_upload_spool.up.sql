@@ -0,0 +1,2 @@
+delete from "upload_sessions" where received > 0;
+drop table if exists "upload_chunks";
\ No newline at end of file
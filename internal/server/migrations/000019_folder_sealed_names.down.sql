@@ -0,0 +1,4 @@
+alter table "folders" alter column name type varchar(255);
+
+create unique index if not exists folders_name_udx
+    on "folders" (user_id, coalesce(parent_id, 0), name);
\ No newline at end of file
@@ -0,0 +1,3 @@
+alter table "folders" alter column name type text;
+
+drop index if exists folders_name_udx;
\ No newline at end of file
@@ -0,0 +1,16 @@
+package secret
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"time"
+)
+
+type Config struct {
+	BlobThreshold  int64         // BlobThreshold размер данных секрета, начиная с которого они сохраняются в хранилище файлов
+	Versions       int           // Versions количество хранимых предыдущих редакций секрета; 0 отключает историю
+	TrashRetention time.Duration // TrashRetention время хранения секрета в корзине до окончательного удаления
+	PurgeInterval  time.Duration // PurgeInterval интервал запуска очистки корзины
+	Quota          domain.Quota  // Quota ограничения хранилища одного пользователя
+	ExpireInterval time.Duration // ExpireInterval интервал проверки истекших секретов
+	PurgeExpired   bool          // PurgeExpired окончательно удалять истекшие секреты, не перемещая их в корзину
+}
@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+type TrashRepository interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Purger периодически окончательно удаляет секреты, которые находятся в корзине дольше TrashRetention.
+type Purger struct {
+	repository TrashRepository
+	config     *Config
+	logger     *zap.Logger
+}
+
+func NewPurger(repository TrashRepository, config *Config, logger *zap.Logger) *Purger {
+	return &Purger{repository: repository, config: config, logger: logger}
+}
+
+// Run очищает корзину с интервалом PurgeInterval до отмены контекста.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.Purge(ctx, time.Now())
+			if err != nil {
+				p.logger.Error("failed to purge trashed secrets", zap.Error(err))
+				continue
+			}
+			if purged > 0 {
+				p.logger.Info("trashed secrets purged", zap.Int64("count", purged))
+			}
+		}
+	}
+}
+
+// Purge удаляет секреты, перемещенные в корзину раньше чем за TrashRetention до now, и возвращает их количество.
+func (p *Purger) Purge(ctx context.Context, now time.Time) (int64, error) {
+	return p.repository.PurgeDeleted(ctx, now.Add(-p.config.TrashRetention))
+}
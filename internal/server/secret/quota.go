@@ -0,0 +1,176 @@
+package secret
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// BlobSizer возвращает размер файлов хранилища файлов
+type BlobSizer interface {
+	Size(ctx context.Context, ref string) (int64, error)
+}
+
+// querier выполняет запросы на чтение; реализуется *sql.DB и *sql.Tx
+type querier interface {
+	rowQuerier
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// QuotaBaseline место, занятое секретами пользователя до изменений транзакции, заблокированной LockQuota
+type QuotaBaseline struct {
+	// Количество секретов
+	secrets int64
+	// Суммарный размер данных секретов без их редакций
+	bytes int64
+}
+
+// LockQuota блокирует строку пользователя userID в транзакции tx, чтобы параллельные изменения его секретов
+// проверяли ограничения хранилища по очереди и не могли превысить их вместе.
+// Вызывается до блокировки секретов, чтобы транзакции блокировали строки в одном порядке.
+// Возвращает место, занятое секретами до изменений транзакции, которое передается в CheckQuota.
+// Если ограничения quota не заданы, пользователь не блокируется.
+func LockQuota(ctx context.Context, tx *sql.Tx, blobs BlobSizer, quota domain.Quota, userID domain.UserID) (*QuotaBaseline, error) {
+	if quota == (domain.Quota{}) {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", userID); err != nil {
+		return nil, fmt.Errorf("failed to lock user quota: %w", err)
+	}
+
+	if quota.MaxSecrets == 0 && quota.MaxBytes == 0 {
+		return nil, nil
+	}
+
+	usage, live, err := usage(ctx, tx, blobs, userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return &QuotaBaseline{secrets: usage.Secrets, bytes: live}, nil
+}
+
+// CheckQuota проверяет в транзакции tx, заблокированной LockQuota, что секреты пользователя userID вместе с изменениями
+// транзакции не превышают ограничения quota. Вызывается перед фиксацией транзакции; при превышении возвращается ErrQuotaExceeded.
+// Изменение, которое по сравнению с baseline не добавляет секретов и не увеличивает размер их данных, разрешается
+// и сверх ограничений, чтобы пользователь, превысивший их, мог уменьшить или заменить свои секреты.
+// Редакции, сохраненные таким изменением, при сравнении не учитываются: их количество ограничено настройкой истории.
+func CheckQuota(ctx context.Context, tx *sql.Tx, blobs BlobSizer, quota domain.Quota, userID domain.UserID, baseline *QuotaBaseline) error {
+	if quota.MaxSecrets == 0 && quota.MaxBytes == 0 {
+		return nil
+	}
+
+	usage, live, err := usage(ctx, tx, blobs, userID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	usage.Quota = quota
+
+	if usage.Fits(0, 0, 0) || (baseline != nil && usage.Secrets <= baseline.secrets && live <= baseline.bytes) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d secrets of %d bytes exceed the limit of %d secrets of %d bytes",
+		storageErrors.ErrQuotaExceeded, usage.Secrets, usage.Bytes, quota.MaxSecrets, quota.MaxBytes)
+}
+
+// BlobSize возвращает значение колонки blob_size для данных размера size со ссылкой на файл ref.
+// Размер хранится только у данных в хранилище файлов, чтобы учитывать их место без обращения к хранилищу.
+func BlobSize(ref sql.NullString, size int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: size, Valid: ref.Valid}
+}
+
+// usage возвращает количество секретов пользователя, включая секреты в корзине, и суммарный размер данных секретов
+// и их предыдущих редакций, а также размер данных самих секретов без редакций. Секреты exclude не учитываются,
+// но их редакции учитываются. Файл, на который ссылаются несколько секретов или редакций, учитывается один раз.
+// Размеры файлов берутся из колонки blob_size; у файлов, сохраненных до ее появления, размер запрашивается
+// у хранилища файлов, а отсутствующие файлы не учитываются.
+func usage(ctx context.Context, q querier, blobs BlobSizer, userID domain.UserID, exclude []uint64) (*domain.Usage, int64, error) {
+	where := "user_id = $1"
+	args := []any{userID}
+	if len(exclude) > 0 {
+		placeholders := make([]string, 0, len(exclude))
+		for _, id := range exclude {
+			args = append(args, id)
+			placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+		}
+		where += " AND id NOT IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	var (
+		usage           = &domain.Usage{}
+		live, versions  int64
+		liveBlobs, size int64
+		legacy          pq.StringArray
+		legacyLive      pq.StringArray
+	)
+	err := q.QueryRowContext(ctx,
+		`SELECT count(*), coalesce(sum(octet_length(payload)), 0),
+			(SELECT coalesce(sum(octet_length(payload)), 0) FROM secret_versions WHERE user_id = $1)
+			FROM secrets WHERE `+where,
+		args...,
+	).Scan(&usage.Secrets, &live, &versions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = q.QueryRowContext(ctx,
+		`SELECT coalesce(sum(size), 0), coalesce(sum(size) FILTER (WHERE live), 0),
+			coalesce(array_agg(blob_ref) FILTER (WHERE size IS NULL AND NOT live), '{}'),
+			coalesce(array_agg(blob_ref) FILTER (WHERE size IS NULL AND live), '{}')
+			FROM (
+				SELECT blob_ref, max(blob_size) AS size, bool_or(live) AS live FROM (
+					SELECT blob_ref, blob_size, true AS live FROM secrets WHERE `+where+` AND blob_ref IS NOT NULL
+					UNION ALL
+					SELECT blob_ref, blob_size, false FROM secret_versions WHERE user_id = $1 AND blob_ref IS NOT NULL
+				) AS refs GROUP BY blob_ref
+			) AS sizes`,
+		args...,
+	).Scan(&size, &liveBlobs, &legacy, &legacyLive)
+	if err != nil {
+		return nil, 0, err
+	}
+	usage.Bytes = live + versions + size
+	live += liveBlobs
+
+	stored, err := blobSizes(ctx, blobs, legacy)
+	if err != nil {
+		return nil, 0, err
+	}
+	storedLive, err := blobSizes(ctx, blobs, legacyLive)
+	if err != nil {
+		return nil, 0, err
+	}
+	usage.Bytes += stored + storedLive
+	live += storedLive
+
+	return usage, live, nil
+}
+
+// blobSizes возвращает суммарный размер файлов refs в хранилище файлов; отсутствующие файлы не учитываются
+func blobSizes(ctx context.Context, blobs BlobSizer, refs []string) (int64, error) {
+	if len(refs) > 0 && blobs == nil {
+		return 0, ErrBlobStoreDisabled
+	}
+
+	var total int64
+	for _, ref := range refs {
+		size, err := blobs.Size(ctx, ref)
+		if err != nil {
+			if errors.Is(err, storageErrors.ErrNotFound) {
+				continue
+			}
+			return 0, fmt.Errorf("failed to stat blob %s: %w", ref, err)
+		}
+		total += size
+	}
+
+	return total, nil
+}
@@ -0,0 +1,67 @@
+package secret
+
+import (
+	"context"
+	"github.com/romanp1989/gophkeeper/domain"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ExpiryRepository interface {
+	ExpireSecrets(ctx context.Context, now time.Time, purge bool) ([]*domain.Secret, error)
+}
+
+// ExpiryEvents рассылает клиентам события удаления истекших секретов
+type ExpiryEvents interface {
+	Publish(ctx context.Context, event domain.SecretEvent)
+}
+
+// Reaper периодически удаляет секреты, срок действия которых истек.
+// Истекшие секреты перемещаются в корзину, а если включен PurgeExpired - удаляются окончательно.
+type Reaper struct {
+	repository ExpiryRepository
+	events     ExpiryEvents
+	config     *Config
+	logger     *zap.Logger
+}
+
+func NewReaper(repository ExpiryRepository, events ExpiryEvents, config *Config, logger *zap.Logger) *Reaper {
+	return &Reaper{repository: repository, events: events, config: config, logger: logger}
+}
+
+// Run удаляет истекшие секреты с интервалом ExpireInterval до отмены контекста.
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.ExpireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := r.Reap(ctx, time.Now())
+			if err != nil {
+				r.logger.Error("failed to remove expired secrets", zap.Error(err))
+				continue
+			}
+			if expired > 0 {
+				r.logger.Info("expired secrets removed", zap.Int("count", expired), zap.Bool("purged", r.config.PurgeExpired))
+			}
+		}
+	}
+}
+
+// Reap удаляет секреты, срок действия которых истек к моменту now, сообщает об этом их владельцам
+// и возвращает количество удаленных секретов.
+func (r *Reaper) Reap(ctx context.Context, now time.Time) (int, error) {
+	expired, err := r.repository.ExpireSecrets(ctx, now, r.config.PurgeExpired)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, secret := range expired {
+		r.events.Publish(ctx, domain.SecretEvent{Type: domain.SecretDeleted, UserID: secret.UserID, SecretID: secret.ID})
+	}
+
+	return len(expired), nil
+}
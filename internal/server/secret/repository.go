@@ -1,41 +1,86 @@
 package secret
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/lib/pq"
 	"github.com/romanp1989/gophkeeper/domain"
 	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// secretColumns список колонок, читаемых из таблицы secrets
+const secretColumns = "id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags"
+
+// summaryColumns список колонок secretColumns без данных и ключа данных секрета, за которыми следует размер данных
+const summaryColumns = "id, user_id, title, metadata, secret_type, NULL::bytea, NULL::bytea, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags, octet_length(payload)"
+
+// ErrBlobStoreDisabled указывает, что данные секрета сохранены в файл, а хранилище файлов не настроено.
+var ErrBlobStoreDisabled = errors.New("blob store is not configured")
+
+// ErrBatchAborted указывает, что элемент пакета не сохранен, так как пакет в режиме "все или ничего"
+// был отменен из-за ошибки другого элемента.
+var ErrBatchAborted = errors.New("batch was rolled back")
+
+// BlobStore хранилище данных секретов вне PostgreSQL
+type BlobStore interface {
+	Put(ctx context.Context, r io.Reader) (ref string, size int64, err error)
+	Open(ctx context.Context, ref string) (io.ReadCloser, int64, error)
+	Size(ctx context.Context, ref string) (int64, error)
+	Delete(ctx context.Context, ref string) error
+}
+
 type Repository struct {
-	db *sql.DB
+	db     *sql.DB
+	blobs  BlobStore
+	config *Config
 }
 
-func NewSecretRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+// NewSecretRepository создает репозиторий секретов. Данные секретов размером больше config.BlobThreshold
+// сохраняются в blobs, а в PostgreSQL остается только ссылка на них. Если blobs равен nil, все данные хранятся в PostgreSQL.
+func NewSecretRepository(db *sql.DB, blobs BlobStore, config *Config) *Repository {
+	return &Repository{db: db, blobs: blobs, config: config}
 }
 
-func (r *Repository) Create(ctx context.Context, secret *domain.Secret) (*domain.Secret, error) {
-	var insertedID uint64
+func (r *Repository) Create(ctx context.Context, secret *domain.Secret) (_ *domain.Secret, err error) {
+	payload, ref, err := r.storePayload(ctx, secret.Payload)
+	if err != nil {
+		return nil, err
+	}
 
-	query := `INSERT INTO secrets (user_id, title, metadata, secret_type, payload) 
-			VALUES ($1, $2, $3, $4, $5) 
-			RETURNING secret_id`
+	defer func() {
+		if err != nil {
+			ReleaseBlobs(ctx, r.db, r.blobs, ref.String)
+		}
+	}()
 
-	result := r.db.QueryRowContext(ctx, query, secret.UserID, secret.Title, secret.Metadata, secret.SecretType, secret.Payload)
-	err := result.Scan(&insertedID)
+	tx, baseline, err := r.begin(ctx, secret.UserID)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = insertSecret(ctx, tx, secret, payload, ref); err != nil {
+		return nil, err
+	}
 
-	secret.ID = insertedID
+	if err = r.commit(ctx, tx, secret.UserID, baseline); err != nil {
+		return nil, err
+	}
 
 	return secret, nil
 }
 
 func (r *Repository) GetAllByUserID(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT * FROM secrets WHERE user_id = ? ORDER BY updated_at DESC", userID)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+secretColumns+" FROM secrets WHERE user_id = $1 AND deleted_at IS NULL ORDER BY updated_at DESC", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -44,25 +89,188 @@ func (r *Repository) GetAllByUserID(ctx context.Context, userID domain.UserID) (
 	secrets := make([]*domain.Secret, 0)
 
 	for rows.Next() {
-		var secret domain.Secret
-
-		err := rows.Scan(&secret.ID, &secret.Title, &secret.Metadata, &secret.Payload)
+		secret, err := scanSecret(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		secrets = append(secrets, &secret)
+		secrets = append(secrets, secret)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secrets {
+		if err = r.loadPayload(ctx, secret); err != nil {
+			return nil, err
+		}
 	}
 
 	return secrets, nil
 }
 
-func (r *Repository) GetByID(ctx context.Context, id uint64, userID domain.UserID) (*domain.Secret, error) {
-	var secret domain.Secret
+// sortColumns колонки таблицы secrets для ключей сортировки списка секретов
+var sortColumns = map[domain.SecretSort]string{
+	domain.SortByUpdatedAt: "updated_at",
+	domain.SortByCreatedAt: "created_at",
+	domain.SortByTitle:     "title",
+}
+
+// hasSealedFields проверяет, есть ли у пользователя секреты с заголовком, зашифрованным клиентом
+func (r *Repository) hasSealedFields(ctx context.Context, userID domain.UserID) (bool, error) {
+	var sealed bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM secrets WHERE user_id = $1 AND deleted_at IS NULL AND starts_with(title, $2))",
+		userID, domain.SealedFieldPrefix,
+	).Scan(&sealed)
+
+	return sealed, err
+}
+
+// List получение страницы секретов пользователя, выбранных и отсортированных по query.
+// Страница начинается после позиции query.After: вместо смещения используется сравнение с ключом сортировки
+// и идентификатором последнего секрета предыдущей страницы, поэтому время запроса не зависит от номера страницы.
+// Сортировка по заголовку и выбор по тегам отклоняются с ошибкой ErrInvalidQuery, если поля секретов пользователя зашифрованы.
+func (r *Repository) List(ctx context.Context, userID domain.UserID, query *domain.SecretQuery) (*domain.SecretPage, error) {
+	column, ok := sortColumns[query.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort key %q", query.Sort)
+	}
+
+	// Зашифрованные клиентом заголовки упорядочились бы по шифротексту, а зашифрованные теги сервер не видит,
+	// поэтому в хранилище с зашифрованными полями такие запросы отклоняются, а не возвращают неполный список
+	if query.Sort == domain.SortByTitle || len(query.Tags) > 0 {
+		sealed, err := r.hasSealedFields(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if sealed && query.Sort == domain.SortByTitle {
+			return nil, fmt.Errorf("%w: encrypted titles cannot be sorted by the server", ErrInvalidQuery)
+		}
+		if sealed {
+			return nil, fmt.Errorf("%w: encrypted tags cannot be filtered by the server, use tag search tokens", ErrInvalidQuery)
+		}
+	}
+
+	columns := secretColumns
+	if query.MetadataOnly {
+		columns = summaryColumns
+	}
+
+	var (
+		where strings.Builder
+		args  = []any{userID}
+	)
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	where.WriteString("user_id = $1 AND deleted_at IS NULL")
+
+	// Условие вхождения массивов использует GIN-индекс по тегам
+	if len(query.Tags) > 0 {
+		where.WriteString(" AND tags @> " + arg(pq.StringArray(query.Tags)) + "::text[]")
+	}
+	if len(query.SearchTokens) > 0 {
+		where.WriteString(" AND search_tokens @> " + arg(pq.ByteaArray(query.SearchTokens)) + "::bytea[]")
+	}
+
+	if len(query.Types) > 0 {
+		placeholders := make([]string, 0, len(query.Types))
+		for _, t := range query.Types {
+			placeholders = append(placeholders, arg(string(t)))
+		}
+		where.WriteString(" AND secret_type IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+
+	ranges := []struct {
+		condition string
+		value     time.Time
+	}{
+		{"created_at >= ", query.CreatedFrom},
+		{"created_at < ", query.CreatedTo},
+		{"updated_at >= ", query.UpdatedFrom},
+		{"updated_at < ", query.UpdatedTo},
+	}
+	for _, rng := range ranges {
+		if !rng.value.IsZero() {
+			where.WriteString(" AND " + rng.condition + arg(rng.value))
+		}
+	}
+
+	order, compare := "ASC", ">"
+	if query.Descending {
+		order, compare = "DESC", "<"
+	}
+
+	if after := query.After; after != nil {
+		var value any = after.Time
+		if query.Sort == domain.SortByTitle {
+			value = after.Title
+		}
+		where.WriteString(fmt.Sprintf(" AND (%s, id) %s (%s, %s)", column, compare, arg(value), arg(after.ID)))
+	}
+
+	// Лишняя строка показывает, что после страницы есть еще секреты
+	limit := arg(query.Limit + 1)
+
+	rows, err := r.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM secrets WHERE %s ORDER BY %s %s, id %s LIMIT %s", columns, where.String(), column, order, order, limit),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.SecretPage{Secrets: make([]*domain.Secret, 0)}
 
-	query := `SELECT * FROM secrets WHERE id = $1 AND user_id = $2`
+	for rows.Next() {
+		var (
+			size  sql.NullInt64
+			extra []any
+		)
+		if query.MetadataOnly {
+			extra = append(extra, &size)
+		}
+
+		secret, err := scanSecret(rows, extra...)
+		if err != nil {
+			return nil, err
+		}
+		secret.PayloadSize = size.Int64
+
+		page.Secrets = append(page.Secrets, secret)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Secrets) > query.Limit {
+		page.Secrets = page.Secrets[:query.Limit]
+
+		page.Next = query.CursorAt(page.Secrets[len(page.Secrets)-1])
+	}
+
+	for _, secret := range page.Secrets {
+		if query.MetadataOnly {
+			err = r.loadPayloadSize(ctx, secret)
+		} else {
+			err = r.loadPayload(ctx, secret)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id uint64, userID domain.UserID) (*domain.Secret, error) {
+	query := "SELECT " + secretColumns + " FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL"
 
-	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(secret.ID, &secret.Title, &secret.Metadata, &secret.Payload)
+	secret, err := scanSecret(r.db.QueryRowContext(ctx, query, id, userID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, storageErrors.ErrNotFound
@@ -70,33 +278,108 @@ func (r *Repository) GetByID(ctx context.Context, id uint64, userID domain.UserI
 		return nil, err
 	}
 
-	return &secret, nil
+	if err = r.loadPayload(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
 }
 
-// Update обновление конфиденциальных данных
-func (r *Repository) Update(ctx context.Context, secret *domain.Secret) (*domain.Secret, error) {
-	var secretID uint64
-	err := r.db.QueryRowContext(ctx, "SELECT id FROM secrets WHERE user_id = $1 AND id = $2 FOR UPDATE", secret.UserID, secret.ID).Scan(secretID)
+// OpenByID получение секрета пользователя с потоком его зашифрованных данных.
+// В отличие от GetByID данные из файла не читаются в память. Поток должен быть закрыт вызывающим.
+func (r *Repository) OpenByID(ctx context.Context, id uint64, userID domain.UserID) (*domain.Secret, io.ReadCloser, error) {
+	query := "SELECT " + secretColumns + " FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL"
+
+	secret, err := scanSecret(r.db.QueryRowContext(ctx, query, id, userID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, storageErrors.ErrNotFound
+			return nil, nil, storageErrors.ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	if secret.BlobRef == "" {
+		secret.PayloadSize = int64(len(secret.Payload))
+		return secret, io.NopCloser(bytes.NewReader(secret.Payload)), nil
+	}
+
+	if r.blobs == nil {
+		return nil, nil, ErrBlobStoreDisabled
+	}
+
+	payload, size, err := r.blobs.Open(ctx, secret.BlobRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open blob %s: %w", secret.BlobRef, err)
+	}
+	secret.PayloadSize = size
+
+	return secret, payload, nil
+}
+
+// Update обновление конфиденциальных данных.
+// Секрет обновляется, только если secret.Revision совпадает с текущей редакцией, иначе возвращается ErrRevisionConflict.
+// При успешном обновлении номер редакции увеличивается и записывается в secret.Revision.
+func (r *Repository) Update(ctx context.Context, secret *domain.Secret) (_ *domain.Secret, err error) {
+	// Файл записывается до начала транзакции, чтобы не держать блокировку строки на время записи
+	payload, ref, err := r.storePayload(ctx, secret.Payload)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			ReleaseBlobs(ctx, r.db, r.blobs, ref.String)
 		}
+	}()
+
+	tx, baseline, err := r.begin(ctx, secret.UserID)
+	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	query := `UPDATE secrets SET title = $1, metadata = $2, payload = $3, updated_at = $4 WHERE id = $5`
-	_, err = r.db.ExecContext(ctx, query, secret.Title, secret.Metadata, secret.Payload, secret.UpdatedAt, secret.ID)
+	pruned, err := r.updateSecret(ctx, tx, secret, payload, ref)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = r.commit(ctx, tx, secret.UserID, baseline); err != nil {
+		return nil, err
+	}
+	ReleaseBlobs(ctx, r.db, r.blobs, pruned...)
+
 	return secret, nil
 }
 
-// Delete удаление конфиденциальных данных
-func (r *Repository) Delete(ctx context.Context, id uint64, userID domain.UserID) error {
-	var secretID uint64
-	err := r.db.QueryRowContext(ctx, "SELECT id FROM secrets WHERE user_id = $1 AND id = $2 FOR UPDATE", userID, id).Scan(secretID)
+// UpdatePayload замена зашифрованных данных и ключа данных файлового секрета данными, читаемыми из payload.
+// Данные передаются без номера редакции и заменяются без проверки, но номер редакции увеличивается,
+// чтобы изменения, начатые до замены данных, были отклонены. Размер сохраненных данных записывается в secret.PayloadSize.
+func (r *Repository) UpdatePayload(ctx context.Context, secret *domain.Secret, payload io.Reader) (err error) {
+	// Файл записывается до начала транзакции, чтобы не держать блокировку строки на время получения данных
+	data, ref, size, err := r.storeStream(ctx, payload)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			ReleaseBlobs(ctx, r.db, r.blobs, ref.String)
+		}
+	}()
+
+	tx, baseline, err := r.begin(ctx, secret.UserID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var oldRef sql.NullString
+	err = tx.QueryRowContext(ctx,
+		"SELECT blob_ref FROM secrets WHERE id = $1 AND user_id = $2 AND secret_type = $3 AND deleted_at IS NULL FOR UPDATE",
+		secret.ID, secret.UserID, string(domain.BlobSecret),
+	).Scan(&oldRef)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return storageErrors.ErrNotFound
@@ -104,8 +387,968 @@ func (r *Repository) Delete(ctx context.Context, id uint64, userID domain.UserID
 		return err
 	}
 
-	query := `DELETE FROM secrets WHERE id = $1 AND user_id = $2`
-	_, err = r.db.ExecContext(ctx, query, id, secretID)
+	pruned, err := SaveVersion(ctx, tx, secret.ID, r.config.Versions)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE secrets SET updated_at = $1, payload = $2, data_key = $3, blob_ref = $4, blob_size = $5, revision = revision + 1 WHERE id = $6",
+		secret.UpdatedAt, data, secret.DataKey, ref, BlobSize(ref, size), secret.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = r.commit(ctx, tx, secret.UserID, baseline); err != nil {
+		return err
+	}
+
+	secret.BlobRef = ref.String
+	secret.PayloadSize = size
+	if oldRef.String != ref.String {
+		pruned = append(pruned, oldRef.String)
+	}
+	ReleaseBlobs(ctx, r.db, r.blobs, pruned...)
+
+	return nil
+}
+
+// BatchSave создание и обновление секретов в одной транзакции. Секреты с нулевым идентификатором создаются,
+// остальные обновляются с проверкой редакции, как в Update. Возвращает ошибки секретов в порядке их передачи:
+// nil для сохраненных секретов. Если atomic равен true, первая ошибка отменяет весь пакет.
+func (r *Repository) BatchSave(ctx context.Context, secrets []*domain.Secret, atomic bool) ([]error, error) {
+	payloads := make([][]byte, len(secrets))
+	refs := make([]sql.NullString, len(secrets))
+	stored := make([]error, len(secrets))
+	pruned := make([][]string, len(secrets))
+
+	// Файлы записываются до начала транзакции, чтобы не держать блокировки строк на время записи
+	for i, secret := range secrets {
+		payloads[i], refs[i], stored[i] = r.storePayload(ctx, secret.Payload)
+	}
+
+	// Пакет сохраняет секреты одного пользователя, ограничения хранилища которого проверяются перед фиксацией
+	var userID domain.UserID
+	if len(secrets) > 0 {
+		userID = secrets[0].UserID
+	}
+
+	results, err := r.batch(ctx, userID, r.config.Quota, len(secrets), atomic, func(tx *sql.Tx, i int) (err error) {
+		if stored[i] != nil {
+			return stored[i]
+		}
+
+		secret := secrets[i]
+		if secret.ID == 0 {
+			return insertSecret(ctx, tx, secret, payloads[i], refs[i])
+		}
+
+		pruned[i], err = r.updateSecret(ctx, tx, secret, payloads[i], refs[i])
+		return err
+	})
+
+	// Файлы освобождаются, только если на них не осталось ссылок, поэтому файлы отмененных секретов не удаляются
+	var release []string
+	for i := range secrets {
+		if err != nil || results[i] != nil {
+			release = append(release, refs[i].String)
+			continue
+		}
+		release = append(release, pruned[i]...)
+	}
+	ReleaseBlobs(ctx, r.db, r.blobs, release...)
+
+	return results, err
+}
+
+// BatchDelete перемещение секретов пользователя в корзину в одной транзакции.
+// Возвращает ошибки секретов в порядке их передачи: nil для удаленных секретов, ErrNotFound для отсутствующих.
+// Если atomic равен true, первая ошибка отменяет весь пакет.
+func (r *Repository) BatchDelete(ctx context.Context, ids []uint64, userID domain.UserID, atomic bool) ([]error, error) {
+	deletedAt := time.Now()
+
+	// Секреты в корзине учитываются в ограничениях хранилища, поэтому удаление их не проверяет
+	return r.batch(ctx, userID, domain.Quota{}, len(ids), atomic, func(tx *sql.Tx, i int) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE secrets SET deleted_at = $1 WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL",
+			deletedAt, ids[i], userID,
+		)
+		if err != nil {
+			return err
+		}
+
+		return checkAffected(result)
+	})
+}
+
+// batch выполняет apply для каждого из n элементов пакета в одной транзакции и возвращает ошибки элементов.
+// Каждый элемент выполняется в точке сохранения, поэтому ошибка элемента отменяет только его изменения.
+// Если atomic равен true, первая ошибка отменяет всю транзакцию, а остальные элементы получают ErrBatchAborted.
+// Перед фиксацией транзакции проверяются ограничения хранилища quota пользователя userID: пакет, превышающий их,
+// отменяется целиком с ошибкой ErrQuotaExceeded.
+// Ошибка возвращается, только если не удалось выполнить саму транзакцию.
+func (r *Repository) batch(ctx context.Context, userID domain.UserID, quota domain.Quota, n int, atomic bool,
+	apply func(tx *sql.Tx, i int) error) ([]error, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	baseline, err := LockQuota(ctx, tx, r.blobs, quota, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]error, n)
+
+	for i := range n {
+		if atomic {
+			if err = apply(tx, i); err != nil {
+				for j := range results {
+					results[j] = ErrBatchAborted
+				}
+				results[i] = err
+
+				return results, nil
+			}
+			continue
+		}
+
+		if _, err = tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+
+		if results[i] = apply(tx, i); results[i] != nil {
+			if _, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = CheckQuota(ctx, tx, r.blobs, quota, userID, baseline); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// begin начинает транзакцию изменения секретов пользователя userID и блокирует пользователя LockQuota.
+// Возвращает также место, занятое секретами до изменений, которое передается в commit.
+func (r *Repository) begin(ctx context.Context, userID domain.UserID) (*sql.Tx, *QuotaBaseline, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseline, err := LockQuota(ctx, tx, r.blobs, r.config.Quota, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, nil, err
+	}
+
+	return tx, baseline, nil
+}
+
+// commit проверяет ограничения хранилища пользователя userID с учетом изменений транзакции tx и фиксирует ее
+func (r *Repository) commit(ctx context.Context, tx *sql.Tx, userID domain.UserID, baseline *QuotaBaseline) error {
+	if err := CheckQuota(ctx, tx, r.blobs, r.config.Quota, userID, baseline); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete перемещение секрета в корзину. Секрет и его история хранятся до окончательного удаления через Purge
+// или до истечения срока хранения корзины.
+func (r *Repository) Delete(ctx context.Context, id uint64, userID domain.UserID) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE secrets SET deleted_at = $1 WHERE id = $2 AND user_id = $3 AND deleted_at IS NULL",
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+// ListTrash получение секретов пользователя из корзины, начиная с удаленного последним
+func (r *Repository) ListTrash(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+secretColumns+", deleted_at FROM secrets WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	secrets := make([]*domain.Secret, 0)
+
+	for rows.Next() {
+		var deletedAt sql.NullTime
+
+		secret, err := scanSecret(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		secret.DeletedAt = deletedAt.Time
+
+		secrets = append(secrets, secret)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secrets {
+		if err = r.loadPayload(ctx, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+// Restore возвращение секрета пользователя из корзины.
+// Срок действия истекшего секрета снимается, иначе секрет сразу снова попал бы в корзину.
+func (r *Repository) Restore(ctx context.Context, id uint64, userID domain.UserID) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE secrets SET deleted_at = NULL, expires_at = CASE WHEN expires_at <= $1 THEN NULL ELSE expires_at END
+			WHERE id = $2 AND user_id = $3 AND deleted_at IS NOT NULL`,
+		time.Now(), id, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(result)
+}
+
+// Purge окончательное удаление секрета пользователя из корзины вместе с историей редакций
+func (r *Repository) Purge(ctx context.Context, id uint64, userID domain.UserID) error {
+	purged, err := r.purge(ctx, "id = $1 AND user_id = $2 AND deleted_at IS NOT NULL", id, userID)
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		return storageErrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// plaintextFields условие записи secrets или secret_versions с заголовком, метаданными или тегами, сохраненными открытым текстом.
+// Параметр $2 - префикс domain.SealedFieldPrefix зашифрованных полей
+const plaintextFields = `((title <> '' AND NOT starts_with(title, $2)) OR (metadata <> '' AND NOT starts_with(metadata, $2)) OR tags <> '{}')`
+
+// ListPlaintextFields загрузка не более limit редакций секретов пользователя и секретов в корзине, заголовок, метаданные
+// или теги которых сохранены открытым текстом. Клиент не может зашифровать их сохранением секрета: редакции не изменяются,
+// а секреты в корзине не сохраняются. Секрет в корзине возвращается как редакция с номером 0.
+func (r *Repository) ListPlaintextFields(ctx context.Context, userID domain.UserID, limit int) ([]*domain.SecretVersion, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, secret_id, title, metadata, tags FROM (
+			SELECT id, secret_id, title, coalesce(metadata, '') AS metadata, tags FROM secret_versions WHERE user_id = $1 AND `+plaintextFields+`
+			UNION ALL
+			SELECT 0, id, title, coalesce(metadata, ''), tags FROM secrets WHERE user_id = $1 AND deleted_at IS NOT NULL AND `+plaintextFields+`
+		) AS records ORDER BY secret_id, id LIMIT $3`,
+		userID, domain.SealedFieldPrefix, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]*domain.SecretVersion, 0)
+	for rows.Next() {
+		var (
+			record domain.SecretVersion
+			secret = domain.Secret{UserID: userID}
+			tags   pq.StringArray
+		)
+		if err = rows.Scan(&record.ID, &secret.ID, &secret.Title, &secret.Metadata, &tags); err != nil {
+			return nil, err
+		}
+		secret.Tags = tags
+		record.Secret = &secret
+
+		records = append(records, &record)
+	}
+
+	return records, rows.Err()
+}
+
+// SealPlaintextFields замена заголовков и метаданных записей, полученных ListPlaintextFields, значениями, зашифрованными
+// клиентом; теги при этом удаляются, так как клиент шифрует их вместе с метаданными. Записи, поля которых уже
+// зашифрованы или которые были удалены, не изменяются. Возвращает количество измененных записей.
+func (r *Repository) SealPlaintextFields(ctx context.Context, userID domain.UserID, records []*domain.SecretVersion) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var sealed int64
+	for _, record := range records {
+		query := "UPDATE secret_versions SET title = $3, metadata = $4, tags = '{}' WHERE user_id = $1 AND secret_id = $5 AND id = $6 AND " + plaintextFields
+		args := []any{userID, domain.SealedFieldPrefix, record.Secret.Title, record.Secret.Metadata, record.Secret.ID, record.ID}
+		if record.ID == 0 {
+			query = "UPDATE secrets SET title = $3, metadata = $4, tags = '{}' WHERE user_id = $1 AND id = $5 AND deleted_at IS NOT NULL AND " + plaintextFields
+			args = args[:5]
+		}
+
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		sealed += n
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return sealed, nil
+}
+
+// PurgeDeleted окончательное удаление всех секретов, перемещенных в корзину раньше before.
+// Возвращает количество удаленных секретов.
+func (r *Repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return r.purge(ctx, "deleted_at < $1", before)
+}
+
+// ExpireSecrets перемещает в корзину секреты, срок действия которых истек к моменту now,
+// и возвращает их идентификаторы и владельцев. Если purge равен true, истекшие секреты из корзины
+// сразу удаляются окончательно, в том числе перемещенные в корзину предыдущими вызовами.
+func (r *Repository) ExpireSecrets(ctx context.Context, now time.Time, purge bool) ([]*domain.Secret, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"UPDATE secrets SET deleted_at = $1 WHERE expires_at <= $1 AND deleted_at IS NULL RETURNING id, user_id",
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expired := make([]*domain.Secret, 0)
+	for rows.Next() {
+		secret := &domain.Secret{}
+		if err = rows.Scan(&secret.ID, &secret.UserID); err != nil {
+			return nil, err
+		}
+		expired = append(expired, secret)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if purge {
+		if _, err = r.purge(ctx, "expires_at <= $1 AND deleted_at IS NOT NULL", now); err != nil {
+			return nil, err
+		}
+	}
+
+	return expired, nil
+}
+
+// purge удаляет секреты, выбранные условием where, и освобождает файлы их данных и редакций.
+// Возвращает количество удаленных секретов.
+func (r *Repository) purge(ctx context.Context, where string, args ...any) (int64, error) {
+	// Редакции удаляются каскадно, поэтому ссылки на их файлы читаются в том же запросе до удаления
+	rows, err := r.db.QueryContext(ctx,
+		`WITH deleted AS (DELETE FROM secrets WHERE `+where+` RETURNING id, blob_ref)
+			SELECT id, blob_ref FROM deleted
+			UNION ALL
+			SELECT NULL, v.blob_ref FROM secret_versions v JOIN deleted d ON v.secret_id = d.id`,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var (
+		purged int64
+		refs   []string
+	)
+	for rows.Next() {
+		var (
+			id  sql.NullInt64
+			ref sql.NullString
+		)
+		if err = rows.Scan(&id, &ref); err != nil {
+			return 0, err
+		}
+
+		if id.Valid {
+			purged++
+		}
+		if ref.Valid {
+			refs = append(refs, ref.String)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	ReleaseBlobs(ctx, r.db, r.blobs, refs...)
+
+	return purged, nil
+}
+
+// ListChanges получение изменений секретов пользователя с номером изменения больше since.
+// Номер изменения пользователя увеличивается триггером при каждой записи в таблицу secrets, а при окончательном
+// удалении секрета триггер сохраняет его в secret_tombstones. Секреты в корзине возвращаются как удаленные.
+// Все запросы выполняются в одном снимке данных, поэтому изменения после возвращенного номера не теряются.
+// Если metadataOnly равен true, данные и ключи данных секретов не читаются, заполняется только размер данных.
+// В ответ включается не больше limit изменений: номер последнего из них становится номером ответа,
+// а следующая страница запрашивается с ним как since. Изменения с одинаковым номером не разделяются между страницами.
+func (r *Repository) ListChanges(ctx context.Context, userID domain.UserID, since uint64, metadataOnly bool, limit int) (*domain.SecretChanges, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	changes := &domain.SecretChanges{
+		Secrets:    make([]*domain.Secret, 0),
+		Tombstones: make([]*domain.SecretTombstone, 0),
+	}
+
+	err = tx.QueryRowContext(ctx, "SELECT change_seq FROM users WHERE id = $1", userID).Scan(&changes.Revision)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	var last uint64
+	err = tx.QueryRowContext(ctx,
+		`SELECT change_seq FROM (
+			SELECT change_seq FROM secrets WHERE user_id = $1 AND change_seq > $2
+			UNION ALL
+			SELECT change_seq FROM secret_tombstones WHERE user_id = $1 AND change_seq > $2
+		) AS changes ORDER BY change_seq LIMIT 1 OFFSET $3`,
+		userID, since, max(limit, 1)-1,
+	).Scan(&last)
+	switch {
+	case err == nil:
+		changes.More = last < changes.Revision
+		changes.Revision = last
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	columns := secretColumns
+	if metadataOnly {
+		columns = summaryColumns
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+columns+" FROM secrets WHERE user_id = $1 AND change_seq > $2 AND change_seq <= $3 AND deleted_at IS NULL ORDER BY change_seq",
+		userID, since, changes.Revision,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			size  sql.NullInt64
+			extra []any
+		)
+		if metadataOnly {
+			extra = append(extra, &size)
+		}
+
+		secret, err := scanSecret(rows, extra...)
+		if err != nil {
+			return nil, err
+		}
+		secret.PayloadSize = size.Int64
+
+		changes.Secrets = append(changes.Secrets, secret)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx,
+		`SELECT id, change_seq, deleted_at FROM secrets WHERE user_id = $1 AND change_seq > $2 AND change_seq <= $3 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT secret_id, change_seq, deleted_at FROM secret_tombstones WHERE user_id = $1 AND change_seq > $2 AND change_seq <= $3
+			ORDER BY 2`,
+		userID, since, changes.Revision,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tombstone := &domain.SecretTombstone{}
+		if err = rows.Scan(&tombstone.ID, &tombstone.Revision, &tombstone.DeletedAt); err != nil {
+			return nil, err
+		}
+
+		changes.Tombstones = append(changes.Tombstones, tombstone)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, secret := range changes.Secrets {
+		if metadataOnly {
+			err = r.loadPayloadSize(ctx, secret)
+		} else {
+			err = r.loadPayload(ctx, secret)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+// Usage возвращает количество секретов пользователя, включая секреты в корзине, и суммарный размер данных секретов
+// и их предыдущих редакций. Секреты exclude не учитываются, чтобы при их обновлении прежние данные не учитывались вместе с новыми.
+// Файл, на который ссылаются несколько секретов или редакций, учитывается один раз; отсутствующие файлы не учитываются.
+func (r *Repository) Usage(ctx context.Context, userID domain.UserID, exclude []uint64) (*domain.Usage, error) {
+	usage, _, err := usage(ctx, r.db, r.blobs, userID, exclude)
+	return usage, err
+}
+
+// ListVersions получение сохраненных редакций секрета пользователя, начиная с последней.
+// Данные редакций, сохраненные в хранилище файлов, не читаются: у таких редакций Payload пустой.
+// Если у пользователя нет секрета secretID, возвращается ErrNotFound.
+func (r *Repository) ListVersions(ctx context.Context, secretID uint64, userID domain.UserID) ([]*domain.SecretVersion, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM secrets WHERE id = $1 AND user_id = $2)", secretID, userID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, storageErrors.ErrNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, secret_id, user_id, title, metadata, secret_type, payload, data_key, created_at, blob_ref, tags
+			FROM secret_versions WHERE secret_id = $1 AND user_id = $2 ORDER BY id DESC`,
+		secretID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]*domain.SecretVersion, 0)
+
+	for rows.Next() {
+		version, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		if version.Secret.BlobRef != "" {
+			version.Secret.Payload = nil
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// RestoreVersion восстанавливает секрет пользователя из редакции versionID.
+// Текущее состояние секрета перед восстановлением сохраняется в историю как новая редакция.
+func (r *Repository) RestoreVersion(ctx context.Context, secretID, versionID uint64, userID domain.UserID) (*domain.Secret, error) {
+	tx, baseline, err := r.begin(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var oldRef sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT blob_ref FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE", secretID, userID).Scan(&oldRef)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Редакция читается до сохранения текущего состояния, которое может вытеснить ее из истории
+	var searchTokens pq.ByteaArray
+	version, err := scanVersion(tx.QueryRowContext(ctx,
+		`SELECT id, secret_id, user_id, title, metadata, secret_type, payload, data_key, created_at, blob_ref, tags, search_tokens
+			FROM secret_versions WHERE id = $1 AND secret_id = $2 AND user_id = $3`,
+		versionID, secretID, userID,
+	), &searchTokens)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	pruned, err := SaveVersion(ctx, tx, secretID, r.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := version.Secret
+	secret.UpdatedAt = time.Now()
+	secret.SearchTokens = searchTokens
+
+	var createdAt sql.NullTime
+	err = tx.QueryRowContext(ctx,
+		`UPDATE secrets SET updated_at = $1, title = $2, metadata = $3, secret_type = $4, payload = $5, data_key = $6, blob_ref = $7,
+			blob_size = (SELECT blob_size FROM secret_versions WHERE id = $8), tags = $9, search_tokens = $10, revision = revision + 1
+			WHERE id = $11
+			RETURNING created_at, revision`,
+		secret.UpdatedAt, secret.Title, secret.Metadata, secret.SecretType, secret.Payload, secret.DataKey,
+		sql.NullString{String: secret.BlobRef, Valid: secret.BlobRef != ""}, versionID, tagsArray(secret.Tags), tokensArray(secret.SearchTokens), secretID,
+	).Scan(&createdAt, &secret.Revision)
+	if err != nil {
+		return nil, err
+	}
+	secret.CreatedAt = createdAt.Time
+
+	if err = r.commit(ctx, tx, userID, baseline); err != nil {
+		return nil, err
+	}
+
+	if oldRef.String != secret.BlobRef {
+		pruned = append(pruned, oldRef.String)
+	}
+	ReleaseBlobs(ctx, r.db, r.blobs, pruned...)
+
+	if err = r.loadPayload(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// BlobRefs возвращает ссылки на файлы хранилища и секреты, которые на них ссылаются сами или через редакции
+func (r *Repository) BlobRefs(ctx context.Context) (map[string][]uint64, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, blob_ref FROM secrets WHERE blob_ref IS NOT NULL
+			UNION ALL
+			SELECT secret_id, blob_ref FROM secret_versions WHERE blob_ref IS NOT NULL`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := make(map[string][]uint64)
+
+	for rows.Next() {
+		var (
+			id  uint64
+			ref string
+		)
+		if err = rows.Scan(&id, &ref); err != nil {
+			return nil, err
+		}
+
+		refs[ref] = append(refs[ref], id)
+	}
+
+	return refs, rows.Err()
+}
+
+// rowQuerier выполняет запрос, возвращающий одну строку; реализуется *sql.DB и *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertSecret создает секрет с данными payload и ссылкой на файл ref, подготовленными storePayload,
+// и записывает в secret присвоенные идентификатор, номер редакции и папку.
+// Секрет, папка которого не найдена среди папок пользователя, создается в корне хранилища.
+func insertSecret(ctx context.Context, q rowQuerier, secret *domain.Secret, payload []byte, ref sql.NullString) error {
+	query := `INSERT INTO secrets (user_id, title, metadata, secret_type, payload, data_key, blob_ref, expires_at, folder_id, tags, search_tokens, blob_size) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT id FROM folders WHERE id = $9 AND user_id = $1), $10, $11, $12) 
+			RETURNING id, revision, folder_id`
+
+	var folderID sql.NullInt64
+	result := q.QueryRowContext(ctx, query, secret.UserID, secret.Title, secret.Metadata, secret.SecretType, payload, secret.DataKey, ref,
+		nullTime(secret.ExpiresAt), secret.FolderID, tagsArray(secret.Tags), tokensArray(secret.SearchTokens), BlobSize(ref, int64(len(secret.Payload))))
+	if err := result.Scan(&secret.ID, &secret.Revision, &folderID); err != nil {
+		return err
+	}
+
+	secret.BlobRef = ref.String
+	secret.FolderID = uint64(folderID.Int64)
+
+	return nil
+}
+
+// updateSecret обновляет секрет в транзакции tx, если secret.Revision совпадает с текущей редакцией,
+// и сохраняет прежнее состояние в историю. Возвращает ссылки на файлы, которые освобождаются после фиксации транзакции.
+func (r *Repository) updateSecret(ctx context.Context, tx *sql.Tx, secret *domain.Secret, payload []byte, ref sql.NullString) ([]string, error) {
+	var (
+		oldRef   sql.NullString
+		revision uint64
+	)
+	err := tx.QueryRowContext(ctx,
+		"SELECT blob_ref, revision FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE",
+		secret.ID, secret.UserID,
+	).Scan(&oldRef, &revision)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Строка заблокирована до конца транзакции, поэтому редакция не может измениться между проверкой и обновлением
+	if revision != secret.Revision {
+		return nil, fmt.Errorf("%w: secret %d has revision %d, got %d", ErrRevisionConflict, secret.ID, revision, secret.Revision)
+	}
+
+	pruned, err := SaveVersion(ctx, tx, secret.ID, r.config.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Папка, не найденная среди папок пользователя, заменяется корнем хранилища
+	query := `UPDATE secrets SET updated_at = $1, title = $2, metadata = $3, secret_type = $4, payload = $5, data_key = $6, blob_ref = $7,
+			expires_at = $8, folder_id = (SELECT id FROM folders WHERE id = $9 AND user_id = $10), tags = $11, search_tokens = $12,
+			blob_size = $13, revision = revision + 1
+			WHERE id = $14 RETURNING folder_id`
+	args := []any{secret.UpdatedAt, secret.Title, secret.Metadata, secret.SecretType, payload, secret.DataKey, ref, nullTime(secret.ExpiresAt),
+		secret.FolderID, secret.UserID, tagsArray(secret.Tags), tokensArray(secret.SearchTokens), BlobSize(ref, int64(len(secret.Payload))), secret.ID}
+
+	// Данные файловых секретов загружаются отдельно через UpdatePayload, поэтому без данных обновляются только атрибуты
+	if secret.Payload == nil {
+		query = `UPDATE secrets SET updated_at = $1, title = $2, metadata = $3, secret_type = $4, expires_at = $5,
+			folder_id = (SELECT id FROM folders WHERE id = $6 AND user_id = $7), tags = $8, search_tokens = $9, revision = revision + 1
+			WHERE id = $10 RETURNING folder_id`
+		args = []any{secret.UpdatedAt, secret.Title, secret.Metadata, secret.SecretType, nullTime(secret.ExpiresAt),
+			secret.FolderID, secret.UserID, tagsArray(secret.Tags), tokensArray(secret.SearchTokens), secret.ID}
+	}
+
+	var folderID sql.NullInt64
+	if err = tx.QueryRowContext(ctx, query, args...).Scan(&folderID); err != nil {
+		return nil, err
+	}
+	secret.Revision = revision + 1
+	secret.FolderID = uint64(folderID.Int64)
+
+	if secret.Payload != nil {
+		secret.BlobRef = ref.String
+		if oldRef.String != ref.String {
+			pruned = append(pruned, oldRef.String)
+		}
+	}
+
+	return pruned, nil
+}
+
+// storePayload сохраняет данные больше порога в хранилище файлов.
+// Возвращает данные для колонки payload и ссылку на файл; ссылка пустая, если данные остаются в PostgreSQL.
+func (r *Repository) storePayload(ctx context.Context, payload []byte) ([]byte, sql.NullString, error) {
+	if r.blobs == nil || int64(len(payload)) <= r.config.BlobThreshold {
+		return payload, sql.NullString{}, nil
+	}
+
+	ref, _, err := r.blobs.Put(ctx, bytes.NewReader(payload))
+	if err != nil {
+		return nil, sql.NullString{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return []byte{}, sql.NullString{String: ref, Valid: true}, nil
+}
+
+// storeStream сохраняет данные, читаемые из payload, как storePayload, но в память читается не больше порога:
+// данные больше порога передаются в хранилище файлов по мере чтения. Без хранилища файлов данные читаются целиком,
+// поэтому их размер должен быть ограничен payload. Возвращает также размер сохраненных данных.
+func (r *Repository) storeStream(ctx context.Context, payload io.Reader) ([]byte, sql.NullString, int64, error) {
+	if r.blobs == nil {
+		data, err := io.ReadAll(payload)
+		if err != nil {
+			return nil, sql.NullString{}, 0, err
+		}
+		return data, sql.NullString{}, int64(len(data)), nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(payload, r.config.BlobThreshold+1))
+	if err != nil {
+		return nil, sql.NullString{}, 0, err
+	}
+	if int64(len(head)) <= r.config.BlobThreshold {
+		return head, sql.NullString{}, int64(len(head)), nil
+	}
+
+	ref, size, err := r.blobs.Put(ctx, io.MultiReader(bytes.NewReader(head), payload))
+	if err != nil {
+		return nil, sql.NullString{}, 0, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return []byte{}, sql.NullString{String: ref, Valid: true}, size, nil
+}
+
+// loadPayload читает данные секрета из хранилища файлов, если они сохранены вне PostgreSQL, и заполняет их размер
+func (r *Repository) loadPayload(ctx context.Context, secret *domain.Secret) error {
+	if secret.BlobRef == "" {
+		secret.PayloadSize = int64(len(secret.Payload))
+		return nil
+	}
+	if r.blobs == nil {
+		return ErrBlobStoreDisabled
+	}
+
+	blob, _, err := r.blobs.Open(ctx, secret.BlobRef)
+	if err != nil {
+		return fmt.Errorf("failed to open blob %s: %w", secret.BlobRef, err)
+	}
+	defer blob.Close()
+
+	secret.Payload, err = io.ReadAll(blob)
+	if err != nil {
+		return fmt.Errorf("failed to read blob %s: %w", secret.BlobRef, err)
+	}
+	secret.PayloadSize = int64(len(secret.Payload))
+
+	return nil
+}
+
+// loadPayloadSize заполняет размер данных секрета, сохраненных в хранилище файлов, не читая их.
+// Размер данных, хранящихся в PostgreSQL, читается запросом вместе с секретом.
+func (r *Repository) loadPayloadSize(ctx context.Context, secret *domain.Secret) (err error) {
+	if secret.BlobRef == "" {
+		return nil
+	}
+	if r.blobs == nil {
+		return ErrBlobStoreDisabled
+	}
+
+	secret.PayloadSize, err = r.blobs.Size(ctx, secret.BlobRef)
+	if err != nil {
+		return fmt.Errorf("failed to stat blob %s: %w", secret.BlobRef, err)
+	}
+
+	return nil
+}
+
+// scanSecret читает секрет из строки результата запроса.
+// Колонки после secretColumns читаются в extra.
+func scanSecret(row interface{ Scan(dest ...any) error }, extra ...any) (*domain.Secret, error) {
+	var (
+		secret    domain.Secret
+		metadata  sql.NullString
+		createdAt sql.NullTime
+		updatedAt sql.NullTime
+		blobRef   sql.NullString
+		expiresAt sql.NullTime
+		folderID  sql.NullInt64
+		tags      pq.StringArray
+	)
+
+	dest := []any{&secret.ID, &secret.UserID, &secret.Title, &metadata, &secret.SecretType,
+		&secret.Payload, &secret.DataKey, &createdAt, &updatedAt, &blobRef, &secret.Revision, &expiresAt, &folderID, &tags}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Metadata = metadata.String
+	secret.CreatedAt = createdAt.Time
+	secret.UpdatedAt = updatedAt.Time
+	secret.BlobRef = blobRef.String
+	secret.ExpiresAt = expiresAt.Time
+	secret.FolderID = uint64(folderID.Int64)
+	secret.Tags = tags
+
+	return &secret, nil
+}
+
+// scanVersion читает редакцию секрета из строки результата запроса.
+// Колонки после tags читаются в extra.
+func scanVersion(row interface{ Scan(dest ...any) error }, extra ...any) (*domain.SecretVersion, error) {
+	var (
+		version   domain.SecretVersion
+		secret    domain.Secret
+		metadata  sql.NullString
+		createdAt sql.NullTime
+		blobRef   sql.NullString
+		tags      pq.StringArray
+	)
+
+	dest := []any{&version.ID, &secret.ID, &secret.UserID, &secret.Title, &metadata, &secret.SecretType,
+		&secret.Payload, &secret.DataKey, &createdAt, &blobRef, &tags}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Metadata = metadata.String
+	secret.UpdatedAt = createdAt.Time
+	secret.BlobRef = blobRef.String
+	secret.Tags = tags
+	version.Secret = &secret
+
+	return &version, nil
+}
+
+// tagsArray возвращает значение колонки тегов; секрет без тегов сохраняется с пустым массивом, а не NULL
+func tagsArray(tags []string) pq.StringArray {
+	if tags == nil {
+		return pq.StringArray{}
+	}
+	return tags
+}
+
+// tokensArray возвращает значение колонки токенов поиска; секрет без токенов сохраняется с пустым массивом, а не NULL
+func tokensArray(tokens [][]byte) pq.ByteaArray {
+	if tokens == nil {
+		return pq.ByteaArray{}
+	}
+	return tokens
+}
+
+// nullTime возвращает значение колонки времени, равное NULL для нулевого времени
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// checkAffected возвращает ErrNotFound, если запрос не изменил ни одной строки
+func checkAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storageErrors.ErrNotFound
+	}
 
-	return err
+	return nil
 }
@@ -1,12 +1,22 @@
 package secret
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/blobstore"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -21,10 +31,10 @@ func TestSecretRepository(t *testing.T) {
 		{
 			name: "GetByID_Success",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "created_at", "updated_at"}).
-					AddRow(1, 1, "Test Secret", "Metadata", "text", []byte("payload"), time.Now(), time.Now())
+				rows := sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}).
+					AddRow(1, 1, "Test Secret", "Metadata", "text", []byte("payload"), []byte("data-key"), time.Now(), time.Now(), nil, 1, nil, nil, "{bank,prod}")
 
-				mock.ExpectQuery(`SELECT \* FROM secrets WHERE id = \$1 AND user_id = \$2`).
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags FROM secrets WHERE id = \$1 AND user_id = \$2`).
 					WithArgs(1, 1).
 					WillReturnRows(rows)
 
@@ -32,22 +42,25 @@ func TestSecretRepository(t *testing.T) {
 				if err != nil {
 					t.Errorf("Expected no error, got %v", err)
 				}
-				if secret.ID != 1 || secret.Title != "Test Secret" {
+				if secret.ID != 1 || secret.Title != "Test Secret" || string(secret.DataKey) != "data-key" {
 					t.Errorf("Unexpected secret data: %+v", secret)
 				}
+				if !secret.HasTags([]string{"bank", "prod"}) {
+					t.Errorf("Expected tags [bank prod], got %v", secret.Tags)
+				}
 			},
 			expectErr: false,
 		},
 		{
 			name: "GetByID_Fail_NotFound",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT \* FROM secrets WHERE id = \$1 AND user_id = \$2`).
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags FROM secrets WHERE id = \$1 AND user_id = \$2`).
 					WithArgs(1, 1).
 					WillReturnError(sql.ErrNoRows)
 
 				_, err := repo.GetByID(ctx, 1, 1)
-				if err == nil {
-					t.Errorf("Expected error, got nil")
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
 				}
 			},
 			expectErr: true,
@@ -55,11 +68,11 @@ func TestSecretRepository(t *testing.T) {
 		{
 			name: "GetAllByUserID_Success",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "created_at", "updated_at"}).
-					AddRow(1, 1, "Secret 1", "Metadata 1", "text", []byte("payload1"), time.Now(), time.Now()).
-					AddRow(2, 1, "Secret 2", "Metadata 2", "text", []byte("payload2"), time.Now(), time.Now())
+				rows := sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}).
+					AddRow(1, 1, "Secret 1", "Metadata 1", "text", []byte("payload1"), []byte("data-key1"), time.Now(), time.Now(), nil, 1, nil, nil, nil).
+					AddRow(2, 1, "Secret 2", "Metadata 2", "text", []byte("payload2"), []byte("data-key2"), time.Now(), time.Now(), nil, 1, nil, nil, nil)
 
-				mock.ExpectQuery(`SELECT \* FROM secrets WHERE user_id = \$1 ORDER BY updated_at DESC`).
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL ORDER BY updated_at DESC`).
 					WithArgs(1).
 					WillReturnRows(rows)
 
@@ -76,7 +89,7 @@ func TestSecretRepository(t *testing.T) {
 		{
 			name: "GetAllByUserID_Fail_QueryError",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT \* FROM secrets WHERE user_id = \$1 ORDER BY updated_at DESC`).
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL ORDER BY updated_at DESC`).
 					WithArgs(1).
 					WillReturnError(fmt.Errorf("database error"))
 
@@ -90,23 +103,29 @@ func TestSecretRepository(t *testing.T) {
 		{
 			name: "Create_Success",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO secrets \(user_id, title, metadata, secret_type, payload\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id`).
-					WithArgs(1, "Test Secret", "Metadata", "text", []byte("payload")).
-					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO secrets \(user_id, title, metadata, secret_type, payload, data_key, blob_ref, expires_at, folder_id, tags, search_tokens, blob_size\)\s+VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \(SELECT id FROM folders WHERE id = \$9 AND user_id = \$1\), \$10, \$11, \$12\)\s+RETURNING id, revision, folder_id`).
+					WithArgs(1, "Test Secret", "Metadata", "text", []byte("payload"), []byte("data-key"), nil, nil, 5, pq.StringArray{"bank", "prod"}, pq.ByteaArray{[]byte("token")}, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(1, 1, 5))
+				mock.ExpectCommit()
 
 				secret := &domain.Secret{
-					UserID:     1,
-					Title:      "Test Secret",
-					Metadata:   "Metadata",
-					SecretType: "text",
-					Payload:    []byte("payload"),
+					UserID:       1,
+					Title:        "Test Secret",
+					Metadata:     "Metadata",
+					SecretType:   "text",
+					Payload:      []byte("payload"),
+					DataKey:      []byte("data-key"),
+					FolderID:     5,
+					Tags:         []string{"bank", "prod"},
+					SearchTokens: [][]byte{[]byte("token")},
 				}
 				insertedSecret, err := repo.Create(ctx, secret)
 				if err != nil {
 					t.Errorf("Expected no error, got %v", err)
 				}
-				if insertedSecret.ID != 1 {
-					t.Errorf("Expected ID 1, got %v", insertedSecret.ID)
+				if insertedSecret.ID != 1 || insertedSecret.FolderID != 5 {
+					t.Errorf("Expected ID 1 in folder 5, got %v in folder %v", insertedSecret.ID, insertedSecret.FolderID)
 				}
 			},
 			expectErr: false,
@@ -115,20 +134,22 @@ func TestSecretRepository(t *testing.T) {
 			name: "Update_Success",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
-				mock.ExpectQuery(`SELECT 1 FROM secrets WHERE id = \$1 FOR UPDATE`).
-					WithArgs(1).
-					WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
-				mock.ExpectExec(`UPDATE secrets SET updated_at = \$1, title = \$2, metadata = \$3, secret_type = \$4, payload = \$5 WHERE id = \$6`).
-					WithArgs(sqlmock.AnyArg(), "Updated Title", "Updated Metadata", "text", []byte("updated payload"), 1).
-					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 0))
+				mock.ExpectQuery(`UPDATE secrets SET updated_at = \$1, title = \$2, metadata = \$3, secret_type = \$4, payload = \$5, data_key = \$6, blob_ref = \$7,\s+expires_at = \$8, folder_id = \(SELECT id FROM folders WHERE id = \$9 AND user_id = \$10\), tags = \$11, search_tokens = \$12,\s+blob_size = \$13, revision = revision \+ 1\s+WHERE id = \$14 RETURNING folder_id`).
+					WithArgs(sqlmock.AnyArg(), "Updated Title", "Updated Metadata", "text", []byte("updated payload"), []byte("data-key"), nil, nil, 0, 1, "{}", "{}", nil, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"folder_id"}).AddRow(nil))
 				mock.ExpectCommit()
 
 				secret := &domain.Secret{
 					ID:         1,
+					UserID:     1,
 					Title:      "Updated Title",
 					Metadata:   "Updated Metadata",
 					SecretType: "text",
 					Payload:    []byte("updated payload"),
+					DataKey:    []byte("data-key"),
 					UpdatedAt:  time.Now(),
 				}
 				secret, err := repo.Update(ctx, secret)
@@ -139,10 +160,92 @@ func TestSecretRepository(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			name: "Delete_Success",
+			name: "Update_Success_WithoutPayload",
+			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 0))
+				mock.ExpectQuery(`UPDATE secrets SET updated_at = \$1, title = \$2, metadata = \$3, secret_type = \$4, expires_at = \$5,\s+folder_id = \(SELECT id FROM folders WHERE id = \$6 AND user_id = \$7\), tags = \$8, search_tokens = \$9, revision = revision \+ 1\s+WHERE id = \$10 RETURNING folder_id`).
+					WithArgs(sqlmock.AnyArg(), "Updated Title", "Updated Metadata", "blob", nil, 0, 1, "{}", "{}", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"folder_id"}).AddRow(nil))
+				mock.ExpectCommit()
+
+				secret := &domain.Secret{
+					ID:         1,
+					UserID:     1,
+					Title:      "Updated Title",
+					Metadata:   "Updated Metadata",
+					SecretType: "blob",
+					UpdatedAt:  time.Now(),
+				}
+				_, err := repo.Update(ctx, secret)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Update_Fail_RevisionConflict",
 			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`DELETE FROM secrets WHERE id = \$1 AND user_id = \$2`).
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
 					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 3))
+				mock.ExpectRollback()
+
+				_, err := repo.Update(ctx, &domain.Secret{ID: 1, UserID: 1, Revision: 2, Payload: []byte("payload")})
+				if !errors.Is(err, ErrRevisionConflict) {
+					t.Errorf("Expected error %v, got %v", ErrRevisionConflict, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "UpdatePayload_Success",
+			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND secret_type = \$3 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1, "blob").
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+				mock.ExpectExec(`UPDATE secrets SET updated_at = \$1, payload = \$2, data_key = \$3, blob_ref = \$4, blob_size = \$5, revision = revision \+ 1 WHERE id = \$6`).
+					WithArgs(sqlmock.AnyArg(), []byte("payload"), []byte("data-key"), nil, nil, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				secret := &domain.Secret{ID: 1, UserID: 1, DataKey: []byte("data-key")}
+				err := repo.UpdatePayload(ctx, secret, strings.NewReader("payload"))
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if secret.PayloadSize != 7 {
+					t.Errorf("Expected payload size 7, got %d", secret.PayloadSize)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "UpdatePayload_Fail_NotFound",
+			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND secret_type = \$3 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1, "blob").
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectRollback()
+
+				err := repo.UpdatePayload(ctx, &domain.Secret{ID: 1, UserID: 1, DataKey: []byte("data-key")}, strings.NewReader("payload"))
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Delete_Success",
+			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE secrets SET deleted_at = \$1 WHERE id = \$2 AND user_id = \$3 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), 1, 1).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 
 				err := repo.Delete(ctx, 1, 1)
@@ -152,6 +255,1212 @@ func TestSecretRepository(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "Delete_Fail_NotFound",
+			testFunc: func(t *testing.T, repo SecretRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE secrets SET deleted_at = \$1 WHERE id = \$2 AND user_id = \$3 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				err := repo.Delete(ctx, 1, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewSecretRepository(db, nil, &Config{})
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_BlobStore(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte("large encrypted payload")
+	sum := sha256.Sum256(payload)
+	ref := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "Create_Offloaded",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WithArgs(1, "File", "", "blob", []byte{}, []byte("data-key"), ref, nil, 0, "{}", "{}", len(payload)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(1, 1, nil))
+				mock.ExpectCommit()
+
+				secret, err := repo.Create(ctx, &domain.Secret{UserID: 1, Title: "File", SecretType: "blob", Payload: payload, DataKey: []byte("data-key")})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if secret.BlobRef != ref {
+					t.Errorf("Expected blob ref %s, got %s", ref, secret.BlobRef)
+				}
+				if exists, _ := store.Exists(ctx, ref); !exists {
+					t.Errorf("Expected blob file to be stored")
+				}
+			},
+		},
+		{
+			name: "Create_BelowThreshold",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WithArgs(1, "Text", "", "text", []byte("small"), []byte("data-key"), nil, nil, 0, "{}", "{}", nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(1, 1, nil))
+				mock.ExpectCommit()
+
+				secret, err := repo.Create(ctx, &domain.Secret{UserID: 1, Title: "Text", SecretType: "text", Payload: []byte("small"), DataKey: []byte("data-key")})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if secret.BlobRef != "" {
+					t.Errorf("Expected payload to stay in database, got blob ref %s", secret.BlobRef)
+				}
+			},
+		},
+		{
+			name: "Create_Fail_ReleasesBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WillReturnError(fmt.Errorf("database error"))
+				mock.ExpectRollback()
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE blob_ref = \$1\) OR EXISTS \(SELECT 1 FROM secret_versions WHERE blob_ref = \$1\)`).
+					WithArgs(ref).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+				_, err := repo.Create(ctx, &domain.Secret{UserID: 1, Title: "File", SecretType: "blob", Payload: payload, DataKey: []byte("data-key")})
+				if err == nil {
+					t.Fatalf("Expected error, got nil")
+				}
+				if exists, _ := store.Exists(ctx, ref); exists {
+					t.Errorf("Expected blob file to be removed")
+				}
+			},
+		},
+		{
+			name: "UpdatePayload_StreamsBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND secret_type = \$3 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1, "blob").
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+				mock.ExpectExec(`UPDATE secrets SET updated_at = \$1, payload = \$2, data_key = \$3, blob_ref = \$4, blob_size = \$5, revision = revision \+ 1 WHERE id = \$6`).
+					WithArgs(sqlmock.AnyArg(), []byte{}, []byte("data-key"), ref, len(payload), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				secret := &domain.Secret{ID: 1, UserID: 1, DataKey: []byte("data-key")}
+				if err := repo.UpdatePayload(ctx, secret, iotest.OneByteReader(bytes.NewReader(payload))); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if secret.BlobRef != ref || secret.PayloadSize != int64(len(payload)) {
+					t.Errorf("Expected blob ref %s of %d bytes, got %s of %d bytes", ref, len(payload), secret.BlobRef, secret.PayloadSize)
+				}
+				if exists, _ := store.Exists(ctx, ref); !exists {
+					t.Errorf("Expected blob file to be stored")
+				}
+			},
+		},
+		{
+			name: "GetByID_LoadsBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(payload)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				mock.ExpectQuery(`SELECT .+ FROM secrets WHERE id = \$1 AND user_id = \$2`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}).
+						AddRow(1, 1, "File", "", "blob", []byte{}, []byte("data-key"), time.Now(), time.Now(), ref, 1, nil, nil, nil))
+
+				secret, err := repo.GetByID(ctx, 1, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !bytes.Equal(secret.Payload, payload) {
+					t.Errorf("Expected payload %q, got %q", payload, secret.Payload)
+				}
+			},
+		},
+		{
+			name: "GetByID_Fail_MissingBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .+ FROM secrets WHERE id = \$1 AND user_id = \$2`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}).
+						AddRow(1, 1, "File", "", "blob", []byte{}, []byte("data-key"), time.Now(), time.Now(), ref, 1, nil, nil, nil))
+
+				_, err := repo.GetByID(ctx, 1, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+		{
+			name: "OpenByID_StreamsBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(payload)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				mock.ExpectQuery(`SELECT .+ FROM secrets WHERE id = \$1 AND user_id = \$2`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}).
+						AddRow(1, 1, "File", "", "blob", []byte{}, []byte("data-key"), time.Now(), time.Now(), ref, 1, nil, nil, nil))
+
+				secret, blob, err := repo.OpenByID(ctx, 1, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				defer blob.Close()
+
+				data, _ := io.ReadAll(blob)
+				if secret.PayloadSize != int64(len(payload)) || !bytes.Equal(data, payload) {
+					t.Errorf("Expected payload %q, got %q of size %d", payload, data, secret.PayloadSize)
+				}
+			},
+		},
+		{
+			name: "Purge_ReleasesBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(payload)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				mock.ExpectQuery(`WITH deleted AS \(DELETE FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NOT NULL RETURNING id, blob_ref\)`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}).AddRow(1, ref))
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE blob_ref = \$1\) OR EXISTS \(SELECT 1 FROM secret_versions WHERE blob_ref = \$1\)`).
+					WithArgs(ref).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+				if err := repo.Purge(ctx, 1, 1); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if exists, _ := store.Exists(ctx, ref); exists {
+					t.Errorf("Expected blob file to be removed")
+				}
+			},
+		},
+		{
+			name: "Purge_KeepsReferencedBlob",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(payload)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				mock.ExpectQuery(`WITH deleted AS \(DELETE FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NOT NULL RETURNING id, blob_ref\)`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}).AddRow(1, ref))
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE blob_ref = \$1\) OR EXISTS \(SELECT 1 FROM secret_versions WHERE blob_ref = \$1\)`).
+					WithArgs(ref).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+				if err := repo.Purge(ctx, 1, 1); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if exists, _ := store.Exists(ctx, ref); !exists {
+					t.Errorf("Expected referenced blob file to be kept")
+				}
+			},
+		},
+		{
+			name: "BlobRefs_Success",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, blob_ref FROM secrets WHERE blob_ref IS NOT NULL`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}).AddRow(1, ref).AddRow(2, ref))
+
+				refs, err := repo.BlobRefs(ctx)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(refs) != 1 || len(refs[ref]) != 2 {
+					t.Errorf("Unexpected blob refs: %v", refs)
+				}
+			},
+		},
+		{
+			name: "Usage",
+			testFunc: func(t *testing.T, repo *Repository, store *blobstore.FileStore, mock sqlmock.Sqlmock) {
+				if _, _, err := store.Put(ctx, bytes.NewReader(payload)); err != nil {
+					t.Fatalf("Failed to put blob: %v", err)
+				}
+				missing := strings.Repeat("0", len(ref))
+
+				mock.ExpectQuery(`SELECT count\(\*\), coalesce\(sum\(octet_length\(payload\)\), 0\),\s+\(SELECT coalesce\(sum\(octet_length\(payload\)\), 0\) FROM secret_versions WHERE user_id = \$1\)\s+FROM secrets WHERE user_id = \$1 AND id NOT IN \(\$2, \$3\)`).
+					WithArgs(1, 5, 6).
+					WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "versions"}).AddRow(3, 60, 40))
+				// Размер файлов, сохраненных до появления колонки blob_size, запрашивается у хранилища файлов
+				mock.ExpectQuery(`SELECT blob_ref, blob_size, true AS live FROM secrets WHERE user_id = \$1 AND id NOT IN \(\$2, \$3\) AND blob_ref IS NOT NULL\s+UNION ALL\s+SELECT blob_ref, blob_size, false FROM secret_versions WHERE user_id = \$1 AND blob_ref IS NOT NULL`).
+					WithArgs(1, 5, 6).
+					WillReturnRows(sqlmock.NewRows([]string{"size", "live", "legacy", "legacy_live"}).AddRow(50, 20, "{"+missing+"}", "{"+ref+"}"))
+
+				usage, err := repo.Usage(ctx, 1, []uint64{5, 6})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if usage.Secrets != 3 || usage.Bytes != 150+int64(len(payload)) {
+					t.Errorf("Unexpected usage: %+v", usage)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			store, err := blobstore.NewFileStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("Failed to create blob store: %v", err)
+			}
+
+			repo := NewSecretRepository(db, store, &Config{BlobThreshold: 16})
+
+			tc.testFunc(t, repo, store, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_Versions(t *testing.T) {
+	ctx := context.Background()
+	versionColumns := []string{"id", "secret_id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "blob_ref", "tags"}
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "Update_SavesVersion",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 0))
+				mock.ExpectExec(`INSERT INTO secret_versions \(secret_id, user_id, title, metadata, secret_type, payload, data_key, blob_ref, blob_size, created_at, tags, search_tokens\)\s+SELECT .+ FROM secrets\s+WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`DELETE FROM secret_versions WHERE secret_id = \$1 AND id NOT IN \(\s+SELECT id FROM secret_versions WHERE secret_id = \$1 ORDER BY id DESC LIMIT \$2\s+\) RETURNING blob_ref`).
+					WithArgs(1, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectQuery(`UPDATE secrets SET updated_at = \$1, title = \$2`).
+					WillReturnRows(sqlmock.NewRows([]string{"folder_id"}).AddRow(nil))
+				mock.ExpectCommit()
+
+				_, err := repo.Update(ctx, &domain.Secret{ID: 1, UserID: 1, Title: "Updated", SecretType: "text", Payload: []byte("payload")})
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+		},
+		{
+			name: "ListVersions_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE id = \$1 AND user_id = \$2\)`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(`SELECT .+ FROM secret_versions WHERE secret_id = \$1 AND user_id = \$2 ORDER BY id DESC`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows(versionColumns).
+						AddRow(3, 1, 1, "Second", "", "text", []byte("payload2"), []byte("key2"), time.Now(), nil, nil).
+						AddRow(2, 1, 1, "File", "", "blob", []byte{}, []byte("key1"), time.Now(), strings.Repeat("a", 64), nil))
+
+				versions, err := repo.ListVersions(ctx, 1, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(versions) != 2 || versions[0].ID != 3 || versions[0].Secret.ID != 1 || string(versions[0].Secret.Payload) != "payload2" {
+					t.Errorf("Unexpected versions: %+v", versions)
+				}
+				if versions[1].Secret.Payload != nil {
+					t.Errorf("Expected payload stored in blob store not to be loaded")
+				}
+			},
+		},
+		{
+			name: "ListVersions_Fail_NotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE id = \$1 AND user_id = \$2\)`).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+				_, err := repo.ListVersions(ctx, 1, 2)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected ErrNotFound, got %v", err)
+				}
+			},
+		},
+		{
+			name: "RestoreVersion_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				createdAt := time.Now().Add(-time.Hour)
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+				mock.ExpectQuery(`SELECT .+ FROM secret_versions WHERE id = \$1 AND secret_id = \$2 AND user_id = \$3`).
+					WithArgs(3, 1, 1).
+					WillReturnRows(sqlmock.NewRows(append(versionColumns, "search_tokens")).
+						AddRow(3, 1, 1, "Old", "", "text", []byte("old payload"), []byte("old-key"), time.Now(), nil, nil, `{"\\x746f6b656e"}`))
+				mock.ExpectExec(`INSERT INTO secret_versions`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectQuery(`DELETE FROM secret_versions`).
+					WithArgs(1, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectQuery(`UPDATE secrets SET updated_at = \$1, title = \$2, metadata = \$3, secret_type = \$4, payload = \$5, data_key = \$6, blob_ref = \$7,\s+blob_size = \(SELECT blob_size FROM secret_versions WHERE id = \$8\), tags = \$9, search_tokens = \$10, revision = revision \+ 1\s+WHERE id = \$11\s+RETURNING created_at, revision`).
+					WithArgs(sqlmock.AnyArg(), "Old", "", "text", []byte("old payload"), []byte("old-key"), nil, 3, "{}", pq.ByteaArray{[]byte("token")}, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "revision"}).AddRow(createdAt, 2))
+				mock.ExpectCommit()
+
+				secret, err := repo.RestoreVersion(ctx, 1, 3, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if secret.ID != 1 || secret.Title != "Old" || !secret.CreatedAt.Equal(createdAt) {
+					t.Errorf("Unexpected restored secret: %+v", secret)
+				}
+			},
+		},
+		{
+			name: "RestoreVersion_Fail_VersionNotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+				mock.ExpectQuery(`SELECT .+ FROM secret_versions WHERE id = \$1 AND secret_id = \$2 AND user_id = \$3`).
+					WithArgs(3, 1, 1).
+					WillReturnRows(sqlmock.NewRows(versionColumns))
+				mock.ExpectRollback()
+
+				_, err := repo.RestoreVersion(ctx, 1, 3, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+		{
+			name: "RestoreVersion_Fail_SecretNotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectRollback()
+
+				_, err := repo.RestoreVersion(ctx, 1, 3, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewSecretRepository(db, nil, &Config{Versions: 5})
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_Trash(t *testing.T) {
+	ctx := context.Background()
+	secretColumns := []string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags", "deleted_at"}
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "ListTrash_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags, deleted_at FROM secrets WHERE user_id = \$1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(secretColumns).
+						AddRow(1, 1, "Test Secret", nil, "text", []byte("payload"), []byte("data-key"), time.Now(), time.Now(), nil, 1, nil, nil, nil, deletedAt))
+
+				secrets, err := repo.ListTrash(ctx, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(secrets) != 1 || !secrets[0].DeletedAt.Equal(deletedAt) || string(secrets[0].Payload) != "payload" {
+					t.Errorf("Unexpected trash: %+v", secrets)
+				}
+			},
+		},
+		{
+			name: "Restore_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE secrets SET deleted_at = NULL, expires_at = CASE WHEN expires_at <= \$1 THEN NULL ELSE expires_at END\s+WHERE id = \$2 AND user_id = \$3 AND deleted_at IS NOT NULL`).
+					WithArgs(sqlmock.AnyArg(), 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				if err := repo.Restore(ctx, 1, 1); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+		},
+		{
+			name: "Restore_Fail_NotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE secrets SET deleted_at = NULL, expires_at = CASE WHEN expires_at <= \$1 THEN NULL ELSE expires_at END\s+WHERE id = \$2 AND user_id = \$3 AND deleted_at IS NOT NULL`).
+					WithArgs(sqlmock.AnyArg(), 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				if err := repo.Restore(ctx, 1, 1); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+		{
+			name: "Purge_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WITH deleted AS \(DELETE FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NOT NULL RETURNING id, blob_ref\)`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}).AddRow(1, nil).AddRow(nil, nil))
+
+				if err := repo.Purge(ctx, 1, 1); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+		},
+		{
+			name: "Purge_Fail_NotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WITH deleted AS \(DELETE FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NOT NULL RETURNING id, blob_ref\)`).
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}))
+
+				if err := repo.Purge(ctx, 1, 1); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+		{
+			name: "PurgeDeleted_CountsSecrets",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				before := time.Now()
+				mock.ExpectQuery(`WITH deleted AS \(DELETE FROM secrets WHERE deleted_at < \$1 RETURNING id, blob_ref\)`).
+					WithArgs(before).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}).AddRow(1, nil).AddRow(2, nil).AddRow(nil, nil))
+
+				purged, err := repo.PurgeDeleted(ctx, before)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if purged != 2 {
+					t.Errorf("Expected 2 purged secrets, got %d", purged)
+				}
+			},
+		},
+		{
+			name: "ListPlaintextFields_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, secret_id, title, metadata, tags FROM \(\s*SELECT id, secret_id, title, coalesce\(metadata, ''\) AS metadata, tags FROM secret_versions WHERE user_id = \$1 AND \(\(title <> '' AND NOT starts_with\(title, \$2\)\).+UNION ALL\s*SELECT 0, id, title, coalesce\(metadata, ''\), tags FROM secrets WHERE user_id = \$1 AND deleted_at IS NOT NULL AND .+ ORDER BY secret_id, id LIMIT \$3`).
+					WithArgs(1, domain.SealedFieldPrefix, 500).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "secret_id", "title", "metadata", "tags"}).
+						AddRow(0, 1, "trashed", "", "{work}").
+						AddRow(7, 2, "versioned", "meta", "{}"))
+
+				records, err := repo.ListPlaintextFields(ctx, 1, 500)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(records) != 2 {
+					t.Fatalf("Expected 2 records, got %d", len(records))
+				}
+				if records[0].ID != 0 || records[0].Secret.ID != 1 || len(records[0].Secret.Tags) != 1 || records[0].Secret.Tags[0] != "work" {
+					t.Errorf("Unexpected trashed secret record: %+v %+v", records[0], records[0].Secret)
+				}
+				if records[1].ID != 7 || records[1].Secret.ID != 2 || records[1].Secret.Metadata != "meta" || records[1].Secret.UserID != 1 {
+					t.Errorf("Unexpected version record: %+v %+v", records[1], records[1].Secret)
+				}
+			},
+		},
+		{
+			name: "SealPlaintextFields_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				records := []*domain.SecretVersion{
+					{ID: 0, Secret: &domain.Secret{ID: 1, Title: "$gk1$a", Metadata: "$gk1$b"}},
+					{ID: 7, Secret: &domain.Secret{ID: 2, Title: "$gk1$c", Metadata: "$gk1$d"}},
+				}
+
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE secrets SET title = \$3, metadata = \$4, tags = '\{\}' WHERE user_id = \$1 AND id = \$5 AND deleted_at IS NOT NULL AND \(\(title <> ''`).
+					WithArgs(1, domain.SealedFieldPrefix, "$gk1$a", "$gk1$b", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE secret_versions SET title = \$3, metadata = \$4, tags = '\{\}' WHERE user_id = \$1 AND secret_id = \$5 AND id = \$6 AND \(\(title <> ''`).
+					WithArgs(1, domain.SealedFieldPrefix, "$gk1$c", "$gk1$d", 2, 7).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+
+				sealed, err := repo.SealPlaintextFields(ctx, 1, records)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if sealed != 1 {
+					t.Errorf("Expected 1 sealed record, got %d", sealed)
+				}
+			},
+		},
+		{
+			name: "SealPlaintextFields_Fail_RolledBack",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				records := []*domain.SecretVersion{{ID: 7, Secret: &domain.Secret{ID: 2, Title: "$gk1$c", Metadata: "$gk1$d"}}}
+
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE secret_versions SET title = \$3`).
+					WithArgs(1, domain.SealedFieldPrefix, "$gk1$c", "$gk1$d", 2, 7).
+					WillReturnError(errors.New("database error"))
+				mock.ExpectRollback()
+
+				if _, err := repo.SealPlaintextFields(ctx, 1, records); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+		},
+		{
+			name: "ExpireSecrets_MovesToTrash",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectQuery(`UPDATE secrets SET deleted_at = \$1 WHERE expires_at <= \$1 AND deleted_at IS NULL RETURNING id, user_id`).
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 7).AddRow(2, 8))
+
+				expired, err := repo.ExpireSecrets(ctx, now, false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(expired) != 2 || expired[0].ID != 1 || expired[0].UserID != 7 || expired[1].ID != 2 || expired[1].UserID != 8 {
+					t.Errorf("Unexpected expired secrets: %+v", expired)
+				}
+			},
+		},
+		{
+			name: "ExpireSecrets_Purge",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectQuery(`UPDATE secrets SET deleted_at = \$1 WHERE expires_at <= \$1 AND deleted_at IS NULL RETURNING id, user_id`).
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 7))
+				mock.ExpectQuery(`WITH deleted AS \(DELETE FROM secrets WHERE expires_at <= \$1 AND deleted_at IS NOT NULL RETURNING id, blob_ref\)`).
+					WithArgs(now).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "blob_ref"}).AddRow(1, nil))
+
+				expired, err := repo.ExpireSecrets(ctx, now, true)
+				if err != nil || len(expired) != 1 {
+					t.Errorf("Unexpected expired secrets: %+v, %v", expired, err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewSecretRepository(db, nil, &Config{})
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_Changes(t *testing.T) {
+	ctx := context.Background()
+	secretColumns := []string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "ListChanges_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT change_seq FROM users WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"change_seq"}).AddRow(7))
+				mock.ExpectQuery(`SELECT change_seq FROM \(`).
+					WithArgs(1, 5, 99).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags FROM secrets WHERE user_id = \$1 AND change_seq > \$2 AND change_seq <= \$3 AND deleted_at IS NULL ORDER BY change_seq`).
+					WithArgs(1, 5, 7).
+					WillReturnRows(sqlmock.NewRows(secretColumns).
+						AddRow(1, 1, "Test Secret", nil, "text", []byte("payload"), []byte("data-key"), time.Now(), time.Now(), nil, 2, nil, nil, nil))
+				mock.ExpectQuery(`SELECT id, change_seq, deleted_at FROM secrets WHERE user_id = \$1 AND change_seq > \$2 AND change_seq <= \$3 AND deleted_at IS NOT NULL`).
+					WithArgs(1, 5, 7).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "change_seq", "deleted_at"}).
+						AddRow(2, 6, deletedAt).
+						AddRow(3, 7, deletedAt))
+				mock.ExpectRollback()
+
+				changes, err := repo.ListChanges(ctx, 1, 5, false, 100)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if changes.Revision != 7 {
+					t.Errorf("Expected revision 7, got %d", changes.Revision)
+				}
+				if len(changes.Secrets) != 1 || changes.Secrets[0].ID != 1 || string(changes.Secrets[0].Payload) != "payload" {
+					t.Errorf("Unexpected secrets: %+v", changes.Secrets)
+				}
+				if len(changes.Tombstones) != 2 || changes.Tombstones[1].ID != 3 || changes.Tombstones[1].Revision != 7 ||
+					!changes.Tombstones[1].DeletedAt.Equal(deletedAt) {
+					t.Errorf("Unexpected tombstones: %+v", changes.Tombstones)
+				}
+			},
+		},
+		{
+			name: "ListChanges_MetadataOnly",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT change_seq FROM users WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"change_seq"}).AddRow(7))
+				mock.ExpectQuery(`SELECT change_seq FROM \(`).
+					WithArgs(1, 5, 99).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, NULL::bytea, NULL::bytea, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags, octet_length\(payload\) FROM secrets WHERE user_id = \$1 AND change_seq > \$2 AND change_seq <= \$3 AND deleted_at IS NULL ORDER BY change_seq`).
+					WithArgs(1, 5, 7).
+					WillReturnRows(sqlmock.NewRows(append(secretColumns, "octet_length")).
+						AddRow(1, 1, "Test Secret", nil, "text", nil, nil, time.Now(), time.Now(), nil, 2, nil, nil, nil, 7))
+				mock.ExpectQuery(`SELECT id, change_seq, deleted_at FROM secrets`).
+					WithArgs(1, 5, 7).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "change_seq", "deleted_at"}))
+				mock.ExpectRollback()
+
+				changes, err := repo.ListChanges(ctx, 1, 5, true, 100)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(changes.Secrets) != 1 || changes.Secrets[0].Payload != nil || changes.Secrets[0].DataKey != nil ||
+					changes.Secrets[0].PayloadSize != 7 {
+					t.Errorf("Unexpected secrets: %+v", changes.Secrets)
+				}
+			},
+		},
+		{
+			name: "ListChanges_NoChanges",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT change_seq FROM users WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"change_seq"}).AddRow(5))
+				mock.ExpectQuery(`SELECT change_seq FROM \(`).
+					WithArgs(1, 5, 99).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`FROM secrets WHERE user_id = \$1 AND change_seq > \$2 AND change_seq <= \$3 AND deleted_at IS NULL`).
+					WithArgs(1, 5, 5).
+					WillReturnRows(sqlmock.NewRows(secretColumns))
+				mock.ExpectQuery(`SELECT id, change_seq, deleted_at FROM secrets`).
+					WithArgs(1, 5, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "change_seq", "deleted_at"}))
+				mock.ExpectRollback()
+
+				changes, err := repo.ListChanges(ctx, 1, 5, false, 100)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if changes.Revision != 5 || len(changes.Secrets) != 0 || len(changes.Tombstones) != 0 {
+					t.Errorf("Unexpected changes: %+v", changes)
+				}
+			},
+		},
+		{
+			name: "ListChanges_Paged",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT change_seq FROM users WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"change_seq"}).AddRow(9))
+				mock.ExpectQuery(`SELECT change_seq FROM \(.+\) AS changes ORDER BY change_seq LIMIT 1 OFFSET \$3`).
+					WithArgs(1, 5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"change_seq"}).AddRow(7))
+				mock.ExpectQuery(`FROM secrets WHERE user_id = \$1 AND change_seq > \$2 AND change_seq <= \$3 AND deleted_at IS NULL`).
+					WithArgs(1, 5, 7).
+					WillReturnRows(sqlmock.NewRows(secretColumns).
+						AddRow(1, 1, "Test Secret", nil, "text", nil, nil, time.Now(), time.Now(), nil, 6, nil, nil, nil))
+				mock.ExpectQuery(`SELECT id, change_seq, deleted_at FROM secrets`).
+					WithArgs(1, 5, 7).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "change_seq", "deleted_at"}).AddRow(2, 7, time.Now()))
+				mock.ExpectRollback()
+
+				changes, err := repo.ListChanges(ctx, 1, 5, false, 2)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if changes.Revision != 7 || !changes.More || len(changes.Secrets) != 1 || len(changes.Tombstones) != 1 {
+					t.Errorf("Unexpected changes: %+v", changes)
+				}
+			},
+		},
+		{
+			name: "ListChanges_Fail_UserNotFound",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT change_seq FROM users WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+
+				if _, err := repo.ListChanges(ctx, 1, 0, false, 100); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewSecretRepository(db, nil, &Config{})
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_List(t *testing.T) {
+	ctx := context.Background()
+	secretColumns := []string{"id", "user_id", "title", "metadata", "secret_type", "payload", "data_key", "created_at", "updated_at", "blob_ref", "revision", "expires_at", "folder_id", "tags"}
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "List_FirstPage",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, payload, data_key, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL ORDER BY updated_at DESC, id DESC LIMIT \$2`).
+					WithArgs(1, 3).
+					WillReturnRows(sqlmock.NewRows(secretColumns).
+						AddRow(3, 1, "Third", nil, "text", []byte("3"), nil, updatedAt, updatedAt, nil, 1, nil, nil, nil).
+						AddRow(2, 1, "Second", nil, "text", []byte("2"), nil, updatedAt, updatedAt, nil, 1, nil, nil, nil).
+						AddRow(1, 1, "First", nil, "text", []byte("1"), nil, updatedAt, updatedAt, nil, 1, nil, nil, nil))
+
+				page, err := repo.List(ctx, 1, &domain.SecretQuery{Sort: domain.SortByUpdatedAt, Descending: true, Limit: 2})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(page.Secrets) != 2 || page.Secrets[1].ID != 2 || page.Secrets[1].PayloadSize != 1 {
+					t.Errorf("Unexpected secrets: %+v", page.Secrets)
+				}
+				expected := &domain.SecretCursor{Sort: domain.SortByUpdatedAt, Descending: true, Time: updatedAt, ID: 2}
+				if page.Next == nil || *page.Next != *expected {
+					t.Errorf("Expected next cursor %+v, got %+v", expected, page.Next)
+				}
+			},
+		},
+		{
+			name: "List_LastPage_Filtered",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				createdFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL AND starts_with\(title, \$2\)\)`).
+					WithArgs(1, domain.SealedFieldPrefix).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(`SELECT id, user_id, title, metadata, secret_type, NULL::bytea, NULL::bytea, created_at, updated_at, blob_ref, revision, expires_at, folder_id, tags, octet_length\(payload\) FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL AND tags @> \$2::text\[\] AND secret_type IN \(\$3, \$4\) AND created_at >= \$5 AND \(title, id\) > \(\$6, \$7\) ORDER BY title ASC, id ASC LIMIT \$8`).
+					WithArgs(1, pq.StringArray{"prod"}, "text", "card", createdFrom, "Second", 2, 11).
+					WillReturnRows(sqlmock.NewRows(append(secretColumns, "octet_length")).
+						AddRow(3, 1, "Third", nil, "text", nil, nil, updatedAt, updatedAt, nil, 1, nil, nil, nil, 42))
+
+				page, err := repo.List(ctx, 1, &domain.SecretQuery{
+					Types:        []domain.SecretType{domain.TextSecret, domain.CardSecret},
+					Tags:         []string{"prod"},
+					CreatedFrom:  createdFrom,
+					Sort:         domain.SortByTitle,
+					Limit:        10,
+					After:        &domain.SecretCursor{Sort: domain.SortByTitle, Title: "Second", ID: 2},
+					MetadataOnly: true,
+				})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(page.Secrets) != 1 || page.Secrets[0].Payload != nil || page.Secrets[0].PayloadSize != 42 {
+					t.Errorf("Unexpected secrets: %+v", page.Secrets)
+				}
+				if page.Next != nil {
+					t.Errorf("Expected no next cursor, got %+v", page.Next)
+				}
+			},
+		},
+		{
+			name: "List_SearchTokens",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				tokens := [][]byte{[]byte("bank"), []byte("mail")}
+				mock.ExpectQuery(`SELECT .+ FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL AND search_tokens @> \$2::bytea\[\] ORDER BY updated_at DESC, id DESC LIMIT \$3`).
+					WithArgs(1, pq.ByteaArray(tokens), 11).
+					WillReturnRows(sqlmock.NewRows(append(secretColumns, "octet_length")).
+						AddRow(3, 1, "Bank", nil, "text", nil, nil, updatedAt, updatedAt, nil, 1, nil, nil, nil, 42))
+
+				page, err := repo.List(ctx, 1, &domain.SecretQuery{
+					SearchTokens: tokens,
+					Sort:         domain.SortByUpdatedAt,
+					Descending:   true,
+					Limit:        10,
+					MetadataOnly: true,
+				})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(page.Secrets) != 1 || page.Secrets[0].Title != "Bank" {
+					t.Errorf("Unexpected secrets: %+v", page.Secrets)
+				}
+			},
+		},
+		{
+			name: "List_Fail_SortByTitle_Sealed",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL AND starts_with\(title, \$2\)\)`).
+					WithArgs(1, domain.SealedFieldPrefix).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+				if _, err := repo.List(ctx, 1, &domain.SecretQuery{Sort: domain.SortByTitle, Limit: 10}); !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidQuery, err)
+				}
+			},
+		},
+		{
+			name: "List_Fail_Tags_Sealed",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM secrets WHERE user_id = \$1 AND deleted_at IS NULL AND starts_with\(title, \$2\)\)`).
+					WithArgs(1, domain.SealedFieldPrefix).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+				_, err := repo.List(ctx, 1, &domain.SecretQuery{Sort: domain.SortByUpdatedAt, Tags: []string{"prod"}, Limit: 10})
+				if !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidQuery, err)
+				}
+			},
+		},
+		{
+			name: "List_Fail_UnknownSort",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				if _, err := repo.List(ctx, 1, &domain.SecretQuery{Sort: "payload", Limit: 10}); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewSecretRepository(db, nil, &Config{})
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_Quota(t *testing.T) {
+	ctx := context.Background()
+	quota := domain.Quota{MaxSecrets: 2, MaxBytes: 20}
+
+	// expectUsage ожидает запросы места, занятого секретами: live байт данных самих секретов и versions байт редакций
+	expectUsage := func(mock sqlmock.Sqlmock, secrets, live, versions int) {
+		mock.ExpectQuery(`SELECT count\(\*\), coalesce\(sum\(octet_length\(payload\)\), 0\),\s+\(SELECT coalesce\(sum\(octet_length\(payload\)\), 0\) FROM secret_versions WHERE user_id = \$1\)\s+FROM secrets WHERE user_id = \$1`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "versions"}).AddRow(secrets, live, versions))
+		mock.ExpectQuery(`SELECT coalesce\(sum\(size\), 0\), coalesce\(sum\(size\) FILTER \(WHERE live\), 0\)`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"size", "live", "legacy", "legacy_live"}).AddRow(0, 0, "{}", "{}"))
+	}
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "Create_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT 1 FROM users WHERE id = \$1 FOR UPDATE`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectUsage(mock, 1, 13, 0)
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(1, 1, nil))
+				expectUsage(mock, 2, 20, 0)
+				mock.ExpectCommit()
+
+				if _, err := repo.Create(ctx, &domain.Secret{UserID: 1, SecretType: "text", Payload: []byte("payload")}); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+		},
+		{
+			name: "Create_Fail_TooManySecrets",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT 1 FROM users WHERE id = \$1 FOR UPDATE`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectUsage(mock, 2, 0, 0)
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(3, 1, nil))
+				expectUsage(mock, 3, 7, 0)
+				mock.ExpectRollback()
+
+				_, err := repo.Create(ctx, &domain.Secret{UserID: 1, SecretType: "text", Payload: []byte("payload")})
+				if !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+		},
+		{
+			name: "Update_Fail_VersionsExceedBytes",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT 1 FROM users WHERE id = \$1 FOR UPDATE`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectUsage(mock, 1, 5, 0)
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 1))
+				mock.ExpectExec(`INSERT INTO secret_versions`).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`DELETE FROM secret_versions`).WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectQuery(`UPDATE secrets SET`).WillReturnRows(sqlmock.NewRows([]string{"folder_id"}).AddRow(nil))
+				// Прежние данные перенесены в историю и учитываются вместе с новыми, которые больше прежних
+				expectUsage(mock, 1, 16, 5)
+				mock.ExpectRollback()
+
+				_, err := repo.Update(ctx, &domain.Secret{ID: 5, UserID: 1, SecretType: "text", Payload: []byte("payload"), Revision: 1})
+				if !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+		},
+		{
+			name: "Update_Success_OverQuotaShrink",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT 1 FROM users WHERE id = \$1 FOR UPDATE`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectUsage(mock, 1, 25, 0)
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(5, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 1))
+				mock.ExpectExec(`INSERT INTO secret_versions`).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`DELETE FROM secret_versions`).WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectQuery(`UPDATE secrets SET`).WillReturnRows(sqlmock.NewRows([]string{"folder_id"}).AddRow(nil))
+				// Хранилище по-прежнему превышает ограничения, но новые данные меньше прежних
+				expectUsage(mock, 1, 7, 25)
+				mock.ExpectCommit()
+
+				_, err := repo.Update(ctx, &domain.Secret{ID: 5, UserID: 1, SecretType: "text", Payload: []byte("payload"), Revision: 1})
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+		},
+		{
+			name: "BatchSave_Fail_RolledBack",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT 1 FROM users WHERE id = \$1 FOR UPDATE`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectUsage(mock, 1, 23, 0)
+				mock.ExpectExec(`SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(10, 1, nil))
+				mock.ExpectExec(`RELEASE SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				expectUsage(mock, 2, 30, 0)
+				mock.ExpectRollback()
+
+				_, err := repo.BatchSave(ctx, []*domain.Secret{{UserID: 1, SecretType: "text", Payload: []byte("payload")}}, false)
+				if !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			repo := NewSecretRepository(db, nil, &Config{Versions: 5, Quota: quota})
+
+			tc.testFunc(t, repo, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretRepository_Batch(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock)
+	}{
+		{
+			name: "BatchSave_PartialFailure",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WithArgs(1, "New", "", "text", []byte("new"), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 0, "{}", "{}", nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(10, 1, nil))
+				mock.ExpectExec(`RELEASE SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref", "revision"}).AddRow(nil, 5))
+				mock.ExpectExec(`ROLLBACK TO SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`RELEASE SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+
+				secrets := []*domain.Secret{
+					{UserID: 1, Title: "New", SecretType: "text", Payload: []byte("new")},
+					{ID: 2, UserID: 1, Title: "Stale", SecretType: "text", Payload: []byte("stale"), Revision: 4},
+				}
+				results, err := repo.BatchSave(ctx, secrets, false)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if results[0] != nil || secrets[0].ID != 10 || secrets[0].Revision != 1 {
+					t.Errorf("Expected first secret to be created, got %v, %+v", results[0], secrets[0])
+				}
+				if !errors.Is(results[1], ErrRevisionConflict) {
+					t.Errorf("Expected error %v, got %v", ErrRevisionConflict, results[1])
+				}
+			},
+		},
+		{
+			name: "BatchSave_Atomic_RolledBack",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO secrets`).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "revision", "folder_id"}).AddRow(10, 1, nil))
+				mock.ExpectQuery(`SELECT blob_ref, revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(2, 1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+
+				secrets := []*domain.Secret{
+					{UserID: 1, Title: "New", SecretType: "text", Payload: []byte("new")},
+					{ID: 2, UserID: 1, Title: "Missing", SecretType: "text", Payload: []byte("missing")},
+					{ID: 3, UserID: 1, Title: "Skipped", SecretType: "text", Payload: []byte("skipped")},
+				}
+				results, err := repo.BatchSave(ctx, secrets, true)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !errors.Is(results[0], ErrBatchAborted) || !errors.Is(results[1], storageErrors.ErrNotFound) ||
+					!errors.Is(results[2], ErrBatchAborted) {
+					t.Errorf("Unexpected results: %v", results)
+				}
+			},
+		},
+		{
+			name: "BatchDelete_Success",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE secrets SET deleted_at = \$1 WHERE id = \$2 AND user_id = \$3 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE secrets SET deleted_at = \$1 WHERE id = \$2 AND user_id = \$3 AND deleted_at IS NULL`).
+					WithArgs(sqlmock.AnyArg(), 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				results, err := repo.BatchDelete(ctx, []uint64{1, 2}, 1, true)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if results[0] != nil || results[1] != nil {
+					t.Errorf("Unexpected results: %v", results)
+				}
+			},
+		},
+		{
+			name: "BatchDelete_Fail_Commit",
+			testFunc: func(t *testing.T, repo *Repository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`UPDATE secrets SET deleted_at`).
+					WithArgs(sqlmock.AnyArg(), 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`ROLLBACK TO SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`RELEASE SAVEPOINT batch_item`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit().WillReturnError(errors.New("commit error"))
+
+				if _, err := repo.BatchDelete(ctx, []uint64{1}, 1, false); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -162,7 +1471,7 @@ func TestSecretRepository(t *testing.T) {
 			}
 			defer db.Close()
 
-			repo := NewSecretRepository(db)
+			repo := NewSecretRepository(db, nil, &Config{})
 
 			tc.testFunc(t, repo, mock)
 
@@ -6,22 +6,69 @@ import (
 	"errors"
 	"fmt"
 	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrRevisionConflict определяет ошибку, возникающую, если секрет был изменен другим запросом
+// после того, как клиент получил его редакцию.
+var ErrRevisionConflict = errors.New("secret was changed concurrently")
+
+// ErrInvalidQuery определяет ошибку в параметрах получения списка секретов.
+var ErrInvalidQuery = errors.New("invalid secret query")
+
+// ErrBatchTooLarge определяет ошибку, возникающую, если пакет содержит больше MaxBatchSize секретов.
+var ErrBatchTooLarge = errors.New("batch is too large")
+
+// ErrFieldNotSealed определяет ошибку, возникающую, если клиент передал для замены открытого поля незашифрованное значение.
+var ErrFieldNotSealed = errors.New("field is not sealed")
+
+const (
+	// DefaultPageSize количество секретов на странице, если клиент его не указал
+	DefaultPageSize = 100
+	// MaxPageSize максимальное количество секретов на странице
+	MaxPageSize = 1000
+	// maxPageBytes суммарный размер данных секретов на странице, при котором страница завершается досрочно,
+	// чтобы ответ не превысил ограничение размера сообщения gRPC в 4 МиБ
+	maxPageBytes = 3 << 20
+	// MaxBatchSize максимальное количество секретов в одном пакете сохранения или удаления
+	MaxBatchSize = 500
+	// MaxBlobSize максимальный размер данных файлового секрета, если ограничение MaxPayloadSize не задано
+	MaxBlobSize = 1 << 30
 )
 
 type SecretRepository interface {
 	Create(ctx context.Context, secret *domain.Secret) (*domain.Secret, error)
 	GetAllByUserID(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error)
 	GetByID(ctx context.Context, id uint64, userID domain.UserID) (*domain.Secret, error)
+	OpenByID(ctx context.Context, id uint64, userID domain.UserID) (*domain.Secret, io.ReadCloser, error)
 	Update(ctx context.Context, secret *domain.Secret) (*domain.Secret, error)
+	UpdatePayload(ctx context.Context, secret *domain.Secret, payload io.Reader) error
 	Delete(ctx context.Context, id uint64, userID domain.UserID) error
+	BatchSave(ctx context.Context, secrets []*domain.Secret, atomic bool) ([]error, error)
+	BatchDelete(ctx context.Context, ids []uint64, userID domain.UserID, atomic bool) ([]error, error)
+	ListTrash(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error)
+	Restore(ctx context.Context, id uint64, userID domain.UserID) error
+	Purge(ctx context.Context, id uint64, userID domain.UserID) error
+	List(ctx context.Context, userID domain.UserID, query *domain.SecretQuery) (*domain.SecretPage, error)
+	ListChanges(ctx context.Context, userID domain.UserID, since uint64, metadataOnly bool, limit int) (*domain.SecretChanges, error)
+	ListVersions(ctx context.Context, secretID uint64, userID domain.UserID) ([]*domain.SecretVersion, error)
+	RestoreVersion(ctx context.Context, secretID, versionID uint64, userID domain.UserID) (*domain.Secret, error)
+	Usage(ctx context.Context, userID domain.UserID, exclude []uint64) (*domain.Usage, error)
+	ListPlaintextFields(ctx context.Context, userID domain.UserID, limit int) ([]*domain.SecretVersion, error)
+	SealPlaintextFields(ctx context.Context, userID domain.UserID, records []*domain.SecretVersion) (int64, error)
 }
 
 type Service struct {
 	repository SecretRepository
+	quota      domain.Quota
 }
 
-func NewSecretService(repository SecretRepository) *Service {
-	return &Service{repository: repository}
+// NewSecretService создает сервис секретов, не позволяющий пользователю превысить ограничения хранилища quota.
+func NewSecretService(repository SecretRepository, quota domain.Quota) *Service {
+	return &Service{repository: repository, quota: quota}
 }
 
 func (s *Service) Get(ctx context.Context, secretID uint64, userID domain.UserID) (*domain.Secret, error) {
@@ -49,7 +96,15 @@ func (s *Service) GetUserSecrets(ctx context.Context, userID domain.UserID) ([]*
 }
 
 func (s *Service) Add(ctx context.Context, secret *domain.Secret) (*domain.Secret, error) {
-	var err error
+	err := prepareSecrets(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkPayloadSize([]*domain.Secret{secret})
+	if err != nil {
+		return nil, err
+	}
 
 	secret, err = s.repository.Create(ctx, secret)
 	if err != nil {
@@ -59,18 +114,27 @@ func (s *Service) Add(ctx context.Context, secret *domain.Secret) (*domain.Secre
 }
 
 func (s *Service) Update(ctx context.Context, secret *domain.Secret) (*domain.Secret, error) {
-	var err error
+	if err := prepareSecrets(secret); err != nil {
+		return nil, err
+	}
 
-	secret, err = s.repository.Update(ctx, secret)
+	if err := s.checkPayloadSize([]*domain.Secret{secret}); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repository.Update(ctx, secret)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("secret not found id %d", secret.ID)
 		}
+		if errors.Is(err, ErrRevisionConflict) || errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, err
+		}
 
-		return nil, fmt.Errorf("failed to update secret: %d", err)
+		return nil, fmt.Errorf("failed to update secret: %w", err)
 	}
 
-	return secret, nil
+	return updated, nil
 }
 
 func (s *Service) Delete(ctx context.Context, secretID uint64, userID domain.UserID) error {
@@ -84,3 +148,339 @@ func (s *Service) Delete(ctx context.Context, secretID uint64, userID domain.Use
 
 	return nil
 }
+
+// BatchSave создает и обновляет секреты одной транзакцией и возвращает ошибки секретов в порядке их передачи.
+// Если atomic равен true, ошибка одного секрета отменяет сохранение всех остальных.
+// Пакет, превышающий ограничения хранилища пользователя, не сохраняется целиком.
+func (s *Service) BatchSave(ctx context.Context, secrets []*domain.Secret, atomic bool) ([]error, error) {
+	if len(secrets) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: %d secrets, at most %d allowed", ErrBatchTooLarge, len(secrets), MaxBatchSize)
+	}
+	if len(secrets) == 0 {
+		return []error{}, nil
+	}
+
+	if err := prepareSecrets(secrets...); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPayloadSize(secrets); err != nil {
+		return nil, err
+	}
+
+	results, err := s.repository.BatchSave(ctx, secrets, atomic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save secrets: %w", err)
+	}
+
+	return results, nil
+}
+
+// BatchDelete перемещает секреты пользователя в корзину одной транзакцией и возвращает ошибки секретов в порядке их передачи.
+// Если atomic равен true, ошибка одного секрета отменяет удаление всех остальных.
+func (s *Service) BatchDelete(ctx context.Context, ids []uint64, userID domain.UserID, atomic bool) ([]error, error) {
+	if len(ids) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: %d secrets, at most %d allowed", ErrBatchTooLarge, len(ids), MaxBatchSize)
+	}
+	if len(ids) == 0 {
+		return []error{}, nil
+	}
+
+	results, err := s.repository.BatchDelete(ctx, ids, userID, atomic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete secrets: %w", err)
+	}
+
+	return results, nil
+}
+
+// ListTrash возвращает секреты пользователя, перемещенные в корзину.
+func (s *Service) ListTrash(ctx context.Context, userID domain.UserID) ([]*domain.Secret, error) {
+	secrets, err := s.repository.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// List возвращает страницу секретов пользователя, выбранных и отсортированных по query.
+// Без ключа сортировки секреты сортируются по времени обновления, размер страницы ограничивается MaxPageSize.
+// Позиция query.After должна быть получена для того же ключа и направления сортировки.
+func (s *Service) List(ctx context.Context, userID domain.UserID, query *domain.SecretQuery) (*domain.SecretPage, error) {
+	if query.Sort == "" {
+		query.Sort = domain.SortByUpdatedAt
+	}
+	switch query.Sort {
+	case domain.SortByUpdatedAt, domain.SortByCreatedAt, domain.SortByTitle:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort key %q", ErrInvalidQuery, query.Sort)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+	query.Limit = min(query.Limit, MaxPageSize)
+
+	tags, err := domain.NormalizeTags(query.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	query.Tags = tags
+
+	if err = domain.CheckSearchTokens(query.SearchTokens); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+
+	if after := query.After; after != nil && (after.Sort != query.Sort || after.Descending != query.Descending) {
+		return nil, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidQuery)
+	}
+
+	page, err := s.repository.List(ctx, userID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	// Страница с данными секретов завершается досрочно, но всегда содержит хотя бы один секрет
+	var size int64
+	for i, secret := range page.Secrets {
+		size += secret.PayloadSize
+		if query.MetadataOnly || size <= maxPageBytes || i == 0 {
+			continue
+		}
+
+		page.Secrets = page.Secrets[:i]
+		page.Next = query.CursorAt(page.Secrets[i-1])
+		break
+	}
+
+	return page, nil
+}
+
+// Search возвращает страницу секретов пользователя, у которых есть все токены слепого индекса query.SearchTokens.
+// Секреты возвращаются без данных и сортируются по времени обновления, начиная с последних измененных.
+func (s *Service) Search(ctx context.Context, userID domain.UserID, query *domain.SecretQuery) (*domain.SecretPage, error) {
+	if len(query.SearchTokens) == 0 {
+		return nil, fmt.Errorf("%w: no search tokens", ErrInvalidQuery)
+	}
+
+	query.Sort = domain.SortByUpdatedAt
+	query.Descending = true
+	query.MetadataOnly = true
+
+	return s.List(ctx, userID, query)
+}
+
+// ListChanges возвращает изменения секретов пользователя, сделанные после изменения с номером since.
+// Если metadataOnly равен true, секреты возвращаются без данных, только с их размером.
+// Изменения возвращаются страницами не больше limit изменений, размер страницы ограничивается MaxPageSize.
+func (s *Service) ListChanges(ctx context.Context, userID domain.UserID, since uint64, metadataOnly bool, limit int) (*domain.SecretChanges, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	changes, err := s.repository.ListChanges(ctx, userID, since, metadataOnly, min(limit, MaxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// Restore возвращает секрет из корзины.
+func (s *Service) Restore(ctx context.Context, secretID uint64, userID domain.UserID) error {
+	err := s.repository.Restore(ctx, secretID, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to restore secret: %w", err)
+	}
+
+	return nil
+}
+
+// Purge окончательно удаляет секрет из корзины.
+func (s *Service) Purge(ctx context.Context, secretID uint64, userID domain.UserID) error {
+	err := s.repository.Purge(ctx, secretID, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to purge secret: %w", err)
+	}
+
+	return nil
+}
+
+// SaveBlob сохраняет зашифрованные данные файлового секрета, получаемые потоком.
+// Данные передаются в хранилище по мере получения, не накапливаясь в памяти, и заменяются только после получения
+// всего потока, поэтому при обрыве передачи остаются прежние. Поток больше MaxPayloadSize, а если оно не задано -
+// больше MaxBlobSize, не дочитывается и отклоняется с ошибкой ErrQuotaExceeded.
+func (s *Service) SaveBlob(ctx context.Context, secret *domain.Secret, payload io.Reader) (int64, error) {
+	limit := s.quota.MaxPayloadSize
+	if limit == 0 {
+		limit = MaxBlobSize
+	}
+
+	secret.UpdatedAt = time.Now()
+
+	err := s.repository.UpdatePayload(ctx, secret, &limitedReader{reader: payload, limit: limit, remaining: limit})
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to save blob: %w", err)
+	}
+
+	return secret.PayloadSize, nil
+}
+
+// OpenBlob возвращает файловый секрет и поток его зашифрованных данных.
+func (s *Service) OpenBlob(ctx context.Context, secretID uint64, userID domain.UserID) (*domain.Secret, io.ReadCloser, error) {
+	secret, payload, err := s.repository.OpenByID(ctx, secretID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if secret.SecretType != string(domain.BlobSecret) {
+		_ = payload.Close()
+		return nil, nil, storageErrors.ErrNotFound
+	}
+
+	return secret, payload, nil
+}
+
+// ListVersions возвращает сохраненные редакции секрета, начиная с последней.
+func (s *Service) ListVersions(ctx context.Context, secretID uint64, userID domain.UserID) ([]*domain.SecretVersion, error) {
+	versions, err := s.repository.ListVersions(ctx, secretID, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to list secret versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion восстанавливает секрет из сохраненной редакции и возвращает восстановленный секрет.
+func (s *Service) RestoreVersion(ctx context.Context, secretID, versionID uint64, userID domain.UserID) (*domain.Secret, error) {
+	secret, err := s.repository.RestoreVersion(ctx, secretID, versionID, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to restore secret version: %w", err)
+	}
+
+	return secret, nil
+}
+
+// ListPlaintextFields возвращает не более MaxBatchSize редакций секретов пользователя и секретов в корзине,
+// поля которых сохранены открытым текстом. Секрет в корзине возвращается как редакция с номером 0.
+func (s *Service) ListPlaintextFields(ctx context.Context, userID domain.UserID) ([]*domain.SecretVersion, error) {
+	records, err := s.repository.ListPlaintextFields(ctx, userID, MaxBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plaintext fields: %w", err)
+	}
+
+	return records, nil
+}
+
+// SealPlaintextFields заменяет открытые заголовки и метаданные редакций и секретов в корзине значениями, зашифрованными клиентом.
+// Принимаются только зашифрованные значения, поэтому замена не может вернуть поля в открытый вид.
+// Возвращает количество измененных записей.
+func (s *Service) SealPlaintextFields(ctx context.Context, userID domain.UserID, records []*domain.SecretVersion) (int64, error) {
+	if len(records) > MaxBatchSize {
+		return 0, fmt.Errorf("%w: %d records, at most %d allowed", ErrBatchTooLarge, len(records), MaxBatchSize)
+	}
+	for _, record := range records {
+		if record.Secret == nil {
+			return 0, fmt.Errorf("%w: record %d has no fields", ErrFieldNotSealed, record.ID)
+		}
+		if !strings.HasPrefix(record.Secret.Title, domain.SealedFieldPrefix) ||
+			!strings.HasPrefix(record.Secret.Metadata, domain.SealedFieldPrefix) || len(record.Secret.Tags) > 0 {
+			return 0, fmt.Errorf("%w: record %d of secret %d", ErrFieldNotSealed, record.ID, record.Secret.ID)
+		}
+	}
+
+	sealed, err := s.repository.SealPlaintextFields(ctx, userID, records)
+	if err != nil {
+		return 0, fmt.Errorf("failed to seal plaintext fields: %w", err)
+	}
+
+	return sealed, nil
+}
+
+// GetUsage возвращает место, занятое секретами пользователя, и ограничения его хранилища.
+func (s *Service) GetUsage(ctx context.Context, userID domain.UserID) (*domain.Usage, error) {
+	usage, err := s.repository.Usage(ctx, userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	usage.Quota = s.quota
+
+	return usage, nil
+}
+
+// prepareSecrets приводит теги секретов к каноническому виду domain.NormalizeTags и проверяет токены поиска.
+// Возвращает ошибку domain.ErrInvalidTag или domain.ErrInvalidSearchTokens, если хотя бы один секрет недопустим.
+func prepareSecrets(secrets ...*domain.Secret) error {
+	for _, secret := range secrets {
+		tags, err := domain.NormalizeTags(secret.Tags)
+		if err != nil {
+			return err
+		}
+		secret.Tags = tags
+
+		if err = domain.CheckSearchTokens(secret.SearchTokens); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkPayloadSize проверяет, что данные секретов не превышают допустимый размер данных одного секрета.
+// Количество и суммарный размер секретов проверяются репозиторием в транзакции сохранения.
+func (s *Service) checkPayloadSize(secrets []*domain.Secret) error {
+	if s.quota.MaxPayloadSize == 0 {
+		return nil
+	}
+
+	for _, secret := range secrets {
+		if size := int64(len(secret.Payload)); size > s.quota.MaxPayloadSize {
+			return fmt.Errorf("%w: secret payload of %d bytes exceeds %d bytes", storageErrors.ErrQuotaExceeded, size, s.quota.MaxPayloadSize)
+		}
+	}
+
+	return nil
+}
+
+// limitedReader читает данные из reader и возвращает ErrQuotaExceeded, как только их окажется больше limit байт.
+// В отличие от io.LimitReader, превышение размера не выглядит как конец данных, поэтому обрезанный поток не будет сохранен.
+type limitedReader struct {
+	reader    io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.reader.Read(p)
+	if int64(n) > l.remaining {
+		n, l.remaining = int(l.remaining), 0
+		return n, fmt.Errorf("%w: secret payload exceeds %d bytes", storageErrors.ErrQuotaExceeded, l.limit)
+	}
+	l.remaining -= int64(n)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, fmt.Errorf("failed to receive blob: %w", err)
+	}
+
+	return n, err
+}
@@ -7,8 +7,13 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 	"github.com/romanp1989/gophkeeper/tests/mocks"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func TestSecretService(t *testing.T) {
@@ -16,10 +21,10 @@ func TestSecretService(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockISecretRepository(ctrl)
-	service := NewSecretService(mockRepo)
+	service := NewSecretService(mockRepo, domain.Quota{})
 
 	ctx := context.Background()
-	testSecret := domain.Secret{
+	testSecret := &domain.Secret{
 		ID:         1,
 		Title:      "Test Secret",
 		UserID:     1,
@@ -34,11 +39,11 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Get_Success",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().GetByID(ctx, uint64(1), uint64(1)).Return(testSecret, nil)
+				mockRepo.EXPECT().GetByID(ctx, uint64(1), domain.UserID(1)).Return(testSecret, nil)
 
 				secret, err := service.Get(ctx, 1, 1)
 				if err != nil {
-					t.Errorf("Expected no error, got %v", err)
+					t.Fatalf("Expected no error, got %v", err)
 				}
 				if secret.ID != testSecret.ID {
 					t.Errorf("Expected secret ID %v, got %v", testSecret.ID, secret.ID)
@@ -49,11 +54,11 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Get_Fail_NotFound",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().GetByID(ctx, uint64(1), uint64(1)).Return(nil, sql.ErrNoRows)
+				mockRepo.EXPECT().GetByID(ctx, uint64(1), domain.UserID(1)).Return(nil, sql.ErrNoRows)
 
 				_, err := service.Get(ctx, 1, 1)
-				if err == nil || err.Error() != "secret not found (id=1)" {
-					t.Errorf("Expected error 'secret not found (id=1)', got %v", err)
+				if err == nil || err.Error() != "secret not found id 1" {
+					t.Errorf("Expected error 'secret not found id 1', got %v", err)
 				}
 			},
 			expectErr: true,
@@ -61,7 +66,7 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Get_Fail",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().GetByID(ctx, uint64(1), uint64(1)).Return(nil, errors.New("some error"))
+				mockRepo.EXPECT().GetByID(ctx, uint64(1), domain.UserID(1)).Return(nil, errors.New("some error"))
 
 				_, err := service.Get(ctx, 1, 1)
 				if err == nil || err.Error() != "some error" {
@@ -73,8 +78,7 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "GetAllByUserID_Success",
 			testFunc: func(t *testing.T) {
-				secrets := []domain.Secret{testSecret}
-				mockRepo.EXPECT().GetAllByUserID(ctx, uint64(1)).Return(secrets, nil)
+				mockRepo.EXPECT().GetAllByUserID(ctx, domain.UserID(1)).Return([]*domain.Secret{testSecret}, nil)
 
 				result, err := service.GetUserSecrets(ctx, 1)
 				if err != nil {
@@ -87,13 +91,13 @@ func TestSecretService(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			name: "GetAllByUserID_Fail_EmptyList",
+			name: "GetAllByUserID_Fail_NotFound",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().GetAllByUserID(ctx, uint64(1)).Return(nil, nil)
+				mockRepo.EXPECT().GetAllByUserID(ctx, domain.UserID(1)).Return(nil, sql.ErrNoRows)
 
 				_, err := service.GetUserSecrets(ctx, 1)
-				if err == nil || err.Error() != "no secrets found" {
-					t.Errorf("Expected error 'no secrets found', got %v", err)
+				if err == nil || err.Error() != "user's secrets not found id 1" {
+					t.Errorf("Expected error 'user's secrets not found id 1', got %v", err)
 				}
 			},
 			expectErr: true,
@@ -101,7 +105,7 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "GetAllByUserID_Fail",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().GetAllByUserID(ctx, uint64(1)).Return(nil, errors.New("some error"))
+				mockRepo.EXPECT().GetAllByUserID(ctx, domain.UserID(1)).Return(nil, errors.New("some error"))
 
 				_, err := service.GetUserSecrets(ctx, 1)
 				if err == nil || err.Error() != "some error" {
@@ -113,11 +117,11 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Add_Success",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Create(ctx, &testSecret).Return(uint64(1), nil)
+				mockRepo.EXPECT().Create(ctx, testSecret).Return(testSecret, nil)
 
-				createdSecret, err := service.Add(ctx, &testSecret)
+				createdSecret, err := service.Add(ctx, testSecret)
 				if err != nil {
-					t.Errorf("Expected no error, got %v", err)
+					t.Fatalf("Expected no error, got %v", err)
 				}
 				if createdSecret.ID != 1 {
 					t.Errorf("Expected secret ID 1, got %v", createdSecret.ID)
@@ -125,12 +129,36 @@ func TestSecretService(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "Add_Fail_InvalidTag",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{UserID: 1, Title: "Tagged", Tags: []string{strings.Repeat("x", domain.MaxTagLength+1)}}
+
+				_, err := service.Add(ctx, secret)
+				if !errors.Is(err, domain.ErrInvalidTag) {
+					t.Errorf("Expected error %v, got %v", domain.ErrInvalidTag, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Add_Fail_InvalidSearchToken",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{UserID: 1, Title: "Indexed", SearchTokens: [][]byte{[]byte("short")}}
+
+				_, err := service.Add(ctx, secret)
+				if !errors.Is(err, domain.ErrInvalidSearchTokens) {
+					t.Errorf("Expected error %v, got %v", domain.ErrInvalidSearchTokens, err)
+				}
+			},
+			expectErr: true,
+		},
 		{
 			name: "Add_Fail",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Create(ctx, testSecret).Return(uint64(1), errors.New("some error"))
+				mockRepo.EXPECT().Create(ctx, testSecret).Return(nil, errors.New("some error"))
 
-				_, err := service.Add(ctx, &testSecret)
+				_, err := service.Add(ctx, testSecret)
 				if err == nil || err.Error() != "failed to create secret: some error" {
 					t.Errorf("Expected error 'failed to create secret: some error', got %v", err)
 				}
@@ -140,11 +168,11 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Update_Success",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Update(ctx, testSecret).Return(nil)
+				mockRepo.EXPECT().Update(ctx, testSecret).Return(testSecret, nil)
 
-				updatedSecret, err := service.Update(ctx, &testSecret)
+				updatedSecret, err := service.Update(ctx, testSecret)
 				if err != nil {
-					t.Errorf("Expected no error, got %v", err)
+					t.Fatalf("Expected no error, got %v", err)
 				}
 				if updatedSecret.ID != testSecret.ID {
 					t.Errorf("Expected secret ID %v, got %v", testSecret.ID, updatedSecret.ID)
@@ -155,11 +183,11 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Update_Fail_NotFound",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Update(ctx, testSecret).Return(sql.ErrNoRows)
+				mockRepo.EXPECT().Update(ctx, testSecret).Return(nil, sql.ErrNoRows)
 
-				_, err := service.Update(ctx, &testSecret)
-				if err == nil || err.Error() != "secret not found (id=1)" {
-					t.Errorf("Expected error 'secret not found (id=1)', got %v", err)
+				_, err := service.Update(ctx, testSecret)
+				if err == nil || err.Error() != "secret not found id 1" {
+					t.Errorf("Expected error 'secret not found id 1', got %v", err)
 				}
 			},
 			expectErr: true,
@@ -168,11 +196,11 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Update_Fail",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Update(ctx, testSecret).Return(errors.New("some error"))
+				mockRepo.EXPECT().Update(ctx, testSecret).Return(nil, errors.New("some error"))
 
-				_, err := service.Update(ctx, &testSecret)
-				if err == nil || err.Error() != "failed to store secret: some error" {
-					t.Errorf("Expected error 'failed to store secret: some error', got %v", err)
+				_, err := service.Update(ctx, testSecret)
+				if err == nil || err.Error() != "failed to update secret: some error" {
+					t.Errorf("Expected error 'failed to update secret: some error', got %v", err)
 				}
 			},
 			expectErr: true,
@@ -180,7 +208,7 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Delete_Success",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Delete(ctx, uint64(1), uint64(1)).Return(nil)
+				mockRepo.EXPECT().Delete(ctx, uint64(1), domain.UserID(1)).Return(nil)
 
 				err := service.Delete(ctx, 1, 1)
 				if err != nil {
@@ -192,11 +220,331 @@ func TestSecretService(t *testing.T) {
 		{
 			name: "Delete_Fail",
 			testFunc: func(t *testing.T) {
-				mockRepo.EXPECT().Delete(ctx, uint64(1), uint64(1)).Return(fmt.Errorf("delete failed"))
+				mockRepo.EXPECT().Delete(ctx, uint64(1), domain.UserID(1)).Return(fmt.Errorf("delete failed"))
 
 				err := service.Delete(ctx, 1, 1)
-				if err == nil || err.Error() != "delete failed" {
-					t.Errorf("Expected error 'delete failed', got %v", err)
+				if err == nil || err.Error() != "failed to delete secret: delete failed" {
+					t.Errorf("Expected error 'failed to delete secret: delete failed', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_Blob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "SaveBlob_Success",
+			testFunc: func(t *testing.T) {
+				blob := &domain.Secret{ID: 1, UserID: 1, SecretType: string(domain.BlobSecret), DataKey: []byte("key")}
+				mockRepo.EXPECT().UpdatePayload(ctx, blob, gomock.Any()).DoAndReturn(func(_ context.Context, secret *domain.Secret, payload io.Reader) error {
+					data, err := io.ReadAll(payload)
+					if err != nil || string(data) != "payload" {
+						t.Errorf("Expected payload to be streamed, got %q, %v", data, err)
+					}
+					secret.PayloadSize = int64(len(data))
+					return nil
+				})
+
+				size, err := service.SaveBlob(ctx, blob, strings.NewReader("payload"))
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if size != 7 {
+					t.Errorf("Expected payload of size 7, got %d", size)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "SaveBlob_Fail_Receive",
+			testFunc: func(t *testing.T) {
+				blob := &domain.Secret{ID: 1, UserID: 1}
+				streamErr := errors.New("stream broken")
+				mockRepo.EXPECT().UpdatePayload(ctx, blob, gomock.Any()).DoAndReturn(func(_ context.Context, _ *domain.Secret, payload io.Reader) error {
+					_, err := io.ReadAll(payload)
+					return err
+				})
+
+				_, err := service.SaveBlob(ctx, blob, iotest.ErrReader(streamErr))
+				if !errors.Is(err, streamErr) || !strings.Contains(err.Error(), "failed to receive blob") {
+					t.Errorf("Expected error 'failed to receive blob: stream broken', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "SaveBlob_Fail_TooLarge_WithoutQuota",
+			testFunc: func(t *testing.T) {
+				blob := &domain.Secret{ID: 1, UserID: 1}
+				mockRepo.EXPECT().UpdatePayload(ctx, blob, gomock.Any()).DoAndReturn(func(_ context.Context, _ *domain.Secret, payload io.Reader) error {
+					n, err := io.Copy(io.Discard, payload)
+					if n != MaxBlobSize {
+						t.Errorf("Expected reading to stop after %d bytes, got %d", MaxBlobSize, n)
+					}
+					return err
+				})
+
+				_, err := service.SaveBlob(ctx, blob, io.LimitReader(zeroReader{}, MaxBlobSize+100))
+				if !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "SaveBlob_Fail_NotFound",
+			testFunc: func(t *testing.T) {
+				blob := &domain.Secret{ID: 1, UserID: 1}
+				mockRepo.EXPECT().UpdatePayload(ctx, blob, gomock.Any()).Return(storageErrors.ErrNotFound)
+
+				_, err := service.SaveBlob(ctx, blob, strings.NewReader("payload"))
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "OpenBlob_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().OpenByID(ctx, uint64(1), domain.UserID(1)).
+					Return(&domain.Secret{ID: 1, SecretType: string(domain.BlobSecret), PayloadSize: 7}, io.NopCloser(strings.NewReader("payload")), nil)
+
+				secret, payload, err := service.OpenBlob(ctx, 1, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				data, _ := io.ReadAll(payload)
+				if secret.PayloadSize != 7 || string(data) != "payload" {
+					t.Errorf("Expected payload of size 7, got %d", secret.PayloadSize)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "OpenBlob_Fail_NotBlob",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().OpenByID(ctx, uint64(1), domain.UserID(1)).
+					Return(&domain.Secret{ID: 1, SecretType: string(domain.TextSecret)}, io.NopCloser(strings.NewReader("text")), nil)
+
+				_, _, err := service.OpenBlob(ctx, 1, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_Versions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "ListVersions_Success",
+			testFunc: func(t *testing.T) {
+				versions := []*domain.SecretVersion{{ID: 2, Secret: &domain.Secret{ID: 1}}}
+				mockRepo.EXPECT().ListVersions(ctx, uint64(1), domain.UserID(1)).Return(versions, nil)
+
+				result, err := service.ListVersions(ctx, 1, 1)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if len(result) != 1 || result[0].ID != 2 {
+					t.Errorf("Unexpected versions: %+v", result)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "ListVersions_Fail",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().ListVersions(ctx, uint64(1), domain.UserID(1)).Return(nil, errors.New("database error"))
+
+				_, err := service.ListVersions(ctx, 1, 1)
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "RestoreVersion_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().RestoreVersion(ctx, uint64(1), uint64(2), domain.UserID(1)).Return(&domain.Secret{ID: 1, Title: "Old"}, nil)
+
+				secret, err := service.RestoreVersion(ctx, 1, 2, 1)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if secret.Title != "Old" {
+					t.Errorf("Unexpected secret: %+v", secret)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "RestoreVersion_Fail_NotFound",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().RestoreVersion(ctx, uint64(1), uint64(2), domain.UserID(1)).Return(nil, storageErrors.ErrNotFound)
+
+				_, err := service.RestoreVersion(ctx, 1, 2, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "ListTrash_Success",
+			testFunc: func(t *testing.T) {
+				secrets := []*domain.Secret{{ID: 1, DeletedAt: time.Now()}}
+				mockRepo.EXPECT().ListTrash(ctx, domain.UserID(1)).Return(secrets, nil)
+
+				result, err := service.ListTrash(ctx, 1)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if len(result) != 1 || result[0].ID != 1 {
+					t.Errorf("Unexpected trash: %+v", result)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Restore_Fail_NotFound",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Restore(ctx, uint64(1), domain.UserID(1)).Return(storageErrors.ErrNotFound)
+
+				err := service.Restore(ctx, 1, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Purge_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Purge(ctx, uint64(1), domain.UserID(1)).Return(nil)
+
+				if err := service.Purge(ctx, 1, 1); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Purge_Fail",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Purge(ctx, uint64(1), domain.UserID(1)).Return(errors.New("database error"))
+
+				if err := service.Purge(ctx, 1, 1); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "Update_Success",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{ID: 1, Revision: 1}
+				mockRepo.EXPECT().Update(ctx, secret).Return(&domain.Secret{ID: 1, Revision: 2}, nil)
+
+				result, err := service.Update(ctx, secret)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if result.Revision != 2 {
+					t.Errorf("Expected revision 2, got %d", result.Revision)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Update_Fail_RevisionConflict",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{ID: 1, Revision: 1}
+				mockRepo.EXPECT().Update(ctx, secret).Return(nil, ErrRevisionConflict)
+
+				_, err := service.Update(ctx, secret)
+				if !errors.Is(err, ErrRevisionConflict) {
+					t.Errorf("Expected error %v, got %v", ErrRevisionConflict, err)
 				}
 			},
 			expectErr: true,
@@ -207,3 +555,477 @@ func TestSecretService(t *testing.T) {
 		t.Run(tc.name, tc.testFunc)
 	}
 }
+
+func TestSecretService_ListChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "ListChanges_Success",
+			testFunc: func(t *testing.T) {
+				changes := &domain.SecretChanges{
+					Secrets:    []*domain.Secret{{ID: 1}},
+					Tombstones: []*domain.SecretTombstone{{ID: 2, Revision: 4}},
+					Revision:   4,
+				}
+				mockRepo.EXPECT().ListChanges(ctx, domain.UserID(1), uint64(3), true, DefaultPageSize).Return(changes, nil)
+
+				result, err := service.ListChanges(ctx, 1, 3, true, 0)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if result != changes {
+					t.Errorf("Unexpected changes: %+v", result)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "ListChanges_PageSizeLimited",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().ListChanges(ctx, domain.UserID(1), uint64(3), false, MaxPageSize).Return(&domain.SecretChanges{}, nil)
+
+				if _, err := service.ListChanges(ctx, 1, 3, false, MaxPageSize+1); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "ListChanges_Fail",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().ListChanges(ctx, domain.UserID(1), uint64(3), true, 10).Return(nil, errors.New("database error"))
+
+				if _, err := service.ListChanges(ctx, 1, 3, true, 10); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "List_Defaults",
+			testFunc: func(t *testing.T) {
+				page := &domain.SecretPage{Secrets: []*domain.Secret{{ID: 1}}}
+				mockRepo.EXPECT().List(ctx, domain.UserID(1), &domain.SecretQuery{Sort: domain.SortByUpdatedAt, Limit: DefaultPageSize}).Return(page, nil)
+
+				result, err := service.List(ctx, 1, &domain.SecretQuery{})
+				if err != nil || result != page {
+					t.Errorf("Unexpected result: %+v, %v", result, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "List_LimitClamped",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().List(ctx, domain.UserID(1), &domain.SecretQuery{Sort: domain.SortByTitle, Limit: MaxPageSize}).
+					Return(&domain.SecretPage{}, nil)
+
+				if _, err := service.List(ctx, 1, &domain.SecretQuery{Sort: domain.SortByTitle, Limit: MaxPageSize + 1}); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "List_PageBytesExceeded",
+			testFunc: func(t *testing.T) {
+				secrets := []*domain.Secret{
+					{ID: 3, Title: "c", PayloadSize: maxPageBytes - 1},
+					{ID: 2, Title: "b", PayloadSize: 1},
+					{ID: 1, Title: "a", PayloadSize: 1},
+				}
+				mockRepo.EXPECT().List(ctx, domain.UserID(1), gomock.Any()).Return(&domain.SecretPage{Secrets: secrets}, nil)
+
+				page, err := service.List(ctx, 1, &domain.SecretQuery{Sort: domain.SortByTitle, Descending: true})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				expected := &domain.SecretCursor{Sort: domain.SortByTitle, Descending: true, Title: "b", ID: 2}
+				if len(page.Secrets) != 2 || page.Next == nil || *page.Next != *expected {
+					t.Errorf("Unexpected page: %+v, next %+v", page.Secrets, page.Next)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "List_Fail_UnknownSort",
+			testFunc: func(t *testing.T) {
+				if _, err := service.List(ctx, 1, &domain.SecretQuery{Sort: "payload"}); !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidQuery, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "List_TagsNormalized",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().List(ctx, domain.UserID(1), &domain.SecretQuery{Sort: domain.SortByUpdatedAt, Limit: DefaultPageSize, Tags: []string{"bank", "prod"}}).
+					Return(&domain.SecretPage{}, nil)
+
+				if _, err := service.List(ctx, 1, &domain.SecretQuery{Tags: []string{" Prod", "bank", "prod", ""}}); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "List_Fail_InvalidTag",
+			testFunc: func(t *testing.T) {
+				_, err := service.List(ctx, 1, &domain.SecretQuery{Tags: []string{"two words"}})
+				if !errors.Is(err, ErrInvalidQuery) || !errors.Is(err, domain.ErrInvalidTag) {
+					t.Errorf("Expected errors %v and %v, got %v", ErrInvalidQuery, domain.ErrInvalidTag, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Search_Success",
+			testFunc: func(t *testing.T) {
+				tokens := [][]byte{make([]byte, domain.SearchTokenSize)}
+				mockRepo.EXPECT().List(ctx, domain.UserID(1), &domain.SecretQuery{
+					SearchTokens: tokens,
+					Sort:         domain.SortByUpdatedAt,
+					Descending:   true,
+					Limit:        DefaultPageSize,
+					MetadataOnly: true,
+				}).Return(&domain.SecretPage{Secrets: []*domain.Secret{{ID: 1}}}, nil)
+
+				page, err := service.Search(ctx, 1, &domain.SecretQuery{SearchTokens: tokens})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(page.Secrets) != 1 {
+					t.Errorf("Expected 1 secret, got %d", len(page.Secrets))
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Search_Fail_NoTokens",
+			testFunc: func(t *testing.T) {
+				if _, err := service.Search(ctx, 1, &domain.SecretQuery{}); !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidQuery, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Search_Fail_InvalidToken",
+			testFunc: func(t *testing.T) {
+				_, err := service.Search(ctx, 1, &domain.SecretQuery{SearchTokens: [][]byte{[]byte("short")}})
+				if !errors.Is(err, ErrInvalidQuery) || !errors.Is(err, domain.ErrInvalidSearchTokens) {
+					t.Errorf("Expected errors %v and %v, got %v", ErrInvalidQuery, domain.ErrInvalidSearchTokens, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "List_Fail_CursorMismatch",
+			testFunc: func(t *testing.T) {
+				query := &domain.SecretQuery{Sort: domain.SortByTitle, After: &domain.SecretCursor{Sort: domain.SortByUpdatedAt, ID: 1}}
+				if _, err := service.List(ctx, 1, query); !errors.Is(err, ErrInvalidQuery) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidQuery, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "List_Fail",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().List(ctx, domain.UserID(1), gomock.Any()).Return(nil, errors.New("database error"))
+
+				if _, err := service.List(ctx, 1, &domain.SecretQuery{}); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_Batch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	service := NewSecretService(mockRepo, domain.Quota{})
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "BatchSave_Success",
+			testFunc: func(t *testing.T) {
+				secrets := []*domain.Secret{{Title: "a"}, {ID: 2, Title: "b"}}
+				results := []error{nil, ErrRevisionConflict}
+				mockRepo.EXPECT().BatchSave(ctx, secrets, true).Return(results, nil)
+
+				got, err := service.BatchSave(ctx, secrets, true)
+				if err != nil || len(got) != 2 || got[1] != ErrRevisionConflict {
+					t.Errorf("Unexpected results: %v, %v", got, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "BatchSave_Empty",
+			testFunc: func(t *testing.T) {
+				got, err := service.BatchSave(ctx, nil, false)
+				if err != nil || len(got) != 0 {
+					t.Errorf("Unexpected results: %v, %v", got, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "BatchSave_Fail_TooLarge",
+			testFunc: func(t *testing.T) {
+				secrets := make([]*domain.Secret, MaxBatchSize+1)
+				if _, err := service.BatchSave(ctx, secrets, false); !errors.Is(err, ErrBatchTooLarge) {
+					t.Errorf("Expected error %v, got %v", ErrBatchTooLarge, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "BatchDelete_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().BatchDelete(ctx, []uint64{1, 2}, domain.UserID(1), false).Return([]error{nil, nil}, nil)
+
+				got, err := service.BatchDelete(ctx, []uint64{1, 2}, 1, false)
+				if err != nil || len(got) != 2 {
+					t.Errorf("Unexpected results: %v, %v", got, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "BatchDelete_Fail",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().BatchDelete(ctx, []uint64{1}, domain.UserID(1), false).Return(nil, errors.New("database error"))
+
+				if _, err := service.BatchDelete(ctx, []uint64{1}, 1, false); err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestSecretService_Quota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockISecretRepository(ctrl)
+	quota := domain.Quota{MaxSecrets: 3, MaxBytes: 20, MaxPayloadSize: 10}
+	service := NewSecretService(mockRepo, quota)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "Add_Success",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{UserID: 1, Payload: []byte("payload")}
+				mockRepo.EXPECT().Create(ctx, secret).Return(secret, nil)
+
+				if _, err := service.Add(ctx, secret); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Add_Fail_TooManySecrets",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{UserID: 1, Payload: []byte("payload")}
+				mockRepo.EXPECT().Create(ctx, secret).Return(nil, fmt.Errorf("%w: 4 secrets", storageErrors.ErrQuotaExceeded))
+
+				if _, err := service.Add(ctx, secret); !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Add_Fail_PayloadTooLarge",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{UserID: 1, Payload: []byte("large payload")}
+
+				if _, err := service.Add(ctx, secret); !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Update_Fail_TooManyBytes",
+			testFunc: func(t *testing.T) {
+				secret := &domain.Secret{ID: 5, UserID: 1, Payload: []byte("payload")}
+				mockRepo.EXPECT().Update(ctx, secret).Return(nil, fmt.Errorf("%w: 21 bytes", storageErrors.ErrQuotaExceeded))
+
+				if _, err := service.Update(ctx, secret); !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "BatchSave_Fail_PayloadTooLarge",
+			testFunc: func(t *testing.T) {
+				secrets := []*domain.Secret{{UserID: 1, Payload: []byte("payload")}, {ID: 5, UserID: 1, Payload: []byte("large payload")}}
+
+				if _, err := service.BatchSave(ctx, secrets, false); !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "SaveBlob_Fail_PayloadTooLarge",
+			testFunc: func(t *testing.T) {
+				blob := &domain.Secret{ID: 1, UserID: 1, SecretType: string(domain.BlobSecret)}
+				mockRepo.EXPECT().UpdatePayload(ctx, blob, gomock.Any()).DoAndReturn(func(_ context.Context, _ *domain.Secret, payload io.Reader) error {
+					data, err := io.ReadAll(payload)
+					if len(data) != 10 {
+						t.Errorf("Expected reading to stop after %d bytes, got %d", 10, len(data))
+					}
+					return err
+				})
+
+				if _, err := service.SaveBlob(ctx, blob, strings.NewReader(strings.Repeat("x", 100))); !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "GetUsage",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Usage(ctx, domain.UserID(1), nil).Return(&domain.Usage{Secrets: 2, Bytes: 13}, nil)
+
+				usage, err := service.GetUsage(ctx, 1)
+				if err != nil || usage.Secrets != 2 || usage.Bytes != 13 || usage.Quota != quota {
+					t.Errorf("Unexpected usage: %+v, %v", usage, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "ListPlaintextFields",
+			testFunc: func(t *testing.T) {
+				records := []*domain.SecretVersion{{ID: 7, Secret: &domain.Secret{ID: 2, Title: "title"}}}
+				mockRepo.EXPECT().ListPlaintextFields(ctx, domain.UserID(1), MaxBatchSize).Return(records, nil)
+
+				result, err := service.ListPlaintextFields(ctx, 1)
+				if err != nil || len(result) != 1 {
+					t.Errorf("Unexpected result: %v, %v", result, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "SealPlaintextFields",
+			testFunc: func(t *testing.T) {
+				records := []*domain.SecretVersion{
+					{ID: 0, Secret: &domain.Secret{ID: 1, Title: domain.SealedFieldPrefix + "a", Metadata: domain.SealedFieldPrefix + "b"}},
+					{ID: 7, Secret: &domain.Secret{ID: 2, Title: domain.SealedFieldPrefix + "c", Metadata: domain.SealedFieldPrefix + "d"}},
+				}
+				mockRepo.EXPECT().SealPlaintextFields(ctx, domain.UserID(1), records).Return(int64(2), nil)
+
+				sealed, err := service.SealPlaintextFields(ctx, 1, records)
+				if err != nil || sealed != 2 {
+					t.Errorf("Unexpected result: %d, %v", sealed, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "SealPlaintextFields_Fail_NotSealed",
+			testFunc: func(t *testing.T) {
+				for _, secret := range []*domain.Secret{
+					{ID: 2, Title: "title", Metadata: domain.SealedFieldPrefix + "d"},
+					{ID: 2, Title: domain.SealedFieldPrefix + "c", Metadata: ""},
+					{ID: 2, Title: domain.SealedFieldPrefix + "c", Metadata: domain.SealedFieldPrefix + "d", Tags: []string{"work"}},
+				} {
+					_, err := service.SealPlaintextFields(ctx, 1, []*domain.SecretVersion{{ID: 7, Secret: secret}})
+					if !errors.Is(err, ErrFieldNotSealed) {
+						t.Errorf("Expected error %v, got %v", ErrFieldNotSealed, err)
+					}
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "SealPlaintextFields_Fail_BatchTooLarge",
+			testFunc: func(t *testing.T) {
+				records := make([]*domain.SecretVersion, MaxBatchSize+1)
+				if _, err := service.SealPlaintextFields(ctx, 1, records); !errors.Is(err, ErrBatchTooLarge) {
+					t.Errorf("Expected error %v, got %v", ErrBatchTooLarge, err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+// zeroReader возвращает бесконечный поток нулевых байт
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,85 @@
+package secret
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BlobDeleter удаляет файлы из хранилища файлов
+type BlobDeleter interface {
+	Delete(ctx context.Context, ref string) error
+}
+
+// SaveVersion сохраняет текущее состояние секрета secretID в историю в транзакции tx и удаляет редакции сверх keep последних.
+// Секрет должен быть заблокирован в транзакции. Секрет без данных, например файловый секрет до завершения загрузки,
+// в историю не сохраняется. Возвращает ссылки на файлы удаленных редакций, которые освобождаются после фиксации транзакции.
+func SaveVersion(ctx context.Context, tx *sql.Tx, secretID uint64, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO secret_versions (secret_id, user_id, title, metadata, secret_type, payload, data_key, blob_ref, blob_size, created_at, tags, search_tokens)
+			SELECT id, user_id, title, metadata, secret_type, payload, data_key, blob_ref, blob_size, updated_at, tags, search_tokens FROM secrets
+			WHERE id = $1 AND (blob_ref IS NOT NULL OR length(payload) > 0)`,
+		secretID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`DELETE FROM secret_versions WHERE secret_id = $1 AND id NOT IN (
+			SELECT id FROM secret_versions WHERE secret_id = $1 ORDER BY id DESC LIMIT $2
+		) RETURNING blob_ref`,
+		secretID, keep,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRefs(rows)
+}
+
+// ReleaseBlobs удаляет файлы, на которые больше не ссылаются ни секреты, ни их редакции.
+// Ошибки не возвращаются: оставшийся файл будет найден проверкой согласованности хранилища.
+func ReleaseBlobs(ctx context.Context, db *sql.DB, blobs BlobDeleter, refs ...string) {
+	if blobs == nil {
+		return
+	}
+
+	for _, ref := range refs {
+		if ref == "" {
+			continue
+		}
+
+		var referenced bool
+		err := db.QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM secrets WHERE blob_ref = $1) OR EXISTS (SELECT 1 FROM secret_versions WHERE blob_ref = $1)",
+			ref,
+		).Scan(&referenced)
+		if err != nil || referenced {
+			continue
+		}
+
+		_ = blobs.Delete(ctx, ref)
+	}
+}
+
+// scanRefs читает непустые ссылки на файлы из результата запроса и закрывает его
+func scanRefs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var ref sql.NullString
+		if err := rows.Scan(&ref); err != nil {
+			return nil, err
+		}
+		if ref.Valid {
+			refs = append(refs, ref.String)
+		}
+	}
+
+	return refs, rows.Err()
+}
@@ -0,0 +1,9 @@
+package upload
+
+import "time"
+
+type Config struct {
+	Dir             string        // Dir каталог данных незавершенных загрузок
+	SessionTTL      time.Duration // SessionTTL время, после которого сессия загрузки без новых данных удаляется
+	CleanupInterval time.Duration // CleanupInterval интервал запуска удаления устаревших сессий
+}
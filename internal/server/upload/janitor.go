@@ -0,0 +1,50 @@
+package upload
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ExpiredRepository interface {
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Janitor периодически удаляет сессии загрузки, которые не получали данных дольше SessionTTL.
+// Клиент, не вернувшийся к загрузке за это время, начинает ее заново.
+type Janitor struct {
+	repository ExpiredRepository
+	config     *Config
+	logger     *zap.Logger
+}
+
+func NewJanitor(repository ExpiredRepository, config *Config, logger *zap.Logger) *Janitor {
+	return &Janitor{repository: repository, config: config, logger: logger}
+}
+
+// Run удаляет устаревшие сессии с интервалом CleanupInterval до отмены контекста.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := j.Clean(ctx, time.Now())
+			if err != nil {
+				j.logger.Error("failed to delete expired upload sessions", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				j.logger.Info("expired upload sessions deleted", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
+
+// Clean удаляет сессии, не получавшие данных дольше SessionTTL к моменту now, и возвращает их количество.
+func (j *Janitor) Clean(ctx context.Context, now time.Time) (int64, error) {
+	return j.repository.DeleteExpired(ctx, now.Add(-j.config.SessionTTL))
+}
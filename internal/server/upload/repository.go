@@ -0,0 +1,293 @@
+package upload
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/secret"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"time"
+)
+
+// BlobStore хранилище данных секретов вне PostgreSQL
+type BlobStore interface {
+	Put(ctx context.Context, r io.Reader) (ref string, size int64, err error)
+	Size(ctx context.Context, ref string) (int64, error)
+	Delete(ctx context.Context, ref string) error
+}
+
+// Spool хранилище данных незавершенных загрузок вне PostgreSQL
+type Spool interface {
+	WriteAt(id string, offset int64, chunk []byte) error
+	Open(id string) (io.ReadCloser, error)
+	RemoveOlder(before time.Time) error
+	Remove(id string) error
+}
+
+type Repository struct {
+	db     *sql.DB
+	blobs  BlobStore
+	spool  Spool
+	config *secret.Config
+}
+
+// NewUploadRepository создает репозиторий сессий загрузки. Полученные данные сессий хранятся в spool,
+// а при завершении загрузки больше config.BlobThreshold сохраняются в blobs.
+// Если blobs равен nil, принимаются только загрузки не больше config.BlobThreshold.
+func NewUploadRepository(db *sql.DB, blobs BlobStore, spool Spool, config *secret.Config) *Repository {
+	return &Repository{db: db, blobs: blobs, spool: spool, config: config}
+}
+
+// Create сохранение новой сессии загрузки.
+// Без хранилища файлов сессия больше порога не создается: ее данные пришлось бы сохранить в PostgreSQL.
+func (r *Repository) Create(ctx context.Context, session *domain.UploadSession) error {
+	if err := r.checkSize(session.Size); err != nil {
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO upload_sessions (id, user_id, secret_id, data_key, size, received, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		session.ID, session.UserID, session.SecretID, session.DataKey, session.Size, session.Received, session.CreatedAt, session.UpdatedAt,
+	)
+	return err
+}
+
+// GetByID получение сессии загрузки пользователя
+func (r *Repository) GetByID(ctx context.Context, id string, userID domain.UserID) (*domain.UploadSession, error) {
+	session := &domain.UploadSession{}
+
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, user_id, secret_id, data_key, size, received, created_at, updated_at FROM upload_sessions WHERE id = $1 AND user_id = $2",
+		id, userID,
+	).Scan(&session.ID, &session.UserID, &session.SecretID, &session.DataKey, &session.Size, &session.Received, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// AppendChunk сохранение части данных, начинающейся с позиции offset.
+// Часть принимается, только если offset совпадает с количеством уже полученных байт и не выходит за размер загрузки,
+// поэтому повторно отправленные или пропущенные данные не попадают в сессию. Часть записывается в spool,
+// пока строка сессии заблокирована обновлением, поэтому части одной сессии записываются по очереди.
+// Возвращает количество полученных байт после сохранения части.
+func (r *Repository) AppendChunk(ctx context.Context, id string, userID domain.UserID, offset int64, chunk []byte) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var received int64
+	err = tx.QueryRowContext(ctx,
+		`UPDATE upload_sessions SET received = received + $1, updated_at = $2
+			WHERE id = $3 AND user_id = $4 AND received = $5 AND received + $1 <= size
+			RETURNING received`,
+		len(chunk), time.Now(), id, userID, offset,
+	).Scan(&received)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrOffsetMismatch
+		}
+		return 0, err
+	}
+
+	if err = r.spool.WriteAt(id, offset, chunk); err != nil {
+		return 0, fmt.Errorf("failed to write upload chunk: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return received, nil
+}
+
+// Complete переносит полученные данные в файловый секрет и удаляет сессию загрузки.
+// Данные больше порога передаются в хранилище файлов потоком до начала транзакции, чтобы не держать блокировки
+// пользователя и секрета на время записи; данные не больше порога сохраняются в PostgreSQL.
+// Ограничения хранилища пользователя проверяются в транзакции замены данных. Возвращает размер сохраненных данных.
+func (r *Repository) Complete(ctx context.Context, id string, userID domain.UserID) (_ int64, err error) {
+	session, err := r.GetByID(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if session.Received != session.Size {
+		return 0, ErrIncomplete
+	}
+
+	// Ограничения могли измениться после открытия сессии
+	if err = r.checkSize(session.Size); err != nil {
+		return 0, err
+	}
+
+	payload, ref, err := r.storeSpooled(ctx, id, session.Size)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			secret.ReleaseBlobs(ctx, r.db, r.blobs, ref.String)
+		}
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	baseline, err := secret.LockQuota(ctx, tx, r.blobs, r.config.Quota, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Сессия могла быть завершена или удалена, пока данные передавались в хранилище файлов
+	err = tx.QueryRowContext(ctx,
+		"SELECT received FROM upload_sessions WHERE id = $1 AND user_id = $2 FOR UPDATE",
+		id, userID,
+	).Scan(&session.Received)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storageErrors.ErrNotFound
+		}
+		return 0, err
+	}
+
+	var oldRef sql.NullString
+	err = tx.QueryRowContext(ctx,
+		"SELECT blob_ref FROM secrets WHERE id = $1 AND user_id = $2 AND secret_type = $3 AND deleted_at IS NULL FOR UPDATE",
+		session.SecretID, userID, string(domain.BlobSecret),
+	).Scan(&oldRef)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storageErrors.ErrNotFound
+		}
+		return 0, err
+	}
+
+	pruned, err := secret.SaveVersion(ctx, tx, session.SecretID, r.config.Versions)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE secrets SET updated_at = $1, data_key = $2, blob_ref = $3, blob_size = $4, payload = $5, revision = revision + 1 WHERE id = $6",
+		time.Now(), session.DataKey, ref, secret.BlobSize(ref, session.Size), payload, session.SecretID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM upload_sessions WHERE id = $1", id); err != nil {
+		return 0, err
+	}
+
+	if err = secret.CheckQuota(ctx, tx, r.blobs, r.config.Quota, userID, baseline); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Оставшиеся данные удалит очистка устаревших сессий
+	_ = r.spool.Remove(id)
+
+	if oldRef.String != ref.String {
+		pruned = append(pruned, oldRef.String)
+	}
+	secret.ReleaseBlobs(ctx, r.db, r.blobs, pruned...)
+
+	return session.Size, nil
+}
+
+// DeleteExpired удаление сессий загрузки, не получавших данных с момента before, вместе с полученными данными.
+// Данные, которые не изменялись с момента before, удаляются, даже если их сессия уже удалена вместе с секретом.
+// Возвращает количество удаленных сессий.
+func (r *Repository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM upload_sessions WHERE updated_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = r.spool.RemoveOlder(before); err != nil {
+		return deleted, fmt.Errorf("failed to remove expired upload data: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// storeSpooled читает данные загрузки id размера size из spool. Данные больше порога передаются в хранилище файлов,
+// остальные возвращаются для колонки payload. Возвращает данные и ссылку на файл; ссылка пустая, если данные остаются в PostgreSQL.
+func (r *Repository) storeSpooled(ctx context.Context, id string, size int64) ([]byte, sql.NullString, error) {
+	if size == 0 {
+		return []byte{}, sql.NullString{}, nil
+	}
+
+	data, err := r.spool.Open(id)
+	if err != nil {
+		return nil, sql.NullString{}, fmt.Errorf("failed to open upload data: %w", err)
+	}
+	defer data.Close()
+
+	chunks := io.LimitReader(data, size)
+
+	if size <= r.config.BlobThreshold {
+		payload, err := io.ReadAll(chunks)
+		if err != nil {
+			return nil, sql.NullString{}, err
+		}
+		if int64(len(payload)) != size {
+			return nil, sql.NullString{}, fmt.Errorf("upload data has %d of %d bytes", len(payload), size)
+		}
+		return payload, sql.NullString{}, nil
+	}
+
+	ref, stored, err := r.blobs.Put(ctx, chunks)
+	if err != nil {
+		return nil, sql.NullString{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+	if stored != size {
+		secret.ReleaseBlobs(ctx, r.db, r.blobs, ref)
+		return nil, sql.NullString{}, fmt.Errorf("upload data has %d of %d bytes", stored, size)
+	}
+
+	return []byte{}, sql.NullString{String: ref, Valid: true}, nil
+}
+
+// checkSize проверяет, что загрузку размера size можно сохранить: она не превышает допустимый размер данных секрета,
+// а загрузка больше порога возможна только с хранилищем файлов
+func (r *Repository) checkSize(size int64) error {
+	limit := r.config.Quota.MaxPayloadSize
+	if limit == 0 {
+		limit = secret.MaxBlobSize
+	}
+	if size > limit {
+		return fmt.Errorf("%w: upload of %d bytes exceeds %d bytes", storageErrors.ErrQuotaExceeded, size, limit)
+	}
+
+	if r.blobs == nil && size > r.config.BlobThreshold {
+		return fmt.Errorf("%w: upload of %d bytes exceeds %d bytes stored in database", secret.ErrBlobStoreDisabled, size, r.config.BlobThreshold)
+	}
+
+	return nil
+}
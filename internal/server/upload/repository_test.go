@@ -0,0 +1,417 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/internal/server/blobstore"
+	"github.com/romanp1989/gophkeeper/internal/server/secret"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// sessionID идентификатор сессии загрузки в тестах
+const sessionID = "0123456789abcdef0123456789abcdef"
+
+// newSpool создает хранилище данных загрузок во временном каталоге теста с данными сессии sessionID
+func newSpool(t *testing.T, data string) *FileSpool {
+	t.Helper()
+
+	spool, err := NewFileSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create upload spool: %v", err)
+	}
+	if data != "" {
+		if err = spool.WriteAt(sessionID, 0, []byte(data)); err != nil {
+			t.Fatalf("Failed to write upload data: %v", err)
+		}
+	}
+
+	return spool
+}
+
+func TestUploadRepository(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock)
+		expectErr bool
+	}{
+		{
+			name: "Create_Success",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				now := time.Now()
+				mock.ExpectExec(`INSERT INTO upload_sessions \(id, user_id, secret_id, data_key, size, received, created_at, updated_at\)`).
+					WithArgs(sessionID, 1, 2, []byte("key"), 10, 0, now, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				err := repo.Create(ctx, &domain.UploadSession{ID: sessionID, UserID: 1, SecretID: 2, DataKey: []byte("key"), Size: 10, CreatedAt: now, UpdatedAt: now})
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Create_Fail_BlobStoreDisabled",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				err := repo.Create(ctx, &domain.UploadSession{ID: sessionID, UserID: 1, SecretID: 2, DataKey: []byte("key"), Size: 100})
+				if !errors.Is(err, secret.ErrBlobStoreDisabled) {
+					t.Errorf("Expected error %v, got %v", secret.ErrBlobStoreDisabled, err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "GetByID_Success",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "secret_id", "data_key", "size", "received", "created_at", "updated_at"}).
+					AddRow(sessionID, 1, 2, []byte("key"), 10, 4, time.Now(), time.Now())
+				mock.ExpectQuery(`SELECT id, user_id, secret_id, data_key, size, received, created_at, updated_at FROM upload_sessions WHERE id = \$1 AND user_id = \$2`).
+					WithArgs(sessionID, 1).
+					WillReturnRows(rows)
+
+				session, err := repo.GetByID(ctx, sessionID, 1)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if session.SecretID != 2 || session.Size != 10 || session.Received != 4 {
+					t.Errorf("Unexpected session data: %+v", session)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "GetByID_Fail_NotFound",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, user_id, secret_id, data_key, size, received, created_at, updated_at FROM upload_sessions`).
+					WithArgs(sessionID, 1).
+					WillReturnError(sql.ErrNoRows)
+
+				_, err := repo.GetByID(ctx, sessionID, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "AppendChunk_Success",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE upload_sessions SET received = received \+ \$1, updated_at = \$2`).
+					WithArgs(5, sqlmock.AnyArg(), sessionID, 1, 5).
+					WillReturnRows(sqlmock.NewRows([]string{"received"}).AddRow(10))
+				mock.ExpectCommit()
+
+				if err := spool.WriteAt(sessionID, 0, []byte("hello")); err != nil {
+					t.Fatalf("Failed to write upload data: %v", err)
+				}
+
+				received, err := repo.AppendChunk(ctx, sessionID, 1, 5, []byte("world"))
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				data, err := spool.Open(sessionID)
+				if err != nil {
+					t.Fatalf("Expected upload data to be stored, got %v", err)
+				}
+				defer data.Close()
+				if content, _ := io.ReadAll(data); string(content) != "helloworld" {
+					t.Errorf("Expected chunk to be appended, got %q", content)
+				}
+				if received != 10 {
+					t.Errorf("Expected received 10, got %d", received)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "AppendChunk_Fail_OffsetMismatch",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE upload_sessions SET received = received \+ \$1, updated_at = \$2`).
+					WithArgs(5, sqlmock.AnyArg(), sessionID, 1, 3).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+
+				_, err := repo.AppendChunk(ctx, sessionID, 1, 3, []byte("world"))
+				if !errors.Is(err, ErrOffsetMismatch) {
+					t.Errorf("Expected error 'ErrOffsetMismatch', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Complete_Success",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				if err := spool.WriteAt(sessionID, 0, []byte("0123456789")); err != nil {
+					t.Fatalf("Failed to write upload data: %v", err)
+				}
+
+				expectSession(mock, 10)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT received FROM upload_sessions WHERE id = \$1 AND user_id = \$2 FOR UPDATE`).
+					WithArgs(sessionID, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"received"}).AddRow(10))
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND secret_type = \$3 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(2, 1, "blob").
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+				mock.ExpectExec(`INSERT INTO secret_versions`).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`DELETE FROM secret_versions WHERE secret_id = \$1`).
+					WithArgs(2, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectExec(`UPDATE secrets SET updated_at = \$1, data_key = \$2, blob_ref = \$3, blob_size = \$4, payload = \$5, revision = revision \+ 1 WHERE id = \$6`).
+					WithArgs(sqlmock.AnyArg(), []byte("key"), nil, nil, []byte("0123456789"), 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM upload_sessions WHERE id = \$1`).
+					WithArgs(sessionID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				size, err := repo.Complete(ctx, sessionID, 1)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if size != 10 {
+					t.Errorf("Expected size 10, got %d", size)
+				}
+				if _, err = spool.Open(sessionID); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected upload data to be removed, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Complete_Fail_Incomplete",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				expectSession(mock, 4)
+
+				_, err := repo.Complete(ctx, sessionID, 1)
+				if !errors.Is(err, ErrIncomplete) {
+					t.Errorf("Expected error 'ErrIncomplete', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Complete_Fail_SessionCompleted",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				if err := spool.WriteAt(sessionID, 0, []byte("0123456789")); err != nil {
+					t.Fatalf("Failed to write upload data: %v", err)
+				}
+
+				expectSession(mock, 10)
+				mock.ExpectBegin()
+				// Сессия завершена параллельным запросом, пока данные читались из spool
+				mock.ExpectQuery(`SELECT received FROM upload_sessions`).
+					WithArgs(sessionID, 1).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+
+				_, err := repo.Complete(ctx, sessionID, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Complete_Fail_SecretNotFound",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				if err := spool.WriteAt(sessionID, 0, []byte("0123456789")); err != nil {
+					t.Fatalf("Failed to write upload data: %v", err)
+				}
+
+				expectSession(mock, 10)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT received FROM upload_sessions`).
+					WithArgs(sessionID, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"received"}).AddRow(10))
+				mock.ExpectQuery(`SELECT blob_ref FROM secrets`).
+					WithArgs(2, 1, "blob").
+					WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}))
+				mock.ExpectRollback()
+
+				_, err := repo.Complete(ctx, sessionID, 1)
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "DeleteExpired_Success",
+			testFunc: func(t *testing.T, repo *Repository, spool *FileSpool, mock sqlmock.Sqlmock) {
+				before := time.Now()
+				if err := spool.WriteAt(sessionID, 0, []byte("hello")); err != nil {
+					t.Fatalf("Failed to write upload data: %v", err)
+				}
+
+				mock.ExpectExec(`DELETE FROM upload_sessions WHERE updated_at < \$1`).
+					WithArgs(before.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+
+				deleted, err := repo.DeleteExpired(ctx, before.Add(time.Hour))
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if deleted != 3 {
+					t.Errorf("Expected 3 deleted sessions, got %d", deleted)
+				}
+				if _, err = spool.Open(sessionID); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected expired upload data to be removed, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("Failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			spool := newSpool(t, "")
+			repo := NewUploadRepository(db, nil, spool, &secret.Config{BlobThreshold: 16, Versions: 10})
+
+			tc.testFunc(t, repo, spool, mock)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet SQL expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUploadRepository_BlobStore(t *testing.T) {
+	ctx := context.Background()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	store, err := blobstore.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create blob store: %v", err)
+	}
+
+	repo := NewUploadRepository(db, store, newSpool(t, "0123456789"), &secret.Config{BlobThreshold: 4})
+
+	sum := sha256.Sum256([]byte("0123456789"))
+	ref := hex.EncodeToString(sum[:])
+
+	// Файл записывается до начала транзакции
+	expectSession(mock, 10)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT received FROM upload_sessions`).
+		WithArgs(sessionID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"received"}).AddRow(10))
+	mock.ExpectQuery(`SELECT blob_ref FROM secrets WHERE id = \$1 AND user_id = \$2 AND secret_type = \$3 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(2, 1, "blob").
+		WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+	mock.ExpectExec(`UPDATE secrets SET updated_at = \$1, data_key = \$2, blob_ref = \$3, blob_size = \$4, payload = \$5, revision = revision \+ 1 WHERE id = \$6`).
+		WithArgs(sqlmock.AnyArg(), []byte("key"), ref, 10, []byte{}, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM upload_sessions WHERE id = \$1`).
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	size, err := repo.Complete(ctx, sessionID, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if size != 10 {
+		t.Errorf("Expected size 10, got %d", size)
+	}
+
+	blob, _, err := store.Open(ctx, ref)
+	if err != nil {
+		t.Fatalf("Expected blob file to be stored, got %v", err)
+	}
+	defer blob.Close()
+
+	data, _ := io.ReadAll(blob)
+	if string(data) != "0123456789" {
+		t.Errorf("Expected upload data to be stored, got %q", data)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet SQL expectations: %v", err)
+	}
+}
+
+func TestUploadRepository_Quota(t *testing.T) {
+	ctx := context.Background()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUploadRepository(db, nil, newSpool(t, "0123456789"), &secret.Config{BlobThreshold: 16, Quota: domain.Quota{MaxBytes: 15}})
+
+	expectSession(mock, 10)
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT 1 FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\), coalesce\(sum\(octet_length\(payload\)\), 0\)`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "versions"}).AddRow(1, 5, 0))
+	mock.ExpectQuery(`SELECT coalesce\(sum\(size\), 0\)`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"size", "live", "legacy", "legacy_live"}).AddRow(0, 0, "{}", "{}"))
+	mock.ExpectQuery(`SELECT received FROM upload_sessions`).
+		WithArgs(sessionID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"received"}).AddRow(10))
+	mock.ExpectQuery(`SELECT blob_ref FROM secrets`).
+		WithArgs(2, 1, "blob").
+		WillReturnRows(sqlmock.NewRows([]string{"blob_ref"}).AddRow(nil))
+	mock.ExpectExec(`UPDATE secrets SET`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM upload_sessions WHERE id = \$1`).
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Данные, загруженные после открытия сессии, не оставили для нее места
+	mock.ExpectQuery(`SELECT count\(\*\), coalesce\(sum\(octet_length\(payload\)\), 0\)`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "versions"}).AddRow(2, 17, 0))
+	mock.ExpectQuery(`SELECT coalesce\(sum\(size\), 0\)`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"size", "live", "legacy", "legacy_live"}).AddRow(0, 0, "{}", "{}"))
+	mock.ExpectRollback()
+
+	_, err = repo.Complete(ctx, sessionID, 1)
+	if !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+		t.Errorf("Expected error %v, got %v", storageErrors.ErrQuotaExceeded, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet SQL expectations: %v", err)
+	}
+}
+
+// expectSession ожидает чтение сессии sessionID размера 10 байт, получившей received байт
+func expectSession(mock sqlmock.Sqlmock, received int64) {
+	mock.ExpectQuery(`SELECT id, user_id, secret_id, data_key, size, received, created_at, updated_at FROM upload_sessions WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(sessionID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "secret_id", "data_key", "size", "received", "created_at", "updated_at"}).
+			AddRow(sessionID, 1, 2, []byte("key"), 10, received, time.Now(), time.Now()))
+}
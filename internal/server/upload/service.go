@@ -0,0 +1,151 @@
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"time"
+)
+
+// ErrOffsetMismatch определяет ошибку, возникающую, если часть данных начинается не с позиции,
+// до которой данные уже получены, или выходит за размер загрузки.
+var ErrOffsetMismatch = errors.New("upload offset mismatch")
+
+// ErrIncomplete определяет ошибку, возникающую при завершении загрузки, в которой получены не все данные.
+var ErrIncomplete = errors.New("upload is incomplete")
+
+// ErrInvalidSession определяет ошибку, возникающую при открытии сессии с некорректными параметрами.
+var ErrInvalidSession = errors.New("invalid upload session")
+
+// sessionIDLength длина случайного идентификатора сессии в байтах
+const sessionIDLength = 16
+
+type UploadRepository interface {
+	Create(ctx context.Context, session *domain.UploadSession) error
+	GetByID(ctx context.Context, id string, userID domain.UserID) (*domain.UploadSession, error)
+	AppendChunk(ctx context.Context, id string, userID domain.UserID, offset int64, chunk []byte) (int64, error)
+	Complete(ctx context.Context, id string, userID domain.UserID) (int64, error)
+}
+
+type SecretRepository interface {
+	GetByID(ctx context.Context, id uint64, userID domain.UserID) (*domain.Secret, error)
+	Usage(ctx context.Context, userID domain.UserID, exclude []uint64) (*domain.Usage, error)
+}
+
+type Service struct {
+	repository       UploadRepository
+	secretRepository SecretRepository
+	quota            domain.Quota
+}
+
+// NewUploadService создает сервис загрузки, не открывающий сессии, данные которых превысят ограничения хранилища quota.
+func NewUploadService(repository UploadRepository, secretRepository SecretRepository, quota domain.Quota) *Service {
+	return &Service{repository: repository, secretRepository: secretRepository, quota: quota}
+}
+
+// Create открывает сессию загрузки данных файлового секрета пользователя.
+func (s *Service) Create(ctx context.Context, session *domain.UploadSession) (*domain.UploadSession, error) {
+	if session.Size < 0 || len(session.DataKey) == 0 {
+		return nil, ErrInvalidSession
+	}
+
+	secret, err := s.secretRepository.GetByID(ctx, session.SecretID, session.UserID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find secret: %w", err)
+	}
+
+	if secret.SecretType != string(domain.BlobSecret) {
+		return nil, storageErrors.ErrNotFound
+	}
+
+	if err = s.checkQuota(ctx, session); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, sessionIDLength)
+	if _, err = rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	session.ID = hex.EncodeToString(id)
+	session.Received = 0
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+
+	if err = s.repository.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Get возвращает сессию загрузки пользователя с количеством уже полученных байт.
+func (s *Service) Get(ctx context.Context, id string, userID domain.UserID) (*domain.UploadSession, error) {
+	return s.repository.GetByID(ctx, id, userID)
+}
+
+// Append сохраняет часть данных, начинающуюся с позиции offset, и возвращает количество полученных байт.
+func (s *Service) Append(ctx context.Context, id string, userID domain.UserID, offset int64, chunk []byte) (int64, error) {
+	received, err := s.repository.AppendChunk(ctx, id, userID, offset, chunk)
+	if err != nil {
+		if errors.Is(err, ErrOffsetMismatch) {
+			// Сессия могла быть удалена, тогда продолжить загрузку нельзя
+			if _, getErr := s.repository.GetByID(ctx, id, userID); getErr != nil {
+				return 0, getErr
+			}
+		}
+		return 0, err
+	}
+
+	return received, nil
+}
+
+// Complete сохраняет полученные данные в файловый секрет и закрывает сессию загрузки.
+func (s *Service) Complete(ctx context.Context, id string, userID domain.UserID) (int64, error) {
+	size, err := s.repository.Complete(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) || errors.Is(err, ErrIncomplete) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	return size, nil
+}
+
+// checkQuota проверяет, что данные сессии вместо прежних данных секрета не превысят ограничения хранилища пользователя.
+// Загрузка, которая не больше прежних данных секрета, разрешается и сверх ограничений, чтобы пользователь,
+// превысивший их, мог заменить файл меньшим.
+func (s *Service) checkQuota(ctx context.Context, session *domain.UploadSession) error {
+	if s.quota == (domain.Quota{}) {
+		return nil
+	}
+
+	usage, err := s.secretRepository.Usage(ctx, session.UserID, []uint64{session.SecretID})
+	if err != nil {
+		return fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	usage.Quota = s.quota
+
+	if usage.Fits(1, session.Size, session.Size) {
+		return nil
+	}
+
+	current, err := s.secretRepository.Usage(ctx, session.UserID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	if session.Size <= current.Bytes-usage.Bytes && usage.Fits(0, 0, session.Size) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: upload of %d bytes does not fit, %d bytes already stored",
+		storageErrors.ErrQuotaExceeded, session.Size, usage.Bytes)
+}
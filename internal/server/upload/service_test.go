@@ -0,0 +1,183 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/romanp1989/gophkeeper/domain"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"github.com/romanp1989/gophkeeper/tests/mocks"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestUploadService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIUploadRepository(ctrl)
+	mockSecrets := mocks.NewMockISecretRepository(ctrl)
+	service := NewUploadService(mockRepo, mockSecrets, domain.Quota{})
+
+	ctx := context.Background()
+	blob := &domain.Secret{ID: 2, UserID: 1, SecretType: string(domain.BlobSecret)}
+
+	tests := []struct {
+		name      string
+		testFunc  func(t *testing.T)
+		expectErr bool
+	}{
+		{
+			name: "Create_Success",
+			testFunc: func(t *testing.T) {
+				mockSecrets.EXPECT().GetByID(ctx, uint64(2), domain.UserID(1)).Return(blob, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+				session, err := service.Create(ctx, &domain.UploadSession{UserID: 1, SecretID: 2, DataKey: []byte("key"), Size: 10, Received: 5})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if len(session.ID) != 2*sessionIDLength || session.Received != 0 {
+					t.Errorf("Unexpected session: %+v", session)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Create_Fail_Invalid",
+			testFunc: func(t *testing.T) {
+				_, err := service.Create(ctx, &domain.UploadSession{UserID: 1, SecretID: 2, Size: 10})
+				if !errors.Is(err, ErrInvalidSession) {
+					t.Errorf("Expected error 'ErrInvalidSession', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Create_Fail_NotBlob",
+			testFunc: func(t *testing.T) {
+				mockSecrets.EXPECT().GetByID(ctx, uint64(2), domain.UserID(1)).
+					Return(&domain.Secret{ID: 2, SecretType: string(domain.TextSecret)}, nil)
+
+				_, err := service.Create(ctx, &domain.UploadSession{UserID: 1, SecretID: 2, DataKey: []byte("key"), Size: 10})
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Create_Fail_QuotaExceeded",
+			testFunc: func(t *testing.T) {
+				limited := NewUploadService(mockRepo, mockSecrets, domain.Quota{MaxBytes: 100})
+				mockSecrets.EXPECT().GetByID(ctx, uint64(2), domain.UserID(1)).Return(blob, nil)
+				mockSecrets.EXPECT().Usage(ctx, domain.UserID(1), []uint64{2}).Return(&domain.Usage{Secrets: 3, Bytes: 95}, nil)
+				mockSecrets.EXPECT().Usage(ctx, domain.UserID(1), nil).Return(&domain.Usage{Secrets: 4, Bytes: 98}, nil)
+
+				_, err := limited.Create(ctx, &domain.UploadSession{UserID: 1, SecretID: 2, DataKey: []byte("key"), Size: 10})
+				if !errors.Is(err, storageErrors.ErrQuotaExceeded) {
+					t.Errorf("Expected error 'ErrQuotaExceeded', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Create_Success_OverQuotaSmallerFile",
+			testFunc: func(t *testing.T) {
+				limited := NewUploadService(mockRepo, mockSecrets, domain.Quota{MaxBytes: 100})
+				mockSecrets.EXPECT().GetByID(ctx, uint64(2), domain.UserID(1)).Return(blob, nil)
+				mockSecrets.EXPECT().Usage(ctx, domain.UserID(1), []uint64{2}).Return(&domain.Usage{Secrets: 3, Bytes: 95}, nil)
+				mockSecrets.EXPECT().Usage(ctx, domain.UserID(1), nil).Return(&domain.Usage{Secrets: 4, Bytes: 115}, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+				if _, err := limited.Create(ctx, &domain.UploadSession{UserID: 1, SecretID: 2, DataKey: []byte("key"), Size: 10}); err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Append_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().AppendChunk(ctx, "session", domain.UserID(1), int64(5), []byte("world")).Return(int64(10), nil)
+
+				received, err := service.Append(ctx, "session", 1, 5, []byte("world"))
+				if err != nil || received != 10 {
+					t.Errorf("Expected 10 received bytes, got %d, %v", received, err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "Append_Fail_SessionExpired",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().AppendChunk(ctx, "session", domain.UserID(1), int64(5), []byte("world")).Return(int64(0), ErrOffsetMismatch)
+				mockRepo.EXPECT().GetByID(ctx, "session", domain.UserID(1)).Return(nil, storageErrors.ErrNotFound)
+
+				_, err := service.Append(ctx, "session", 1, 5, []byte("world"))
+				if !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error 'ErrNotFound', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Append_Fail_OffsetMismatch",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().AppendChunk(ctx, "session", domain.UserID(1), int64(3), []byte("world")).Return(int64(0), ErrOffsetMismatch)
+				mockRepo.EXPECT().GetByID(ctx, "session", domain.UserID(1)).Return(&domain.UploadSession{ID: "session"}, nil)
+
+				_, err := service.Append(ctx, "session", 1, 3, []byte("world"))
+				if !errors.Is(err, ErrOffsetMismatch) {
+					t.Errorf("Expected error 'ErrOffsetMismatch', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Complete_Fail_Incomplete",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Complete(ctx, "session", domain.UserID(1)).Return(int64(0), ErrIncomplete)
+
+				_, err := service.Complete(ctx, "session", 1)
+				if !errors.Is(err, ErrIncomplete) {
+					t.Errorf("Expected error 'ErrIncomplete', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "Complete_Fail",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().Complete(ctx, "session", domain.UserID(1)).Return(int64(0), errors.New("some error"))
+
+				_, err := service.Complete(ctx, "session", 1)
+				if err == nil || err.Error() != "failed to complete upload: some error" {
+					t.Errorf("Expected error 'failed to complete upload: some error', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestJanitor_Clean(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIUploadRepository(ctrl)
+	janitor := NewJanitor(mockRepo, &Config{SessionTTL: time.Hour, CleanupInterval: time.Minute}, zap.NewNop())
+
+	now := time.Now()
+	mockRepo.EXPECT().DeleteExpired(gomock.Any(), now.Add(-time.Hour)).Return(int64(2), nil)
+
+	deleted, err := janitor.Clean(context.Background(), now)
+	if err != nil || deleted != 2 {
+		t.Errorf("Expected 2 deleted sessions, got %d, %v", deleted, err)
+	}
+}
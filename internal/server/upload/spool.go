@@ -0,0 +1,142 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrInvalidSessionID указывает на идентификатор сессии, который не является hex-строкой допустимой длины.
+var ErrInvalidSessionID = errors.New("invalid upload session id")
+
+// FileSpool хранит данные незавершенных загрузок в локальной файловой системе, по файлу на сессию.
+// Данные не попадают в PostgreSQL и при завершении загрузки передаются в хранилище файлов потоком.
+type FileSpool struct {
+	root string
+}
+
+// NewFileSpool создает хранилище данных загрузок в каталоге root
+func NewFileSpool(root string) (*FileSpool, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	return &FileSpool{root: root}, nil
+}
+
+// WriteAt записывает часть данных сессии id, начиная с позиции offset. Данные после offset, оставшиеся
+// от части, прием которой не был зафиксирован, отбрасываются. Часть сбрасывается на диск до возврата,
+// поэтому принятая часть не теряется при сбое сервера.
+func (s *FileSpool) WriteAt(id string, offset int64, chunk []byte) (err error) {
+	if !validSessionID(id) {
+		return ErrInvalidSessionID
+	}
+
+	file, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if err = file.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = file.WriteAt(chunk, offset); err != nil {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// Open открывает данные сессии id для чтения
+func (s *FileSpool) Open(id string) (io.ReadCloser, error) {
+	if !validSessionID(id) {
+		return nil, ErrInvalidSessionID
+	}
+
+	file, err := os.Open(s.path(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, storageErrors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Remove удаляет данные сессии id. Удаление отсутствующих данных не является ошибкой
+func (s *FileSpool) Remove(id string) error {
+	if !validSessionID(id) {
+		return ErrInvalidSessionID
+	}
+
+	err := os.Remove(s.path(id))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveOlder удаляет данные сессий, которые не изменялись с момента before
+func (s *FileSpool) RemoveOlder(before time.Time) error {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !validSessionID(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if !info.ModTime().Before(before) {
+			continue
+		}
+
+		if err = s.Remove(entry.Name()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// path возвращает путь к файлу данных сессии
+func (s *FileSpool) path(id string) string {
+	return filepath.Join(s.root, id)
+}
+
+// validSessionID проверяет, что идентификатор сессии является hex-строкой не длиннее колонки upload_sessions.id.
+// Идентификаторы проверяются перед построением пути, чтобы они не могли указать на файл вне каталога загрузок
+func validSessionID(id string) bool {
+	if id == "" || len(id) > 2*sessionIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,118 @@
+package upload
+
+import (
+	"errors"
+	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSpool(t *testing.T) {
+	tests := []struct {
+		name     string
+		testFunc func(t *testing.T, spool *FileSpool, root string)
+	}{
+		{
+			name: "WriteAt_Open_Success",
+			testFunc: func(t *testing.T, spool *FileSpool, root string) {
+				if err := spool.WriteAt(sessionID, 0, []byte("hello")); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if err := spool.WriteAt(sessionID, 5, []byte("world")); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				data, err := spool.Open(sessionID)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				defer data.Close()
+
+				if got, _ := io.ReadAll(data); string(got) != "helloworld" {
+					t.Errorf("Expected %q, got %q", "helloworld", got)
+				}
+			},
+		},
+		{
+			name: "WriteAt_DiscardsUncommittedTail",
+			testFunc: func(t *testing.T, spool *FileSpool, root string) {
+				// Прием части "world!" не был зафиксирован, и клиент отправил с той же позиции часть короче
+				_ = spool.WriteAt(sessionID, 0, []byte("hello"))
+				_ = spool.WriteAt(sessionID, 5, []byte("world!"))
+				if err := spool.WriteAt(sessionID, 5, []byte("go")); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				data, err := spool.Open(sessionID)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				defer data.Close()
+
+				if got, _ := io.ReadAll(data); string(got) != "hellogo" {
+					t.Errorf("Expected %q, got %q", "hellogo", got)
+				}
+			},
+		},
+		{
+			name: "Open_Fail_NotFound",
+			testFunc: func(t *testing.T, spool *FileSpool, root string) {
+				if _, err := spool.Open(sessionID); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected error %v, got %v", storageErrors.ErrNotFound, err)
+				}
+			},
+		},
+		{
+			name: "Fail_InvalidSessionID",
+			testFunc: func(t *testing.T, spool *FileSpool, root string) {
+				if err := spool.WriteAt("../secret", 0, []byte("data")); !errors.Is(err, ErrInvalidSessionID) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidSessionID, err)
+				}
+				if _, err := spool.Open("../secret"); !errors.Is(err, ErrInvalidSessionID) {
+					t.Errorf("Expected error %v, got %v", ErrInvalidSessionID, err)
+				}
+			},
+		},
+		{
+			name: "RemoveOlder_Success",
+			testFunc: func(t *testing.T, spool *FileSpool, root string) {
+				const fresh = "fedcba9876543210fedcba9876543210"
+				_ = spool.WriteAt(sessionID, 0, []byte("stale"))
+				_ = spool.WriteAt(fresh, 0, []byte("fresh"))
+
+				old := time.Now().Add(-2 * time.Hour)
+				if err := os.Chtimes(filepath.Join(root, sessionID), old, old); err != nil {
+					t.Fatalf("Failed to change file time: %v", err)
+				}
+
+				if err := spool.RemoveOlder(time.Now().Add(-time.Hour)); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+
+				if _, err := spool.Open(sessionID); !errors.Is(err, storageErrors.ErrNotFound) {
+					t.Errorf("Expected stale upload data to be removed, got %v", err)
+				}
+				data, err := spool.Open(fresh)
+				if err != nil {
+					t.Fatalf("Expected fresh upload data to be kept, got %v", err)
+				}
+				_ = data.Close()
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			spool, err := NewFileSpool(root)
+			if err != nil {
+				t.Fatalf("Failed to create upload spool: %v", err)
+			}
+
+			tc.testFunc(t, spool, root)
+		})
+	}
+}
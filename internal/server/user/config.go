@@ -0,0 +1,5 @@
+package user
+
+type Config struct {
+	FakeSaltKey []byte // FakeSaltKey ключ HMAC, из которого формируются параметры KDF несуществующих логинов в PreLogin
+}
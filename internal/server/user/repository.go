@@ -3,7 +3,9 @@ package user
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/romanp1989/gophkeeper/domain"
 	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 )
@@ -20,10 +22,18 @@ func NewUserRepository(db *sql.DB) *Repository {
 func (r *Repository) CreateUser(ctx context.Context, user *domain.User) (domain.UserID, error) {
 	var newUserID domain.UserID
 
-	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO users (login, password) VALUES ($1, $2) RETURNING id",
+	kdf, err := json.Marshal(user.KDF)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO users (login, password, auth_scheme, kdf, vault_key) VALUES ($1, $2, $3, $4, $5) RETURNING id",
 		user.Login,
 		user.Password,
+		user.AuthScheme,
+		kdf,
+		user.VaultKey,
 	).Scan(&newUserID)
 
 	if err != nil {
@@ -37,11 +47,22 @@ func (r *Repository) CreateUser(ctx context.Context, user *domain.User) (domain.
 
 // FindByLogin Поиск пользователя по логину
 func (r *Repository) FindByLogin(ctx context.Context, login string) (*domain.User, error) {
+	return r.findUser(ctx, "login = $1", login)
+}
+
+// FindByID Поиск пользователя по идентификатору
+func (r *Repository) FindByID(ctx context.Context, userID domain.UserID) (*domain.User, error) {
+	return r.findUser(ctx, "id = $1", userID)
+}
+
+// findUser ищет пользователя по условию where
+func (r *Repository) findUser(ctx context.Context, where string, arg any) (*domain.User, error) {
 	u := domain.User{}
+	var kdf []byte
 
 	err := r.db.QueryRowContext(ctx,
-		"SELECT id, login, password FROM users WHERE login = $1", login,
-	).Scan(&u.ID, &u.Login, &u.Password)
+		"SELECT id, login, password, auth_scheme, kdf, vault_key FROM users WHERE "+where, arg,
+	).Scan(&u.ID, &u.Login, &u.Password, &u.AuthScheme, &kdf, &u.VaultKey)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -49,5 +70,102 @@ func (r *Repository) FindByLogin(ctx context.Context, login string) (*domain.Use
 		}
 		return nil, err
 	}
+
+	// Для аккаунтов, созданных до появления параметров KDF, kdf = NULL
+	if len(kdf) > 0 {
+		if err = json.Unmarshal(kdf, &u.KDF); err != nil {
+			return nil, err
+		}
+	}
+
 	return &u, nil
 }
+
+// UpdatePassword Смена хеша пароля пользователя вместе со способом аутентификации, параметрами KDF,
+// ключом хранилища и перешифрованными секретами одной транзакцией.
+// Для секретов с пустым payload обновляется только ключ данных.
+// Перешифрованные данные сохраняются в PostgreSQL, прежний файл из хранилища файлов находит проверка согласованности.
+// При перешифровании секретов их история удаляется.
+// Хеш меняется, только если текущий хеш пароля все еще равен oldHash, иначе возвращается ErrConcurrentUpdate.
+func (r *Repository) UpdatePassword(ctx context.Context, user *domain.User, oldHash string, secrets []*domain.Secret) error {
+	kdfData, err := json.Marshal(user.KDF)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Блокируем пользователя, чтобы между подсчетом и обновлением не появились новые секреты
+	var (
+		currentHash     string
+		currentVaultKey []byte
+	)
+	err = tx.QueryRowContext(ctx, "SELECT password, vault_key FROM users WHERE id = $1 FOR UPDATE", user.ID).Scan(&currentHash, &currentVaultKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storageErrors.ErrNotFound
+		}
+		return err
+	}
+
+	if currentHash != oldHash {
+		return ErrConcurrentUpdate
+	}
+
+	// Если ключ хранилища уже есть, достаточно перешифровать только его.
+	// Иначе секреты зашифрованы ключом из мастер-пароля и должны быть перешифрованы все.
+	if len(currentVaultKey) == 0 || len(secrets) > 0 {
+		var total int
+		err = tx.QueryRowContext(ctx, "SELECT count(*) FROM secrets WHERE user_id = $1", user.ID).Scan(&total)
+		if err != nil {
+			return err
+		}
+
+		if total != len(secrets) {
+			return fmt.Errorf("%w: expected %d, got %d", ErrIncompleteReencryption, total, len(secrets))
+		}
+	}
+
+	// Редакции секретов зашифрованы прежними ключами, которые после перешифрования недоступны
+	if len(secrets) > 0 {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM secret_versions WHERE user_id = $1", user.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, secret := range secrets {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE secrets SET payload = coalesce($1::bytea, payload), blob_ref = CASE WHEN $1::bytea IS NULL THEN blob_ref END, data_key = $2,
+				revision = revision + 1
+				WHERE id = $3 AND user_id = $4`,
+			secret.Payload, secret.DataKey, secret.ID, user.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return storageErrors.ErrNotFound
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE users SET password = $1, auth_scheme = $2, kdf = $3, vault_key = $4 WHERE id = $5",
+		user.Password, user.AuthScheme, kdfData, user.VaultKey, user.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -9,9 +9,19 @@ import (
 	"github.com/romanp1989/gophkeeper/domain"
 	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 	"testing"
-	"time"
 )
 
+// newPasswordUser возвращает пользователя с новым хешем аутентификации и ключом хранилища
+func newPasswordUser() *domain.User {
+	return &domain.User{
+		ID:         1,
+		Password:   "new_hash",
+		AuthScheme: domain.AuthSchemeDerived,
+		KDF:        domain.KDFParams{Algorithm: string(domain.KDFArgon2id)},
+		VaultKey:   []byte("new-vault-key"),
+	}
+}
+
 func TestUserRepository(t *testing.T) {
 	ctx := context.Background()
 	tests := []struct {
@@ -22,13 +32,15 @@ func TestUserRepository(t *testing.T) {
 		{
 			name: "CreateUser_Success",
 			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO users \(login, password\) VALUES \(\$1, \$2\) RETURNING id`).
-					WithArgs("new_user", "hashed_password").
+				mock.ExpectQuery(`INSERT INTO users \(login, password, auth_scheme, kdf, vault_key\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id`).
+					WithArgs("new_user", "hashed_password", "derived", sqlmock.AnyArg(), []byte("wrapped-vault-key")).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 				user := domain.User{
-					Login:    "new_user",
-					Password: "hashed_password",
+					Login:      "new_user",
+					Password:   "hashed_password",
+					AuthScheme: domain.AuthSchemeDerived,
+					VaultKey:   []byte("wrapped-vault-key"),
 				}
 				id, err := repo.CreateUser(ctx, &user)
 				if err != nil {
@@ -43,13 +55,15 @@ func TestUserRepository(t *testing.T) {
 		{
 			name: "CreateUser_Fail_DatabaseError",
 			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`INSERT INTO users \(login, password\) VALUES \(\$1, \$2\) RETURNING id`).
-					WithArgs("new_user", "hashed_password").
+				mock.ExpectQuery(`INSERT INTO users \(login, password, auth_scheme, kdf, vault_key\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id`).
+					WithArgs("new_user", "hashed_password", "derived", sqlmock.AnyArg(), []byte("wrapped-vault-key")).
 					WillReturnError(fmt.Errorf("database error"))
 
 				user := domain.User{
-					Login:    "new_user",
-					Password: "hashed_password",
+					Login:      "new_user",
+					Password:   "hashed_password",
+					AuthScheme: domain.AuthSchemeDerived,
+					VaultKey:   []byte("wrapped-vault-key"),
 				}
 				_, err := repo.CreateUser(ctx, &user)
 				if err == nil || err.Error() != "database error" {
@@ -61,10 +75,10 @@ func TestUserRepository(t *testing.T) {
 		{
 			name: "FindByLogin_Success",
 			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, login, created_at, password FROM users WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, login, password, auth_scheme, kdf, vault_key FROM users WHERE login = \$1`).
 					WithArgs("existing_user").
-					WillReturnRows(sqlmock.NewRows([]string{"id", "login", "created_at", "password"}).
-						AddRow(1, "existing_user", time.Now(), "hashed_password"))
+					WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password", "auth_scheme", "kdf", "vault_key"}).
+						AddRow(1, "existing_user", "hashed_password", "derived", []byte(`{"algorithm":"scrypt","salt":"AAAAAAAAAAAAAAAAAAAAAA==","n":32768,"r":8,"p":1}`), []byte("wrapped-vault-key")))
 
 				user, err := repo.FindByLogin(ctx, "existing_user")
 				if err != nil {
@@ -73,13 +87,22 @@ func TestUserRepository(t *testing.T) {
 				if user.Login != "existing_user" {
 					t.Errorf("Expected login 'existing_user', got %v", user.Login)
 				}
+				if user.KDF.Algorithm != string(domain.KDFScrypt) || len(user.KDF.Salt) != 16 {
+					t.Errorf("Unexpected kdf params: %+v", user.KDF)
+				}
+				if user.AuthScheme != domain.AuthSchemeDerived {
+					t.Errorf("Unexpected auth scheme: %q", user.AuthScheme)
+				}
+				if string(user.VaultKey) != "wrapped-vault-key" {
+					t.Errorf("Unexpected vault key: %q", user.VaultKey)
+				}
 			},
 			expectErr: false,
 		},
 		{
 			name: "FindByLogin_Fail_NotFound",
 			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT id, login, created_at, password FROM users WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, login, password, auth_scheme, kdf, vault_key FROM users WHERE login = \$1`).
 					WithArgs("not_existing_user").
 					WillReturnError(sql.ErrNoRows)
 
@@ -90,6 +113,107 @@ func TestUserRepository(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "UpdatePassword_Success_MigrateSecrets",
+			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT password, vault_key FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"password", "vault_key"}).AddRow("old_hash", nil))
+				mock.ExpectQuery(`SELECT count\(\*\) FROM secrets WHERE user_id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec(`DELETE FROM secret_versions WHERE user_id = \$1`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`UPDATE secrets SET payload = coalesce\(\$1::bytea, payload\), blob_ref = CASE WHEN \$1::bytea IS NULL THEN blob_ref END, data_key = \$2,\s+revision = revision \+ 1\s+WHERE id = \$3 AND user_id = \$4`).
+					WithArgs([]byte("payload"), []byte("data-key"), 7, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE users SET password = \$1, auth_scheme = \$2, kdf = \$3, vault_key = \$4 WHERE id = \$5`).
+					WithArgs("new_hash", "derived", sqlmock.AnyArg(), []byte("new-vault-key"), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				secrets := []*domain.Secret{{ID: 7, Payload: []byte("payload"), DataKey: []byte("data-key")}}
+				err := repo.UpdatePassword(ctx, newPasswordUser(), "old_hash", secrets)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "UpdatePassword_Fail_Incomplete",
+			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT password, vault_key FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"password", "vault_key"}).AddRow("old_hash", nil))
+				mock.ExpectQuery(`SELECT count\(\*\) FROM secrets WHERE user_id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectRollback()
+
+				err := repo.UpdatePassword(ctx, newPasswordUser(), "old_hash", nil)
+				if !errors.Is(err, ErrIncompleteReencryption) {
+					t.Errorf("Expected error 'not all secrets are re-encrypted', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "FindByID_Success",
+			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT id, login, password, auth_scheme, kdf, vault_key FROM users WHERE id = \$1`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password", "auth_scheme", "kdf", "vault_key"}).
+						AddRow(1, "existing_user", "hashed_password", "", nil, nil))
+
+				user, err := repo.FindByID(ctx, 1)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				if user.Login != "existing_user" {
+					t.Errorf("Expected login 'existing_user', got %v", user.Login)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "UpdatePassword_Success",
+			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT password, vault_key FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"password", "vault_key"}).AddRow("old_hash", []byte("old-vault-key")))
+				mock.ExpectExec(`UPDATE users SET password = \$1, auth_scheme = \$2, kdf = \$3, vault_key = \$4 WHERE id = \$5`).
+					WithArgs("new_hash", "derived", sqlmock.AnyArg(), []byte("new-vault-key"), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				err := repo.UpdatePassword(ctx, newPasswordUser(), "old_hash", nil)
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "UpdatePassword_Fail_ConcurrentUpdate",
+			testFunc: func(t *testing.T, repo UserRepository, mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT password, vault_key FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"password", "vault_key"}).AddRow("other_hash", []byte("old-vault-key")))
+				mock.ExpectRollback()
+
+				err := repo.UpdatePassword(ctx, newPasswordUser(), "old_hash", nil)
+				if !errors.Is(err, ErrConcurrentUpdate) {
+					t.Errorf("Expected error 'vault was changed concurrently', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range tests {
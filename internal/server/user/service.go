@@ -2,6 +2,9 @@ package user
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/romanp1989/gophkeeper/domain"
@@ -13,24 +16,93 @@ import (
 // ErrBadCredentials определяет ошибку, возникающую при неверных учетных данных для аутентификации.
 var ErrBadCredentials = errors.New("bad token credentials")
 
+// ErrInvalidKDF определяет ошибку, возникающую при регистрации с некорректными параметрами KDF.
+var ErrInvalidKDF = errors.New("invalid kdf params")
+
+// ErrInvalidVaultKey определяет ошибку, возникающую, если клиент не передал зашифрованный ключ хранилища.
+var ErrInvalidVaultKey = errors.New("invalid vault key")
+
+// ErrIncompleteReencryption определяет ошибку, возникающую при смене ключа хранилища,
+// если клиент перешифровал не все секреты пользователя.
+var ErrIncompleteReencryption = errors.New("not all secrets are re-encrypted")
+
+// ErrInvalidPassword определяет ошибку, возникающую, если вместо хеша аутентификации передан пустой
+// или произвольный пароль.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ErrConcurrentUpdate определяет ошибку, возникающую, если пароль пользователя был изменен
+// другим запросом во время смены пароля.
+var ErrConcurrentUpdate = errors.New("vault was changed concurrently")
+
+const (
+	// authHashLength длина хеша аутентификации (32 байта в hex), который формирует клиент
+	authHashLength = 64
+
+	// Параметры KDF, которые формирует клиент: значения по умолчанию для scrypt и Argon2id
+	// и границы параметров Argon2id после калибровки. Из них выбираются параметры несуществующих логинов,
+	// чтобы по ответу PreLogin нельзя было определить, зарегистрирован ли логин
+	fakeScryptN             = 32768
+	fakeScryptR             = 8
+	fakeScryptP             = 1
+	fakeArgon2Memory        = 64 * 1024
+	fakeArgon2Iterations    = 3
+	fakeArgon2Parallelism   = 4
+	fakeArgon2MinMemory     = 32 * 1024
+	fakeArgon2MemorySteps   = 4
+	fakeArgon2MinIterations = 2
+	fakeArgon2MaxIterations = 10
+)
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) (domain.UserID, error)
 	FindByLogin(ctx context.Context, login string) (*domain.User, error)
+	FindByID(ctx context.Context, userID domain.UserID) (*domain.User, error)
+	UpdatePassword(ctx context.Context, user *domain.User, oldHash string, secrets []*domain.Secret) error
 }
 
 type Service struct {
 	userRepository UserRepository
+	saltKey        []byte
 }
 
-// NewUserService создает новый экземпляр UserService с заданным репозиторием
-func NewUserService(userRepository UserRepository) *Service {
-	return &Service{userRepository: userRepository}
+// NewUserService создает новый экземпляр UserService с заданным репозиторием.
+// saltKey используется для формирования соли KDF несуществующих логинов в PreLogin.
+func NewUserService(userRepository UserRepository, saltKey []byte) *Service {
+	return &Service{userRepository: userRepository, saltKey: saltKey}
 }
 
-// RegisterUser метод регистрации пользователя
-func (s *Service) RegisterUser(ctx context.Context, login string, password string) (*domain.User, error) {
+// PreLogin метод получения параметров, необходимых клиенту для формирования хеша аутентификации.
+// Для несуществующего логина возвращаются детерминированные фиктивные параметры.
+func (s *Service) PreLogin(ctx context.Context, login string) (domain.KDFParams, domain.AuthScheme, error) {
+	user, err := s.userRepository.FindByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return s.fakeKDF(login), domain.AuthSchemeDerived, nil
+		}
+		return domain.KDFParams{}, "", fmt.Errorf("failed to find user by login: %w", err)
+	}
+
+	return user.KDF, user.AuthScheme, nil
+}
+
+// RegisterUser метод регистрации пользователя.
+// Клиент передает хеш аутентификации, выведенный из мастер-пароля, а не сам пароль.
+// Параметры KDF и зашифрованный ключ хранилища формируются клиентом и сохраняются вместе с пользователем.
+func (s *Service) RegisterUser(ctx context.Context, login string, authHash string, kdf domain.KDFParams, vaultKey []byte) (*domain.User, error) {
 	var newUser *domain.User
 
+	if err := validateAuthHash(authHash); err != nil {
+		return newUser, err
+	}
+
+	if err := validateKDF(kdf); err != nil {
+		return newUser, err
+	}
+
+	if len(vaultKey) == 0 {
+		return newUser, ErrInvalidVaultKey
+	}
+
 	user, err := s.userRepository.FindByLogin(ctx, login)
 	if err != nil && !errors.Is(err, storageErrors.ErrNotFound) {
 		return newUser, fmt.Errorf("failed to find user by login: %w", err)
@@ -40,16 +112,19 @@ func (s *Service) RegisterUser(ctx context.Context, login string, password strin
 		return newUser, fmt.Errorf("user already exists")
 	}
 
-	hashPwd, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashPwd, err := bcrypt.GenerateFromPassword([]byte(authHash), bcrypt.DefaultCost)
 	if err != nil {
 		return newUser, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	newUser = &domain.User{
-		Login:     login,
-		Password:  string(hashPwd),
-		CreatedAt: time.Time{},
-		UpdatedAt: time.Time{},
+		Login:      login,
+		Password:   string(hashPwd),
+		AuthScheme: domain.AuthSchemeDerived,
+		KDF:        kdf,
+		VaultKey:   vaultKey,
+		CreatedAt:  time.Time{},
+		UpdatedAt:  time.Time{},
 	}
 
 	var userID domain.UserID
@@ -81,3 +156,144 @@ func (s *Service) LoginUser(ctx context.Context, login string, password string)
 
 	return user, nil
 }
+
+// ChangePassword метод смены мастер-пароля или параметров KDF пользователя.
+// Клиент подтверждает текущий секрет аутентификации и передает новый хеш аутентификации.
+// Новый хеш, параметры KDF, ключ хранилища, зашифрованный ключом из нового пароля, и перешифрованные
+// секреты сохраняются одной транзакцией: при обрыве соединения до ее завершения продолжает действовать старый пароль.
+// Аккаунты со способом аутентификации AuthSchemePassword при этом переводятся на AuthSchemeDerived.
+// Если старый секрет не подходит, но подходит новый, смена уже была выполнена предыдущим запросом,
+// и метод возвращает сохраненные параметры, чтобы клиент мог продолжить работу с новым паролем.
+func (s *Service) ChangePassword(ctx context.Context, userID domain.UserID, oldPassword, newAuthHash string, kdf domain.KDFParams, vaultKey []byte, secrets []*domain.Secret) (*domain.User, error) {
+	if err := validateAuthHash(newAuthHash); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)) != nil {
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(newAuthHash)) == nil {
+			return user, nil
+		}
+		return nil, ErrBadCredentials
+	}
+
+	if err = validateKDF(kdf); err != nil {
+		return nil, err
+	}
+
+	if len(vaultKey) == 0 {
+		return nil, ErrInvalidVaultKey
+	}
+
+	if err = checkDuplicates(secrets); err != nil {
+		return nil, err
+	}
+
+	hashPwd, err := bcrypt.GenerateFromPassword([]byte(newAuthHash), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	oldHash := user.Password
+
+	user.Password = string(hashPwd)
+	user.AuthScheme = domain.AuthSchemeDerived
+	user.KDF = kdf
+	user.VaultKey = vaultKey
+
+	err = s.userRepository.UpdatePassword(ctx, user, oldHash, secrets)
+	if err != nil {
+		if errors.Is(err, ErrIncompleteReencryption) || errors.Is(err, ErrConcurrentUpdate) || errors.Is(err, storageErrors.ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to change password: %w", err)
+	}
+
+	return user, nil
+}
+
+// checkDuplicates проверяет, что каждый секрет передан клиентом не более одного раза
+func checkDuplicates(secrets []*domain.Secret) error {
+	seen := make(map[uint64]struct{}, len(secrets))
+	for _, secret := range secrets {
+		if _, ok := seen[secret.ID]; ok {
+			return fmt.Errorf("%w: duplicate secret id %d", ErrIncompleteReencryption, secret.ID)
+		}
+		seen[secret.ID] = struct{}{}
+	}
+
+	return nil
+}
+
+// fakeKDF формирует параметры KDF для несуществующего логина.
+// Соль, алгоритм и стоимость вычисляются из логина, поэтому повторные запросы возвращают одинаковые параметры,
+// а разные логины получают такие же наборы параметров, как у настоящих пользователей: параметры scrypt
+// или Argon2id по умолчанию либо параметры Argon2id после калибровки.
+func (s *Service) fakeKDF(login string) domain.KDFParams {
+	mac := hmac.New(sha256.New, s.saltKey)
+	mac.Write([]byte("kdf-salt:" + login))
+	sum := mac.Sum(nil)
+
+	salt, choice := sum[:domain.KDFMinSaltLength], sum[domain.KDFMinSaltLength:]
+
+	switch choice[0] % 4 {
+	case 0:
+		return domain.KDFParams{
+			Algorithm: string(domain.KDFScrypt),
+			Salt:      salt,
+			N:         fakeScryptN,
+			R:         fakeScryptR,
+			P:         fakeScryptP,
+		}
+	case 1:
+		return domain.KDFParams{
+			Algorithm:   string(domain.KDFArgon2id),
+			Salt:        salt,
+			Memory:      fakeArgon2Memory,
+			Iterations:  fakeArgon2Iterations,
+			Parallelism: fakeArgon2Parallelism,
+		}
+	default:
+		return domain.KDFParams{
+			Algorithm:   string(domain.KDFArgon2id),
+			Salt:        salt,
+			Memory:      fakeArgon2MinMemory << (choice[1] % fakeArgon2MemorySteps),
+			Iterations:  fakeArgon2MinIterations + uint32(choice[2])%(fakeArgon2MaxIterations-fakeArgon2MinIterations+1),
+			Parallelism: fakeArgon2Parallelism,
+		}
+	}
+}
+
+// validateAuthHash проверяет, что клиент передал хеш аутентификации, а не мастер-пароль
+func validateAuthHash(authHash string) error {
+	if len(authHash) != authHashLength {
+		return fmt.Errorf("%w: auth hash must be %d hex characters", ErrInvalidPassword, authHashLength)
+	}
+
+	if _, err := hex.DecodeString(authHash); err != nil {
+		return fmt.Errorf("%w: auth hash must be hex encoded", ErrInvalidPassword)
+	}
+
+	return nil
+}
+
+// validateKDF проверяет параметры KDF, переданные клиентом при регистрации или смене пароля.
+// Клиенты отклоняют параметры вне границ domain.CheckKDFParams, поэтому такие параметры не сохраняются.
+func validateKDF(kdf domain.KDFParams) error {
+	if kdf.Algorithm == string(domain.KDFLegacy) {
+		return fmt.Errorf("%w: algorithm is not set", ErrInvalidKDF)
+	}
+
+	if err := domain.CheckKDFParams(kdf); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKDF, err)
+	}
+
+	return nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/romanp1989/gophkeeper/domain"
 	storageErrors "github.com/romanp1989/gophkeeper/pkg/errors"
 	"github.com/romanp1989/gophkeeper/tests/mocks"
 	"golang.org/x/crypto/bcrypt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -17,9 +20,19 @@ func TestUserService(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockIUserRepository(ctrl)
-	svc := NewUserService(mockRepo)
+	svc := NewUserService(mockRepo, []byte("salt-key"))
 
 	ctx := context.Background()
+	testKDF := domain.KDFParams{
+		Algorithm: string(domain.KDFScrypt),
+		Salt:      []byte("0123456789abcdef"),
+		N:         32768,
+		R:         8,
+		P:         1,
+	}
+	testVaultKey := []byte("wrapped-vault-key")
+	oldAuthHash := strings.Repeat("ab", 32)
+	newAuthHash := strings.Repeat("cd", 32)
 	tests := []struct {
 		name      string
 		testFunc  func(t *testing.T)
@@ -29,9 +42,9 @@ func TestUserService(t *testing.T) {
 			name: "RegisterUser_Success",
 			testFunc: func(t *testing.T) {
 				mockRepo.EXPECT().FindByLogin(ctx, "new_user").Return(nil, storageErrors.ErrNotFound).Times(1)
-				mockRepo.EXPECT().CreateUser(ctx, gomock.Any()).Return(1, nil).Times(1)
+				mockRepo.EXPECT().CreateUser(ctx, gomock.Any()).Return(domain.UserID(1), nil).Times(1)
 
-				user, err := svc.RegisterUser(ctx, "new_user", "password123")
+				user, err := svc.RegisterUser(ctx, "new_user", oldAuthHash, testKDF, testVaultKey)
 				if err != nil {
 					t.Errorf("Expected no error, got %v", err)
 				}
@@ -45,9 +58,9 @@ func TestUserService(t *testing.T) {
 			name: "RegisterUser_Fail_Create_User",
 			testFunc: func(t *testing.T) {
 				mockRepo.EXPECT().FindByLogin(ctx, "new_user").Return(nil, storageErrors.ErrNotFound).Times(1)
-				mockRepo.EXPECT().CreateUser(ctx, gomock.Any()).Return(1, errors.New("some error")).Times(1)
+				mockRepo.EXPECT().CreateUser(ctx, gomock.Any()).Return(domain.UserID(1), errors.New("some error")).Times(1)
 
-				_, err := svc.RegisterUser(ctx, "new_user", "password123")
+				_, err := svc.RegisterUser(ctx, "new_user", oldAuthHash, testKDF, testVaultKey)
 				if err == nil || err.Error() != "failed to create user: some error" {
 					t.Errorf("Expected error 'failed to create user: some error', got %v", err)
 				}
@@ -59,7 +72,7 @@ func TestUserService(t *testing.T) {
 			testFunc: func(t *testing.T) {
 				mockRepo.EXPECT().FindByLogin(ctx, "existing_user").Return(&domain.User{Login: "existing_user"}, nil).Times(1)
 
-				_, err := svc.RegisterUser(ctx, "existing_user", "password123")
+				_, err := svc.RegisterUser(ctx, "existing_user", oldAuthHash, testKDF, testVaultKey)
 				if err == nil || err.Error() != "user already exists (existing_user)" {
 					t.Errorf("Expected error 'user already exists (existing_user)', got %v", err)
 				}
@@ -71,13 +84,46 @@ func TestUserService(t *testing.T) {
 			testFunc: func(t *testing.T) {
 				mockRepo.EXPECT().FindByLogin(ctx, "existing_user").Return(nil, errors.New("some error")).Times(1)
 
-				_, err := svc.RegisterUser(ctx, "existing_user", "password123")
+				_, err := svc.RegisterUser(ctx, "existing_user", oldAuthHash, testKDF, testVaultKey)
 				if err == nil || err.Error() != "failed to fetch user: some error" {
 					t.Errorf("Expected error 'failed to fetch user: some error', got %v", err)
 				}
 			},
 			expectErr: true,
 		},
+		{
+			name: "RegisterUser_Fail_InvalidKDF",
+			testFunc: func(t *testing.T) {
+				_, err := svc.RegisterUser(ctx, "new_user", oldAuthHash, domain.KDFParams{Algorithm: string(domain.KDFScrypt)}, testVaultKey)
+				if err == nil || !errors.Is(err, ErrInvalidKDF) {
+					t.Errorf("Expected error 'invalid kdf params', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "RegisterUser_Fail_WeakKDF",
+			testFunc: func(t *testing.T) {
+				weak := testKDF
+				weak.N = 2
+
+				_, err := svc.RegisterUser(ctx, "new_user", oldAuthHash, weak, testVaultKey)
+				if err == nil || !errors.Is(err, ErrInvalidKDF) {
+					t.Errorf("Expected error 'invalid kdf params', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "RegisterUser_Fail_NoVaultKey",
+			testFunc: func(t *testing.T) {
+				_, err := svc.RegisterUser(ctx, "new_user", oldAuthHash, testKDF, nil)
+				if err == nil || !errors.Is(err, ErrInvalidVaultKey) {
+					t.Errorf("Expected error 'invalid vault key', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
 		{
 			name: "LoginUser_Success",
 			testFunc: func(t *testing.T) {
@@ -120,6 +166,170 @@ func TestUserService(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "RegisterUser_Fail_RawPassword",
+			testFunc: func(t *testing.T) {
+				_, err := svc.RegisterUser(ctx, "new_user", "password123", testKDF, testVaultKey)
+				if !errors.Is(err, ErrInvalidPassword) {
+					t.Errorf("Expected error 'invalid password', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "PreLogin_Success",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().FindByLogin(ctx, "valid_user").Return(&domain.User{Login: "valid_user", KDF: testKDF}, nil).Times(1)
+
+				kdf, scheme, err := svc.PreLogin(ctx, "valid_user")
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if !reflect.DeepEqual(kdf, testKDF) {
+					t.Errorf("Expected stored kdf params, got %+v", kdf)
+				}
+				if scheme != domain.AuthSchemePassword {
+					t.Errorf("Expected password auth scheme, got %q", scheme)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "PreLogin_UnknownUser",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().FindByLogin(ctx, "unknown_user").Return(nil, storageErrors.ErrNotFound).Times(2)
+				mockRepo.EXPECT().FindByLogin(ctx, "other_user").Return(nil, storageErrors.ErrNotFound).Times(1)
+
+				first, scheme, err := svc.PreLogin(ctx, "unknown_user")
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				second, _, _ := svc.PreLogin(ctx, "unknown_user")
+				other, _, _ := svc.PreLogin(ctx, "other_user")
+
+				if scheme != domain.AuthSchemeDerived || domain.CheckKDFParams(first) != nil || len(first.Salt) != domain.KDFMinSaltLength {
+					t.Errorf("Unexpected fake params: %+v, %q", first, scheme)
+				}
+				if !reflect.DeepEqual(first, second) {
+					t.Errorf("Expected deterministic fake params, got %+v and %+v", first, second)
+				}
+				if reflect.DeepEqual(first.Salt, other.Salt) {
+					t.Errorf("Expected different salts for different logins")
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "PreLogin_Fail_Fetch_User",
+			testFunc: func(t *testing.T) {
+				mockRepo.EXPECT().FindByLogin(ctx, "valid_user").Return(nil, errors.New("some error")).Times(1)
+
+				_, _, err := svc.PreLogin(ctx, "valid_user")
+				if err == nil || err.Error() != "failed to find user by login: some error" {
+					t.Errorf("Expected error 'failed to find user by login: some error', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "ChangePassword_Success",
+			testFunc: func(t *testing.T) {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.MinCost)
+				mockRepo.EXPECT().FindByID(ctx, domain.UserID(1)).Return(&domain.User{ID: 1, Password: string(hashedPassword)}, nil).Times(1)
+				mockRepo.EXPECT().UpdatePassword(ctx, gomock.Any(), string(hashedPassword), gomock.Nil()).Return(nil).Times(1)
+
+				user, err := svc.ChangePassword(ctx, 1, "old_password", newAuthHash, testKDF, testVaultKey, nil)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(newAuthHash)) != nil {
+					t.Errorf("Expected password hash to match new auth hash")
+				}
+				if user.AuthScheme != domain.AuthSchemeDerived || string(user.VaultKey) != string(testVaultKey) {
+					t.Errorf("Unexpected user after password change: %+v", user)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "ChangePassword_AlreadyChanged",
+			testFunc: func(t *testing.T) {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(newAuthHash), bcrypt.MinCost)
+				stored := &domain.User{ID: 1, Password: string(hashedPassword), KDF: testKDF, VaultKey: []byte("stored-vault-key")}
+				mockRepo.EXPECT().FindByID(ctx, domain.UserID(1)).Return(stored, nil).Times(1)
+
+				user, err := svc.ChangePassword(ctx, 1, oldAuthHash, newAuthHash, testKDF, testVaultKey, nil)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if string(user.VaultKey) != "stored-vault-key" {
+					t.Errorf("Expected stored vault key, got %q", user.VaultKey)
+				}
+			},
+			expectErr: false,
+		},
+		{
+			name: "ChangePassword_Fail_WrongPassword",
+			testFunc: func(t *testing.T) {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.MinCost)
+				mockRepo.EXPECT().FindByID(ctx, domain.UserID(1)).Return(&domain.User{ID: 1, Password: string(hashedPassword)}, nil).Times(1)
+
+				_, err := svc.ChangePassword(ctx, 1, "wrong_password", newAuthHash, testKDF, testVaultKey, nil)
+				if !errors.Is(err, ErrBadCredentials) {
+					t.Errorf("Expected error 'bad token credentials', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "ChangePassword_Fail_EmptyPassword",
+			testFunc: func(t *testing.T) {
+				_, err := svc.ChangePassword(ctx, 1, "old_password", "", testKDF, testVaultKey, nil)
+				if !errors.Is(err, ErrInvalidPassword) {
+					t.Errorf("Expected error 'invalid password', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "ChangePassword_Fail_HugeKDF",
+			testFunc: func(t *testing.T) {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.MinCost)
+				mockRepo.EXPECT().FindByID(ctx, domain.UserID(1)).Return(&domain.User{ID: 1, Password: string(hashedPassword)}, nil).Times(1)
+
+				huge := domain.KDFParams{Algorithm: string(domain.KDFArgon2id), Salt: testKDF.Salt, Memory: 64 * 1024 * 1024, Iterations: 3, Parallelism: 4}
+
+				_, err := svc.ChangePassword(ctx, 1, "old_password", newAuthHash, huge, testVaultKey, nil)
+				if err == nil || !errors.Is(err, ErrInvalidKDF) {
+					t.Errorf("Expected error 'invalid kdf params', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "ChangePassword_Fail_DuplicateSecret",
+			testFunc: func(t *testing.T) {
+				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.MinCost)
+				mockRepo.EXPECT().FindByID(ctx, domain.UserID(1)).Return(&domain.User{ID: 1, Password: string(hashedPassword)}, nil).Times(1)
+
+				secrets := []*domain.Secret{{ID: 1, Payload: []byte("payload1")}, {ID: 1, Payload: []byte("payload2")}}
+				_, err := svc.ChangePassword(ctx, 1, "old_password", newAuthHash, testKDF, testVaultKey, secrets)
+				if !errors.Is(err, ErrIncompleteReencryption) {
+					t.Errorf("Expected error 'not all secrets are re-encrypted', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "ChangePassword_Fail_RawPassword",
+			testFunc: func(t *testing.T) {
+				_, err := svc.ChangePassword(ctx, 1, "old_password", "new_password", testKDF, testVaultKey, nil)
+				if !errors.Is(err, ErrInvalidPassword) {
+					t.Errorf("Expected error 'invalid password', got %v", err)
+				}
+			},
+			expectErr: true,
+		},
 		{
 			name: "LoginUser_Fail_UserNotFound",
 			testFunc: func(t *testing.T) {
@@ -138,3 +348,29 @@ func TestUserService(t *testing.T) {
 		t.Run(tc.name, tc.testFunc)
 	}
 }
+
+func TestService_FakeKDF(t *testing.T) {
+	svc := NewUserService(nil, []byte("salt-key"))
+
+	algorithms := make(map[string]int)
+	memory := make(map[uint32]struct{})
+
+	for i := 0; i < 200; i++ {
+		kdf := svc.fakeKDF(fmt.Sprintf("user-%d", i))
+		if err := domain.CheckKDFParams(kdf); err != nil {
+			t.Fatalf("fakeKDF() returned invalid params %+v: %v", kdf, err)
+		}
+
+		algorithms[kdf.Algorithm]++
+		if kdf.Algorithm == string(domain.KDFArgon2id) {
+			memory[kdf.Memory] = struct{}{}
+		}
+	}
+
+	if algorithms[string(domain.KDFScrypt)] == 0 || algorithms[string(domain.KDFArgon2id)] == 0 {
+		t.Errorf("fakeKDF() algorithms = %v, want both scrypt and argon2id", algorithms)
+	}
+	if len(memory) < 2 {
+		t.Errorf("fakeKDF() returned the same argon2id memory for all logins")
+	}
+}
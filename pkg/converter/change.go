@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ChangesToProto конвертирует объект модели данных SecretChanges в ответ ListChanges protobuf
+func ChangesToProto(changes *domain.SecretChanges) *proto.ListChangesResponse {
+	tombstones := make([]*proto.SecretTombstone, 0, len(changes.Tombstones))
+	for _, t := range changes.Tombstones {
+		tombstones = append(tombstones, &proto.SecretTombstone{
+			Id:        t.ID,
+			Revision:  t.Revision,
+			DeletedAt: timestamppb.New(t.DeletedAt),
+		})
+	}
+
+	return &proto.ListChangesResponse{
+		Secrets:    SecretsToProto(changes.Secrets),
+		Tombstones: tombstones,
+		Revision:   changes.Revision,
+		HasMore:    changes.More,
+	}
+}
+
+// ProtoToChanges конвертирует ответ ListChanges protobuf в объект модели данных SecretChanges
+func ProtoToChanges(pbChanges *proto.ListChangesResponse) *domain.SecretChanges {
+	tombstones := make([]*domain.SecretTombstone, 0, len(pbChanges.GetTombstones()))
+	for _, t := range pbChanges.GetTombstones() {
+		tombstones = append(tombstones, &domain.SecretTombstone{
+			ID:        t.GetId(),
+			Revision:  t.GetRevision(),
+			DeletedAt: t.GetDeletedAt().AsTime(),
+		})
+	}
+
+	return &domain.SecretChanges{
+		Secrets:    ProtoToSecrets(pbChanges.GetSecrets()),
+		Tombstones: tombstones,
+		Revision:   pbChanges.GetRevision(),
+		More:       pbChanges.GetHasMore(),
+	}
+}
+
+// EventToProto конвертирует объект модели данных SecretEvent в объект SecretEvent protobuf
+func EventToProto(event domain.SecretEvent) *proto.SecretEvent {
+	pbType := proto.SecretEventType_SECRET_EVENT_TYPE_UNSPECIFIED
+	switch event.Type {
+	case domain.SecretCreated:
+		pbType = proto.SecretEventType_SECRET_EVENT_TYPE_CREATED
+	case domain.SecretUpdated:
+		pbType = proto.SecretEventType_SECRET_EVENT_TYPE_UPDATED
+	case domain.SecretDeleted:
+		pbType = proto.SecretEventType_SECRET_EVENT_TYPE_DELETED
+	}
+
+	return &proto.SecretEvent{Type: pbType, SecretId: event.SecretID}
+}
+
+// ProtoToEvent конвертирует объект protobuf SecretEvent в объект модели данных SecretEvent
+func ProtoToEvent(pbEvent *proto.SecretEvent) domain.SecretEvent {
+	event := domain.SecretEvent{SecretID: pbEvent.GetSecretId()}
+	switch pbEvent.GetType() {
+	case proto.SecretEventType_SECRET_EVENT_TYPE_CREATED:
+		event.Type = domain.SecretCreated
+	case proto.SecretEventType_SECRET_EVENT_TYPE_UPDATED:
+		event.Type = domain.SecretUpdated
+	case proto.SecretEventType_SECRET_EVENT_TYPE_DELETED:
+		event.Type = domain.SecretDeleted
+	}
+
+	return event
+}
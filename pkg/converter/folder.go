@@ -0,0 +1,47 @@
+package converter
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FolderToProto конвертирует объект модели данных Folder в объект protobuf Folder
+func FolderToProto(folder *domain.Folder) *proto.Folder {
+	return &proto.Folder{
+		Id:        folder.ID,
+		ParentId:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: timestamppb.New(folder.CreatedAt),
+		UpdatedAt: timestamppb.New(folder.UpdatedAt),
+	}
+}
+
+// ProtoToFolder конвертирует объект protobuf Folder в объект Folder модели данных
+func ProtoToFolder(pbFolder *proto.Folder) *domain.Folder {
+	return &domain.Folder{
+		ID:        pbFolder.Id,
+		ParentID:  pbFolder.ParentId,
+		Name:      pbFolder.Name,
+		CreatedAt: pbFolder.CreatedAt.AsTime(),
+		UpdatedAt: pbFolder.UpdatedAt.AsTime(),
+	}
+}
+
+// FoldersToProto конвертирует список папок модели данных в список папок protobuf
+func FoldersToProto(folders []*domain.Folder) []*proto.Folder {
+	var pbFolders []*proto.Folder
+	for _, f := range folders {
+		pbFolders = append(pbFolders, FolderToProto(f))
+	}
+	return pbFolders
+}
+
+// ProtoToFolders конвертирует список папок protobuf в список папок модели данных
+func ProtoToFolders(pbFolders []*proto.Folder) []*domain.Folder {
+	var folders []*domain.Folder
+	for _, f := range pbFolders {
+		folders = append(folders, ProtoToFolder(f))
+	}
+	return folders
+}
@@ -0,0 +1,185 @@
+package converter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// ErrInvalidCursor указывает, что позиция в списке секретов повреждена или получена не от сервера
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor кодирует позицию в списке секретов в непрозрачную строку; для nil возвращает пустую строку
+func EncodeCursor(cursor *domain.SecretCursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor декодирует позицию в списке секретов, полученную от EncodeCursor; для пустой строки возвращает nil
+func DecodeCursor(raw string) (*domain.SecretCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var cursor domain.SecretCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return &cursor, nil
+}
+
+// SortToProto конвертирует объект модели данных SecretSort в объект protobuf SecretSortKey
+func SortToProto(sort domain.SecretSort) proto.SecretSortKey {
+	switch sort {
+	case domain.SortByUpdatedAt:
+		return proto.SecretSortKey_SECRET_SORT_KEY_UPDATED_AT
+	case domain.SortByCreatedAt:
+		return proto.SecretSortKey_SECRET_SORT_KEY_CREATED_AT
+	case domain.SortByTitle:
+		return proto.SecretSortKey_SECRET_SORT_KEY_TITLE
+	default:
+		return proto.SecretSortKey_SECRET_SORT_KEY_UNSPECIFIED
+	}
+}
+
+// ProtoToSort конвертирует объект protobuf SecretSortKey в объект модели данных SecretSort.
+// Для неуказанного ключа возвращает пустую строку.
+func ProtoToSort(pbSort proto.SecretSortKey) domain.SecretSort {
+	switch pbSort {
+	case proto.SecretSortKey_SECRET_SORT_KEY_UPDATED_AT:
+		return domain.SortByUpdatedAt
+	case proto.SecretSortKey_SECRET_SORT_KEY_CREATED_AT:
+		return domain.SortByCreatedAt
+	case proto.SecretSortKey_SECRET_SORT_KEY_TITLE:
+		return domain.SortByTitle
+	default:
+		return ""
+	}
+}
+
+// QueryToProto конвертирует объект модели данных SecretQuery в запрос ListSecrets protobuf
+func QueryToProto(query *domain.SecretQuery) *proto.ListSecretsRequest {
+	types := make([]proto.SecretType, 0, len(query.Types))
+	for _, t := range query.Types {
+		types = append(types, TypeToProto(string(t)))
+	}
+
+	return &proto.ListSecretsRequest{
+		PageSize:     uint32(max(query.Limit, 0)),
+		Cursor:       EncodeCursor(query.After),
+		Types:        types,
+		Tags:         query.Tags,
+		CreatedFrom:  timeToProto(query.CreatedFrom),
+		CreatedTo:    timeToProto(query.CreatedTo),
+		UpdatedFrom:  timeToProto(query.UpdatedFrom),
+		UpdatedTo:    timeToProto(query.UpdatedTo),
+		Sort:         SortToProto(query.Sort),
+		Descending:   query.Descending,
+		MetadataOnly: query.MetadataOnly,
+	}
+}
+
+// ProtoToQuery конвертирует запрос ListSecrets protobuf в объект модели данных SecretQuery.
+// Возвращает ErrInvalidCursor, если позицию в списке не удалось декодировать.
+func ProtoToQuery(pbQuery *proto.ListSecretsRequest) (*domain.SecretQuery, error) {
+	after, err := DecodeCursor(pbQuery.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]domain.SecretType, 0, len(pbQuery.GetTypes()))
+	for _, t := range pbQuery.GetTypes() {
+		types = append(types, ProtoToType(t))
+	}
+
+	return &domain.SecretQuery{
+		Types:        types,
+		Tags:         pbQuery.GetTags(),
+		CreatedFrom:  protoToTime(pbQuery.GetCreatedFrom()),
+		CreatedTo:    protoToTime(pbQuery.GetCreatedTo()),
+		UpdatedFrom:  protoToTime(pbQuery.GetUpdatedFrom()),
+		UpdatedTo:    protoToTime(pbQuery.GetUpdatedTo()),
+		Sort:         ProtoToSort(pbQuery.GetSort()),
+		Descending:   pbQuery.GetDescending(),
+		Limit:        int(pbQuery.GetPageSize()),
+		After:        after,
+		MetadataOnly: pbQuery.GetMetadataOnly(),
+	}, nil
+}
+
+// SearchToProto конвертирует объект модели данных SecretQuery в запрос SearchSecrets protobuf.
+// Передаются только токены поиска, размер страницы и позиция: остальные параметры выборки поиск не поддерживает.
+func SearchToProto(query *domain.SecretQuery) *proto.SearchSecretsRequest {
+	return &proto.SearchSecretsRequest{
+		Tokens:   query.SearchTokens,
+		PageSize: uint32(max(query.Limit, 0)),
+		Cursor:   EncodeCursor(query.After),
+	}
+}
+
+// ProtoToSearch конвертирует запрос SearchSecrets protobuf в объект модели данных SecretQuery.
+// Возвращает ErrInvalidCursor, если позицию в списке не удалось декодировать.
+func ProtoToSearch(pbQuery *proto.SearchSecretsRequest) (*domain.SecretQuery, error) {
+	after, err := DecodeCursor(pbQuery.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SecretQuery{
+		SearchTokens: pbQuery.GetTokens(),
+		Limit:        int(pbQuery.GetPageSize()),
+		After:        after,
+	}, nil
+}
+
+// PageToProto конвертирует объект модели данных SecretPage в ответ ListSecrets protobuf
+func PageToProto(page *domain.SecretPage) *proto.ListSecretsResponse {
+	return &proto.ListSecretsResponse{
+		Secrets:    SecretsToProto(page.Secrets),
+		NextCursor: EncodeCursor(page.Next),
+	}
+}
+
+// ProtoToPage конвертирует ответ ListSecrets protobuf в объект модели данных SecretPage
+func ProtoToPage(pbPage *proto.ListSecretsResponse) (*domain.SecretPage, error) {
+	next, err := DecodeCursor(pbPage.GetNextCursor())
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SecretPage{
+		Secrets: ProtoToSecrets(pbPage.GetSecrets()),
+		Next:    next,
+	}, nil
+}
+
+// timeToProto конвертирует границу интервала времени; для нулевого времени возвращает nil
+func timeToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// protoToTime конвертирует границу интервала времени; для отсутствующей границы возвращает нулевое время
+func protoToTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/romanp1989/gophkeeper/domain"
 	"github.com/romanp1989/gophkeeper/pkg/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 // ProtoToType конвертирует объект protobuf SecretType в объект модели данных SecretType
@@ -41,29 +42,61 @@ func TypeToProto(sType string) proto.SecretType {
 // SecretToProto конвертирует объект модели данных Secret в объект protobuf Secret
 func SecretToProto(secret *domain.Secret) *proto.Secret {
 	return &proto.Secret{
-		Id:         secret.ID,
-		Title:      secret.Title,
-		Metadata:   secret.Metadata,
-		Payload:    secret.Payload,
-		SecretType: TypeToProto(secret.SecretType),
-		CreatedAt:  timestamppb.New(secret.CreatedAt),
-		UpdatedAt:  timestamppb.New(secret.UpdatedAt),
+		Id:           secret.ID,
+		Title:        secret.Title,
+		Metadata:     secret.Metadata,
+		Payload:      secret.Payload,
+		DataKey:      secret.DataKey,
+		SecretType:   TypeToProto(secret.SecretType),
+		CreatedAt:    timestamppb.New(secret.CreatedAt),
+		UpdatedAt:    timestamppb.New(secret.UpdatedAt),
+		DeletedAt:    deletedAtToProto(secret.DeletedAt),
+		Revision:     secret.Revision,
+		PayloadSize:  secret.PayloadSize,
+		ExpiresAt:    timeToProto(secret.ExpiresAt),
+		FolderId:     secret.FolderID,
+		Tags:         secret.Tags,
+		SearchTokens: secret.SearchTokens,
 	}
 }
 
 // ProtoToSecret конвертирует объект protobuf Secret в объект Secret модели данных
 func ProtoToSecret(pbSecret *proto.Secret) *domain.Secret {
 	return &domain.Secret{
-		ID:         pbSecret.Id,
-		Title:      pbSecret.Title,
-		Metadata:   pbSecret.Metadata,
-		SecretType: string(ProtoToType(pbSecret.SecretType)),
-		Payload:    pbSecret.Payload,
-		CreatedAt:  pbSecret.CreatedAt.AsTime(),
-		UpdatedAt:  pbSecret.UpdatedAt.AsTime(),
+		ID:           pbSecret.Id,
+		Title:        pbSecret.Title,
+		Metadata:     pbSecret.Metadata,
+		SecretType:   string(ProtoToType(pbSecret.SecretType)),
+		Payload:      pbSecret.Payload,
+		DataKey:      pbSecret.DataKey,
+		CreatedAt:    pbSecret.CreatedAt.AsTime(),
+		UpdatedAt:    pbSecret.UpdatedAt.AsTime(),
+		DeletedAt:    protoToDeletedAt(pbSecret.DeletedAt),
+		Revision:     pbSecret.Revision,
+		PayloadSize:  pbSecret.PayloadSize,
+		ExpiresAt:    protoToTime(pbSecret.ExpiresAt),
+		FolderID:     pbSecret.FolderId,
+		Tags:         pbSecret.Tags,
+		SearchTokens: pbSecret.SearchTokens,
 	}
 }
 
+// deletedAtToProto конвертирует время удаления секрета; для секретов вне корзины возвращает nil
+func deletedAtToProto(deletedAt time.Time) *timestamppb.Timestamp {
+	if deletedAt.IsZero() {
+		return nil
+	}
+	return timestamppb.New(deletedAt)
+}
+
+// protoToDeletedAt конвертирует время удаления секрета; для секретов вне корзины возвращает нулевое время
+func protoToDeletedAt(deletedAt *timestamppb.Timestamp) time.Time {
+	if deletedAt == nil {
+		return time.Time{}
+	}
+	return deletedAt.AsTime()
+}
+
 // ProtoToSecrets конвертирует список объекто protobuf Secret в список объектов Secret модели данных
 func ProtoToSecrets(pbSecrets []*proto.Secret) []*domain.Secret {
 	var secrets []*domain.Secret
@@ -0,0 +1,26 @@
+package converter
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+)
+
+// UploadSessionToProto конвертирует объект модели данных UploadSession в объект protobuf UploadSession
+func UploadSessionToProto(session *domain.UploadSession) *proto.UploadSession {
+	return &proto.UploadSession{
+		Id:       session.ID,
+		SecretId: session.SecretID,
+		Size:     uint64(session.Size),
+		Offset:   uint64(session.Received),
+	}
+}
+
+// ProtoToUploadSession конвертирует объект protobuf UploadSession в объект UploadSession модели данных
+func ProtoToUploadSession(pbSession *proto.UploadSession) *domain.UploadSession {
+	return &domain.UploadSession{
+		ID:       pbSession.GetId(),
+		SecretID: pbSession.GetSecretId(),
+		Size:     int64(pbSession.GetSize()),
+		Received: int64(pbSession.GetOffset()),
+	}
+}
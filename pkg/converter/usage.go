@@ -0,0 +1,32 @@
+package converter
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+)
+
+// UsageToProto конвертирует объект модели данных Usage в ответ GetUsage protobuf
+func UsageToProto(usage *domain.Usage) *proto.GetUsageResponse {
+	return &proto.GetUsageResponse{
+		Secrets: uint64(usage.Secrets),
+		Bytes:   uint64(usage.Bytes),
+		Quota: &proto.StorageQuota{
+			MaxSecrets:     uint64(usage.Quota.MaxSecrets),
+			MaxBytes:       uint64(usage.Quota.MaxBytes),
+			MaxPayloadSize: uint64(usage.Quota.MaxPayloadSize),
+		},
+	}
+}
+
+// ProtoToUsage конвертирует ответ GetUsage protobuf в объект Usage модели данных
+func ProtoToUsage(pbUsage *proto.GetUsageResponse) *domain.Usage {
+	return &domain.Usage{
+		Secrets: int64(pbUsage.GetSecrets()),
+		Bytes:   int64(pbUsage.GetBytes()),
+		Quota: domain.Quota{
+			MaxSecrets:     int64(pbUsage.GetQuota().GetMaxSecrets()),
+			MaxBytes:       int64(pbUsage.GetQuota().GetMaxBytes()),
+			MaxPayloadSize: int64(pbUsage.GetQuota().GetMaxPayloadSize()),
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package converter
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+)
+
+// KDFToProto конвертирует параметры KDF модели данных в объект protobuf KDFParams
+func KDFToProto(kdf domain.KDFParams) *proto.KDFParams {
+	return &proto.KDFParams{
+		Algorithm:   kdf.Algorithm,
+		Salt:        kdf.Salt,
+		N:           kdf.N,
+		R:           kdf.R,
+		P:           kdf.P,
+		Memory:      kdf.Memory,
+		Iterations:  kdf.Iterations,
+		Parallelism: kdf.Parallelism,
+	}
+}
+
+// ProtoToKDF конвертирует объект protobuf KDFParams в параметры KDF модели данных
+func ProtoToKDF(pbKDF *proto.KDFParams) domain.KDFParams {
+	if pbKDF == nil {
+		return domain.KDFParams{}
+	}
+
+	return domain.KDFParams{
+		Algorithm:   pbKDF.Algorithm,
+		Salt:        pbKDF.Salt,
+		N:           pbKDF.N,
+		R:           pbKDF.R,
+		P:           pbKDF.P,
+		Memory:      pbKDF.Memory,
+		Iterations:  pbKDF.Iterations,
+		Parallelism: pbKDF.Parallelism,
+	}
+}
@@ -0,0 +1,24 @@
+package converter
+
+import (
+	"github.com/romanp1989/gophkeeper/domain"
+	"github.com/romanp1989/gophkeeper/pkg/proto"
+)
+
+// VersionsToProto конвертирует список объектов модели данных SecretVersion в список объектов SecretVersion protobuf
+func VersionsToProto(versions []*domain.SecretVersion) []*proto.SecretVersion {
+	pbVersions := make([]*proto.SecretVersion, 0, len(versions))
+	for _, v := range versions {
+		pbVersions = append(pbVersions, &proto.SecretVersion{Id: v.ID, Secret: SecretToProto(v.Secret)})
+	}
+	return pbVersions
+}
+
+// ProtoToVersions конвертирует список объектов protobuf SecretVersion в список объектов SecretVersion модели данных
+func ProtoToVersions(pbVersions []*proto.SecretVersion) []*domain.SecretVersion {
+	versions := make([]*domain.SecretVersion, 0, len(pbVersions))
+	for _, v := range pbVersions {
+		versions = append(versions, &domain.SecretVersion{ID: v.GetId(), Secret: ProtoToSecret(v.GetSecret())})
+	}
+	return versions
+}
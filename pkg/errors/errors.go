@@ -4,4 +4,6 @@ import "errors"
 
 var (
 	ErrNotFound = errors.New("not found")
+	// ErrQuotaExceeded указывает, что изменение превысило бы ограничения хранилища пользователя
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
 )
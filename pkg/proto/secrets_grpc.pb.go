@@ -20,10 +20,28 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Secrets_GetUserSecret_FullMethodName    = "/proto.Secrets/GetUserSecret"
-	Secrets_GetUserSecrets_FullMethodName   = "/proto.Secrets/GetUserSecrets"
-	Secrets_SaveUserSecret_FullMethodName   = "/proto.Secrets/SaveUserSecret"
-	Secrets_DeleteUserSecret_FullMethodName = "/proto.Secrets/DeleteUserSecret"
+	Secrets_GetUserSecret_FullMethodName        = "/proto.Secrets/GetUserSecret"
+	Secrets_GetUserSecrets_FullMethodName       = "/proto.Secrets/GetUserSecrets"
+	Secrets_ListSecrets_FullMethodName          = "/proto.Secrets/ListSecrets"
+	Secrets_SearchSecrets_FullMethodName        = "/proto.Secrets/SearchSecrets"
+	Secrets_SaveUserSecret_FullMethodName       = "/proto.Secrets/SaveUserSecret"
+	Secrets_DeleteUserSecret_FullMethodName     = "/proto.Secrets/DeleteUserSecret"
+	Secrets_BatchSaveSecrets_FullMethodName     = "/proto.Secrets/BatchSaveSecrets"
+	Secrets_BatchDeleteSecrets_FullMethodName   = "/proto.Secrets/BatchDeleteSecrets"
+	Secrets_GetUsage_FullMethodName             = "/proto.Secrets/GetUsage"
+	Secrets_ListTrash_FullMethodName            = "/proto.Secrets/ListTrash"
+	Secrets_ListPlaintextFields_FullMethodName  = "/proto.Secrets/ListPlaintextFields"
+	Secrets_SealPlaintextFields_FullMethodName  = "/proto.Secrets/SealPlaintextFields"
+	Secrets_RestoreSecret_FullMethodName        = "/proto.Secrets/RestoreSecret"
+	Secrets_PurgeSecret_FullMethodName          = "/proto.Secrets/PurgeSecret"
+	Secrets_ListChanges_FullMethodName          = "/proto.Secrets/ListChanges"
+	Secrets_WatchSecrets_FullMethodName         = "/proto.Secrets/WatchSecrets"
+	Secrets_ListSecretVersions_FullMethodName   = "/proto.Secrets/ListSecretVersions"
+	Secrets_RestoreSecretVersion_FullMethodName = "/proto.Secrets/RestoreSecretVersion"
+	Secrets_CreateUploadSession_FullMethodName  = "/proto.Secrets/CreateUploadSession"
+	Secrets_GetUploadSession_FullMethodName     = "/proto.Secrets/GetUploadSession"
+	Secrets_UploadBlob_FullMethodName           = "/proto.Secrets/UploadBlob"
+	Secrets_DownloadBlob_FullMethodName         = "/proto.Secrets/DownloadBlob"
 )
 
 // SecretsClient is the client API for Secrets service.
@@ -32,8 +50,26 @@ const (
 type SecretsClient interface {
 	GetUserSecret(ctx context.Context, in *GetUserSecretRequest, opts ...grpc.CallOption) (*GetUserSecretResponse, error)
 	GetUserSecrets(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*GetUserSecretsResponse, error)
-	SaveUserSecret(ctx context.Context, in *SaveUserSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListSecrets(ctx context.Context, in *ListSecretsRequest, opts ...grpc.CallOption) (*ListSecretsResponse, error)
+	SearchSecrets(ctx context.Context, in *SearchSecretsRequest, opts ...grpc.CallOption) (*ListSecretsResponse, error)
+	SaveUserSecret(ctx context.Context, in *SaveUserSecretRequest, opts ...grpc.CallOption) (*SaveUserSecretResponse, error)
 	DeleteUserSecret(ctx context.Context, in *DeleteUserSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	BatchSaveSecrets(ctx context.Context, in *BatchSaveSecretsRequest, opts ...grpc.CallOption) (*BatchSaveSecretsResponse, error)
+	BatchDeleteSecrets(ctx context.Context, in *BatchDeleteSecretsRequest, opts ...grpc.CallOption) (*BatchDeleteSecretsResponse, error)
+	GetUsage(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*GetUsageResponse, error)
+	ListTrash(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	ListPlaintextFields(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListPlaintextFieldsResponse, error)
+	SealPlaintextFields(ctx context.Context, in *SealPlaintextFieldsRequest, opts ...grpc.CallOption) (*SealPlaintextFieldsResponse, error)
+	RestoreSecret(ctx context.Context, in *RestoreSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	PurgeSecret(ctx context.Context, in *PurgeSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	ListChanges(ctx context.Context, in *ListChangesRequest, opts ...grpc.CallOption) (*ListChangesResponse, error)
+	WatchSecrets(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SecretEvent], error)
+	ListSecretVersions(ctx context.Context, in *ListSecretVersionsRequest, opts ...grpc.CallOption) (*ListSecretVersionsResponse, error)
+	RestoreSecretVersion(ctx context.Context, in *RestoreSecretVersionRequest, opts ...grpc.CallOption) (*RestoreSecretVersionResponse, error)
+	CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*CreateUploadSessionResponse, error)
+	GetUploadSession(ctx context.Context, in *GetUploadSessionRequest, opts ...grpc.CallOption) (*GetUploadSessionResponse, error)
+	UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadBlobRequest, UploadBlobResponse], error)
+	DownloadBlob(ctx context.Context, in *DownloadBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadBlobResponse], error)
 }
 
 type secretsClient struct {
@@ -64,9 +100,29 @@ func (c *secretsClient) GetUserSecrets(ctx context.Context, in *empty.Empty, opt
 	return out, nil
 }
 
-func (c *secretsClient) SaveUserSecret(ctx context.Context, in *SaveUserSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+func (c *secretsClient) ListSecrets(ctx context.Context, in *ListSecretsRequest, opts ...grpc.CallOption) (*ListSecretsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(empty.Empty)
+	out := new(ListSecretsResponse)
+	err := c.cc.Invoke(ctx, Secrets_ListSecrets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) SearchSecrets(ctx context.Context, in *SearchSecretsRequest, opts ...grpc.CallOption) (*ListSecretsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSecretsResponse)
+	err := c.cc.Invoke(ctx, Secrets_SearchSecrets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) SaveUserSecret(ctx context.Context, in *SaveUserSecretRequest, opts ...grpc.CallOption) (*SaveUserSecretResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveUserSecretResponse)
 	err := c.cc.Invoke(ctx, Secrets_SaveUserSecret_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -84,14 +140,213 @@ func (c *secretsClient) DeleteUserSecret(ctx context.Context, in *DeleteUserSecr
 	return out, nil
 }
 
+func (c *secretsClient) BatchSaveSecrets(ctx context.Context, in *BatchSaveSecretsRequest, opts ...grpc.CallOption) (*BatchSaveSecretsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchSaveSecretsResponse)
+	err := c.cc.Invoke(ctx, Secrets_BatchSaveSecrets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) BatchDeleteSecrets(ctx context.Context, in *BatchDeleteSecretsRequest, opts ...grpc.CallOption) (*BatchDeleteSecretsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchDeleteSecretsResponse)
+	err := c.cc.Invoke(ctx, Secrets_BatchDeleteSecrets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) GetUsage(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, Secrets_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) ListTrash(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, Secrets_ListTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) ListPlaintextFields(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListPlaintextFieldsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlaintextFieldsResponse)
+	err := c.cc.Invoke(ctx, Secrets_ListPlaintextFields_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) SealPlaintextFields(ctx context.Context, in *SealPlaintextFieldsRequest, opts ...grpc.CallOption) (*SealPlaintextFieldsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SealPlaintextFieldsResponse)
+	err := c.cc.Invoke(ctx, Secrets_SealPlaintextFields_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) RestoreSecret(ctx context.Context, in *RestoreSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, Secrets_RestoreSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) PurgeSecret(ctx context.Context, in *PurgeSecretRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, Secrets_PurgeSecret_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) ListChanges(ctx context.Context, in *ListChangesRequest, opts ...grpc.CallOption) (*ListChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChangesResponse)
+	err := c.cc.Invoke(ctx, Secrets_ListChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) WatchSecrets(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SecretEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Secrets_ServiceDesc.Streams[0], Secrets_WatchSecrets_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[empty.Empty, SecretEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Secrets_WatchSecretsClient = grpc.ServerStreamingClient[SecretEvent]
+
+func (c *secretsClient) ListSecretVersions(ctx context.Context, in *ListSecretVersionsRequest, opts ...grpc.CallOption) (*ListSecretVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSecretVersionsResponse)
+	err := c.cc.Invoke(ctx, Secrets_ListSecretVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) RestoreSecretVersion(ctx context.Context, in *RestoreSecretVersionRequest, opts ...grpc.CallOption) (*RestoreSecretVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreSecretVersionResponse)
+	err := c.cc.Invoke(ctx, Secrets_RestoreSecretVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*CreateUploadSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateUploadSessionResponse)
+	err := c.cc.Invoke(ctx, Secrets_CreateUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) GetUploadSession(ctx context.Context, in *GetUploadSessionRequest, opts ...grpc.CallOption) (*GetUploadSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUploadSessionResponse)
+	err := c.cc.Invoke(ctx, Secrets_GetUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadBlobRequest, UploadBlobResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Secrets_ServiceDesc.Streams[1], Secrets_UploadBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadBlobRequest, UploadBlobResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Secrets_UploadBlobClient = grpc.ClientStreamingClient[UploadBlobRequest, UploadBlobResponse]
+
+func (c *secretsClient) DownloadBlob(ctx context.Context, in *DownloadBlobRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadBlobResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Secrets_ServiceDesc.Streams[2], Secrets_DownloadBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadBlobRequest, DownloadBlobResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Secrets_DownloadBlobClient = grpc.ServerStreamingClient[DownloadBlobResponse]
+
 // SecretsServer is the server API for Secrets service.
 // All implementations must embed UnimplementedSecretsServer
 // for forward compatibility.
 type SecretsServer interface {
 	GetUserSecret(context.Context, *GetUserSecretRequest) (*GetUserSecretResponse, error)
 	GetUserSecrets(context.Context, *empty.Empty) (*GetUserSecretsResponse, error)
-	SaveUserSecret(context.Context, *SaveUserSecretRequest) (*empty.Empty, error)
+	ListSecrets(context.Context, *ListSecretsRequest) (*ListSecretsResponse, error)
+	SearchSecrets(context.Context, *SearchSecretsRequest) (*ListSecretsResponse, error)
+	SaveUserSecret(context.Context, *SaveUserSecretRequest) (*SaveUserSecretResponse, error)
 	DeleteUserSecret(context.Context, *DeleteUserSecretRequest) (*empty.Empty, error)
+	BatchSaveSecrets(context.Context, *BatchSaveSecretsRequest) (*BatchSaveSecretsResponse, error)
+	BatchDeleteSecrets(context.Context, *BatchDeleteSecretsRequest) (*BatchDeleteSecretsResponse, error)
+	GetUsage(context.Context, *empty.Empty) (*GetUsageResponse, error)
+	ListTrash(context.Context, *empty.Empty) (*ListTrashResponse, error)
+	ListPlaintextFields(context.Context, *empty.Empty) (*ListPlaintextFieldsResponse, error)
+	SealPlaintextFields(context.Context, *SealPlaintextFieldsRequest) (*SealPlaintextFieldsResponse, error)
+	RestoreSecret(context.Context, *RestoreSecretRequest) (*empty.Empty, error)
+	PurgeSecret(context.Context, *PurgeSecretRequest) (*empty.Empty, error)
+	ListChanges(context.Context, *ListChangesRequest) (*ListChangesResponse, error)
+	WatchSecrets(*empty.Empty, grpc.ServerStreamingServer[SecretEvent]) error
+	ListSecretVersions(context.Context, *ListSecretVersionsRequest) (*ListSecretVersionsResponse, error)
+	RestoreSecretVersion(context.Context, *RestoreSecretVersionRequest) (*RestoreSecretVersionResponse, error)
+	CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*CreateUploadSessionResponse, error)
+	GetUploadSession(context.Context, *GetUploadSessionRequest) (*GetUploadSessionResponse, error)
+	UploadBlob(grpc.ClientStreamingServer[UploadBlobRequest, UploadBlobResponse]) error
+	DownloadBlob(*DownloadBlobRequest, grpc.ServerStreamingServer[DownloadBlobResponse]) error
 	mustEmbedUnimplementedSecretsServer()
 }
 
@@ -108,12 +363,66 @@ func (UnimplementedSecretsServer) GetUserSecret(context.Context, *GetUserSecretR
 func (UnimplementedSecretsServer) GetUserSecrets(context.Context, *empty.Empty) (*GetUserSecretsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserSecrets not implemented")
 }
-func (UnimplementedSecretsServer) SaveUserSecret(context.Context, *SaveUserSecretRequest) (*empty.Empty, error) {
+func (UnimplementedSecretsServer) ListSecrets(context.Context, *ListSecretsRequest) (*ListSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecrets not implemented")
+}
+func (UnimplementedSecretsServer) SearchSecrets(context.Context, *SearchSecretsRequest) (*ListSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSecrets not implemented")
+}
+func (UnimplementedSecretsServer) SaveUserSecret(context.Context, *SaveUserSecretRequest) (*SaveUserSecretResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SaveUserSecret not implemented")
 }
 func (UnimplementedSecretsServer) DeleteUserSecret(context.Context, *DeleteUserSecretRequest) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserSecret not implemented")
 }
+func (UnimplementedSecretsServer) BatchSaveSecrets(context.Context, *BatchSaveSecretsRequest) (*BatchSaveSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSaveSecrets not implemented")
+}
+func (UnimplementedSecretsServer) BatchDeleteSecrets(context.Context, *BatchDeleteSecretsRequest) (*BatchDeleteSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteSecrets not implemented")
+}
+func (UnimplementedSecretsServer) GetUsage(context.Context, *empty.Empty) (*GetUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedSecretsServer) ListTrash(context.Context, *empty.Empty) (*ListTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedSecretsServer) ListPlaintextFields(context.Context, *empty.Empty) (*ListPlaintextFieldsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlaintextFields not implemented")
+}
+func (UnimplementedSecretsServer) SealPlaintextFields(context.Context, *SealPlaintextFieldsRequest) (*SealPlaintextFieldsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SealPlaintextFields not implemented")
+}
+func (UnimplementedSecretsServer) RestoreSecret(context.Context, *RestoreSecretRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecret not implemented")
+}
+func (UnimplementedSecretsServer) PurgeSecret(context.Context, *PurgeSecretRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSecret not implemented")
+}
+func (UnimplementedSecretsServer) ListChanges(context.Context, *ListChangesRequest) (*ListChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChanges not implemented")
+}
+func (UnimplementedSecretsServer) WatchSecrets(*empty.Empty, grpc.ServerStreamingServer[SecretEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSecrets not implemented")
+}
+func (UnimplementedSecretsServer) ListSecretVersions(context.Context, *ListSecretVersionsRequest) (*ListSecretVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecretVersions not implemented")
+}
+func (UnimplementedSecretsServer) RestoreSecretVersion(context.Context, *RestoreSecretVersionRequest) (*RestoreSecretVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecretVersion not implemented")
+}
+func (UnimplementedSecretsServer) CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*CreateUploadSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUploadSession not implemented")
+}
+func (UnimplementedSecretsServer) GetUploadSession(context.Context, *GetUploadSessionRequest) (*GetUploadSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadSession not implemented")
+}
+func (UnimplementedSecretsServer) UploadBlob(grpc.ClientStreamingServer[UploadBlobRequest, UploadBlobResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBlob not implemented")
+}
+func (UnimplementedSecretsServer) DownloadBlob(*DownloadBlobRequest, grpc.ServerStreamingServer[DownloadBlobResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadBlob not implemented")
+}
 func (UnimplementedSecretsServer) mustEmbedUnimplementedSecretsServer() {}
 func (UnimplementedSecretsServer) testEmbeddedByValue()                 {}
 
@@ -171,6 +480,42 @@ func _Secrets_GetUserSecrets_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Secrets_ListSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListSecrets(ctx, req.(*ListSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_SearchSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).SearchSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_SearchSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).SearchSecrets(ctx, req.(*SearchSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Secrets_SaveUserSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SaveUserSecretRequest)
 	if err := dec(in); err != nil {
@@ -207,6 +552,269 @@ func _Secrets_DeleteUserSecret_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Secrets_BatchSaveSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSaveSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).BatchSaveSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_BatchSaveSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).BatchSaveSecrets(ctx, req.(*BatchSaveSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_BatchDeleteSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).BatchDeleteSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_BatchDeleteSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).BatchDeleteSecrets(ctx, req.(*BatchDeleteSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).GetUsage(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListTrash(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_ListPlaintextFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListPlaintextFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListPlaintextFields_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListPlaintextFields(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_SealPlaintextFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SealPlaintextFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).SealPlaintextFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_SealPlaintextFields_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).SealPlaintextFields(ctx, req.(*SealPlaintextFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_RestoreSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).RestoreSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_RestoreSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).RestoreSecret(ctx, req.(*RestoreSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_PurgeSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).PurgeSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_PurgeSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).PurgeSecret(ctx, req.(*PurgeSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_ListChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListChanges(ctx, req.(*ListChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_WatchSecrets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(empty.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SecretsServer).WatchSecrets(m, &grpc.GenericServerStream[empty.Empty, SecretEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Secrets_WatchSecretsServer = grpc.ServerStreamingServer[SecretEvent]
+
+func _Secrets_ListSecretVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListSecretVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListSecretVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListSecretVersions(ctx, req.(*ListSecretVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_RestoreSecretVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).RestoreSecretVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_RestoreSecretVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).RestoreSecretVersion(ctx, req.(*RestoreSecretVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_CreateUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).CreateUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_CreateUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).CreateUploadSession(ctx, req.(*CreateUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_GetUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).GetUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_GetUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).GetUploadSession(ctx, req.(*GetUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_UploadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SecretsServer).UploadBlob(&grpc.GenericServerStream[UploadBlobRequest, UploadBlobResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Secrets_UploadBlobServer = grpc.ClientStreamingServer[UploadBlobRequest, UploadBlobResponse]
+
+func _Secrets_DownloadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadBlobRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SecretsServer).DownloadBlob(m, &grpc.GenericServerStream[DownloadBlobRequest, DownloadBlobResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Secrets_DownloadBlobServer = grpc.ServerStreamingServer[DownloadBlobResponse]
+
 // Secrets_ServiceDesc is the grpc.ServiceDesc for Secrets service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -222,6 +830,14 @@ var Secrets_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserSecrets",
 			Handler:    _Secrets_GetUserSecrets_Handler,
 		},
+		{
+			MethodName: "ListSecrets",
+			Handler:    _Secrets_ListSecrets_Handler,
+		},
+		{
+			MethodName: "SearchSecrets",
+			Handler:    _Secrets_SearchSecrets_Handler,
+		},
 		{
 			MethodName: "SaveUserSecret",
 			Handler:    _Secrets_SaveUserSecret_Handler,
@@ -230,7 +846,75 @@ var Secrets_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUserSecret",
 			Handler:    _Secrets_DeleteUserSecret_Handler,
 		},
+		{
+			MethodName: "BatchSaveSecrets",
+			Handler:    _Secrets_BatchSaveSecrets_Handler,
+		},
+		{
+			MethodName: "BatchDeleteSecrets",
+			Handler:    _Secrets_BatchDeleteSecrets_Handler,
+		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _Secrets_GetUsage_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _Secrets_ListTrash_Handler,
+		},
+		{
+			MethodName: "ListPlaintextFields",
+			Handler:    _Secrets_ListPlaintextFields_Handler,
+		},
+		{
+			MethodName: "SealPlaintextFields",
+			Handler:    _Secrets_SealPlaintextFields_Handler,
+		},
+		{
+			MethodName: "RestoreSecret",
+			Handler:    _Secrets_RestoreSecret_Handler,
+		},
+		{
+			MethodName: "PurgeSecret",
+			Handler:    _Secrets_PurgeSecret_Handler,
+		},
+		{
+			MethodName: "ListChanges",
+			Handler:    _Secrets_ListChanges_Handler,
+		},
+		{
+			MethodName: "ListSecretVersions",
+			Handler:    _Secrets_ListSecretVersions_Handler,
+		},
+		{
+			MethodName: "RestoreSecretVersion",
+			Handler:    _Secrets_RestoreSecretVersion_Handler,
+		},
+		{
+			MethodName: "CreateUploadSession",
+			Handler:    _Secrets_CreateUploadSession_Handler,
+		},
+		{
+			MethodName: "GetUploadSession",
+			Handler:    _Secrets_GetUploadSession_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSecrets",
+			Handler:       _Secrets_WatchSecrets_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadBlob",
+			Handler:       _Secrets_UploadBlob_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadBlob",
+			Handler:       _Secrets_DownloadBlob_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/secrets.proto",
 }
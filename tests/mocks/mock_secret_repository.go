@@ -6,6 +6,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,36 @@ func (m *MockISecretRepository) EXPECT() *MockISecretRepositoryMockRecorder {
 	return m.recorder
 }
 
+// BatchDelete mocks base method.
+func (m *MockISecretRepository) BatchDelete(arg0 context.Context, arg1 []uint64, arg2 domain.UserID, arg3 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockISecretRepositoryMockRecorder) BatchDelete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockISecretRepository)(nil).BatchDelete), arg0, arg1, arg2, arg3)
+}
+
+// BatchSave mocks base method.
+func (m *MockISecretRepository) BatchSave(arg0 context.Context, arg1 []*domain.Secret, arg2 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchSave", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchSave indicates an expected call of BatchSave.
+func (mr *MockISecretRepositoryMockRecorder) BatchSave(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchSave", reflect.TypeOf((*MockISecretRepository)(nil).BatchSave), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockISecretRepository) Create(arg0 context.Context, arg1 *domain.Secret) (*domain.Secret, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +125,155 @@ func (mr *MockISecretRepositoryMockRecorder) GetByID(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockISecretRepository)(nil).GetByID), arg0, arg1, arg2)
 }
 
+// List mocks base method.
+func (m *MockISecretRepository) List(arg0 context.Context, arg1 domain.UserID, arg2 *domain.SecretQuery) (*domain.SecretPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.SecretPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockISecretRepositoryMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockISecretRepository)(nil).List), arg0, arg1, arg2)
+}
+
+// ListChanges mocks base method.
+func (m *MockISecretRepository) ListChanges(arg0 context.Context, arg1 domain.UserID, arg2 uint64, arg3 bool, arg4 int) (*domain.SecretChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*domain.SecretChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanges indicates an expected call of ListChanges.
+func (mr *MockISecretRepositoryMockRecorder) ListChanges(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockISecretRepository)(nil).ListChanges), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListPlaintextFields mocks base method.
+func (m *MockISecretRepository) ListPlaintextFields(arg0 context.Context, arg1 domain.UserID, arg2 int) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPlaintextFields", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPlaintextFields indicates an expected call of ListPlaintextFields.
+func (mr *MockISecretRepositoryMockRecorder) ListPlaintextFields(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPlaintextFields", reflect.TypeOf((*MockISecretRepository)(nil).ListPlaintextFields), arg0, arg1, arg2)
+}
+
+// ListTrash mocks base method.
+func (m *MockISecretRepository) ListTrash(arg0 context.Context, arg1 domain.UserID) ([]*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockISecretRepositoryMockRecorder) ListTrash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockISecretRepository)(nil).ListTrash), arg0, arg1)
+}
+
+// ListVersions mocks base method.
+func (m *MockISecretRepository) ListVersions(arg0 context.Context, arg1 uint64, arg2 domain.UserID) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockISecretRepositoryMockRecorder) ListVersions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockISecretRepository)(nil).ListVersions), arg0, arg1, arg2)
+}
+
+// OpenByID mocks base method.
+func (m *MockISecretRepository) OpenByID(arg0 context.Context, arg1 uint64, arg2 domain.UserID) (*domain.Secret, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenByID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenByID indicates an expected call of OpenByID.
+func (mr *MockISecretRepositoryMockRecorder) OpenByID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenByID", reflect.TypeOf((*MockISecretRepository)(nil).OpenByID), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockISecretRepository) Purge(arg0 context.Context, arg1 uint64, arg2 domain.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockISecretRepositoryMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockISecretRepository)(nil).Purge), arg0, arg1, arg2)
+}
+
+// Restore mocks base method.
+func (m *MockISecretRepository) Restore(arg0 context.Context, arg1 uint64, arg2 domain.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockISecretRepositoryMockRecorder) Restore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockISecretRepository)(nil).Restore), arg0, arg1, arg2)
+}
+
+// RestoreVersion mocks base method.
+func (m *MockISecretRepository) RestoreVersion(arg0 context.Context, arg1, arg2 uint64, arg3 domain.UserID) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVersion", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVersion indicates an expected call of RestoreVersion.
+func (mr *MockISecretRepositoryMockRecorder) RestoreVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVersion", reflect.TypeOf((*MockISecretRepository)(nil).RestoreVersion), arg0, arg1, arg2, arg3)
+}
+
+// SealPlaintextFields mocks base method.
+func (m *MockISecretRepository) SealPlaintextFields(arg0 context.Context, arg1 domain.UserID, arg2 []*domain.SecretVersion) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealPlaintextFields", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SealPlaintextFields indicates an expected call of SealPlaintextFields.
+func (mr *MockISecretRepositoryMockRecorder) SealPlaintextFields(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealPlaintextFields", reflect.TypeOf((*MockISecretRepository)(nil).SealPlaintextFields), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *MockISecretRepository) Update(arg0 context.Context, arg1 *domain.Secret) (*domain.Secret, error) {
 	m.ctrl.T.Helper()
@@ -108,3 +288,32 @@ func (mr *MockISecretRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockISecretRepository)(nil).Update), arg0, arg1)
 }
+
+// UpdatePayload mocks base method.
+func (m *MockISecretRepository) UpdatePayload(arg0 context.Context, arg1 *domain.Secret, arg2 io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayload", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayload indicates an expected call of UpdatePayload.
+func (mr *MockISecretRepositoryMockRecorder) UpdatePayload(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayload", reflect.TypeOf((*MockISecretRepository)(nil).UpdatePayload), arg0, arg1, arg2)
+}
+
+// Usage mocks base method.
+func (m *MockISecretRepository) Usage(arg0 context.Context, arg1 domain.UserID, arg2 []uint64) (*domain.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockISecretRepositoryMockRecorder) Usage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockISecretRepository)(nil).Usage), arg0, arg1, arg2)
+}
@@ -6,6 +6,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -50,6 +51,36 @@ func (mr *MockISecretServiceMockRecorder) Add(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockISecretService)(nil).Add), arg0, arg1)
 }
 
+// BatchDelete mocks base method.
+func (m *MockISecretService) BatchDelete(arg0 context.Context, arg1 []uint64, arg2 domain.UserID, arg3 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDelete", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDelete indicates an expected call of BatchDelete.
+func (mr *MockISecretServiceMockRecorder) BatchDelete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDelete", reflect.TypeOf((*MockISecretService)(nil).BatchDelete), arg0, arg1, arg2, arg3)
+}
+
+// BatchSave mocks base method.
+func (m *MockISecretService) BatchSave(arg0 context.Context, arg1 []*domain.Secret, arg2 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchSave", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchSave indicates an expected call of BatchSave.
+func (mr *MockISecretServiceMockRecorder) BatchSave(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchSave", reflect.TypeOf((*MockISecretService)(nil).BatchSave), arg0, arg1, arg2)
+}
+
 // Delete mocks base method.
 func (m *MockISecretService) Delete(arg0 context.Context, arg1 uint64, arg2 domain.UserID) error {
 	m.ctrl.T.Helper()
@@ -79,6 +110,21 @@ func (mr *MockISecretServiceMockRecorder) Get(arg0, arg1, arg2 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockISecretService)(nil).Get), arg0, arg1, arg2)
 }
 
+// GetUsage mocks base method.
+func (m *MockISecretService) GetUsage(arg0 context.Context, arg1 domain.UserID) (*domain.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockISecretServiceMockRecorder) GetUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockISecretService)(nil).GetUsage), arg0, arg1)
+}
+
 // GetUserSecrets mocks base method.
 func (m *MockISecretService) GetUserSecrets(arg0 context.Context, arg1 domain.UserID) ([]*domain.Secret, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +140,185 @@ func (mr *MockISecretServiceMockRecorder) GetUserSecrets(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSecrets", reflect.TypeOf((*MockISecretService)(nil).GetUserSecrets), arg0, arg1)
 }
 
+// List mocks base method.
+func (m *MockISecretService) List(arg0 context.Context, arg1 domain.UserID, arg2 *domain.SecretQuery) (*domain.SecretPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.SecretPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockISecretServiceMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockISecretService)(nil).List), arg0, arg1, arg2)
+}
+
+// ListChanges mocks base method.
+func (m *MockISecretService) ListChanges(arg0 context.Context, arg1 domain.UserID, arg2 uint64, arg3 bool, arg4 int) (*domain.SecretChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*domain.SecretChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanges indicates an expected call of ListChanges.
+func (mr *MockISecretServiceMockRecorder) ListChanges(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockISecretService)(nil).ListChanges), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListPlaintextFields mocks base method.
+func (m *MockISecretService) ListPlaintextFields(arg0 context.Context, arg1 domain.UserID) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPlaintextFields", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPlaintextFields indicates an expected call of ListPlaintextFields.
+func (mr *MockISecretServiceMockRecorder) ListPlaintextFields(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPlaintextFields", reflect.TypeOf((*MockISecretService)(nil).ListPlaintextFields), arg0, arg1)
+}
+
+// ListTrash mocks base method.
+func (m *MockISecretService) ListTrash(arg0 context.Context, arg1 domain.UserID) ([]*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockISecretServiceMockRecorder) ListTrash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockISecretService)(nil).ListTrash), arg0, arg1)
+}
+
+// ListVersions mocks base method.
+func (m *MockISecretService) ListVersions(arg0 context.Context, arg1 uint64, arg2 domain.UserID) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockISecretServiceMockRecorder) ListVersions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockISecretService)(nil).ListVersions), arg0, arg1, arg2)
+}
+
+// OpenBlob mocks base method.
+func (m *MockISecretService) OpenBlob(arg0 context.Context, arg1 uint64, arg2 domain.UserID) (*domain.Secret, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenBlob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenBlob indicates an expected call of OpenBlob.
+func (mr *MockISecretServiceMockRecorder) OpenBlob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenBlob", reflect.TypeOf((*MockISecretService)(nil).OpenBlob), arg0, arg1, arg2)
+}
+
+// Purge mocks base method.
+func (m *MockISecretService) Purge(arg0 context.Context, arg1 uint64, arg2 domain.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockISecretServiceMockRecorder) Purge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockISecretService)(nil).Purge), arg0, arg1, arg2)
+}
+
+// Restore mocks base method.
+func (m *MockISecretService) Restore(arg0 context.Context, arg1 uint64, arg2 domain.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockISecretServiceMockRecorder) Restore(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockISecretService)(nil).Restore), arg0, arg1, arg2)
+}
+
+// RestoreVersion mocks base method.
+func (m *MockISecretService) RestoreVersion(arg0 context.Context, arg1, arg2 uint64, arg3 domain.UserID) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVersion", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVersion indicates an expected call of RestoreVersion.
+func (mr *MockISecretServiceMockRecorder) RestoreVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVersion", reflect.TypeOf((*MockISecretService)(nil).RestoreVersion), arg0, arg1, arg2, arg3)
+}
+
+// SaveBlob mocks base method.
+func (m *MockISecretService) SaveBlob(arg0 context.Context, arg1 *domain.Secret, arg2 io.Reader) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBlob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBlob indicates an expected call of SaveBlob.
+func (mr *MockISecretServiceMockRecorder) SaveBlob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBlob", reflect.TypeOf((*MockISecretService)(nil).SaveBlob), arg0, arg1, arg2)
+}
+
+// SealPlaintextFields mocks base method.
+func (m *MockISecretService) SealPlaintextFields(arg0 context.Context, arg1 domain.UserID, arg2 []*domain.SecretVersion) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SealPlaintextFields", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SealPlaintextFields indicates an expected call of SealPlaintextFields.
+func (mr *MockISecretServiceMockRecorder) SealPlaintextFields(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SealPlaintextFields", reflect.TypeOf((*MockISecretService)(nil).SealPlaintextFields), arg0, arg1, arg2)
+}
+
+// Search mocks base method.
+func (m *MockISecretService) Search(arg0 context.Context, arg1 domain.UserID, arg2 *domain.SecretQuery) (*domain.SecretPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.SecretPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockISecretServiceMockRecorder) Search(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockISecretService)(nil).Search), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *MockISecretService) Update(arg0 context.Context, arg1 *domain.Secret) (*domain.Secret, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +50,21 @@ func (mr *MockIUserRepositoryMockRecorder) CreateUser(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockIUserRepository)(nil).CreateUser), arg0, arg1)
 }
 
+// FindByID mocks base method.
+func (m *MockIUserRepository) FindByID(arg0 context.Context, arg1 domain.UserID) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", arg0, arg1)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockIUserRepositoryMockRecorder) FindByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockIUserRepository)(nil).FindByID), arg0, arg1)
+}
+
 // FindByLogin mocks base method.
 func (m *MockIUserRepository) FindByLogin(arg0 context.Context, arg1 string) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -64,3 +79,17 @@ func (mr *MockIUserRepositoryMockRecorder) FindByLogin(arg0, arg1 interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByLogin", reflect.TypeOf((*MockIUserRepository)(nil).FindByLogin), arg0, arg1)
 }
+
+// UpdatePassword mocks base method.
+func (m *MockIUserRepository) UpdatePassword(arg0 context.Context, arg1 *domain.User, arg2 string, arg3 []*domain.Secret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockIUserRepositoryMockRecorder) UpdatePassword(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockIUserRepository)(nil).UpdatePassword), arg0, arg1, arg2, arg3)
+}
@@ -35,6 +35,21 @@ func (m *MockIUserService) EXPECT() *MockIUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockIUserService) ChangePassword(arg0 context.Context, arg1 domain.UserID, arg2, arg3 string, arg4 domain.KDFParams, arg5 []byte, arg6 []*domain.Secret) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockIUserServiceMockRecorder) ChangePassword(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockIUserService)(nil).ChangePassword), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
 // LoginUser mocks base method.
 func (m *MockIUserService) LoginUser(arg0 context.Context, arg1, arg2 string) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -50,17 +65,33 @@ func (mr *MockIUserServiceMockRecorder) LoginUser(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockIUserService)(nil).LoginUser), arg0, arg1, arg2)
 }
 
+// PreLogin mocks base method.
+func (m *MockIUserService) PreLogin(arg0 context.Context, arg1 string) (domain.KDFParams, domain.AuthScheme, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreLogin", arg0, arg1)
+	ret0, _ := ret[0].(domain.KDFParams)
+	ret1, _ := ret[1].(domain.AuthScheme)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PreLogin indicates an expected call of PreLogin.
+func (mr *MockIUserServiceMockRecorder) PreLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreLogin", reflect.TypeOf((*MockIUserService)(nil).PreLogin), arg0, arg1)
+}
+
 // RegisterUser mocks base method.
-func (m *MockIUserService) RegisterUser(arg0 context.Context, arg1, arg2 string) (*domain.User, error) {
+func (m *MockIUserService) RegisterUser(arg0 context.Context, arg1, arg2 string, arg3 domain.KDFParams, arg4 []byte) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterUser", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "RegisterUser", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RegisterUser indicates an expected call of RegisterUser.
-func (mr *MockIUserServiceMockRecorder) RegisterUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockIUserServiceMockRecorder) RegisterUser(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockIUserService)(nil).RegisterUser), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockIUserService)(nil).RegisterUser), arg0, arg1, arg2, arg3, arg4)
 }